@@ -8,10 +8,14 @@ import (
 	"claudex/internal/doc"
 	"claudex/internal/hooks/notification"
 	"claudex/internal/hooks/posttooluse"
+	"claudex/internal/hooks/precompact"
 	"claudex/internal/hooks/pretooluse"
 	"claudex/internal/hooks/sessionend"
+	"claudex/internal/hooks/sessionstart"
 	"claudex/internal/hooks/shared"
+	"claudex/internal/hooks/stop"
 	"claudex/internal/hooks/subagent"
+	"claudex/internal/hooks/userpromptsubmit"
 	"claudex/internal/notify"
 	"claudex/internal/services/commander"
 	"claudex/internal/services/env"
@@ -22,7 +26,7 @@ import (
 func main() {
 	if len(os.Args) < 2 {
 		fmt.Fprintf(os.Stderr, "Usage: claudex-hooks <command>\n")
-		fmt.Fprintf(os.Stderr, "Commands: notification, pre-tool-use, post-tool-use, auto-doc, doc-update, session-end, subagent-stop\n")
+		fmt.Fprintf(os.Stderr, "Commands: notification, pre-tool-use, post-tool-use, auto-doc, doc-update, session-start, session-end, user-prompt-submit, stop, pre-compact, subagent-stop\n")
 		os.Exit(1)
 	}
 
@@ -50,11 +54,19 @@ func main() {
 	case "pre-tool-use":
 		err = handlePreToolUse(fs, environ, logger, parser, builder)
 	case "post-tool-use":
-		err = handlePostToolUse(logger, parser, builder)
+		err = handlePostToolUse(fs, environ, logger, parser, builder)
 	case "auto-doc":
 		err = handleAutoDoc(fs, cmdr, environ, logger, parser, builder)
+	case "session-start":
+		err = handleSessionStart(fs, environ, logger, parser, builder)
 	case "session-end":
 		err = handleSessionEnd(fs, cmdr, environ, logger, parser, builder)
+	case "user-prompt-submit":
+		err = handleUserPromptSubmit(fs, environ, logger, parser, builder)
+	case "stop":
+		err = handleStop(fs, environ, logger, parser)
+	case "pre-compact":
+		err = handlePreCompact(fs, cmdr, environ, logger, parser)
 	case "subagent-stop":
 		err = handleSubagentStop(fs, cmdr, environ, logger, parser, builder)
 	case "doc-update":
@@ -107,13 +119,13 @@ func handlePreToolUse(fs afero.Fs, environ env.Environment, logger *shared.Logge
 }
 
 // handlePostToolUse processes post-tool-use hook events
-func handlePostToolUse(logger *shared.Logger, parser *shared.Parser, builder *shared.Builder) error {
+func handlePostToolUse(fs afero.Fs, environ env.Environment, logger *shared.Logger, parser *shared.Parser, builder *shared.Builder) error {
 	input, err := parser.ParsePostToolUse()
 	if err != nil {
 		return err
 	}
 
-	handler := posttooluse.NewHandler(logger)
+	handler := posttooluse.NewHandler(fs, environ, logger)
 	output, err := handler.Handle(input)
 	if err != nil {
 		return err
@@ -163,6 +175,63 @@ func handleSessionEnd(fs afero.Fs, cmdr commander.Commander, environ env.Environ
 	return handler.Handle(input)
 }
 
+// handleSessionStart processes session-start hook events
+func handleSessionStart(fs afero.Fs, environ env.Environment, logger *shared.Logger, parser *shared.Parser, builder *shared.Builder) error {
+	input, err := parser.ParseSessionStart()
+	if err != nil {
+		return err
+	}
+
+	handler := sessionstart.NewHandler(fs, environ, logger)
+	output, err := handler.Handle(input)
+	if err != nil {
+		return err
+	}
+
+	return builder.BuildCustom(*output)
+}
+
+// handleUserPromptSubmit processes user-prompt-submit hook events
+func handleUserPromptSubmit(fs afero.Fs, environ env.Environment, logger *shared.Logger, parser *shared.Parser, builder *shared.Builder) error {
+	input, err := parser.ParseUserPromptSubmit()
+	if err != nil {
+		return err
+	}
+
+	handler := userpromptsubmit.NewHandler(fs, environ, logger)
+	output, err := handler.Handle(input)
+	if err != nil {
+		return err
+	}
+
+	return builder.BuildCustom(*output)
+}
+
+// handleStop processes stop hook events
+func handleStop(fs afero.Fs, environ env.Environment, logger *shared.Logger, parser *shared.Parser) error {
+	input, err := parser.ParseStop()
+	if err != nil {
+		return err
+	}
+
+	handler := stop.NewHandler(fs, environ, logger)
+	return handler.Handle(input)
+}
+
+// handlePreCompact processes pre-compact hook events
+func handlePreCompact(fs afero.Fs, cmdr commander.Commander, environ env.Environment, logger *shared.Logger, parser *shared.Parser) error {
+	input, err := parser.ParsePreCompact()
+	if err != nil {
+		return err
+	}
+
+	// Create documentation updater
+	updater := doc.NewUpdater(fs, cmdr, environ)
+
+	handler := precompact.NewHandler(fs, environ, updater, logger, precompact.Budget(environ))
+	return handler.Handle(input)
+}
+
 // handleSubagentStop processes subagent-stop hook events
 func handleSubagentStop(fs afero.Fs, cmdr commander.Commander, environ env.Environment, logger *shared.Logger, parser *shared.Parser, builder *shared.Builder) error {
 	input, err := parser.ParseSubagentStop()
@@ -23,6 +23,11 @@ var showVersion = flag.Bool("version", false, "print version and exit")
 var updateDocs = flag.Bool("update-docs", false, "update index.md files based on git changes")
 var setupMCP = flag.Bool("setup-mcp", false, "configure recommended MCP servers (sequential-thinking, context7)")
 var createIndex = flag.String("create-index", "", "create index.md file at specified directory path")
+var staged = flag.Bool("staged", false, "with --update-docs, use staged changes instead of commits")
+var worktree = flag.Bool("worktree", false, "with --update-docs, use uncommitted working tree changes instead of commits")
+var addDocs = flag.Bool("add-docs", false, "with --update-docs --staged, wait for updates and stage the regenerated docs")
+var setupHook = flag.Bool("setup-hook", false, "install the git hook that updates docs after commits")
+var preCommit = flag.Bool("pre-commit", false, "with --setup-hook, also install a pre-commit hook that adds updated docs to the commit")
 var docPaths stringSlice
 
 func init() {
@@ -30,7 +35,13 @@ func init() {
 }
 
 func main() {
-	application := app.New(Version, showVersion, noOverwrite, updateDocs, setupMCP, createIndex, docPaths)
+	application := app.New(Version, showVersion, noOverwrite, updateDocs, setupMCP, createIndex, docPaths, app.DocsFlags{
+		Staged:    staged,
+		Worktree:  worktree,
+		AddDocs:   addDocs,
+		SetupHook: setupHook,
+		PreCommit: preCommit,
+	})
 
 	if err := application.Init(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -0,0 +1,228 @@
+// Package diffcontext turns `git diff` output into compact prompt context for
+// index.md updates: the changed hunks per file, truncated to a token budget,
+// plus the exported symbols the change added or removed.
+package diffcontext
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"claudex/internal/services/tokens"
+)
+
+// DefaultTokenBudget is the approximate number of tokens of diff context
+// given to Claude per index.md when no budget is configured
+const DefaultTokenBudget = 3000
+
+// FileDiff is the diff of a single file
+type FileDiff struct {
+	// Path is the file path (new path for renames), relative to the repository root
+	Path string
+
+	// Hunks are the @@ sections of the diff, in order
+	Hunks []Hunk
+
+	// Added and Removed count the changed lines across all hunks
+	Added   int
+	Removed int
+
+	// Binary is set for binary files, which have no hunks
+	Binary bool
+}
+
+// Hunk is one @@ section of a file diff
+type Hunk struct {
+	// Header is the @@ line
+	Header string
+
+	// Lines are the hunk body lines including their +, - or space prefix
+	Lines []string
+}
+
+// Parse splits unified diff output into per-file diffs.
+// Diffs for the same path (e.g. from several commits) are merged in order.
+func Parse(diff string) []FileDiff {
+	var files []FileDiff
+	index := make(map[string]int)
+	var current *FileDiff
+	var hunk *Hunk
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		if hunk != nil {
+			current.Hunks = append(current.Hunks, *hunk)
+			hunk = nil
+		}
+		if i, ok := index[current.Path]; ok {
+			merged := &files[i]
+			merged.Hunks = append(merged.Hunks, current.Hunks...)
+			merged.Added += current.Added
+			merged.Removed += current.Removed
+			merged.Binary = merged.Binary || current.Binary
+		} else {
+			index[current.Path] = len(files)
+			files = append(files, *current)
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			current = &FileDiff{Path: pathFromDiffLine(line)}
+		case current == nil:
+			continue
+		case hunk == nil && strings.HasPrefix(line, "+++ "):
+			if path := strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/"); path != "/dev/null" {
+				current.Path = path
+			}
+		case hunk == nil && strings.HasPrefix(line, "Binary files "):
+			current.Binary = true
+		case strings.HasPrefix(line, "@@"):
+			if hunk != nil {
+				current.Hunks = append(current.Hunks, *hunk)
+			}
+			hunk = &Hunk{Header: line}
+		case hunk != nil && line != "":
+			hunk.Lines = append(hunk.Lines, line)
+			switch line[0] {
+			case '+':
+				current.Added++
+			case '-':
+				current.Removed++
+			}
+		}
+	}
+	flush()
+	return files
+}
+
+// pathFromDiffLine extracts the new path from a "diff --git a/x b/x" line
+func pathFromDiffLine(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if i := strings.LastIndex(rest, " b/"); i >= 0 {
+		return rest[i+3:]
+	}
+	return rest
+}
+
+// Format renders the exported symbol changes and the diffs of files, keeping
+// the result within roughly budget tokens. Every file gets a summary line;
+// hunks are shared fairly between files, smaller files first, and whatever
+// does not fit is replaced by an omission note. A budget <= 0 uses
+// DefaultTokenBudget.
+func Format(files []FileDiff, budget int) string {
+	if len(files) == 0 {
+		return ""
+	}
+	if budget <= 0 {
+		budget = DefaultTokenBudget
+	}
+
+	var b strings.Builder
+	if symbols := FormatSymbols(ExportedSymbols(files)); symbols != "" {
+		b.WriteString("EXPORTED SYMBOLS:\n")
+		b.WriteString(symbols)
+		b.WriteString("\n")
+	}
+
+	// Summary lines are always kept so every file is at least named
+	summaries := make([]string, len(files))
+	remaining := budget - tokens.Estimate(b.String())
+	for i, f := range files {
+		summaries[i] = summaryLine(f)
+		remaining -= tokens.Estimate(summaries[i])
+	}
+
+	// Allocate the rest to hunks: each file gets an equal share of what is
+	// left, and unused share rolls over to the larger files after it
+	order := make([]int, len(files))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return hunkTokens(files[order[a]]) < hunkTokens(files[order[b]])
+	})
+	bodies := make([]string, len(files))
+	for n, i := range order {
+		share := 0
+		if remaining > 0 {
+			share = remaining / (len(files) - n)
+		}
+		bodies[i] = renderHunks(files[i], share)
+		remaining -= tokens.Estimate(bodies[i])
+	}
+
+	b.WriteString("DIFF:\n")
+	for i := range files {
+		b.WriteString(summaries[i])
+		b.WriteString(bodies[i])
+	}
+	return b.String()
+}
+
+// summaryLine names a file with its line counts
+func summaryLine(f FileDiff) string {
+	if f.Binary {
+		return fmt.Sprintf("=== %s (binary)\n", f.Path)
+	}
+	return fmt.Sprintf("=== %s (+%d -%d)\n", f.Path, f.Added, f.Removed)
+}
+
+// hunkTokens estimates the tokens needed to render all hunks of f
+func hunkTokens(f FileDiff) int {
+	total := 0
+	for _, h := range f.Hunks {
+		total += tokens.Estimate(h.Header) + 1
+		for _, line := range h.Lines {
+			total += tokens.Estimate(line) + 1
+		}
+	}
+	return total
+}
+
+// renderHunks renders as many hunk lines of f as fit in budget tokens and
+// notes what was omitted
+func renderHunks(f FileDiff, budget int) string {
+	var b strings.Builder
+	used := 0
+	for hi, h := range f.Hunks {
+		cost := tokens.Estimate(h.Header) + 1
+		if used+cost > budget {
+			writeOmitted(&b, f.Hunks[hi:], 0)
+			return b.String()
+		}
+		used += cost
+		b.WriteString(h.Header)
+		b.WriteString("\n")
+
+		for li, line := range h.Lines {
+			cost := tokens.Estimate(line) + 1
+			if used+cost > budget {
+				writeOmitted(&b, f.Hunks[hi:], li)
+				return b.String()
+			}
+			used += cost
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// writeOmitted notes the lines left out, starting at line skip of hunks[0]
+func writeOmitted(b *strings.Builder, hunks []Hunk, skip int) {
+	lines := len(hunks[0].Lines) - skip
+	for _, h := range hunks[1:] {
+		lines += len(h.Lines)
+	}
+	if skip > 0 {
+		fmt.Fprintf(b, "[... %d more lines omitted]\n", lines)
+		return
+	}
+	fmt.Fprintf(b, "[... %d more hunks (%d lines) omitted]\n", len(hunks), lines)
+}
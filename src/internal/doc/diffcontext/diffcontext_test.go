@@ -0,0 +1,134 @@
+package diffcontext
+
+import (
+	"strings"
+	"testing"
+
+	"claudex/internal/services/tokens"
+)
+
+const sampleDiff = `diff --git a/pkg/store/store.go b/pkg/store/store.go
+index 1111111..2222222 100644
+--- a/pkg/store/store.go
++++ b/pkg/store/store.go
+@@ -10,7 +10,12 @@ import (
+ type Store struct {
+ 	db *sql.DB
+ }
+-func NewStore(db *sql.DB) *Store {
++func NewStore(db *sql.DB, opts Options) *Store {
+ 	return &Store{db: db}
+ }
++
++// Options configures a Store
++type Options struct {
++	ReadOnly bool
++}
+-func (s *Store) Purge() error {
+-	return nil
+-}
+diff --git a/pkg/store/logo.png b/pkg/store/logo.png
+new file mode 100644
+index 0000000..3333333
+Binary files /dev/null and b/pkg/store/logo.png differ
+diff --git a/web/api.ts b/web/api.ts
+new file mode 100644
+--- /dev/null
++++ b/web/api.ts
+@@ -0,0 +1,3 @@
++export async function fetchUser(id: string) {
++  return get(id)
++}
+`
+
+func TestParse_SplitsFilesAndCountsLines(t *testing.T) {
+	files := Parse(sampleDiff)
+
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %d", len(files))
+	}
+	store := files[0]
+	if store.Path != "pkg/store/store.go" {
+		t.Errorf("expected store.go path, got %s", store.Path)
+	}
+	if store.Added != 6 || store.Removed != 4 {
+		t.Errorf("expected +6 -4, got +%d -%d", store.Added, store.Removed)
+	}
+	if len(store.Hunks) != 1 {
+		t.Errorf("expected 1 hunk, got %d", len(store.Hunks))
+	}
+	if !files[1].Binary {
+		t.Error("expected logo.png to be binary")
+	}
+	if files[2].Path != "web/api.ts" || files[2].Added != 3 {
+		t.Errorf("unexpected new file diff: %+v", files[2])
+	}
+}
+
+func TestParse_MergesSamePath(t *testing.T) {
+	diff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-x\n+y\n" +
+		"diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -5 +5 @@\n-z\n+w\n"
+
+	files := Parse(diff)
+
+	if len(files) != 1 {
+		t.Fatalf("expected diffs of one path to merge, got %d files", len(files))
+	}
+	if len(files[0].Hunks) != 2 || files[0].Added != 2 || files[0].Removed != 2 {
+		t.Errorf("unexpected merged diff: %+v", files[0])
+	}
+}
+
+func TestExportedSymbols(t *testing.T) {
+	changes := ExportedSymbols(Parse(sampleDiff))
+
+	got := FormatSymbols(changes)
+	expected := "+ pkg/store/store.go: type Options\n" +
+		"+ web/api.ts: function fetchUser\n" +
+		"- pkg/store/store.go: method Store.Purge\n" +
+		"~ pkg/store/store.go: func NewStore\n"
+	if got != expected {
+		t.Errorf("expected symbols:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestExportedSymbols_IgnoresUnexportedAndContext(t *testing.T) {
+	diff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1,2 +1,3 @@\n func Kept() {}\n+func helper() {}\n+type internal struct{}\n" +
+		"diff --git a/b.py b/b.py\n--- a/b.py\n+++ b/b.py\n@@ -1 +1,2 @@\n+def _private():\n+class Public:\n"
+
+	changes := ExportedSymbols(Parse(diff))
+
+	if len(changes.Added) != 1 || changes.Added[0].Name != "Public" {
+		t.Errorf("expected only Public to be reported, got %+v", changes)
+	}
+}
+
+func TestFormat_FitsBudgetAndNotesOmissions(t *testing.T) {
+	var big strings.Builder
+	big.WriteString("diff --git a/big.go b/big.go\n--- a/big.go\n+++ b/big.go\n@@ -1,0 +1,400 @@\n")
+	for i := 0; i < 400; i++ {
+		big.WriteString("+\tvalue := computeSomethingExpensive(input)\n")
+	}
+	small := "diff --git a/small.go b/small.go\n--- a/small.go\n+++ b/small.go\n@@ -1 +1 @@\n-a := 1\n+a := 2\n"
+
+	out := Format(Parse(big.String()+small), 500)
+
+	if n := tokens.Estimate(out); n > 550 {
+		t.Errorf("expected output near the 500 token budget, got %d tokens", n)
+	}
+	if !strings.Contains(out, "=== big.go (+400 -0)") || !strings.Contains(out, "=== small.go (+1 -1)") {
+		t.Errorf("expected summaries for every file, got:\n%s", out)
+	}
+	if !strings.Contains(out, "+a := 2") {
+		t.Error("expected the small diff to be kept whole")
+	}
+	if !strings.Contains(out, "more lines omitted]") {
+		t.Error("expected an omission note for the truncated file")
+	}
+}
+
+func TestFormat_Empty(t *testing.T) {
+	if out := Format(nil, 100); out != "" {
+		t.Errorf("expected empty context, got %q", out)
+	}
+}
@@ -0,0 +1,172 @@
+package diffcontext
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Symbol is an exported declaration found on a changed line
+type Symbol struct {
+	// Path is the file declaring the symbol
+	Path string
+
+	// Kind is the declaration keyword (func, type, class, ...)
+	Kind string
+
+	// Name is the symbol name; Go methods are Receiver.Name
+	Name string
+}
+
+// String renders the symbol as "path: kind name"
+func (s Symbol) String() string {
+	return fmt.Sprintf("%s: %s %s", s.Path, s.Kind, s.Name)
+}
+
+// SymbolChanges groups exported symbols by how the diff affected them
+type SymbolChanges struct {
+	// Added symbols only appear on added lines
+	Added []Symbol
+
+	// Removed symbols only appear on removed lines
+	Removed []Symbol
+
+	// Changed symbols appear on both, e.g. a changed signature
+	Changed []Symbol
+}
+
+// Declaration patterns per language. Each names the groups "kind" and
+// "name", plus "recv" for Go methods.
+var (
+	goPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^(?P<kind>func) (?:\([^)]*?\*?(?P<recv>\w+)(?:\[[^\]]*\])?\) )?(?P<name>[A-Z]\w*)`),
+		regexp.MustCompile(`^(?P<kind>type|var|const) (?P<name>[A-Z]\w*)`),
+	}
+	jsPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^export\s+(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?(?:async\s+)?(?P<kind>function|class|const|let|var|interface|type|enum)\*?\s+(?P<name>\w+)`),
+	}
+	pyPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^(?:async\s+)?(?P<kind>def|class)\s+(?P<name>[A-Za-z]\w*)`),
+	}
+	rustPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^\s*pub\s+(?:async\s+)?(?P<kind>fn|struct|enum|trait|type|const|static|mod)\s+(?P<name>\w+)`),
+	}
+	javaPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^\s*public\s+(?:(?:abstract|final|static|sealed)\s+)*(?P<kind>class|interface|enum|record)\s+(?P<name>\w+)`),
+	}
+)
+
+// patternsFor returns the declaration patterns for a file's language, or nil
+func patternsFor(path string) []*regexp.Regexp {
+	switch filepath.Ext(path) {
+	case ".go":
+		return goPatterns
+	case ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx":
+		return jsPatterns
+	case ".py":
+		return pyPatterns
+	case ".rs":
+		return rustPatterns
+	case ".java", ".kt", ".cs":
+		return javaPatterns
+	}
+	return nil
+}
+
+// ExportedSymbols finds exported declarations on the added and removed lines
+// of files. Languages without known declaration syntax are ignored.
+func ExportedSymbols(files []FileDiff) SymbolChanges {
+	var changes SymbolChanges
+	for _, f := range files {
+		patterns := patternsFor(f.Path)
+		if patterns == nil {
+			continue
+		}
+
+		added := map[Symbol]bool{}
+		removed := map[Symbol]bool{}
+		for _, h := range f.Hunks {
+			for _, line := range h.Lines {
+				if line == "" || (line[0] != '+' && line[0] != '-') {
+					continue
+				}
+				sym, ok := matchDecl(patterns, f.Path, line[1:])
+				if !ok {
+					continue
+				}
+				if line[0] == '+' {
+					added[sym] = true
+				} else {
+					removed[sym] = true
+				}
+			}
+		}
+
+		for sym := range added {
+			if removed[sym] {
+				changes.Changed = append(changes.Changed, sym)
+			} else {
+				changes.Added = append(changes.Added, sym)
+			}
+		}
+		for sym := range removed {
+			if !added[sym] {
+				changes.Removed = append(changes.Removed, sym)
+			}
+		}
+	}
+
+	for _, list := range [][]Symbol{changes.Added, changes.Removed, changes.Changed} {
+		sort.Slice(list, func(i, j int) bool { return list[i].String() < list[j].String() })
+	}
+	return changes
+}
+
+// matchDecl reports the exported symbol declared on line, if any
+func matchDecl(patterns []*regexp.Regexp, path, line string) (Symbol, bool) {
+	for _, re := range patterns {
+		m := re.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		sym := Symbol{Path: path}
+		recv := ""
+		for i, group := range re.SubexpNames() {
+			switch group {
+			case "kind":
+				sym.Kind = m[i]
+			case "name":
+				sym.Name = m[i]
+			case "recv":
+				recv = m[i]
+			}
+		}
+		if recv != "" {
+			sym.Kind = "method"
+			sym.Name = recv + "." + sym.Name
+		}
+		return sym, true
+	}
+	return Symbol{}, false
+}
+
+// FormatSymbols renders symbol changes one per line, prefixed with + (added),
+// - (removed) or ~ (changed); empty when there are none
+func FormatSymbols(changes SymbolChanges) string {
+	var b strings.Builder
+	for _, group := range []struct {
+		prefix  string
+		symbols []Symbol
+	}{
+		{"+", changes.Added},
+		{"-", changes.Removed},
+		{"~", changes.Changed},
+	} {
+		for _, sym := range group.symbols {
+			fmt.Fprintf(&b, "%s %s\n", group.prefix, sym)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,146 @@
+// Package docpatch renders proposed index.md changes as a unified diff that
+// `git apply` accepts, prefixed with a header recording where the proposal
+// came from so it can be applied later by `claudex docs update --apply`.
+package docpatch
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// headerMarker identifies a patch written by claudex; git apply ignores
+// comment lines before the first diff
+const headerMarker = "# claudex-docs-patch"
+
+// Modes recorded in the header, matching the update's change source
+const (
+	ModeCommits  = "commits"
+	ModeStaged   = "staged"
+	ModeWorktree = "worktree"
+)
+
+// Header describes the update a patch was generated from
+type Header struct {
+	// Mode is the change source (one of the Mode* constants)
+	Mode string
+
+	// Branch is the branch the update ran on
+	Branch string
+
+	// Head is the HEAD commit the proposals were computed against
+	Head string
+
+	// Range is the processed commit range or change set description
+	Range string
+}
+
+// FileChange is one proposed file edit
+type FileChange struct {
+	// Path is relative to the repository root
+	Path string
+
+	Original string
+	Proposed string
+}
+
+// Render produces the patch text for the given changes.
+// Unchanged files are omitted; the result is empty if nothing changed.
+func Render(h Header, changes []FileChange) (string, error) {
+	var body strings.Builder
+	for _, c := range changes {
+		if c.Original == c.Proposed {
+			continue
+		}
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(c.Original),
+			B:        splitLines(c.Proposed),
+			FromFile: "a/" + c.Path,
+			ToFile:   "b/" + c.Path,
+			Context:  3,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to diff %s: %w", c.Path, err)
+		}
+		fmt.Fprintf(&body, "diff --git a/%s b/%s\n%s", c.Path, c.Path, diff)
+	}
+	if body.Len() == 0 {
+		return "", nil
+	}
+
+	var out strings.Builder
+	out.WriteString(headerMarker + "\n")
+	fmt.Fprintf(&out, "# mode: %s\n", h.Mode)
+	fmt.Fprintf(&out, "# branch: %s\n", h.Branch)
+	fmt.Fprintf(&out, "# head: %s\n", h.Head)
+	fmt.Fprintf(&out, "# range: %s\n", h.Range)
+	out.WriteString(body.String())
+	return out.String(), nil
+}
+
+// Parse reads the header and the paths of the files a patch changes.
+// Returns an error if the patch was not written by claudex.
+func Parse(patch string) (Header, []string, error) {
+	var h Header
+	var files []string
+	sawMarker := false
+
+	scanner := bufio.NewScanner(strings.NewReader(patch))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == headerMarker:
+			sawMarker = true
+		case strings.HasPrefix(line, "# ") && len(files) == 0:
+			key, value, ok := strings.Cut(strings.TrimPrefix(line, "# "), ": ")
+			if !ok {
+				continue
+			}
+			switch key {
+			case "mode":
+				h.Mode = value
+			case "branch":
+				h.Branch = value
+			case "head":
+				h.Head = value
+			case "range":
+				h.Range = value
+			}
+		case strings.HasPrefix(line, "+++ b/"):
+			files = append(files, strings.TrimSpace(strings.TrimPrefix(line, "+++ b/")))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Header{}, nil, err
+	}
+
+	if !sawMarker {
+		return Header{}, nil, fmt.Errorf("not a claudex docs patch (missing %q header)", headerMarker)
+	}
+	if len(files) == 0 {
+		return Header{}, nil, fmt.Errorf("patch contains no file changes")
+	}
+	return h, files, nil
+}
+
+// noNewlineMarker follows a last line that has no newline in a unified diff
+const noNewlineMarker = "\\ No newline at end of file\n"
+
+// splitLines splits content into newline-terminated lines. A last line
+// without newline carries the "\ No newline at end of file" marker, so the
+// diff line stays complete, git apply reproduces the file exactly, and adding
+// or removing only the final newline still shows up as a change.
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if last := lines[len(lines)-1]; last == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n" + noNewlineMarker
+	return lines
+}
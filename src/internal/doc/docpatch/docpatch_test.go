@@ -0,0 +1,139 @@
+package docpatch
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender_ParseRoundTrip(t *testing.T) {
+	header := Header{
+		Mode:   ModeCommits,
+		Branch: "feature/x",
+		Head:   "abc123",
+		Range:  "aaa111..abc123",
+	}
+	changes := []FileChange{
+		{Path: "src/index.md", Original: "# Src\n\n- a.go\n", Proposed: "# Src\n\n- a.go\n- b.go\n"},
+		{Path: "lib/index.md", Original: "# Lib\n", Proposed: "# Lib\n"},
+	}
+
+	patch, err := Render(header, changes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(patch, "diff --git a/src/index.md b/src/index.md\n") {
+		t.Errorf("expected git diff header for src/index.md, got:\n%s", patch)
+	}
+	if !strings.Contains(patch, "+- b.go\n") {
+		t.Errorf("expected added line, got:\n%s", patch)
+	}
+	if strings.Contains(patch, "lib/index.md") {
+		t.Errorf("expected unchanged lib/index.md to be omitted, got:\n%s", patch)
+	}
+
+	parsed, files, err := Parse(patch)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if parsed != header {
+		t.Errorf("expected header %+v, got %+v", header, parsed)
+	}
+	if len(files) != 1 || files[0] != "src/index.md" {
+		t.Errorf("expected files [src/index.md], got %v", files)
+	}
+}
+
+func TestRender_NoChanges_ReturnsEmpty(t *testing.T) {
+	patch, err := Render(Header{Mode: ModeStaged}, []FileChange{
+		{Path: "src/index.md", Original: "# Src\n", Proposed: "# Src\n"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if patch != "" {
+		t.Errorf("expected empty patch, got:\n%s", patch)
+	}
+}
+
+func TestRender_MissingFinalNewline(t *testing.T) {
+	patch, err := Render(Header{Mode: ModeWorktree}, []FileChange{
+		{Path: "index.md", Original: "# Title", Proposed: "# New title"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	marker := "\\ No newline at end of file\n"
+	if !strings.Contains(patch, "-# Title\n"+marker+"+# New title\n"+marker) {
+		t.Errorf("expected complete diff lines with no-newline markers, got:\n%s", patch)
+	}
+
+	// Adding only the final newline is a change
+	patch, err = Render(Header{Mode: ModeWorktree}, []FileChange{
+		{Path: "index.md", Original: "# Title", Proposed: "# Title\n"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(patch, "-# Title\n"+marker+"+# Title\n") {
+		t.Errorf("expected the final newline to be added, got:\n%s", patch)
+	}
+}
+
+func TestRender_GitApplyRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	changes := []FileChange{
+		{Path: "a/index.md", Original: "# A\n\n- x.go", Proposed: "# A\n\n- x.go\n- y.go"},
+		{Path: "b/index.md", Original: "# B", Proposed: "# B\n"},
+		{Path: "c/index.md", Original: "# C\n- old\n", Proposed: "# C\n- new"},
+		{Path: "d/index.md", Original: "# D\n\nintro\n", Proposed: "# D\n\nintro\nmore\n"},
+	}
+	patch, err := Render(Header{Mode: ModeWorktree}, changes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dir := t.TempDir()
+	for _, c := range changes {
+		path := filepath.Join(dir, c.Path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(c.Original), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	patchPath := filepath.Join(dir, "docs.patch")
+	if err := os.WriteFile(patchPath, []byte(patch), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{{"apply", "--check", patchPath}, {"apply", patchPath}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %v\n%s\npatch:\n%s", strings.Join(args, " "), err, out, patch)
+		}
+	}
+	for _, c := range changes {
+		got, err := os.ReadFile(filepath.Join(dir, c.Path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != c.Proposed {
+			t.Errorf("%s: expected %q after git apply, got %q", c.Path, c.Proposed, got)
+		}
+	}
+}
+
+func TestParse_RejectsForeignPatch(t *testing.T) {
+	foreign := "--- a/x.md\n+++ b/x.md\n@@ -1 +1 @@\n-a\n+b\n"
+	if _, _, err := Parse(foreign); err == nil {
+		t.Error("expected error for patch without claudex header")
+	}
+}
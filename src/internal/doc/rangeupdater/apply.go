@@ -0,0 +1,80 @@
+package rangeupdater
+
+import (
+	"fmt"
+	"log"
+
+	"claudex/internal/doc/docpatch"
+
+	"github.com/spf13/afero"
+)
+
+// applyCommitMessage is used for commits created from reviewed doc patches.
+// The tag keeps the post-commit hook from treating the docs commit as new work.
+const applyCommitMessage = "docs: apply reviewed index.md updates [skip-docs]"
+
+// ApplyPatch applies a patch written by a dry run and commits the changed
+// index.md files on their own, leaving other staged changes alone.
+// A patch for a commit range is only applied while its branch is still at the
+// commit it was generated against; tracking then advances past the docs commit
+// so the reviewed range is not processed again.
+func (ru *RangeUpdater) ApplyPatch(patchPath string) (*UpdateResult, error) {
+	content, err := afero.ReadFile(ru.fs, patchPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read patch: %w", err)
+	}
+	header, files, err := docpatch.Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	// Hold the lock so the post-commit hook fired by our commit stays out
+	lock, lockedResult, err := ru.acquireLock()
+	if lock == nil {
+		return lockedResult, err
+	}
+	defer lock.Release()
+
+	branch, err := ru.gitSvc.GetCurrentBranch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	tracked := header.Mode == docpatch.ModeCommits
+	if tracked {
+		headSHA, err := ru.gitSvc.GetCurrentSHA()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current SHA: %w", err)
+		}
+		if branch != header.Branch || headSHA != header.Head {
+			return nil, fmt.Errorf("patch was generated on %s at %s but HEAD is %s at %s; run the dry run again",
+				header.Branch, shortSHA(header.Head), branch, shortSHA(headSHA))
+		}
+	}
+
+	if err := ru.gitSvc.ApplyPatch(patchPath); err != nil {
+		return nil, err
+	}
+	if err := ru.gitSvc.CommitFiles(applyCommitMessage, files...); err != nil {
+		return nil, err
+	}
+
+	newHead, err := ru.gitSvc.GetCurrentSHA()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current SHA: %w", err)
+	}
+	if tracked {
+		if err := ru.updateTracking(branch, newHead, nil, nil); err != nil {
+			return nil, err
+		}
+	}
+	log.Printf("Applied docs patch %s as %s", patchPath, shortSHA(newHead))
+
+	return &UpdateResult{
+		Status:          "success",
+		AffectedIndexes: files,
+		Branch:          branch,
+		ProcessedRange:  header.Range,
+		HeadSHA:         newHead,
+	}, nil
+}
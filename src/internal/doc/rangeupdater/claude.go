@@ -2,58 +2,70 @@ package rangeupdater
 
 import (
 	"fmt"
-	"log"
-	"os/exec"
+	"path/filepath"
 
-	"claudex/internal/services/commander"
-	"claudex/internal/services/env"
+	"claudex/internal/services/docfiles"
+	"claudex/internal/services/jobs"
 )
 
-// InvokeClaudeForIndex invokes Claude to regenerate an index.md file.
-// Claude uses its Edit tool to update the file directly.
-// The recursion guard (CLAUDE_HOOK_INTERNAL=1) prevents infinite loops.
-func InvokeClaudeForIndex(cmdr commander.Commander, env env.Environment, indexPath, listing, modifiedFiles string) error {
-	// Recursion guard: check if we're already inside a hook invocation
-	if env.Get("CLAUDE_HOOK_INTERNAL") == "1" {
-		log.Printf("Skipping index update for %s: recursion guard triggered", indexPath)
-		return nil
-	}
-
-	log.Printf("Spawning background process to regenerate %s", indexPath)
-
-	// Build Claude prompt with context
-	prompt := buildPrompt(indexPath, listing, modifiedFiles)
+// RecursionGuardEnv marks processes started by Claudex hooks and doc updates;
+// when set, index updates are skipped to prevent infinite loops
+const RecursionGuardEnv = "CLAUDE_HOOK_INTERNAL"
 
-	// Create a detached background process using bash
-	// This ensures the process survives even after the calling process exits
-	// Claude will use its Edit tool to update the file directly
-	// Using --model haiku for cost efficiency (index updates are simple tasks)
-	bashScript := fmt.Sprintf(`
-export CLAUDE_HOOK_INTERNAL=1
-claude -p %q --model haiku 2>/dev/null
-`, prompt)
-
-	cmd := exec.Command("bash", "-c", bashScript)
-
-	// Detach the process so it survives after we exit
-	if err := cmd.Start(); err != nil {
-		log.Printf("Failed to start background Claude process for %s: %v", indexPath, err)
-		return fmt.Errorf("failed to start background Claude process: %w", err)
+// claudeJob builds the supervised Claude invocation for an index.md prompt.
+// Claude uses its Edit tool to update the file named in the prompt directly.
+// Using --model haiku for cost efficiency (index updates are simple tasks).
+func claudeJob(indexPath, prompt string) jobs.Job {
+	return jobs.Job{
+		Name:    indexPath,
+		Command: "claude",
+		Args:    []string{"-p", prompt, "--model", "haiku"},
+		Env:     []string{RecursionGuardEnv + "=1"},
 	}
-
-	log.Printf("Background process started (PID: %d) for %s", cmd.Process.Pid, indexPath)
-	return nil
 }
 
 // buildPrompt constructs the Claude prompt for index.md regeneration
-func buildPrompt(indexPath, listing, modifiedFiles string) string {
-	return fmt.Sprintf(`A code change was made. Update the index.md at %s if needed.
+func buildPrompt(names docfiles.Names, indexPath, listing, modifiedFiles, diffContext string) string {
+	return fmt.Sprintf(`A code change was made. Update the %s at %s if needed.
 
 MODIFIED FILES:
 %s
+%s
+FILES IN DIRECTORY:
+%s
+
+%s`, filepath.Base(indexPath), indexPath, modifiedFiles, changesSection(diffContext), listing, scopeGuidance(names))
+}
 
+// buildProposalPrompt constructs the Claude prompt for a reviewable index.md
+// update: Claude edits a copy instead of the real file
+func buildProposalPrompt(names docfiles.Names, indexPath, proposalPath, listing, modifiedFiles, diffContext string) string {
+	return fmt.Sprintf(`A code change was made. The %s at %s may need updating.
+
+Do NOT edit %s. A copy of it is at %s: apply any updates to the copy only, and leave the copy unchanged if no update is needed.
+
+MODIFIED FILES:
+%s
+%s
 FILES IN DIRECTORY:
 %s
 
-This is a lightweight documentation pointer that helps developers understand the codebase. Other index.md files may exist in parent or child directories - explore them to understand the documentation structure and determine the appropriate scope for this file. Make thoughtful updates that keep it relevant and useful.`, indexPath, modifiedFiles, listing)
+%s`, filepath.Base(indexPath), indexPath, indexPath, proposalPath, modifiedFiles, changesSection(diffContext), listing, scopeGuidance(names))
+}
+
+// scopeGuidance closes a prompt with how the file fits the documentation tree
+func scopeGuidance(names docfiles.Names) string {
+	return fmt.Sprintf("This is a lightweight documentation pointer that helps developers understand the codebase. Other documentation files (%s) may exist in parent or child directories - explore them to understand the documentation structure and determine the appropriate scope for this file. Make thoughtful updates that keep it relevant and useful.", names)
+}
+
+// changesSection renders the diff context block of a prompt, or a blank line
+// when there is none
+func changesSection(diffContext string) string {
+	if diffContext == "" {
+		return ""
+	}
+	return fmt.Sprintf(`
+CHANGES (diff of the files this index covers; may be truncated, so read a file only if the diff is not enough):
+%s
+`, diffContext)
 }
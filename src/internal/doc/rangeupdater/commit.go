@@ -0,0 +1,93 @@
+package rangeupdater
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// DefaultCommitMessage is the message of auto-commits when none is configured
+const DefaultCommitMessage = "docs: update index.md files"
+
+// SourceRangeTrailer names the commit trailer that records which commits an
+// auto-commit documents
+const SourceRangeTrailer = "Docs-Source-Range"
+
+// autoCommitMessage builds the auto-commit message: the configured message
+// tagged [skip-docs] (so the post-commit hook ignores it), followed by a
+// trailer referencing the source range
+func autoCommitMessage(message, baseSHA, headSHA string) string {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		message = DefaultCommitMessage
+	}
+	if !hasSkipDocsTag(message) {
+		subject, body, _ := strings.Cut(message, "\n")
+		message = strings.TrimSpace(subject) + " " + skipDocsTag
+		if body != "" {
+			message += "\n" + body
+		}
+	}
+
+	source := headSHA
+	if baseSHA != "" {
+		source = baseSHA + ".." + headSHA
+	}
+	return fmt.Sprintf("%s\n\n%s: %s", message, SourceRangeTrailer, source)
+}
+
+// dirtyFiles returns the working tree changes as a set; on error it returns
+// nil and auto-commit is skipped
+func (ru *RangeUpdater) dirtyFiles() map[string]bool {
+	files, err := ru.gitSvc.GetWorktreeFiles()
+	if err != nil {
+		log.Printf("Warning: cannot read working tree status, docs will not be committed: %v", err)
+		return nil
+	}
+	return absPaths(files)
+}
+
+// commitDocs commits the regenerated indexes on their own once all jobs have
+// finished. Indexes that had uncommitted changes before the update are left
+// alone, as are all other staged changes. It returns the new HEAD, or "" when
+// nothing was committed; failures are logged rather than returned so the
+// update itself still counts.
+func (ru *RangeUpdater) commitDocs(indexes []string, dirtyBefore map[string]bool, baseSHA, headSHA string) string {
+	if dirtyBefore == nil {
+		return ""
+	}
+
+	// Commits made while Claude ran belong to the next update; committing on
+	// top of them would mark them as processed
+	current, err := ru.gitSvc.GetCurrentSHA()
+	if err != nil || current != headSHA {
+		log.Printf("HEAD moved during the docs update, leaving regenerated docs uncommitted")
+		return ""
+	}
+
+	dirtyAfter := ru.dirtyFiles()
+	var files []string
+	for _, idx := range indexes {
+		switch {
+		case dirtyBefore[idx]:
+			log.Printf("Not committing %s: it had uncommitted changes before the update", idx)
+		case dirtyAfter[idx]:
+			files = append(files, idx)
+		}
+	}
+	if len(files) == 0 {
+		return ""
+	}
+
+	if err := ru.gitSvc.CommitFiles(autoCommitMessage(ru.config.CommitMessage, baseSHA, headSHA), files...); err != nil {
+		log.Printf("Warning: failed to commit regenerated docs: %v", err)
+		return ""
+	}
+	newHead, err := ru.gitSvc.GetCurrentSHA()
+	if err != nil {
+		log.Printf("Warning: committed regenerated docs but cannot read HEAD: %v", err)
+		return ""
+	}
+	log.Printf("Committed %d regenerated doc file(s) as %s", len(files), shortSHA(newHead))
+	return newHead
+}
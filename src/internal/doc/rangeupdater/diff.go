@@ -0,0 +1,82 @@
+package rangeupdater
+
+import (
+	"log"
+	"strings"
+
+	"claudex/internal/doc/diffcontext"
+	"claudex/internal/services/git"
+)
+
+// collectRangeDiff returns the diff hunks behind changedFiles. When the range
+// was filtered, the kept commits are diffed one by one so skipped commits and
+// commits processed on other branches stay out of the context. Each commit's
+// diff is limited to the files collectChangedFiles lists for it, so a merge
+// only contributes the files it changed itself (diff-tree --cc), not the
+// whole merged branch. Diff context is best effort: on failure the prompt
+// falls back to file names only.
+func (ru *RangeUpdater) collectRangeDiff(baseSHA, headSHA string, kept []git.Commit, filtered bool, changedFiles []string) []diffcontext.FileDiff {
+	if ru.config.DiffTokenBudget < 0 {
+		return nil
+	}
+
+	if !filtered {
+		diff, err := ru.gitSvc.GetDiff(baseSHA, headSHA, changedFiles...)
+		if err != nil {
+			log.Printf("Warning: failed to get diff for %s: %v", formatRange(baseSHA, headSHA), err)
+			return nil
+		}
+		return diffcontext.Parse(diff)
+	}
+
+	var b strings.Builder
+	for _, c := range kept {
+		files, err := ru.gitSvc.GetCommitFiles(c.SHA)
+		if err != nil || len(files) == 0 {
+			continue
+		}
+		diff, err := ru.gitSvc.GetDiff(c.SHA+"^", c.SHA, files...)
+		if err != nil {
+			// Root commits have no parent to diff against
+			log.Printf("Warning: failed to get diff for %s: %v", shortSHA(c.SHA), err)
+			continue
+		}
+		b.WriteString(diff)
+	}
+	return diffcontext.Parse(b.String())
+}
+
+// collectUncommittedDiff returns the diff hunks of staged or working tree changes.
+// Untracked files have no diff and are only listed by name.
+func (ru *RangeUpdater) collectUncommittedDiff(changeSet ChangeSet) []diffcontext.FileDiff {
+	if ru.config.DiffTokenBudget < 0 {
+		return nil
+	}
+
+	head := ""
+	if changeSet == ChangeSetStaged {
+		head = git.IndexRef
+	}
+	diff, err := ru.gitSvc.GetDiff("HEAD", head)
+	if err != nil && changeSet == ChangeSetStaged {
+		// An unborn branch has no HEAD; diff the index against the empty tree
+		diff, err = ru.gitSvc.GetDiff("", git.IndexRef)
+	}
+	if err != nil {
+		log.Printf("Warning: failed to get diff for %s changes: %v", changeSet, err)
+		return nil
+	}
+	return diffcontext.Parse(diff)
+}
+
+// diffContext renders the diffs of the files whose nearest index is indexPath,
+// within the configured token budget
+func (ru *RangeUpdater) diffContext(indexPath string, diffs []diffcontext.FileDiff) string {
+	var covered []diffcontext.FileDiff
+	for _, d := range diffs {
+		if findNearestIndexMd(ru.fs, ru.config.DocFilenames, d.Path) == indexPath {
+			covered = append(covered, d)
+		}
+	}
+	return diffcontext.Format(covered, ru.config.DiffTokenBudget)
+}
@@ -2,40 +2,81 @@ package rangeupdater
 
 import (
 	"fmt"
+	"strings"
 
 	"claudex/internal/services/git"
 )
 
+// Fallback sources recorded in FallbackBase.Source
+const (
+	// FallbackSourceConfig means the branch was set explicitly in configuration
+	FallbackSourceConfig = "config"
+
+	// FallbackSourceBuiltin means the branch is one of the conventional names (main, master)
+	FallbackSourceBuiltin = "builtin"
+)
+
+// FallbackBase describes the merge-base used when the tracked base commit is unreachable
+type FallbackBase struct {
+	// SHA is the merge-base commit used as the new base
+	SHA string
+
+	// Branch is the branch the merge-base was computed against
+	Branch string
+
+	// Source records where Branch came from: "config", "origin/HEAD",
+	// "init.defaultBranch" or "builtin"
+	Source string
+}
+
+// String returns a human-readable description for logs and result output
+func (f *FallbackBase) String() string {
+	return fmt.Sprintf("merge-base with %s (%s): %s", f.Branch, f.Source, shortSHA(f.SHA))
+}
+
 // HandleUnreachableBase handles the case where the base commit is unreachable.
 // This typically happens after a rebase or force push that rewrites history.
 // It attempts to find a suitable fallback commit using merge-base with the default branch.
 //
 // Fallback strategy:
-//  1. Try merge-base with provided defaultBranch (if not empty)
-//  2. Try merge-base with "main"
-//  3. Try merge-base with "master"
-//  4. Return error if all attempts fail
-func HandleUnreachableBase(gitSvc git.GitService, defaultBranch string) (string, error) {
-	// Try provided default branch first
-	if defaultBranch != "" {
-		sha, err := gitSvc.GetMergeBase(defaultBranch)
-		if err == nil && sha != "" {
-			return sha, nil
-		}
+//  1. Try merge-base with the configured defaultBranch (if not empty)
+//  2. Try merge-base with the detected default branch (origin/HEAD, then init.defaultBranch)
+//  3. Try merge-base with "main"
+//  4. Try merge-base with "master"
+//  5. Return error if all attempts fail
+func HandleUnreachableBase(gitSvc git.GitService, defaultBranch string) (*FallbackBase, error) {
+	type candidate struct {
+		branch string
+		source string
 	}
 
-	// Try "main" branch
-	sha, err := gitSvc.GetMergeBase("main")
-	if err == nil && sha != "" {
-		return sha, nil
+	var candidates []candidate
+	if defaultBranch != "" {
+		candidates = append(candidates, candidate{defaultBranch, FallbackSourceConfig})
+	}
+	if branch, source, err := gitSvc.GetDefaultBranch(); err == nil && branch != "" {
+		candidates = append(candidates, candidate{branch, source})
 	}
+	candidates = append(candidates,
+		candidate{"main", FallbackSourceBuiltin},
+		candidate{"master", FallbackSourceBuiltin},
+	)
 
-	// Try "master" branch
-	sha, err = gitSvc.GetMergeBase("master")
-	if err == nil && sha != "" {
-		return sha, nil
+	tried := make(map[string]bool)
+	var triedNames []string
+	for _, c := range candidates {
+		if tried[c.branch] {
+			continue
+		}
+		tried[c.branch] = true
+		triedNames = append(triedNames, c.branch)
+
+		sha, err := gitSvc.GetMergeBase(c.branch)
+		if err == nil && sha != "" {
+			return &FallbackBase{SHA: sha, Branch: c.branch, Source: c.source}, nil
+		}
 	}
 
 	// All fallback attempts failed
-	return "", fmt.Errorf("failed to find merge-base with any default branch (tried: %s, main, master)", defaultBranch)
+	return nil, fmt.Errorf("failed to find merge-base with any default branch (tried: %s)", strings.Join(triedNames, ", "))
 }
@@ -11,9 +11,11 @@ import (
 	"testing"
 	"time"
 
+	"claudex/internal/doc/docpatch"
 	"claudex/internal/services/commander"
 	"claudex/internal/services/doctracking"
 	"claudex/internal/services/git"
+	"claudex/internal/services/jobs"
 	"claudex/internal/services/lock"
 
 	"github.com/spf13/afero"
@@ -94,6 +96,30 @@ func makeCommit(t *testing.T, repoPath string, files map[string]string, message
 	return strings.TrimSpace(string(output))
 }
 
+// runGit runs a git command in the repository and fails the test on error
+func runGit(t *testing.T, repoPath string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, output)
+	}
+}
+
+// currentBranch returns the checked-out branch name (depends on init.defaultBranch)
+func currentBranch(t *testing.T, repoPath string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Failed to get current branch: %v", err)
+	}
+	return strings.TrimSpace(string(output))
+}
+
 // mockEnv is a simple mock implementation of Environment for testing
 type mockEnv struct {
 	vars map[string]string
@@ -151,7 +177,7 @@ func createUpdater(t *testing.T, repoPath string) (*RangeUpdater, string, *mockE
 		LockTimeout:   0,
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, mockEnv)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, jobs.NewExecutor(), fs, mockEnv)
 
 	return updater, sessionPath, mockEnv
 }
@@ -179,7 +205,7 @@ func TestIntegration_HappyPath(t *testing.T) {
 		UpdatedAt:           time.Now().Format(time.RFC3339),
 		StrategyVersion:     "v1",
 	}
-	if err := updater.trackingSvc.Write(tracking); err != nil {
+	if err := updater.trackingSvc.Write(currentBranch(t, repoPath), tracking); err != nil {
 		t.Fatalf("Failed to initialize tracking: %v", err)
 	}
 
@@ -218,7 +244,7 @@ func TestIntegration_HappyPath(t *testing.T) {
 	}
 
 	// Verify tracking was updated to commit2
-	newTracking, err := updater.trackingSvc.Read()
+	newTracking, err := updater.trackingSvc.Read(currentBranch(t, repoPath))
 	if err != nil {
 		t.Fatalf("Failed to read tracking: %v", err)
 	}
@@ -249,7 +275,7 @@ func TestIntegration_NoChanges(t *testing.T) {
 		UpdatedAt:           time.Now().Format(time.RFC3339),
 		StrategyVersion:     "v1",
 	}
-	if err := updater.trackingSvc.Write(tracking); err != nil {
+	if err := updater.trackingSvc.Write(currentBranch(t, repoPath), tracking); err != nil {
 		t.Fatalf("Failed to initialize tracking: %v", err)
 	}
 
@@ -294,7 +320,7 @@ func TestIntegration_DocsOnlyChanges(t *testing.T) {
 		UpdatedAt:           time.Now().Format(time.RFC3339),
 		StrategyVersion:     "v1",
 	}
-	if err := updater.trackingSvc.Write(tracking); err != nil {
+	if err := updater.trackingSvc.Write(currentBranch(t, repoPath), tracking); err != nil {
 		t.Fatalf("Failed to initialize tracking: %v", err)
 	}
 
@@ -322,8 +348,6 @@ func TestIntegration_DocsOnlyChanges(t *testing.T) {
 
 // TestIntegration_SkipDocsTag tests skipping when commit message has [skip-docs]
 func TestIntegration_SkipDocsTag(t *testing.T) {
-	t.Skip("Skipping: commit message checking not yet implemented in current skiprules")
-
 	repoPath := setupTestRepo(t)
 	originalDir, _ := os.Getwd()
 	defer os.Chdir(originalDir)
@@ -342,7 +366,7 @@ func TestIntegration_SkipDocsTag(t *testing.T) {
 		UpdatedAt:           time.Now().Format(time.RFC3339),
 		StrategyVersion:     "v1",
 	}
-	if err := updater.trackingSvc.Write(tracking); err != nil {
+	if err := updater.trackingSvc.Write(currentBranch(t, repoPath), tracking); err != nil {
 		t.Fatalf("Failed to initialize tracking: %v", err)
 	}
 
@@ -357,8 +381,18 @@ func TestIntegration_SkipDocsTag(t *testing.T) {
 		t.Fatalf("Run() failed: %v", err)
 	}
 
-	// TODO: Update this test once commit message checking is implemented in skiprules
-	t.Logf("Result: %s - %s", result.Status, result.Reason)
+	// Verify
+	if result.Status != "skipped" {
+		t.Errorf("Expected status 'skipped', got '%s'", result.Status)
+	}
+
+	tracking, err = updater.trackingSvc.Read(currentBranch(t, repoPath))
+	if err != nil {
+		t.Fatalf("Failed to read tracking: %v", err)
+	}
+	if len(tracking.SkippedCommits) != 1 {
+		t.Errorf("Expected 1 skipped commit recorded, got %v", tracking.SkippedCommits)
+	}
 }
 
 // TestIntegration_UnreachableBase tests fallback when base SHA is unreachable
@@ -393,7 +427,7 @@ func TestIntegration_UnreachableBase(t *testing.T) {
 		UpdatedAt:           time.Now().Format(time.RFC3339),
 		StrategyVersion:     "v1",
 	}
-	if err := updater.trackingSvc.Write(tracking); err != nil {
+	if err := updater.trackingSvc.Write(currentBranch(t, repoPath), tracking); err != nil {
 		t.Fatalf("Failed to write tracking: %v", err)
 	}
 
@@ -413,7 +447,7 @@ func TestIntegration_UnreachableBase(t *testing.T) {
 	}
 
 	// Verify tracking was updated to current HEAD (commit2)
-	newTracking, err := updater.trackingSvc.Read()
+	newTracking, err := updater.trackingSvc.Read(currentBranch(t, repoPath))
 	if err != nil {
 		t.Fatalf("Failed to read tracking: %v", err)
 	}
@@ -449,7 +483,7 @@ func TestIntegration_LockContention(t *testing.T) {
 		UpdatedAt:           time.Now().Format(time.RFC3339),
 		StrategyVersion:     "v1",
 	}
-	if err := updater.trackingSvc.Write(tracking); err != nil {
+	if err := updater.trackingSvc.Write(currentBranch(t, repoPath), tracking); err != nil {
 		t.Fatalf("Failed to initialize tracking: %v", err)
 	}
 
@@ -508,7 +542,7 @@ func TestIntegration_MultipleIndexes(t *testing.T) {
 		UpdatedAt:           time.Now().Format(time.RFC3339),
 		StrategyVersion:     "v1",
 	}
-	if err := updater.trackingSvc.Write(tracking); err != nil {
+	if err := updater.trackingSvc.Write(currentBranch(t, repoPath), tracking); err != nil {
 		t.Fatalf("Failed to initialize tracking: %v", err)
 	}
 
@@ -553,7 +587,7 @@ func TestIntegration_MultipleIndexes(t *testing.T) {
 	}
 
 	// Verify tracking was updated to commit2
-	newTracking, err := updater.trackingSvc.Read()
+	newTracking, err := updater.trackingSvc.Read(currentBranch(t, repoPath))
 	if err != nil {
 		t.Fatalf("Failed to read tracking: %v", err)
 	}
@@ -562,3 +596,205 @@ func TestIntegration_MultipleIndexes(t *testing.T) {
 		t.Errorf("Expected tracking to be %s, got %s", commit2, newTracking.LastProcessedCommit)
 	}
 }
+
+// TestIntegration_BranchSwitch tests that each branch only processes its own new commits
+func TestIntegration_BranchSwitch(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(repoPath)
+
+	// Create initial commit on main with two documented packages
+	makeCommit(t, repoPath, map[string]string{
+		".gitignore":   ".claudex-session/\n",
+		"src/index.md": "# Src\n",
+		"src/foo.go":   "package src\n",
+		"lib/index.md": "# Lib\n",
+		"lib/bar.go":   "package lib\n",
+	}, "Initial commit")
+
+	updater, _, _ := createUpdater(t, repoPath)
+
+	// First run initializes tracking for main
+	if _, err := updater.Run(); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	// Commit on a feature branch
+	mainBranch := currentBranch(t, repoPath)
+	runGit(t, repoPath, "checkout", "-q", "-b", "feature")
+	featureCommit := makeCommit(t, repoPath, map[string]string{
+		"lib/bar.go": "package lib\n\nfunc Bar() {}\n",
+	}, "feat: add Bar")
+
+	result, err := updater.Run()
+	if err != nil {
+		t.Fatalf("Run() on feature failed: %v", err)
+	}
+	if result.Status != "success" || result.Branch != "feature" {
+		t.Fatalf("Expected success on feature, got %s on %s (%s)", result.Status, result.Branch, result.Reason)
+	}
+
+	featureTracking, err := updater.trackingSvc.Read("feature")
+	if err != nil {
+		t.Fatalf("Failed to read tracking: %v", err)
+	}
+	if len(featureTracking.ProcessedCommits) != 1 || featureTracking.ProcessedCommits[0] != featureCommit {
+		t.Errorf("Expected feature to process only %s, got %v", featureCommit, featureTracking.ProcessedCommits)
+	}
+
+	// Merge the feature into main and add a commit touching src only
+	runGit(t, repoPath, "checkout", "-q", mainBranch)
+	runGit(t, repoPath, "merge", "-q", "--no-ff", "-m", "Merge feature", "feature")
+	makeCommit(t, repoPath, map[string]string{
+		"src/foo.go": "package src\n\nfunc Foo() {}\n",
+	}, "feat: add Foo")
+
+	result, err = updater.Run()
+	if err != nil {
+		t.Fatalf("Run() on main failed: %v", err)
+	}
+
+	// Only src/index.md is affected; lib changes were already processed on feature
+	if len(result.AffectedIndexes) != 1 || !strings.HasSuffix(result.AffectedIndexes[0], filepath.Join("src", "index.md")) {
+		t.Errorf("Expected only src/index.md to be affected, got %v", result.AffectedIndexes)
+	}
+
+	// Deleting the feature branch prunes its tracking entry on the next run
+	runGit(t, repoPath, "branch", "-q", "-D", "feature")
+	if _, err := updater.Run(); err != nil {
+		t.Fatalf("Run() after branch deletion failed: %v", err)
+	}
+	all, err := updater.trackingSvc.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read tracking: %v", err)
+	}
+	if _, ok := all["feature"]; ok {
+		t.Errorf("Expected feature tracking to be pruned, got %v", all)
+	}
+}
+
+// TestIntegration_MergeDiffContext tests that a merge commit contributes the
+// diff of the files it changed itself, not the whole merged branch
+func TestIntegration_MergeDiffContext(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(repoPath)
+
+	makeCommit(t, repoPath, map[string]string{
+		".gitignore":   ".claudex-session/\n",
+		"src/index.md": "# Src\n",
+		"src/foo.go":   "package src\n\nconst Name = \"base\"\n",
+		"lib/index.md": "# Lib\n",
+		"lib/bar.go":   "package lib\n",
+	}, "Initial commit")
+	mainBranch := currentBranch(t, repoPath)
+
+	// Both branches change src/foo.go; only the feature touches lib
+	runGit(t, repoPath, "checkout", "-q", "-b", "feature")
+	makeCommit(t, repoPath, map[string]string{
+		"src/foo.go": "package src\n\nconst Name = \"feature\"\n",
+		"lib/bar.go": "package lib\n\nfunc Bar() {}\n",
+	}, "feat: feature side")
+	runGit(t, repoPath, "checkout", "-q", mainBranch)
+	makeCommit(t, repoPath, map[string]string{
+		"src/foo.go": "package src\n\nconst Name = \"main\"\n",
+	}, "feat: main side")
+
+	// The merge conflicts on src/foo.go and is resolved by hand
+	merge := exec.Command("git", "merge", "-q", "--no-ff", "feature")
+	merge.Dir = repoPath
+	if err := merge.Run(); err == nil {
+		t.Fatal("expected the merge to conflict")
+	}
+	mergeSHA := makeCommit(t, repoPath, map[string]string{
+		"src/foo.go": "package src\n\nconst Name = \"merged\"\n",
+	}, "Merge feature")
+
+	updater, _, _ := createUpdater(t, repoPath)
+	diffs := updater.collectRangeDiff("", mergeSHA, []git.Commit{{SHA: mergeSHA, Message: "Merge feature"}}, true, nil)
+
+	if len(diffs) != 1 || diffs[0].Path != "src/foo.go" {
+		var paths []string
+		for _, d := range diffs {
+			paths = append(paths, d.Path)
+		}
+		t.Fatalf("expected only the merge's own change to src/foo.go, got %v", paths)
+	}
+}
+
+// TestIntegration_ApplyPatch tests that a reviewed docs patch is applied and
+// committed on its own, leaving unrelated staged changes staged
+func TestIntegration_ApplyPatch(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(repoPath)
+
+	head := makeCommit(t, repoPath, map[string]string{
+		".gitignore":   ".claudex-session/\n*.patch\n",
+		"src/index.md": "# Src\n\n- foo.go: entry point\n",
+		"src/foo.go":   "package src\n",
+	}, "Initial commit")
+
+	updater, _, _ := createUpdater(t, repoPath)
+	branch := currentBranch(t, repoPath)
+
+	// Stage an unrelated change that must not end up in the docs commit
+	if err := os.WriteFile(filepath.Join(repoPath, "src", "foo.go"), []byte("package src\n\nfunc Foo() {}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	runGit(t, repoPath, "add", "src/foo.go")
+
+	patch, err := docpatch.Render(docpatch.Header{
+		Mode:   docpatch.ModeCommits,
+		Branch: branch,
+		Head:   head,
+		Range:  "initial",
+	}, []docpatch.FileChange{{
+		Path:     "src/index.md",
+		Original: "# Src\n\n- foo.go: entry point\n",
+		Proposed: "# Src\n\n- foo.go: entry point with Foo\n",
+	}})
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	patchPath := filepath.Join(repoPath, "docs.patch")
+	if err := os.WriteFile(patchPath, []byte(patch), 0644); err != nil {
+		t.Fatalf("Failed to write patch: %v", err)
+	}
+
+	result, err := updater.ApplyPatch(patchPath)
+	if err != nil {
+		t.Fatalf("ApplyPatch() failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(repoPath, "src", "index.md"))
+	if !strings.Contains(string(content), "entry point with Foo") {
+		t.Errorf("Expected patched index.md, got %q", content)
+	}
+
+	cmd := exec.Command("git", "show", "--name-only", "--format=%s", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git show failed: %v", err)
+	}
+	if strings.Contains(string(output), "foo.go") || !strings.Contains(string(output), "src/index.md") {
+		t.Errorf("Expected docs commit to contain only src/index.md, got:\n%s", output)
+	}
+
+	cmd = exec.Command("git", "diff", "--cached", "--name-only")
+	output, _ = cmd.Output()
+	if strings.TrimSpace(string(output)) != "src/foo.go" {
+		t.Errorf("Expected src/foo.go to stay staged, got %q", output)
+	}
+
+	tracking, err := updater.trackingSvc.Read(branch)
+	if err != nil {
+		t.Fatalf("Failed to read tracking: %v", err)
+	}
+	if tracking.LastProcessedCommit != result.HeadSHA {
+		t.Errorf("Expected tracking at docs commit %s, got %s", result.HeadSHA, tracking.LastProcessedCommit)
+	}
+}
@@ -0,0 +1,112 @@
+package rangeupdater
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"claudex/internal/doc/diffcontext"
+	"claudex/internal/services/jobs"
+
+	"github.com/spf13/afero"
+)
+
+// proposeIndexes runs a supervised Claude job against a temporary copy of each
+// affected index and returns the proposals that differ from the current
+// content, along with the indexes whose job failed
+func (ru *RangeUpdater) proposeIndexes(affectedIndexes, changedFiles []string, diffs []diffcontext.FileDiff) ([]Proposal, []string) {
+	log.Printf("Proposing updates for %d index.md files (dry run)", len(affectedIndexes))
+
+	var batch []jobs.Job
+	var pending []Proposal
+	var pendingCopies []string
+	var copies []string
+	var failed []string
+	defer func() {
+		for _, copyPath := range copies {
+			ru.fs.Remove(copyPath)
+		}
+	}()
+
+	for _, indexPath := range affectedIndexes {
+		original, copyPath, prompt, err := ru.prepareProposal(indexPath, changedFiles, diffs)
+		if copyPath != "" {
+			copies = append(copies, copyPath)
+		}
+		if err != nil {
+			log.Printf("Warning: failed to propose update for %s: %v", indexPath, err)
+			failed = append(failed, indexPath)
+			continue
+		}
+		batch = append(batch, claudeJob(indexPath, prompt))
+		pending = append(pending, Proposal{IndexPath: indexPath, Original: string(original)})
+		pendingCopies = append(pendingCopies, copyPath)
+	}
+
+	var proposals []Proposal
+	for i, record := range ru.supervisor.Run(batch) {
+		if record.Status != jobs.StatusSucceeded {
+			failed = append(failed, record.Name)
+			continue
+		}
+		proposed, err := afero.ReadFile(ru.fs, pendingCopies[i])
+		if err != nil {
+			log.Printf("Warning: failed to read proposed %s: %v", record.Name, err)
+			failed = append(failed, record.Name)
+			continue
+		}
+		proposal := pending[i]
+		proposal.Proposed = string(proposed)
+		if proposal.Changed() {
+			proposals = append(proposals, proposal)
+		}
+	}
+	return proposals, failed
+}
+
+// prepareProposal copies indexPath to a temporary file and builds the prompt
+// asking Claude to edit the copy. The copy path is returned even on error so
+// the caller can clean it up.
+func (ru *RangeUpdater) prepareProposal(indexPath string, changedFiles []string, diffs []diffcontext.FileDiff) ([]byte, string, string, error) {
+	original, err := afero.ReadFile(ru.fs, indexPath)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to read index: %w", err)
+	}
+
+	copyPath, err := ru.writeTempCopy(original)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	indexDir := filepath.Dir(indexPath)
+	listing, err := ru.getDirectoryListing(indexDir)
+	if err != nil {
+		return nil, copyPath, "", fmt.Errorf("failed to get directory listing: %w", err)
+	}
+	filesContext := formatChangedFilesContext(changedFiles, indexDir)
+
+	diffContext := ru.diffContext(indexPath, diffs)
+
+	return original, copyPath, buildProposalPrompt(ru.config.DocFilenames, indexPath, copyPath, listing, filesContext, diffContext), nil
+}
+
+// writeTempCopy stores content in a new temporary index.md copy and returns
+// its path. Copies live under the .claudex directory: Claude only edits files
+// inside the project it runs in.
+func (ru *RangeUpdater) writeTempCopy(content []byte) (string, error) {
+	dir := filepath.Join(ru.config.SessionPath, ProposalDir)
+	if err := ru.fs.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	file, err := afero.TempFile(ru.fs, dir, "index-*.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp copy: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(content); err != nil {
+		ru.fs.Remove(file.Name())
+		return "", fmt.Errorf("failed to write temp copy: %w", err)
+	}
+	return file.Name(), nil
+}
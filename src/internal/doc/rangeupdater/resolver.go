@@ -4,17 +4,20 @@ import (
 	"path/filepath"
 	"sort"
 
+	"claudex/internal/services/docfiles"
+
 	"github.com/spf13/afero"
 )
 
-// ResolveAffectedIndexes maps a list of changed files to their affected index.md files.
-// It walks up the directory tree from each file to find the nearest parent index.md,
-// de-duplicates the results, and returns them in sorted order for deterministic behavior.
-func ResolveAffectedIndexes(fs afero.Fs, changedFiles []string) ([]string, error) {
+// ResolveAffectedIndexes maps a list of changed files to their affected index files.
+// It walks up the directory tree from each file to find the nearest parent doc file
+// (see docfiles.Names for the priority within a directory), de-duplicates the
+// results, and returns them in sorted order for deterministic behavior.
+func ResolveAffectedIndexes(fs afero.Fs, names docfiles.Names, changedFiles []string) ([]string, error) {
 	indexMap := make(map[string]bool)
 
 	for _, file := range changedFiles {
-		indexPath := findNearestIndexMd(fs, file)
+		indexPath := findNearestIndexMd(fs, names, file)
 		if indexPath != "" {
 			indexMap[indexPath] = true
 		}
@@ -30,9 +33,9 @@ func ResolveAffectedIndexes(fs afero.Fs, changedFiles []string) ([]string, error
 	return indexes, nil
 }
 
-// findNearestIndexMd walks up the directory tree to find the nearest parent index.md.
+// findNearestIndexMd walks up the directory tree to find the nearest parent doc file.
 // This is adapted from indexupdater.go:98-127 for batch processing.
-func findNearestIndexMd(fs afero.Fs, filePath string) string {
+func findNearestIndexMd(fs afero.Fs, names docfiles.Names, filePath string) string {
 	// Get absolute path and resolve any symlinks
 	absPath, err := filepath.Abs(filePath)
 	if err != nil {
@@ -44,16 +47,14 @@ func findNearestIndexMd(fs afero.Fs, filePath string) string {
 
 	// Walk up the directory tree
 	for {
-		indexPath := filepath.Join(dir, "index.md")
-		exists, err := afero.Exists(fs, indexPath)
-		if err == nil && exists {
+		if indexPath := names.Find(fs, dir); indexPath != "" {
 			return indexPath
 		}
 
 		// Check if we've reached the root
 		parent := filepath.Dir(dir)
 		if parent == dir {
-			// Reached root, no index.md found
+			// Reached root, no doc file found
 			break
 		}
 		dir = parent
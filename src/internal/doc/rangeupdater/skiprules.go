@@ -5,8 +5,12 @@ import (
 	"strings"
 
 	"claudex/internal/services/env"
+	"claudex/internal/services/git"
 )
 
+// skipDocsTag is the commit message tag that opts a commit out of doc updates
+const skipDocsTag = "[skip-docs]"
+
 // ShouldSkip determines if documentation updates should be skipped based on skip rules.
 // Returns (skip=true, reason) if any rule matches, (false, "") otherwise.
 //
@@ -21,7 +25,7 @@ func ShouldSkip(files []string, commitMsg string, env env.Environment) (skip boo
 	}
 
 	// Rule 2: Commit message tag
-	if strings.Contains(commitMsg, "[skip-docs]") {
+	if hasSkipDocsTag(commitMsg) {
 		return true, "commit message contains [skip-docs] tag"
 	}
 
@@ -48,3 +52,21 @@ func allMarkdownFiles(files []string) bool {
 
 	return true
 }
+
+// PartitionCommits splits commits into those that should drive doc updates
+// and those opted out via the [skip-docs] tag, preserving order.
+func PartitionCommits(commits []git.Commit) (kept, skipped []git.Commit) {
+	for _, c := range commits {
+		if hasSkipDocsTag(c.Message) {
+			skipped = append(skipped, c)
+		} else {
+			kept = append(kept, c)
+		}
+	}
+	return kept, skipped
+}
+
+// hasSkipDocsTag reports whether a commit message contains the [skip-docs] tag
+func hasSkipDocsTag(commitMsg string) bool {
+	return strings.Contains(commitMsg, skipDocsTag)
+}
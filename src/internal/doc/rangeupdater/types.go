@@ -3,7 +3,11 @@
 // to update index.md files based on commit range changes.
 package rangeupdater
 
-import "time"
+import (
+	"time"
+
+	"claudex/internal/services/docfiles"
+)
 
 // RangeUpdaterConfig holds configuration for the range-based doc updater
 type RangeUpdaterConfig struct {
@@ -12,9 +16,14 @@ type RangeUpdaterConfig struct {
 	SessionPath string
 
 	// DefaultBranch is the branch name to use for merge-base fallback
-	// Typically "main" or "master"
+	// Optional override; when empty the branch is detected from origin/HEAD
+	// or init.defaultBranch before falling back to "main" and "master"
 	DefaultBranch string
 
+	// DocFilenames lists the documentation file names in priority order
+	// Empty uses docfiles.Default (index.md)
+	DocFilenames docfiles.Names
+
 	// SkipPatterns is a list of file path patterns to ignore
 	// Files matching these patterns won't trigger doc updates
 	SkipPatterns []string
@@ -22,4 +31,71 @@ type RangeUpdaterConfig struct {
 	// LockTimeout is the maximum time to wait for lock acquisition
 	// Zero means no waiting (immediate failure if locked)
 	LockTimeout time.Duration
+
+	// StageDocs adds regenerated index.md files to the git index
+	// Only honored by RunUncommitted
+	StageDocs bool
+
+	// MaxJobs bounds how many Claude processes run at once
+	// Zero uses jobs.DefaultMaxConcurrent
+	MaxJobs int
+
+	// JobTimeout bounds each Claude process; zero uses jobs.DefaultTimeout
+	JobTimeout time.Duration
+
+	// DiffTokenBudget bounds the diff context given to Claude per index.md,
+	// in approximate tokens. Zero uses diffcontext.DefaultTokenBudget and a
+	// negative value sends file names only.
+	DiffTokenBudget int
+
+	// AutoCommit commits the regenerated index.md files of a commit range
+	// update on their own once all jobs finish. Only honored by Run.
+	AutoCommit bool
+
+	// CommitMessage is the auto-commit message; empty uses DefaultCommitMessage.
+	// [skip-docs] and a Docs-Source-Range trailer are always added.
+	CommitMessage string
+
+	// DryRun has Claude edit temporary copies of the affected index.md files
+	// and reports the results as proposals instead of changing anything.
+	// Tracking state is neither initialized nor advanced.
+	DryRun bool
 }
+
+// JobLogName is the file in SessionPath where index update jobs are recorded
+const JobLogName = "doc_jobs.json"
+
+// LockFileName is the file in SessionPath that is held while docs are updated
+const LockFileName = "doc_update.lock"
+
+// ProposalDir is the directory in SessionPath holding the index.md copies a
+// dry run has Claude edit
+const ProposalDir = "doc_proposals"
+
+// Proposal is a reviewable index.md change produced by a dry run
+type Proposal struct {
+	// IndexPath is the absolute path of the index.md file
+	IndexPath string
+
+	// Original is the current content of the file
+	Original string
+
+	// Proposed is the content Claude produced
+	Proposed string
+}
+
+// Changed reports whether the proposal differs from the current content
+func (p Proposal) Changed() bool {
+	return p.Original != p.Proposed
+}
+
+// ChangeSet selects which uncommitted changes RunUncommitted reads
+type ChangeSet string
+
+const (
+	// ChangeSetStaged uses files staged in the index
+	ChangeSetStaged ChangeSet = "staged"
+
+	// ChangeSetWorktree uses working tree changes relative to HEAD, including untracked files
+	ChangeSetWorktree ChangeSet = "worktree"
+)
@@ -0,0 +1,129 @@
+package rangeupdater
+
+import (
+	"fmt"
+	"log"
+)
+
+// RunUncommitted updates the index.md files affected by uncommitted changes.
+// Changed files come from the index (ChangeSetStaged) or the working tree
+// (ChangeSetWorktree) instead of a commit range, so tracking state is left
+// untouched. When StageDocs is set, regenerated indexes are added to the index
+// so a pre-commit hook can include them in the commit being created.
+func (ru *RangeUpdater) RunUncommitted(changeSet ChangeSet) (*UpdateResult, error) {
+	// Step 1: Acquire lock (skip if locked)
+	lock, lockedResult, err := ru.acquireLock()
+	if lock == nil {
+		return lockedResult, err
+	}
+	defer lock.Release()
+
+	// Step 2: Collect uncommitted changes
+	var changedFiles []string
+	switch changeSet {
+	case ChangeSetStaged:
+		changedFiles, err = ru.gitSvc.GetStagedFiles()
+	case ChangeSetWorktree:
+		changedFiles, err = ru.gitSvc.GetWorktreeFiles()
+	default:
+		return nil, fmt.Errorf("unknown change set: %s", changeSet)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s files: %w", changeSet, err)
+	}
+
+	processedRange := fmt.Sprintf("%s changes", changeSet)
+	if len(changedFiles) == 0 {
+		return &UpdateResult{
+			Status:         "skipped",
+			Reason:         "no files changed",
+			ProcessedRange: processedRange,
+		}, nil
+	}
+
+	// Step 3: Apply skip rules (no commit message exists yet)
+	if shouldSkip, reason := ShouldSkip(changedFiles, "", ru.env); shouldSkip {
+		return &UpdateResult{
+			Status:         "skipped",
+			Reason:         reason,
+			ProcessedRange: processedRange,
+		}, nil
+	}
+
+	// Step 4: Map files to affected index.md, skipping ones already edited
+	affectedIndexes, err := ResolveAffectedIndexes(ru.fs, ru.config.DocFilenames, changedFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve affected indexes: %w", err)
+	}
+	affectedIndexes = excludeChangedIndexes(affectedIndexes, changedFiles)
+	if changeSet == ChangeSetStaged {
+		// Indexes with unstaged edits would be regenerated on top of them,
+		// and staging the result would sweep those edits into the commit
+		worktreeFiles, err := ru.gitSvc.GetWorktreeFiles()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s files: %w", ChangeSetWorktree, err)
+		}
+		affectedIndexes = excludeDirtyIndexes(affectedIndexes, worktreeFiles)
+	}
+
+	if len(affectedIndexes) == 0 {
+		return &UpdateResult{
+			Status:         "success",
+			Reason:         "no indexes affected by changes",
+			ProcessedRange: processedRange,
+		}, nil
+	}
+
+	// Step 5: Update each index via Claude, holding the lock until all jobs finish
+	diffs := ru.collectUncommittedDiff(changeSet)
+	proposals, failed := ru.updateIndexes(affectedIndexes, changedFiles, diffs)
+
+	// Step 6: Stage regenerated docs so they land in the same commit
+	if ru.config.StageDocs && !ru.config.DryRun {
+		if err := ru.gitSvc.StageFiles(succeeded(affectedIndexes, failed)...); err != nil {
+			return nil, fmt.Errorf("failed to stage updated docs: %w", err)
+		}
+	}
+
+	// HEAD is informational here (an unborn branch has none)
+	headSHA, _ := ru.gitSvc.GetCurrentSHA()
+
+	return &UpdateResult{
+		Status:          "success",
+		AffectedIndexes: affectedIndexes,
+		ProcessedRange:  processedRange,
+		HeadSHA:         headSHA,
+		Proposals:       proposals,
+		FailedIndexes:   failed,
+	}, nil
+}
+
+// succeeded returns the indexes not listed in failed
+func succeeded(indexes, failed []string) []string {
+	skip := make(map[string]bool, len(failed))
+	for _, f := range failed {
+		skip[f] = true
+	}
+	var result []string
+	for _, idx := range indexes {
+		if !skip[idx] {
+			result = append(result, idx)
+		}
+	}
+	return result
+}
+
+// excludeDirtyIndexes drops indexes that have uncommitted changes in the
+// working tree
+func excludeDirtyIndexes(indexes, worktreeFiles []string) []string {
+	dirty := absPaths(worktreeFiles)
+	var result []string
+	for _, indexPath := range indexes {
+		if dirty[indexPath] {
+			log.Printf("Skipping %s: it has unstaged changes", indexPath)
+			continue
+		}
+		result = append(result, indexPath)
+	}
+	return result
+}
@@ -7,12 +7,17 @@ import (
 	"fmt"
 	"log"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
-	"claudex/internal/services/commander"
+	"claudex/internal/doc/diffcontext"
+	"claudex/internal/services/docfiles"
 	"claudex/internal/services/doctracking"
 	"claudex/internal/services/env"
 	"claudex/internal/services/git"
+	"claudex/internal/services/ignore"
+	"claudex/internal/services/jobs"
 	"claudex/internal/services/lock"
 
 	"github.com/spf13/afero"
@@ -29,8 +34,28 @@ type UpdateResult struct {
 	// AffectedIndexes lists the index.md files that were updated
 	AffectedIndexes []string
 
+	// Branch is the branch whose tracking state was used and updated
+	Branch string
+
 	// ProcessedRange indicates the commit range that was processed
 	ProcessedRange string
+
+	// Fallback is set when the tracked base was unreachable and a merge-base
+	// fallback was used instead; nil otherwise
+	Fallback *FallbackBase
+
+	// HeadSHA is the commit the update was computed against
+	HeadSHA string
+
+	// Proposals holds the changed index.md files of a dry run; nil otherwise
+	Proposals []Proposal
+
+	// FailedIndexes lists the index.md files whose Claude job failed or timed out
+	FailedIndexes []string
+
+	// DocsCommit is the SHA of the auto-commit holding the regenerated docs;
+	// empty when nothing was committed
+	DocsCommit string
 }
 
 // RangeUpdater orchestrates range-based documentation updates
@@ -39,58 +64,55 @@ type RangeUpdater struct {
 	gitSvc      git.GitService
 	lockSvc     lock.LockService
 	trackingSvc doctracking.TrackingService
-	cmdr        commander.Commander
 	fs          afero.Fs
 	env         env.Environment
+	supervisor  *jobs.Supervisor
 }
 
-// New creates a new RangeUpdater instance
+// New creates a new RangeUpdater instance; executor runs the Claude jobs
+// that update index files
 func New(
 	config RangeUpdaterConfig,
 	gitSvc git.GitService,
 	lockSvc lock.LockService,
 	trackingSvc doctracking.TrackingService,
-	cmdr commander.Commander,
+	executor jobs.Executor,
 	fs afero.Fs,
 	env env.Environment,
 ) *RangeUpdater {
+	config.DocFilenames = docfiles.New(config.DocFilenames)
 	return &RangeUpdater{
 		config:      config,
 		gitSvc:      gitSvc,
 		lockSvc:     lockSvc,
 		trackingSvc: trackingSvc,
-		cmdr:        cmdr,
 		fs:          fs,
 		env:         env,
+		supervisor: jobs.New(fs, filepath.Join(config.SessionPath, JobLogName), jobs.Options{
+			MaxConcurrent: config.MaxJobs,
+			Timeout:       config.JobTimeout,
+		}, executor),
 	}
 }
 
 // Run executes the main update flow
 func (ru *RangeUpdater) Run() (*UpdateResult, error) {
 	// Step 1: Acquire lock (skip if locked)
-	lockPath := filepath.Join(ru.config.SessionPath, "doc_update.lock")
-	isLocked, err := ru.lockSvc.IsLocked(lockPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check lock status: %w", err)
-	}
-	if isLocked {
-		return &UpdateResult{
-			Status: "locked",
-			Reason: "another update process is running",
-		}, nil
+	lock, lockedResult, err := ru.acquireLock()
+	if lock == nil {
+		return lockedResult, err
 	}
+	defer lock.Release()
 
-	lock, err := ru.lockSvc.Acquire(lockPath)
+	// Step 2: Resolve the current branch and drop tracking for deleted branches
+	branch, err := ru.gitSvc.GetCurrentBranch()
 	if err != nil {
-		return &UpdateResult{
-			Status: "locked",
-			Reason: "failed to acquire lock",
-		}, nil
+		return nil, fmt.Errorf("failed to get current branch: %w", err)
 	}
-	defer lock.Release()
+	ru.pruneTracking(branch)
 
-	// Step 2: Read tracking to get base SHA
-	tracking, err := ru.trackingSvc.Read()
+	// Step 3: Read tracking to get base SHA
+	tracking, err := ru.trackingSvc.Read(branch)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read tracking: %w", err)
 	}
@@ -101,99 +123,191 @@ func (ru *RangeUpdater) Run() (*UpdateResult, error) {
 		return nil, fmt.Errorf("failed to get current SHA: %w", err)
 	}
 
-	// Initialize tracking if this is the first run
-	if tracking.LastProcessedCommit == "" {
-		log.Printf("No tracking found, initializing with HEAD: %s", headSHA)
-		if err := ru.trackingSvc.Initialize(headSHA); err != nil {
-			return nil, fmt.Errorf("failed to initialize tracking: %w", err)
-		}
-		return &UpdateResult{
-			Status: "success",
-			Reason: "initialized tracking",
-		}, nil
+	// Commits already processed on other branches are not new to this one
+	exclude, err := ru.otherBranchCommits(branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tracking: %w", err)
 	}
 
-	// Check if HEAD has changed
-	if tracking.LastProcessedCommit == headSHA {
+	if tracking.LastProcessedCommit == "" {
+		// Initialize tracking if this is the first run in the repository
+		if len(exclude) == 0 {
+			if ru.config.DryRun {
+				return &UpdateResult{
+					Status:  "skipped",
+					Reason:  "no tracking yet; run an update first",
+					Branch:  branch,
+					HeadSHA: headSHA,
+				}, nil
+			}
+			log.Printf("No tracking found, initializing %s with HEAD: %s", branch, headSHA)
+			if err := ru.trackingSvc.Initialize(branch, headSHA); err != nil {
+				return nil, fmt.Errorf("failed to initialize tracking: %w", err)
+			}
+			return &UpdateResult{
+				Status: "success",
+				Reason: "initialized tracking",
+				Branch: branch,
+			}, nil
+		}
+		log.Printf("No tracking for branch %s, using commits not processed on other branches", branch)
+	} else if tracking.LastProcessedCommit == headSHA {
+		// Check if HEAD has changed
 		return &UpdateResult{
 			Status: "skipped",
 			Reason: "no new commits since last update",
+			Branch: branch,
 		}, nil
 	}
 
 	baseSHA := tracking.LastProcessedCommit
+	var fallback *FallbackBase
+
+	// Step 4: Validate SHA reachability (fallback if unreachable)
+	if baseSHA != "" {
+		valid, err := ru.gitSvc.ValidateCommit(baseSHA)
+		if err != nil || !valid {
+			log.Printf("Base commit %s is unreachable, attempting fallback", baseSHA)
+			fallback, err = HandleUnreachableBase(ru.gitSvc, ru.config.DefaultBranch)
+			if err != nil {
+				return nil, fmt.Errorf("failed to handle unreachable base: %w", err)
+			}
+			baseSHA = fallback.SHA
+			log.Printf("Using fallback base: %s", fallback)
+		}
+	}
 
-	// Step 3: Validate SHA reachability (fallback if unreachable)
-	valid, err := ru.gitSvc.ValidateCommit(baseSHA)
-	if err != nil || !valid {
-		log.Printf("Base commit %s is unreachable, attempting fallback", baseSHA)
-		fallbackSHA, err := HandleUnreachableBase(ru.gitSvc, ru.config.DefaultBranch)
-		if err != nil {
-			return nil, fmt.Errorf("failed to handle unreachable base: %w", err)
+	processedRange := formatRange(baseSHA, headSHA)
+
+	// Step 5: List commits new to this branch and drop those tagged [skip-docs]
+	commits, err := ru.gitSvc.GetCommits(baseSHA, headSHA, exclude...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+	kept, skipped := PartitionCommits(commits)
+	processedSHAs := commitSHAs(kept)
+	skippedSHAs := commitSHAs(skipped)
+
+	// Per-commit file lists are needed whenever the range diff would include
+	// commits that are not ours to process
+	filtered := len(skipped) > 0 || len(exclude) > 0 || baseSHA == ""
+
+	// Everything in the range was already processed on another branch
+	if filtered && len(commits) == 0 {
+		if err := ru.updateTracking(branch, headSHA, nil, nil); err != nil {
+			return nil, err
+		}
+		return &UpdateResult{
+			Status:         "skipped",
+			Reason:         fmt.Sprintf("no commits new to branch %s", branch),
+			Branch:         branch,
+			ProcessedRange: processedRange,
+			Fallback:       fallback,
+		}, nil
+	}
+
+	// Every commit in the range opted out: record them so they are not revisited
+	if len(skipped) > 0 && len(kept) == 0 {
+		_, reason := ShouldSkip(nil, joinCommitMessages(skipped), ru.env)
+		if err := ru.updateTracking(branch, headSHA, nil, skippedSHAs); err != nil {
+			return nil, err
 		}
-		baseSHA = fallbackSHA
-		log.Printf("Using fallback base: %s", baseSHA)
+		return &UpdateResult{
+			Status:         "skipped",
+			Reason:         reason,
+			Branch:         branch,
+			ProcessedRange: processedRange,
+			Fallback:       fallback,
+		}, nil
 	}
 
-	// Step 4: Get changed files for base..HEAD
-	changedFiles, err := ru.gitSvc.GetChangedFiles(baseSHA, headSHA)
+	// Step 6: Get changed files, limited to the remaining commits when filtered
+	changedFiles, err := ru.collectChangedFiles(baseSHA, headSHA, kept, filtered)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get changed files: %w", err)
 	}
 
 	if len(changedFiles) == 0 {
 		return &UpdateResult{
-			Status: "skipped",
-			Reason: "no files changed",
+			Status:   "skipped",
+			Reason:   "no files changed",
+			Branch:   branch,
+			Fallback: fallback,
 		}, nil
 	}
 
-	// Step 5: Apply skip rules
+	// Step 7: Apply skip rules
 	shouldSkip, reason := ShouldSkip(changedFiles, "", ru.env)
 	if shouldSkip {
 		return &UpdateResult{
 			Status:         "skipped",
 			Reason:         reason,
-			ProcessedRange: fmt.Sprintf("%s..%s", shortSHA(baseSHA), shortSHA(headSHA)),
+			Branch:         branch,
+			ProcessedRange: processedRange,
+			Fallback:       fallback,
 		}, nil
 	}
 
-	// Step 6: Map files to affected index.md
-	affectedIndexes, err := ResolveAffectedIndexes(ru.fs, changedFiles)
+	// Step 8: Map files to affected index.md, leaving alone indexes that were
+	// already updated alongside the code (e.g., by the pre-commit hook)
+	affectedIndexes, err := ResolveAffectedIndexes(ru.fs, ru.config.DocFilenames, changedFiles)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve affected indexes: %w", err)
 	}
+	affectedIndexes = excludeChangedIndexes(affectedIndexes, changedFiles)
 
 	if len(affectedIndexes) == 0 {
 		// No indexes affected, but still update tracking
-		if err := ru.updateTracking(headSHA); err != nil {
+		if err := ru.updateTracking(branch, headSHA, processedSHAs, skippedSHAs); err != nil {
 			return nil, err
 		}
 		return &UpdateResult{
 			Status:         "success",
 			Reason:         "no indexes affected by changes",
-			ProcessedRange: fmt.Sprintf("%s..%s", shortSHA(baseSHA), shortSHA(headSHA)),
+			Branch:         branch,
+			ProcessedRange: processedRange,
+			Fallback:       fallback,
 		}, nil
 	}
 
-	// Step 7: Update each index via Claude
-	log.Printf("Updating %d index.md files", len(affectedIndexes))
-	for _, indexPath := range affectedIndexes {
-		if err := ru.updateIndex(indexPath, changedFiles); err != nil {
-			log.Printf("Warning: failed to update %s: %v", indexPath, err)
-			// Continue with other indexes even if one fails
+	// Remember which files were already dirty so auto-commit leaves them alone
+	var dirtyBefore map[string]bool
+	autoCommit := ru.config.AutoCommit && !ru.config.DryRun
+	if autoCommit {
+		dirtyBefore = ru.dirtyFiles()
+	}
+
+	// Step 9: Update each index via Claude, holding the lock until all jobs finish
+	diffs := ru.collectRangeDiff(baseSHA, headSHA, kept, filtered, changedFiles)
+	proposals, failed := ru.updateIndexes(affectedIndexes, changedFiles, diffs)
+
+	// Step 10: Commit the regenerated docs; the lock keeps the post-commit
+	// hook fired by this commit from starting another update
+	var docsCommit string
+	trackedHead := headSHA
+	if autoCommit {
+		docsCommit = ru.commitDocs(succeeded(affectedIndexes, failed), dirtyBefore, baseSHA, headSHA)
+		if docsCommit != "" {
+			trackedHead = docsCommit
+			skippedSHAs = append(skippedSHAs, docsCommit)
 		}
 	}
 
-	// Step 8: Write tracking with new HEAD
-	if err := ru.updateTracking(headSHA); err != nil {
+	// Step 11: Write tracking with new HEAD (past the docs commit, if any)
+	if err := ru.updateTracking(branch, trackedHead, processedSHAs, skippedSHAs); err != nil {
 		return nil, err
 	}
 
 	return &UpdateResult{
 		Status:          "success",
 		AffectedIndexes: affectedIndexes,
-		ProcessedRange:  fmt.Sprintf("%s..%s", shortSHA(baseSHA), shortSHA(headSHA)),
+		Branch:          branch,
+		ProcessedRange:  processedRange,
+		Fallback:        fallback,
+		HeadSHA:         headSHA,
+		Proposals:       proposals,
+		FailedIndexes:   failed,
+		DocsCommit:      docsCommit,
 	}, nil
 }
 
@@ -205,26 +319,162 @@ func shortSHA(sha string) string {
 	return sha
 }
 
-// updateIndex updates a single index.md file via Claude
-func (ru *RangeUpdater) updateIndex(indexPath string, changedFiles []string) error {
+// collectChangedFiles returns the files changed in base..head.
+// When the range was filtered (skipped commits, commits already processed on
+// other branches, or no base), only files touched by the kept commits are
+// returned (de-duplicated, in first-seen order); otherwise the range diff is used.
+func (ru *RangeUpdater) collectChangedFiles(baseSHA, headSHA string, kept []git.Commit, filtered bool) ([]string, error) {
+	if !filtered {
+		return ru.gitSvc.GetChangedFiles(baseSHA, headSHA)
+	}
+
+	seen := make(map[string]bool)
+	var files []string
+	for _, c := range kept {
+		commitFiles, err := ru.gitSvc.GetCommitFiles(c.SHA)
+		if err != nil {
+			return nil, fmt.Errorf("commit %s: %w", shortSHA(c.SHA), err)
+		}
+		for _, f := range commitFiles {
+			if !seen[f] {
+				seen[f] = true
+				files = append(files, f)
+			}
+		}
+	}
+	return files, nil
+}
+
+// commitSHAs extracts the SHAs from a list of commits
+func commitSHAs(commits []git.Commit) []string {
+	var shas []string
+	for _, c := range commits {
+		shas = append(shas, c.SHA)
+	}
+	return shas
+}
+
+// joinCommitMessages concatenates commit messages for skip rule evaluation
+func joinCommitMessages(commits []git.Commit) string {
+	messages := make([]string, 0, len(commits))
+	for _, c := range commits {
+		messages = append(messages, c.Message)
+	}
+	return strings.Join(messages, "\n")
+}
+
+// acquireLock acquires the doc update lock. When the lock is held elsewhere it
+// returns a nil lock together with the "locked" result to report.
+func (ru *RangeUpdater) acquireLock() (*lock.Lock, *UpdateResult, error) {
+	lockPath := filepath.Join(ru.config.SessionPath, LockFileName)
+	isLocked, err := ru.lockSvc.IsLocked(lockPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check lock status: %w", err)
+	}
+	if isLocked {
+		return nil, &UpdateResult{
+			Status: "locked",
+			Reason: "another update process is running",
+		}, nil
+	}
+
+	l, err := ru.lockSvc.Acquire(lockPath)
+	if err != nil {
+		return nil, &UpdateResult{
+			Status: "locked",
+			Reason: "failed to acquire lock",
+		}, nil
+	}
+	return l, nil, nil
+}
+
+// excludeChangedIndexes drops indexes that are themselves among the changed files
+func excludeChangedIndexes(indexes, changedFiles []string) []string {
+	changed := absPaths(changedFiles)
+	var result []string
+	for _, indexPath := range indexes {
+		if changed[indexPath] {
+			log.Printf("Skipping %s: already updated in this change", indexPath)
+			continue
+		}
+		result = append(result, indexPath)
+	}
+	return result
+}
+
+// absPaths returns the set of files as absolute paths; git reports them
+// relative to the repository root, the hooks' working directory
+func absPaths(files []string) map[string]bool {
+	set := make(map[string]bool, len(files))
+	for _, file := range files {
+		if absPath, err := filepath.Abs(file); err == nil {
+			set[absPath] = true
+		}
+	}
+	return set
+}
+
+// updateIndexes runs a supervised Claude job per affected index.md and waits
+// for all of them. Failed indexes are returned; the rest of the batch still runs.
+// In dry-run mode nothing is edited and the changed proposals are returned instead.
+func (ru *RangeUpdater) updateIndexes(affectedIndexes, changedFiles []string, diffs []diffcontext.FileDiff) ([]Proposal, []string) {
+	// Recursion guard: check if we're already inside a hook invocation
+	if ru.env.Get(RecursionGuardEnv) == "1" {
+		log.Printf("Skipping %d index updates: recursion guard triggered", len(affectedIndexes))
+		return nil, nil
+	}
+
+	if ru.config.DryRun {
+		return ru.proposeIndexes(affectedIndexes, changedFiles, diffs)
+	}
+
+	log.Printf("Updating %d index.md files", len(affectedIndexes))
+	var batch []jobs.Job
+	var failed []string
+	for _, indexPath := range affectedIndexes {
+		prompt, err := ru.indexPrompt(indexPath, changedFiles, diffs)
+		if err != nil {
+			log.Printf("Warning: failed to update %s: %v", indexPath, err)
+			failed = append(failed, indexPath)
+			continue
+		}
+		batch = append(batch, claudeJob(indexPath, prompt))
+	}
+
+	return nil, append(failed, failedJobs(ru.supervisor.Run(batch))...)
+}
+
+// indexPrompt builds the Claude prompt that updates indexPath in place
+func (ru *RangeUpdater) indexPrompt(indexPath string, changedFiles []string, diffs []diffcontext.FileDiff) (string, error) {
 	indexDir := filepath.Dir(indexPath)
 
 	// Get directory listing for context
 	listing, err := ru.getDirectoryListing(indexDir)
 	if err != nil {
-		return fmt.Errorf("failed to get directory listing: %w", err)
+		return "", fmt.Errorf("failed to get directory listing: %w", err)
 	}
 
 	// Format changed files for context
 	filesContext := formatChangedFilesContext(changedFiles, indexDir)
 
-	// Invoke Claude to update the index file directly
-	return InvokeClaudeForIndex(ru.cmdr, ru.env, indexPath, listing, filesContext)
+	return buildPrompt(ru.config.DocFilenames, indexPath, listing, filesContext, ru.diffContext(indexPath, diffs)), nil
 }
 
-// getDirectoryListing returns a formatted listing of files in the directory
+// failedJobs returns the names (index paths) of jobs that did not succeed
+func failedJobs(records []jobs.Record) []string {
+	var failed []string
+	for _, r := range records {
+		if r.Status != jobs.StatusSucceeded {
+			failed = append(failed, r.Name)
+		}
+	}
+	return failed
+}
+
+// getDirectoryListing returns a formatted listing of files in the directory,
+// leaving out entries ignored by .gitignore or .claudexignore
 func (ru *RangeUpdater) getDirectoryListing(dir string) (string, error) {
-	entries, err := afero.ReadDir(ru.fs, dir)
+	entries, err := ignore.New(ru.fs, dir).ReadDir(dir)
 	if err != nil {
 		return "", fmt.Errorf("failed to read directory: %w", err)
 	}
@@ -271,15 +521,79 @@ func formatChangedFilesContext(changedFiles []string, indexDir string) string {
 	return result
 }
 
-// updateTracking updates the tracking state with the new HEAD SHA
-func (ru *RangeUpdater) updateTracking(headSHA string) error {
+// updateTracking updates the branch's tracking state with the new HEAD SHA and
+// the commits that were processed or skipped in this run
+func (ru *RangeUpdater) updateTracking(branch, headSHA string, processed, skipped []string) error {
+	if ru.config.DryRun {
+		log.Printf("Dry run: leaving tracking for %s unchanged", branch)
+		return nil
+	}
+
 	tracking := doctracking.DocUpdateTracking{
 		LastProcessedCommit: headSHA,
 		UpdatedAt:           time.Now().Format(time.RFC3339),
-		StrategyVersion:     "v1",
+		StrategyVersion:     doctracking.StrategyVersion,
+		ProcessedCommits:    processed,
+		SkippedCommits:      skipped,
 	}
-	if err := ru.trackingSvc.Write(tracking); err != nil {
+	if err := ru.trackingSvc.Write(branch, tracking); err != nil {
 		return fmt.Errorf("failed to write tracking: %w", err)
 	}
 	return nil
 }
+
+// pruneTracking removes tracking entries for branches that no longer exist.
+// Failures are logged and ignored; stale entries only cost a little precision.
+func (ru *RangeUpdater) pruneTracking(currentBranch string) {
+	if ru.config.DryRun {
+		return
+	}
+
+	branches, err := ru.gitSvc.ListBranches()
+	if err != nil {
+		log.Printf("Warning: could not list branches for tracking cleanup: %v", err)
+		return
+	}
+
+	removed, err := ru.trackingSvc.Prune(append(branches, currentBranch))
+	if err != nil {
+		log.Printf("Warning: could not prune tracking entries: %v", err)
+		return
+	}
+	if len(removed) > 0 {
+		log.Printf("Pruned tracking for deleted branches: %s", strings.Join(removed, ", "))
+	}
+}
+
+// otherBranchCommits returns the reachable last-processed commits of all
+// tracked branches other than the given one, sorted for deterministic output
+func (ru *RangeUpdater) otherBranchCommits(branch string) ([]string, error) {
+	all, err := ru.trackingSvc.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var shas []string
+	for name, tracking := range all {
+		sha := tracking.LastProcessedCommit
+		if name == branch || sha == "" || seen[sha] {
+			continue
+		}
+		seen[sha] = true
+		if valid, err := ru.gitSvc.ValidateCommit(sha); err != nil || !valid {
+			continue
+		}
+		shas = append(shas, sha)
+	}
+	sort.Strings(shas)
+	return shas, nil
+}
+
+// formatRange describes the processed range for result output
+func formatRange(baseSHA, headSHA string) string {
+	if baseSHA == "" {
+		return shortSHA(headSHA)
+	}
+	return fmt.Sprintf("%s..%s", shortSHA(baseSHA), shortSHA(headSHA))
+}
@@ -1,13 +1,20 @@
 package rangeupdater
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"claudex/internal/services/docfiles"
 	"claudex/internal/services/doctracking"
+	"claudex/internal/services/git"
+	"claudex/internal/services/jobs"
 	"claudex/internal/services/lock"
 
 	"github.com/spf13/afero"
@@ -23,6 +30,23 @@ type mockGitService struct {
 	validateError  error
 	changedError   error
 	mergeBaseError error
+	commits        []git.Commit
+	commitFiles    map[string][]string
+	defaultBranch  string
+	branch         string
+	branches       []string
+	excluded       []string
+	stagedFiles    []string
+	worktreeFiles  []string
+	stagedPaths    []string
+	appliedPatch   string
+	applyError     error
+	commitMessage  string
+	committedPaths []string
+	newCommitSHA   string // HEAD after CommitFiles, when set
+	diff           string
+	diffError      error
+	diffCalls      [][]string
 }
 
 func (m *mockGitService) GetCurrentSHA() (string, error) {
@@ -47,6 +71,65 @@ func (m *mockGitService) GetMergeBase(branch string) (string, error) {
 	return m.mergeBase, nil
 }
 
+func (m *mockGitService) GetCommits(base, head string, exclude ...string) ([]git.Commit, error) {
+	m.excluded = exclude
+	return m.commits, nil
+}
+
+func (m *mockGitService) GetCommitFiles(sha string) ([]string, error) {
+	return m.commitFiles[sha], nil
+}
+
+func (m *mockGitService) GetDefaultBranch() (string, string, error) {
+	if m.defaultBranch == "" {
+		return "", "", fmt.Errorf("no default branch")
+	}
+	return m.defaultBranch, git.DefaultBranchSourceRemote, nil
+}
+
+func (m *mockGitService) GetCurrentBranch() (string, error) {
+	if m.branch == "" {
+		return "main", nil
+	}
+	return m.branch, nil
+}
+
+func (m *mockGitService) ListBranches() ([]string, error) {
+	return m.branches, nil
+}
+
+func (m *mockGitService) GetStagedFiles() ([]string, error) {
+	return m.stagedFiles, nil
+}
+
+func (m *mockGitService) GetWorktreeFiles() ([]string, error) {
+	return m.worktreeFiles, nil
+}
+
+func (m *mockGitService) StageFiles(paths ...string) error {
+	m.stagedPaths = append(m.stagedPaths, paths...)
+	return nil
+}
+
+func (m *mockGitService) ApplyPatch(patchPath string) error {
+	m.appliedPatch = patchPath
+	return m.applyError
+}
+
+func (m *mockGitService) CommitFiles(message string, paths ...string) error {
+	m.commitMessage = message
+	m.committedPaths = paths
+	if m.newCommitSHA != "" {
+		m.currentSHA = m.newCommitSHA
+	}
+	return nil
+}
+
+func (m *mockGitService) GetDiff(base, head string, paths ...string) (string, error) {
+	m.diffCalls = append(m.diffCalls, append([]string{base, head}, paths...))
+	return m.diff, m.diffError
+}
+
 type mockLockService struct {
 	isLocked     bool
 	acquireFails bool
@@ -72,6 +155,17 @@ func (m *mockLockService) IsLocked(path string) (bool, error) {
 	return m.isLocked, nil
 }
 
+func (m *mockLockService) Inspect(path string) (*lock.Info, error) {
+	if !m.isLocked {
+		return nil, nil
+	}
+	return &lock.Info{Path: path}, nil
+}
+
+func (m *mockLockService) Clear(path string, force bool) (*lock.Info, error) {
+	return nil, nil
+}
+
 type mockFile struct{}
 
 func (m *mockFile) Close() error                                  { return nil }
@@ -94,45 +188,61 @@ func (m *mockFile) WriteString(s string) (ret int, err error) {
 	return len(s), nil
 }
 
+// mockTrackingService keeps the current branch's state in tracking and any
+// other branches' state in others
 type mockTrackingService struct {
 	tracking    doctracking.DocUpdateTracking
+	others      map[string]doctracking.DocUpdateTracking
 	writeError  error
 	writeCalled bool
+	writeBranch string
+	pruned      []string
 }
 
-func (m *mockTrackingService) Read() (doctracking.DocUpdateTracking, error) {
+func (m *mockTrackingService) Read(branch string) (doctracking.DocUpdateTracking, error) {
 	return m.tracking, nil
 }
 
-func (m *mockTrackingService) Write(tracking doctracking.DocUpdateTracking) error {
+func (m *mockTrackingService) ReadAll() (map[string]doctracking.DocUpdateTracking, error) {
+	all := make(map[string]doctracking.DocUpdateTracking)
+	for name, tracking := range m.others {
+		all[name] = tracking
+	}
+	return all, nil
+}
+
+func (m *mockTrackingService) Write(branch string, tracking doctracking.DocUpdateTracking) error {
 	if m.writeError != nil {
 		return m.writeError
 	}
 	m.tracking = tracking
 	m.writeCalled = true
+	m.writeBranch = branch
 	return nil
 }
 
-func (m *mockTrackingService) Initialize(headSHA string) error {
+func (m *mockTrackingService) Initialize(branch, headSHA string) error {
 	m.tracking = doctracking.DocUpdateTracking{
 		LastProcessedCommit: headSHA,
 		UpdatedAt:           time.Now().Format(time.RFC3339),
-		StrategyVersion:     "v1",
+		StrategyVersion:     doctracking.StrategyVersion,
 	}
+	m.writeBranch = branch
 	return nil
 }
 
-type mockCommander struct {
-	output []byte
-	err    error
-}
-
-func (m *mockCommander) Run(name string, args ...string) ([]byte, error) {
-	return m.output, m.err
-}
-
-func (m *mockCommander) Start(name string, stdin io.Reader, stdout, stderr io.Writer, args ...string) error {
-	return nil
+func (m *mockTrackingService) Prune(liveBranches []string) ([]string, error) {
+	live := make(map[string]bool)
+	for _, b := range liveBranches {
+		live[b] = true
+	}
+	for name := range m.others {
+		if !live[name] {
+			delete(m.others, name)
+			m.pruned = append(m.pruned, name)
+		}
+	}
+	return m.pruned, nil
 }
 
 type mockEnvironment struct {
@@ -150,6 +260,22 @@ func (m *mockEnvironment) Set(key, value string) {
 	m.vars[key] = value
 }
 
+// mockExecutor runs jobs in-process; exitCodes maps job names to exit codes
+type mockExecutor struct {
+	exitCodes map[string]int
+	onExecute func(job jobs.Job)
+	executed  []string
+}
+
+func (m *mockExecutor) Execute(ctx context.Context, job jobs.Job, started func(pid int)) jobs.Result {
+	started(1234)
+	m.executed = append(m.executed, job.Name)
+	if m.onExecute != nil {
+		m.onExecute(job)
+	}
+	return jobs.Result{ExitCode: m.exitCodes[job.Name]}
+}
+
 // Test cases
 
 func TestRangeUpdater_Run_FirstRun_Initializes(t *testing.T) {
@@ -162,7 +288,6 @@ func TestRangeUpdater_Run_FirstRun_Initializes(t *testing.T) {
 	}
 	lockSvc := newMockLockService()
 	trackingSvc := &mockTrackingService{}
-	cmdr := &mockCommander{}
 	env := &mockEnvironment{vars: make(map[string]string)}
 
 	config := RangeUpdaterConfig{
@@ -170,7 +295,7 @@ func TestRangeUpdater_Run_FirstRun_Initializes(t *testing.T) {
 		DefaultBranch: "main",
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, &mockExecutor{}, fs, env)
 	result, err := updater.Run()
 
 	if err != nil {
@@ -200,7 +325,6 @@ func TestRangeUpdater_Run_NoNewCommits_Skips(t *testing.T) {
 			LastProcessedCommit: "abc123", // Same as current
 		},
 	}
-	cmdr := &mockCommander{}
 	env := &mockEnvironment{vars: make(map[string]string)}
 
 	config := RangeUpdaterConfig{
@@ -208,7 +332,7 @@ func TestRangeUpdater_Run_NoNewCommits_Skips(t *testing.T) {
 		DefaultBranch: "main",
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, &mockExecutor{}, fs, env)
 	result, err := updater.Run()
 
 	if err != nil {
@@ -235,7 +359,6 @@ func TestRangeUpdater_Run_Locked_SkipsWithLockStatus(t *testing.T) {
 	lockSvc := newMockLockService()
 	lockSvc.isLocked = true // Already locked
 	trackingSvc := &mockTrackingService{}
-	cmdr := &mockCommander{}
 	env := &mockEnvironment{vars: make(map[string]string)}
 
 	config := RangeUpdaterConfig{
@@ -243,7 +366,7 @@ func TestRangeUpdater_Run_Locked_SkipsWithLockStatus(t *testing.T) {
 		DefaultBranch: "main",
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, &mockExecutor{}, fs, env)
 	result, err := updater.Run()
 
 	if err != nil {
@@ -271,7 +394,6 @@ func TestRangeUpdater_Run_SkipRules_AllMarkdown(t *testing.T) {
 			LastProcessedCommit: "abc123",
 		},
 	}
-	cmdr := &mockCommander{}
 	env := &mockEnvironment{vars: make(map[string]string)}
 
 	config := RangeUpdaterConfig{
@@ -279,7 +401,7 @@ func TestRangeUpdater_Run_SkipRules_AllMarkdown(t *testing.T) {
 		DefaultBranch: "main",
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, &mockExecutor{}, fs, env)
 	result, err := updater.Run()
 
 	if err != nil {
@@ -311,7 +433,6 @@ func TestRangeUpdater_Run_SkipRules_EnvVar(t *testing.T) {
 			LastProcessedCommit: "abc123",
 		},
 	}
-	cmdr := &mockCommander{}
 	env := &mockEnvironment{
 		vars: map[string]string{
 			"CLAUDEX_SKIP_DOCS": "1",
@@ -323,7 +444,7 @@ func TestRangeUpdater_Run_SkipRules_EnvVar(t *testing.T) {
 		DefaultBranch: "main",
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, &mockExecutor{}, fs, env)
 	result, err := updater.Run()
 
 	if err != nil {
@@ -356,7 +477,6 @@ func TestRangeUpdater_Run_UnreachableBase_UsesFallback(t *testing.T) {
 			LastProcessedCommit: "unreachable",
 		},
 	}
-	cmdr := &mockCommander{}
 	env := &mockEnvironment{vars: make(map[string]string)}
 
 	config := RangeUpdaterConfig{
@@ -368,7 +488,7 @@ func TestRangeUpdater_Run_UnreachableBase_UsesFallback(t *testing.T) {
 	fs.MkdirAll("/src", 0755)
 	afero.WriteFile(fs, "/src/index.md", []byte("# Index"), 0644)
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, &mockExecutor{}, fs, env)
 	result, err := updater.Run()
 
 	// Should succeed with fallback
@@ -384,6 +504,11 @@ func TestRangeUpdater_Run_UnreachableBase_UsesFallback(t *testing.T) {
 	if !trackingSvc.writeCalled {
 		t.Error("expected tracking to be updated")
 	}
+
+	// Verify the fallback is recorded in the result
+	if result.Fallback == nil || result.Fallback.SHA != "fallback123" {
+		t.Errorf("expected fallback to be recorded, got %+v", result.Fallback)
+	}
 }
 
 func TestRangeUpdater_Run_NoAffectedIndexes_UpdatesTracking(t *testing.T) {
@@ -402,7 +527,6 @@ func TestRangeUpdater_Run_NoAffectedIndexes_UpdatesTracking(t *testing.T) {
 			LastProcessedCommit: "abc123",
 		},
 	}
-	cmdr := &mockCommander{}
 	env := &mockEnvironment{vars: make(map[string]string)}
 
 	config := RangeUpdaterConfig{
@@ -410,7 +534,7 @@ func TestRangeUpdater_Run_NoAffectedIndexes_UpdatesTracking(t *testing.T) {
 		DefaultBranch: "main",
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, &mockExecutor{}, fs, env)
 	result, err := updater.Run()
 
 	if err != nil {
@@ -431,174 +555,926 @@ func TestRangeUpdater_Run_NoAffectedIndexes_UpdatesTracking(t *testing.T) {
 	}
 }
 
-func TestResolveAffectedIndexes_MultipleIndexes(t *testing.T) {
+func TestRangeUpdater_Run_SkipDocsTag_ExcludesTaggedCommits(t *testing.T) {
 	fs := afero.NewMemMapFs()
-
-	// Create directory structure with indexes
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
 	fs.MkdirAll("/pkg/a", 0755)
 	fs.MkdirAll("/pkg/b", 0755)
 	afero.WriteFile(fs, "/pkg/a/index.md", []byte("# A"), 0644)
 	afero.WriteFile(fs, "/pkg/b/index.md", []byte("# B"), 0644)
 
-	changedFiles := []string{
-		"/pkg/a/foo.go",
-		"/pkg/a/bar.go",
-		"/pkg/b/baz.go",
+	gitSvc := &mockGitService{
+		currentSHA:     "ccc333",
+		changedFiles:   []string{"/pkg/a/foo.go", "/pkg/b/bar.go"},
+		validateResult: true,
+		commits: []git.Commit{
+			{SHA: "bbb222", Message: "feat: touch a"},
+			{SHA: "ccc333", Message: "chore: touch b [skip-docs]"},
+		},
+		commitFiles: map[string][]string{
+			"bbb222": {"/pkg/a/foo.go"},
+			"ccc333": {"/pkg/b/bar.go"},
+		},
 	}
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{
+		tracking: doctracking.DocUpdateTracking{
+			LastProcessedCommit: "aaa111",
+		},
+	}
+	env := &mockEnvironment{vars: map[string]string{"CLAUDE_HOOK_INTERNAL": "1"}}
+
+	config := RangeUpdaterConfig{
+		SessionPath:   sessionPath,
+		DefaultBranch: "main",
+	}
+
+	updater := New(config, gitSvc, lockSvc, trackingSvc, &mockExecutor{}, fs, env)
+	result, err := updater.Run()
 
-	indexes, err := ResolveAffectedIndexes(fs, changedFiles)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if len(indexes) != 2 {
-		t.Errorf("expected 2 affected indexes, got %d", len(indexes))
+	if result.Status != "success" {
+		t.Errorf("expected status 'success', got '%s'", result.Status)
 	}
 
-	// Verify both indexes are present
-	hasA := false
-	hasB := false
-	for _, idx := range indexes {
-		if idx == "/pkg/a/index.md" {
-			hasA = true
-		}
-		if idx == "/pkg/b/index.md" {
-			hasB = true
-		}
+	if len(result.AffectedIndexes) != 1 || result.AffectedIndexes[0] != "/pkg/a/index.md" {
+		t.Errorf("expected only /pkg/a/index.md to be affected, got %v", result.AffectedIndexes)
 	}
 
-	if !hasA || !hasB {
-		t.Errorf("expected both /pkg/a/index.md and /pkg/b/index.md, got %v", indexes)
+	if got := trackingSvc.tracking.ProcessedCommits; len(got) != 1 || got[0] != "bbb222" {
+		t.Errorf("expected processed commits [bbb222], got %v", got)
+	}
+
+	if got := trackingSvc.tracking.SkippedCommits; len(got) != 1 || got[0] != "ccc333" {
+		t.Errorf("expected skipped commits [ccc333], got %v", got)
 	}
 }
 
-func TestResolveAffectedIndexes_Deduplication(t *testing.T) {
+func TestRangeUpdater_Run_SkipDocsTag_AllTagged_SkipsAndRecords(t *testing.T) {
 	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
 
-	// Create directory structure
-	fs.MkdirAll("/pkg/a", 0755)
-	afero.WriteFile(fs, "/pkg/a/index.md", []byte("# A"), 0644)
+	gitSvc := &mockGitService{
+		currentSHA:     "ccc333",
+		changedFiles:   []string{"src/foo.go"},
+		validateResult: true,
+		commits: []git.Commit{
+			{SHA: "bbb222", Message: "fix: typo [skip-docs]"},
+			{SHA: "ccc333", Message: "chore: bump [skip-docs]"},
+		},
+	}
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{
+		tracking: doctracking.DocUpdateTracking{
+			LastProcessedCommit: "aaa111",
+		},
+	}
+	env := &mockEnvironment{vars: make(map[string]string)}
 
-	changedFiles := []string{
-		"/pkg/a/foo.go",
-		"/pkg/a/bar.go",
-		"/pkg/a/baz.go",
+	config := RangeUpdaterConfig{
+		SessionPath:   sessionPath,
+		DefaultBranch: "main",
 	}
 
-	indexes, err := ResolveAffectedIndexes(fs, changedFiles)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, &mockExecutor{}, fs, env)
+	result, err := updater.Run()
+
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if len(indexes) != 1 {
-		t.Errorf("expected 1 affected index (deduplicated), got %d", len(indexes))
+	if result.Status != "skipped" {
+		t.Errorf("expected status 'skipped', got '%s'", result.Status)
 	}
 
-	if indexes[0] != "/pkg/a/index.md" {
-		t.Errorf("expected /pkg/a/index.md, got %s", indexes[0])
+	if result.Reason != "commit message contains [skip-docs] tag" {
+		t.Errorf("unexpected reason: %s", result.Reason)
+	}
+
+	if trackingSvc.tracking.LastProcessedCommit != "ccc333" {
+		t.Errorf("expected tracking to advance to 'ccc333', got '%s'", trackingSvc.tracking.LastProcessedCommit)
+	}
+
+	if len(trackingSvc.tracking.SkippedCommits) != 2 {
+		t.Errorf("expected 2 skipped commits recorded, got %v", trackingSvc.tracking.SkippedCommits)
 	}
 }
 
-func TestShouldSkip_EnvVar(t *testing.T) {
-	env := &mockEnvironment{
-		vars: map[string]string{
-			"CLAUDEX_SKIP_DOCS": "1",
+func TestRangeUpdater_Run_NewBranch_UsesCommitsNotProcessedElsewhere(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+	fs.MkdirAll("/pkg/a", 0755)
+	afero.WriteFile(fs, "/pkg/a/index.md", []byte("# A"), 0644)
+
+	gitSvc := &mockGitService{
+		currentSHA:     "feat222",
+		validateResult: true,
+		branch:         "feature/x",
+		branches:       []string{"main", "feature/x"},
+		commits: []git.Commit{
+			{SHA: "feat222", Message: "feat: new parser"},
+		},
+		commitFiles: map[string][]string{
+			"feat222": {"/pkg/a/parser.go"},
 		},
 	}
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{
+		others: map[string]doctracking.DocUpdateTracking{
+			"main": {LastProcessedCommit: "main111"},
+		},
+	}
+	env := &mockEnvironment{vars: map[string]string{"CLAUDE_HOOK_INTERNAL": "1"}}
 
-	skip, reason := ShouldSkip([]string{"foo.go"}, "", env)
-
-	if !skip {
-		t.Error("expected skip to be true for CLAUDEX_SKIP_DOCS=1")
+	config := RangeUpdaterConfig{
+		SessionPath: sessionPath,
 	}
 
-	if reason == "" {
-		t.Error("expected reason to be set")
+	updater := New(config, gitSvc, lockSvc, trackingSvc, &mockExecutor{}, fs, env)
+	result, err := updater.Run()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-}
 
-func TestShouldSkip_CommitMessageTag(t *testing.T) {
-	env := &mockEnvironment{vars: make(map[string]string)}
+	if result.Status != "success" {
+		t.Errorf("expected status 'success', got '%s' (%s)", result.Status, result.Reason)
+	}
 
-	skip, reason := ShouldSkip([]string{"foo.go"}, "fix: typo [skip-docs]", env)
+	if len(gitSvc.excluded) != 1 || gitSvc.excluded[0] != "main111" {
+		t.Errorf("expected main's tracked commit to be excluded, got %v", gitSvc.excluded)
+	}
 
-	if !skip {
-		t.Error("expected skip to be true for [skip-docs] tag")
+	if trackingSvc.writeBranch != "feature/x" {
+		t.Errorf("expected tracking to be written for 'feature/x', got '%s'", trackingSvc.writeBranch)
 	}
 
-	if reason == "" {
-		t.Error("expected reason to be set")
+	if got := trackingSvc.tracking.ProcessedCommits; len(got) != 1 || got[0] != "feat222" {
+		t.Errorf("expected processed commits [feat222], got %v", got)
 	}
 }
 
-func TestShouldSkip_AllMarkdownFiles(t *testing.T) {
+func TestRangeUpdater_Run_AllCommitsProcessedOnOtherBranch_Skips(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+
+	gitSvc := &mockGitService{
+		currentSHA:     "merge333",
+		changedFiles:   []string{"src/foo.go"},
+		validateResult: true,
+		branches:       []string{"main", "feature/x"},
+	}
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{
+		tracking: doctracking.DocUpdateTracking{
+			LastProcessedCommit: "main111",
+		},
+		others: map[string]doctracking.DocUpdateTracking{
+			"feature/x": {LastProcessedCommit: "feat222"},
+		},
+	}
 	env := &mockEnvironment{vars: make(map[string]string)}
 
-	skip, reason := ShouldSkip([]string{"docs/readme.md", "docs/guide.md"}, "", env)
+	config := RangeUpdaterConfig{
+		SessionPath: sessionPath,
+	}
 
-	if !skip {
-		t.Error("expected skip to be true for all markdown files")
+	updater := New(config, gitSvc, lockSvc, trackingSvc, &mockExecutor{}, fs, env)
+	result, err := updater.Run()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if reason == "" {
-		t.Error("expected reason to be set")
+	if result.Status != "skipped" {
+		t.Errorf("expected status 'skipped', got '%s'", result.Status)
+	}
+
+	if trackingSvc.tracking.LastProcessedCommit != "merge333" {
+		t.Errorf("expected tracking to advance to 'merge333', got '%s'", trackingSvc.tracking.LastProcessedCommit)
 	}
 }
 
-func TestShouldSkip_MixedFiles_NoSkip(t *testing.T) {
+func TestRangeUpdater_Run_PrunesDeletedBranches(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+
+	gitSvc := &mockGitService{
+		currentSHA: "abc123",
+		branches:   []string{"main"},
+	}
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{
+		tracking: doctracking.DocUpdateTracking{
+			LastProcessedCommit: "abc123",
+		},
+		others: map[string]doctracking.DocUpdateTracking{
+			"feature/deleted": {LastProcessedCommit: "old999"},
+		},
+	}
 	env := &mockEnvironment{vars: make(map[string]string)}
 
-	skip, _ := ShouldSkip([]string{"src/foo.go", "docs/readme.md"}, "", env)
+	config := RangeUpdaterConfig{
+		SessionPath: sessionPath,
+	}
 
-	if skip {
-		t.Error("expected skip to be false for mixed file types")
+	updater := New(config, gitSvc, lockSvc, trackingSvc, &mockExecutor{}, fs, env)
+	if _, err := updater.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(trackingSvc.pruned) != 1 || trackingSvc.pruned[0] != "feature/deleted" {
+		t.Errorf("expected feature/deleted to be pruned, got %v", trackingSvc.pruned)
 	}
 }
 
-func TestHandleUnreachableBase_DefaultBranch(t *testing.T) {
+func TestRangeUpdater_RunUncommitted_Staged_UpdatesAffectedIndexes(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+	fs.MkdirAll("/pkg/a", 0755)
+	afero.WriteFile(fs, "/pkg/a/index.md", []byte("# A"), 0644)
+
 	gitSvc := &mockGitService{
-		mergeBase: "fallback123",
+		stagedFiles:   []string{"/pkg/a/foo.go"},
+		worktreeFiles: []string{"/pkg/b/bar.go"},
+	}
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{}
+	env := &mockEnvironment{vars: map[string]string{"CLAUDE_HOOK_INTERNAL": "1"}}
+
+	config := RangeUpdaterConfig{
+		SessionPath: sessionPath,
 	}
 
-	sha, err := HandleUnreachableBase(gitSvc, "main")
+	updater := New(config, gitSvc, lockSvc, trackingSvc, &mockExecutor{}, fs, env)
+	result, err := updater.RunUncommitted(ChangeSetStaged)
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if sha != "fallback123" {
-		t.Errorf("expected 'fallback123', got '%s'", sha)
+	if result.Status != "success" {
+		t.Errorf("expected status 'success', got '%s'", result.Status)
 	}
-}
 
-func TestHandleUnreachableBase_FallsBackToMain(t *testing.T) {
-	// Create a mock that will fail for "develop" but succeed for "main"
-	gitSvc := &mockGitServiceWithCallback{
-		mergeBaseCallback: func(branch string) (string, error) {
-			if branch == "develop" {
-				return "", fmt.Errorf("branch not found")
-			}
-			if branch == "main" {
-				return "main-fallback", nil
-			}
-			return "", fmt.Errorf("branch not found")
-		},
+	if len(result.AffectedIndexes) != 1 || result.AffectedIndexes[0] != "/pkg/a/index.md" {
+		t.Errorf("expected /pkg/a/index.md to be affected, got %v", result.AffectedIndexes)
 	}
 
-	sha, err := HandleUnreachableBase(gitSvc, "develop")
-
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+	if trackingSvc.writeCalled {
+		t.Error("expected tracking to be left untouched for uncommitted changes")
 	}
 
-	if sha != "main-fallback" {
-		t.Errorf("expected 'main-fallback', got '%s'", sha)
+	if len(gitSvc.stagedPaths) != 0 {
+		t.Errorf("expected no files staged without StageDocs, got %v", gitSvc.stagedPaths)
 	}
 }
 
-// mockGitServiceWithCallback allows custom behavior per call
-type mockGitServiceWithCallback struct {
+func TestRangeUpdater_RunUncommitted_StageDocs_StagesRegeneratedIndexes(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+	fs.MkdirAll("/pkg/a", 0755)
+	fs.MkdirAll("/pkg/b", 0755)
+	afero.WriteFile(fs, "/pkg/a/index.md", []byte("# A"), 0644)
+	afero.WriteFile(fs, "/pkg/b/index.md", []byte("# B"), 0644)
+
+	// /pkg/b/index.md is already edited in the commit, so it must not be regenerated
+	gitSvc := &mockGitService{
+		stagedFiles: []string{"/pkg/a/foo.go", "/pkg/b/bar.go", "/pkg/b/index.md"},
+	}
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{}
+	env := &mockEnvironment{vars: make(map[string]string)}
+
+	config := RangeUpdaterConfig{
+		SessionPath: sessionPath,
+		StageDocs:   true,
+	}
+
+	executor := &mockExecutor{}
+	updater := New(config, gitSvc, lockSvc, trackingSvc, executor, fs, env)
+	result, err := updater.RunUncommitted(ChangeSetStaged)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.AffectedIndexes) != 1 || result.AffectedIndexes[0] != "/pkg/a/index.md" {
+		t.Errorf("expected only /pkg/a/index.md to be affected, got %v", result.AffectedIndexes)
+	}
+
+	if len(executor.executed) != 1 || executor.executed[0] != "/pkg/a/index.md" {
+		t.Errorf("expected a job for /pkg/a/index.md only, got %v", executor.executed)
+	}
+
+	if len(gitSvc.stagedPaths) != 1 || gitSvc.stagedPaths[0] != "/pkg/a/index.md" {
+		t.Errorf("expected /pkg/a/index.md to be staged, got %v", gitSvc.stagedPaths)
+	}
+}
+
+func TestRangeUpdater_RunUncommitted_StageDocs_SkipsIndexesWithUnstagedEdits(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+	fs.MkdirAll("/pkg/a", 0755)
+	fs.MkdirAll("/pkg/b", 0755)
+	afero.WriteFile(fs, "/pkg/a/index.md", []byte("# A"), 0644)
+	afero.WriteFile(fs, "/pkg/b/index.md", []byte("# B\n\nUnstaged notes"), 0644)
+
+	// /pkg/b/index.md has unstaged edits: regenerating and staging it would
+	// put them in the commit
+	gitSvc := &mockGitService{
+		stagedFiles:   []string{"/pkg/a/foo.go", "/pkg/b/bar.go"},
+		worktreeFiles: []string{"/pkg/a/foo.go", "/pkg/b/bar.go", "/pkg/b/index.md"},
+	}
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{}
+	env := &mockEnvironment{vars: make(map[string]string)}
+
+	config := RangeUpdaterConfig{
+		SessionPath: sessionPath,
+		StageDocs:   true,
+	}
+
+	executor := &mockExecutor{}
+	updater := New(config, gitSvc, lockSvc, trackingSvc, executor, fs, env)
+	result, err := updater.RunUncommitted(ChangeSetStaged)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.AffectedIndexes) != 1 || result.AffectedIndexes[0] != "/pkg/a/index.md" {
+		t.Errorf("expected only /pkg/a/index.md to be affected, got %v", result.AffectedIndexes)
+	}
+
+	if len(executor.executed) != 1 || executor.executed[0] != "/pkg/a/index.md" {
+		t.Errorf("expected a job for /pkg/a/index.md only, got %v", executor.executed)
+	}
+
+	if len(gitSvc.stagedPaths) != 1 || gitSvc.stagedPaths[0] != "/pkg/a/index.md" {
+		t.Errorf("expected /pkg/a/index.md to be staged, got %v", gitSvc.stagedPaths)
+	}
+
+	content, _ := afero.ReadFile(fs, "/pkg/b/index.md")
+	if string(content) != "# B\n\nUnstaged notes" {
+		t.Errorf("expected /pkg/b/index.md to be left alone, got %q", content)
+	}
+}
+
+func TestRangeUpdater_RunUncommitted_Worktree_AllMarkdownSkips(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+
+	gitSvc := &mockGitService{
+		worktreeFiles: []string{"README.md", "docs/guide.md"},
+	}
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{}
+	env := &mockEnvironment{vars: make(map[string]string)}
+
+	config := RangeUpdaterConfig{
+		SessionPath: sessionPath,
+	}
+
+	updater := New(config, gitSvc, lockSvc, trackingSvc, &mockExecutor{}, fs, env)
+	result, err := updater.RunUncommitted(ChangeSetWorktree)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Status != "skipped" {
+		t.Errorf("expected status 'skipped', got '%s'", result.Status)
+	}
+
+	if result.ProcessedRange != "worktree changes" {
+		t.Errorf("expected range 'worktree changes', got '%s'", result.ProcessedRange)
+	}
+}
+
+func TestRangeUpdater_Run_DryRun_LeavesTrackingUntouched(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+	fs.MkdirAll("/pkg/a", 0755)
+	afero.WriteFile(fs, "/pkg/a/index.md", []byte("# A"), 0644)
+
+	gitSvc := &mockGitService{
+		currentSHA:     "bbb222",
+		changedFiles:   []string{"/pkg/a/foo.go"},
+		validateResult: true,
+		commits:        []git.Commit{{SHA: "bbb222", Message: "feat: touch a"}},
+	}
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{
+		tracking: doctracking.DocUpdateTracking{
+			LastProcessedCommit: "aaa111",
+		},
+	}
+	env := &mockEnvironment{vars: map[string]string{"CLAUDE_HOOK_INTERNAL": "1"}}
+
+	config := RangeUpdaterConfig{
+		SessionPath: sessionPath,
+		DryRun:      true,
+	}
+
+	updater := New(config, gitSvc, lockSvc, trackingSvc, &mockExecutor{}, fs, env)
+	result, err := updater.Run()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.HeadSHA != "bbb222" {
+		t.Errorf("expected head 'bbb222', got '%s'", result.HeadSHA)
+	}
+
+	// The recursion guard keeps Claude from running, so the copy is unchanged
+	if len(result.Proposals) != 0 {
+		t.Errorf("expected no changed proposals, got %v", result.Proposals)
+	}
+
+	if trackingSvc.writeCalled {
+		t.Error("expected dry run to leave tracking untouched")
+	}
+
+	content, _ := afero.ReadFile(fs, "/pkg/a/index.md")
+	if string(content) != "# A" {
+		t.Errorf("expected index.md to be untouched, got %q", content)
+	}
+}
+
+func TestRangeUpdater_PrepareProposal_CopiesInsideProject(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	fs.MkdirAll("/repo/pkg", 0755)
+	afero.WriteFile(fs, "/repo/pkg/index.md", []byte("# Pkg"), 0644)
+
+	config := RangeUpdaterConfig{SessionPath: "/repo/.claudex"}
+	updater := New(config, &mockGitService{}, newMockLockService(), &mockTrackingService{}, &mockExecutor{}, fs, &mockEnvironment{vars: map[string]string{}})
+
+	_, copyPath, prompt, err := updater.prepareProposal("/repo/pkg/index.md", nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filepath.Dir(copyPath) != "/repo/.claudex/doc_proposals" {
+		t.Errorf("expected the copy under .claudex/doc_proposals, got %s", copyPath)
+	}
+	if content, _ := afero.ReadFile(fs, copyPath); string(content) != "# Pkg" {
+		t.Errorf("expected the copy to hold the index, got %q", content)
+	}
+	if !strings.Contains(prompt, copyPath) {
+		t.Errorf("expected the prompt to name the copy, got %q", prompt)
+	}
+}
+
+func TestRangeUpdater_Run_DryRun_NoTracking_DoesNotInitialize(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+
+	gitSvc := &mockGitService{currentSHA: "abc123"}
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{}
+	env := &mockEnvironment{vars: make(map[string]string)}
+
+	config := RangeUpdaterConfig{
+		SessionPath: sessionPath,
+		DryRun:      true,
+	}
+
+	updater := New(config, gitSvc, lockSvc, trackingSvc, &mockExecutor{}, fs, env)
+	result, err := updater.Run()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Status != "skipped" {
+		t.Errorf("expected status 'skipped', got '%s'", result.Status)
+	}
+
+	if trackingSvc.tracking.LastProcessedCommit != "" {
+		t.Errorf("expected tracking not to be initialized, got '%s'", trackingSvc.tracking.LastProcessedCommit)
+	}
+}
+
+const testPatch = `# claudex-docs-patch
+# mode: commits
+# branch: main
+# head: abc123
+# range: aaa111..abc123
+diff --git a/pkg/a/index.md b/pkg/a/index.md
+--- a/pkg/a/index.md
++++ b/pkg/a/index.md
+@@ -1 +1 @@
+-# A
++# A (updated)
+`
+
+func TestRangeUpdater_ApplyPatch_CommitsAndAdvancesTracking(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+	afero.WriteFile(fs, "/docs.patch", []byte(testPatch), 0644)
+
+	gitSvc := &mockGitService{currentSHA: "abc123"}
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{}
+	env := &mockEnvironment{vars: make(map[string]string)}
+
+	updater := New(RangeUpdaterConfig{SessionPath: sessionPath}, gitSvc, lockSvc, trackingSvc, &mockExecutor{}, fs, env)
+	result, err := updater.ApplyPatch("/docs.patch")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gitSvc.appliedPatch != "/docs.patch" {
+		t.Errorf("expected patch to be applied, got '%s'", gitSvc.appliedPatch)
+	}
+
+	if len(gitSvc.committedPaths) != 1 || gitSvc.committedPaths[0] != "pkg/a/index.md" {
+		t.Errorf("expected only pkg/a/index.md to be committed, got %v", gitSvc.committedPaths)
+	}
+
+	if !hasSkipDocsTag(gitSvc.commitMessage) {
+		t.Errorf("expected commit message to carry [skip-docs], got '%s'", gitSvc.commitMessage)
+	}
+
+	if !trackingSvc.writeCalled || trackingSvc.writeBranch != "main" {
+		t.Errorf("expected tracking to be written for main")
+	}
+
+	if result.Status != "success" {
+		t.Errorf("expected status 'success', got '%s'", result.Status)
+	}
+}
+
+func TestRangeUpdater_ApplyPatch_HeadMoved_Refuses(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+	afero.WriteFile(fs, "/docs.patch", []byte(testPatch), 0644)
+
+	gitSvc := &mockGitService{currentSHA: "def456"}
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{}
+	env := &mockEnvironment{vars: make(map[string]string)}
+
+	updater := New(RangeUpdaterConfig{SessionPath: sessionPath}, gitSvc, lockSvc, trackingSvc, &mockExecutor{}, fs, env)
+	_, err := updater.ApplyPatch("/docs.patch")
+
+	if err == nil {
+		t.Fatal("expected error when HEAD moved since the dry run")
+	}
+
+	if gitSvc.appliedPatch != "" {
+		t.Error("expected patch not to be applied")
+	}
+}
+
+func TestRangeUpdater_Run_SupervisedJobs_HoldLockAndReportFailures(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+	fs.MkdirAll("/pkg/a", 0755)
+	fs.MkdirAll("/pkg/b", 0755)
+	afero.WriteFile(fs, "/pkg/a/index.md", []byte("# A"), 0644)
+	afero.WriteFile(fs, "/pkg/b/index.md", []byte("# B"), 0644)
+
+	gitSvc := &mockGitService{
+		currentSHA:     "bbb222",
+		changedFiles:   []string{"/pkg/a/foo.go", "/pkg/b/bar.go"},
+		validateResult: true,
+		commits:        []git.Commit{{SHA: "bbb222", Message: "feat: touch a and b"}},
+	}
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{
+		tracking: doctracking.DocUpdateTracking{LastProcessedCommit: "aaa111"},
+	}
+	env := &mockEnvironment{vars: make(map[string]string)}
+
+	lockPath := filepath.Join(sessionPath, "doc_update.lock")
+	executor := &mockExecutor{
+		exitCodes: map[string]int{"/pkg/b/index.md": 1},
+		onExecute: func(job jobs.Job) {
+			if exists, _ := afero.Exists(lockSvc.fs, lockPath); !exists {
+				t.Errorf("expected lock to be held while %s runs", job.Name)
+			}
+		},
+	}
+
+	config := RangeUpdaterConfig{SessionPath: sessionPath}
+	updater := New(config, gitSvc, lockSvc, trackingSvc, executor, fs, env)
+	result, err := updater.Run()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(executor.executed) != 2 {
+		t.Errorf("expected 2 jobs to run, got %v", executor.executed)
+	}
+
+	if len(result.FailedIndexes) != 1 || result.FailedIndexes[0] != "/pkg/b/index.md" {
+		t.Errorf("expected /pkg/b/index.md to fail, got %v", result.FailedIndexes)
+	}
+
+	if exists, _ := afero.Exists(lockSvc.fs, lockPath); exists {
+		t.Error("expected lock to be released after all jobs finished")
+	}
+
+	records, err := jobs.Load(fs, filepath.Join(sessionPath, JobLogName))
+	if err != nil {
+		t.Fatalf("failed to load job log: %v", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("expected 2 job records, got %d", len(records))
+	}
+}
+
+func TestPartitionCommits(t *testing.T) {
+	commits := []git.Commit{
+		{SHA: "a", Message: "feat: one"},
+		{SHA: "b", Message: "docs: two\n\n[skip-docs]"},
+		{SHA: "c", Message: "fix: three"},
+	}
+
+	kept, skipped := PartitionCommits(commits)
+
+	if len(kept) != 2 || kept[0].SHA != "a" || kept[1].SHA != "c" {
+		t.Errorf("expected kept [a c], got %v", kept)
+	}
+
+	if len(skipped) != 1 || skipped[0].SHA != "b" {
+		t.Errorf("expected skipped [b], got %v", skipped)
+	}
+}
+
+func TestResolveAffectedIndexes_MultipleIndexes(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	// Create directory structure with indexes
+	fs.MkdirAll("/pkg/a", 0755)
+	fs.MkdirAll("/pkg/b", 0755)
+	afero.WriteFile(fs, "/pkg/a/index.md", []byte("# A"), 0644)
+	afero.WriteFile(fs, "/pkg/b/index.md", []byte("# B"), 0644)
+
+	changedFiles := []string{
+		"/pkg/a/foo.go",
+		"/pkg/a/bar.go",
+		"/pkg/b/baz.go",
+	}
+
+	indexes, err := ResolveAffectedIndexes(fs, docfiles.Default(), changedFiles)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(indexes) != 2 {
+		t.Errorf("expected 2 affected indexes, got %d", len(indexes))
+	}
+
+	// Verify both indexes are present
+	hasA := false
+	hasB := false
+	for _, idx := range indexes {
+		if idx == "/pkg/a/index.md" {
+			hasA = true
+		}
+		if idx == "/pkg/b/index.md" {
+			hasB = true
+		}
+	}
+
+	if !hasA || !hasB {
+		t.Errorf("expected both /pkg/a/index.md and /pkg/b/index.md, got %v", indexes)
+	}
+}
+
+func TestResolveAffectedIndexes_Deduplication(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	// Create directory structure
+	fs.MkdirAll("/pkg/a", 0755)
+	afero.WriteFile(fs, "/pkg/a/index.md", []byte("# A"), 0644)
+
+	changedFiles := []string{
+		"/pkg/a/foo.go",
+		"/pkg/a/bar.go",
+		"/pkg/a/baz.go",
+	}
+
+	indexes, err := ResolveAffectedIndexes(fs, docfiles.Default(), changedFiles)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(indexes) != 1 {
+		t.Errorf("expected 1 affected index (deduplicated), got %d", len(indexes))
+	}
+
+	if indexes[0] != "/pkg/a/index.md" {
+		t.Errorf("expected /pkg/a/index.md, got %s", indexes[0])
+	}
+}
+
+func TestResolveAffectedIndexes_ConfiguredNames(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	// README.md outranks index.md; CLAUDE.md is found further up
+	fs.MkdirAll("/repo/pkg/a", 0755)
+	afero.WriteFile(fs, "/repo/CLAUDE.md", []byte("# Repo"), 0644)
+	afero.WriteFile(fs, "/repo/pkg/a/index.md", []byte("# A"), 0644)
+	afero.WriteFile(fs, "/repo/pkg/a/README.md", []byte("# A"), 0644)
+
+	names := docfiles.New([]string{"README.md", "index.md", "CLAUDE.md"})
+	indexes, err := ResolveAffectedIndexes(fs, names, []string{"/repo/pkg/a/foo.go", "/repo/pkg/b/bar.go"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"/repo/CLAUDE.md", "/repo/pkg/a/README.md"}
+	if len(indexes) != len(expected) || indexes[0] != expected[0] || indexes[1] != expected[1] {
+		t.Errorf("expected %v, got %v", expected, indexes)
+	}
+}
+
+func TestShouldSkip_EnvVar(t *testing.T) {
+	env := &mockEnvironment{
+		vars: map[string]string{
+			"CLAUDEX_SKIP_DOCS": "1",
+		},
+	}
+
+	skip, reason := ShouldSkip([]string{"foo.go"}, "", env)
+
+	if !skip {
+		t.Error("expected skip to be true for CLAUDEX_SKIP_DOCS=1")
+	}
+
+	if reason == "" {
+		t.Error("expected reason to be set")
+	}
+}
+
+func TestShouldSkip_CommitMessageTag(t *testing.T) {
+	env := &mockEnvironment{vars: make(map[string]string)}
+
+	skip, reason := ShouldSkip([]string{"foo.go"}, "fix: typo [skip-docs]", env)
+
+	if !skip {
+		t.Error("expected skip to be true for [skip-docs] tag")
+	}
+
+	if reason == "" {
+		t.Error("expected reason to be set")
+	}
+}
+
+func TestShouldSkip_AllMarkdownFiles(t *testing.T) {
+	env := &mockEnvironment{vars: make(map[string]string)}
+
+	skip, reason := ShouldSkip([]string{"docs/readme.md", "docs/guide.md"}, "", env)
+
+	if !skip {
+		t.Error("expected skip to be true for all markdown files")
+	}
+
+	if reason == "" {
+		t.Error("expected reason to be set")
+	}
+}
+
+func TestShouldSkip_MixedFiles_NoSkip(t *testing.T) {
+	env := &mockEnvironment{vars: make(map[string]string)}
+
+	skip, _ := ShouldSkip([]string{"src/foo.go", "docs/readme.md"}, "", env)
+
+	if skip {
+		t.Error("expected skip to be false for mixed file types")
+	}
+}
+
+func TestHandleUnreachableBase_DefaultBranch(t *testing.T) {
+	gitSvc := &mockGitService{
+		mergeBase: "fallback123",
+	}
+
+	fallback, err := HandleUnreachableBase(gitSvc, "main")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fallback.SHA != "fallback123" {
+		t.Errorf("expected 'fallback123', got '%s'", fallback.SHA)
+	}
+
+	if fallback.Branch != "main" || fallback.Source != FallbackSourceConfig {
+		t.Errorf("expected main from config, got %s from %s", fallback.Branch, fallback.Source)
+	}
+}
+
+func TestHandleUnreachableBase_FallsBackToMain(t *testing.T) {
+	// Create a mock that will fail for "develop" but succeed for "main"
+	gitSvc := &mockGitServiceWithCallback{
+		mergeBaseCallback: func(branch string) (string, error) {
+			if branch == "develop" {
+				return "", fmt.Errorf("branch not found")
+			}
+			if branch == "main" {
+				return "main-fallback", nil
+			}
+			return "", fmt.Errorf("branch not found")
+		},
+	}
+
+	fallback, err := HandleUnreachableBase(gitSvc, "develop")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fallback.SHA != "main-fallback" {
+		t.Errorf("expected 'main-fallback', got '%s'", fallback.SHA)
+	}
+
+	if fallback.Source != FallbackSourceBuiltin {
+		t.Errorf("expected source '%s', got '%s'", FallbackSourceBuiltin, fallback.Source)
+	}
+}
+
+func TestHandleUnreachableBase_UsesDetectedDefaultBranch(t *testing.T) {
+	var tried []string
+	gitSvc := &mockGitServiceWithCallback{
+		defaultBranch: "origin/develop",
+		defaultSource: git.DefaultBranchSourceRemote,
+		mergeBaseCallback: func(branch string) (string, error) {
+			tried = append(tried, branch)
+			if branch == "origin/develop" {
+				return "develop-fallback", nil
+			}
+			return "", fmt.Errorf("branch not found")
+		},
+	}
+
+	fallback, err := HandleUnreachableBase(gitSvc, "")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fallback.SHA != "develop-fallback" || fallback.Branch != "origin/develop" {
+		t.Errorf("expected origin/develop fallback, got %+v", fallback)
+	}
+
+	if fallback.Source != git.DefaultBranchSourceRemote {
+		t.Errorf("expected source '%s', got '%s'", git.DefaultBranchSourceRemote, fallback.Source)
+	}
+
+	if len(tried) != 1 {
+		t.Errorf("expected detected branch to be tried first, tried %v", tried)
+	}
+}
+
+func TestHandleUnreachableBase_ConfigTakesPrecedence(t *testing.T) {
+	gitSvc := &mockGitServiceWithCallback{
+		defaultBranch: "origin/main",
+		defaultSource: git.DefaultBranchSourceRemote,
+		mergeBaseCallback: func(branch string) (string, error) {
+			return branch + "-base", nil
+		},
+	}
+
+	fallback, err := HandleUnreachableBase(gitSvc, "trunk")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fallback.Branch != "trunk" || fallback.Source != FallbackSourceConfig {
+		t.Errorf("expected trunk from config, got %s from %s", fallback.Branch, fallback.Source)
+	}
+}
+
+// mockGitServiceWithCallback allows custom behavior per call
+type mockGitServiceWithCallback struct {
 	mergeBaseCallback func(branch string) (string, error)
+	defaultBranch     string
+	defaultSource     string
 }
 
 func (m *mockGitServiceWithCallback) GetCurrentSHA() (string, error) {
@@ -620,6 +1496,53 @@ func (m *mockGitServiceWithCallback) GetMergeBase(branch string) (string, error)
 	return "", fmt.Errorf("not implemented")
 }
 
+func (m *mockGitServiceWithCallback) GetCommits(base, head string, exclude ...string) ([]git.Commit, error) {
+	return nil, nil
+}
+
+func (m *mockGitServiceWithCallback) GetCommitFiles(sha string) ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockGitServiceWithCallback) GetDefaultBranch() (string, string, error) {
+	if m.defaultBranch == "" {
+		return "", "", fmt.Errorf("no default branch")
+	}
+	return m.defaultBranch, m.defaultSource, nil
+}
+
+func (m *mockGitServiceWithCallback) GetCurrentBranch() (string, error) {
+	return "main", nil
+}
+
+func (m *mockGitServiceWithCallback) ListBranches() ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockGitServiceWithCallback) GetStagedFiles() ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockGitServiceWithCallback) GetWorktreeFiles() ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockGitServiceWithCallback) StageFiles(paths ...string) error {
+	return nil
+}
+
+func (m *mockGitServiceWithCallback) ApplyPatch(patchPath string) error {
+	return nil
+}
+
+func (m *mockGitServiceWithCallback) CommitFiles(message string, paths ...string) error {
+	return nil
+}
+
+func (m *mockGitServiceWithCallback) GetDiff(base, head string, paths ...string) (string, error) {
+	return "", nil
+}
+
 func TestHandleUnreachableBase_AllFail_ReturnsError(t *testing.T) {
 	gitSvc := &mockGitService{
 		mergeBaseError: fmt.Errorf("no merge base found"),
@@ -640,3 +1563,217 @@ func createTempDir(t *testing.T) (string, func()) {
 	}
 	return dir, func() { os.RemoveAll(dir) }
 }
+
+func TestRangeUpdater_Run_DiffContext_KeptCommitsOnly(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+	fs.MkdirAll("/pkg/a", 0755)
+	afero.WriteFile(fs, "/pkg/a/index.md", []byte("# A"), 0644)
+
+	gitSvc := &mockGitService{
+		currentSHA:     "ccc333",
+		validateResult: true,
+		commits: []git.Commit{
+			{SHA: "bbb222", Message: "feat: add store options"},
+			{SHA: "ccc333", Message: "chore: tweak [skip-docs]"},
+		},
+		commitFiles: map[string][]string{
+			"bbb222": {"/pkg/a/store.go"},
+			"ccc333": {"/pkg/a/other.go"},
+		},
+		diff: "diff --git a//pkg/a/store.go b//pkg/a/store.go\n--- a//pkg/a/store.go\n+++ b//pkg/a/store.go\n" +
+			"@@ -1,2 +1,3 @@\n package a\n+type Options struct{}\n",
+	}
+	trackingSvc := &mockTrackingService{
+		tracking: doctracking.DocUpdateTracking{LastProcessedCommit: "aaa111"},
+	}
+	env := &mockEnvironment{vars: map[string]string{}}
+
+	var prompts []string
+	executor := &mockExecutor{onExecute: func(job jobs.Job) { prompts = append(prompts, job.Args[1]) }}
+	config := RangeUpdaterConfig{SessionPath: sessionPath}
+	updater := New(config, gitSvc, newMockLockService(), trackingSvc, executor, fs, env)
+
+	if _, err := updater.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(gitSvc.diffCalls) != 1 || strings.Join(gitSvc.diffCalls[0], " ") != "bbb222^ bbb222 /pkg/a/store.go" {
+		t.Errorf("expected a single diff of the kept commit, got %v", gitSvc.diffCalls)
+	}
+	if len(prompts) != 1 {
+		t.Fatalf("expected one index job, got %d", len(prompts))
+	}
+	for _, want := range []string{"CHANGES", "+ /pkg/a/store.go: type Options", "+type Options struct{}"} {
+		if !strings.Contains(prompts[0], want) {
+			t.Errorf("expected prompt to contain %q, got:\n%s", want, prompts[0])
+		}
+	}
+}
+
+func TestRangeUpdater_Run_DiffContext_DisabledByNegativeBudget(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+	fs.MkdirAll("/pkg/a", 0755)
+	afero.WriteFile(fs, "/pkg/a/index.md", []byte("# A"), 0644)
+
+	gitSvc := &mockGitService{
+		currentSHA:     "bbb222",
+		changedFiles:   []string{"/pkg/a/foo.go"},
+		validateResult: true,
+		commits:        []git.Commit{{SHA: "bbb222", Message: "feat: a"}},
+	}
+	trackingSvc := &mockTrackingService{
+		tracking: doctracking.DocUpdateTracking{LastProcessedCommit: "aaa111"},
+	}
+	env := &mockEnvironment{vars: map[string]string{}}
+
+	var prompts []string
+	executor := &mockExecutor{onExecute: func(job jobs.Job) { prompts = append(prompts, job.Args[1]) }}
+	config := RangeUpdaterConfig{SessionPath: sessionPath, DiffTokenBudget: -1}
+	updater := New(config, gitSvc, newMockLockService(), trackingSvc, executor, fs, env)
+
+	if _, err := updater.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(gitSvc.diffCalls) != 0 {
+		t.Errorf("expected no diff with a negative budget, got %v", gitSvc.diffCalls)
+	}
+	if len(prompts) != 1 || strings.Contains(prompts[0], "CHANGES") {
+		t.Errorf("expected a file-names-only prompt, got %v", prompts)
+	}
+}
+
+func TestRangeUpdater_RunUncommitted_Staged_DiffsIndex(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+	fs.MkdirAll("/pkg/a", 0755)
+	afero.WriteFile(fs, "/pkg/a/index.md", []byte("# A"), 0644)
+
+	gitSvc := &mockGitService{stagedFiles: []string{"/pkg/a/foo.go"}}
+	env := &mockEnvironment{vars: map[string]string{}}
+	config := RangeUpdaterConfig{SessionPath: sessionPath}
+	updater := New(config, gitSvc, newMockLockService(), &mockTrackingService{}, &mockExecutor{}, fs, env)
+
+	if _, err := updater.RunUncommitted(ChangeSetStaged); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(gitSvc.diffCalls) != 1 || gitSvc.diffCalls[0][0] != "HEAD" || gitSvc.diffCalls[0][1] != git.IndexRef {
+		t.Errorf("expected staged diff against HEAD, got %v", gitSvc.diffCalls)
+	}
+}
+
+func TestRangeUpdater_Run_AutoCommit_CommitsOnlyRegeneratedDocs(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	fs.MkdirAll("/session", 0755)
+	for _, dir := range []string{"/pkg/a", "/pkg/b", "/pkg/c"} {
+		fs.MkdirAll(dir, 0755)
+		afero.WriteFile(fs, dir+"/index.md", []byte("# doc"), 0644)
+	}
+
+	gitSvc := &mockGitService{
+		currentSHA:     "bbb222",
+		changedFiles:   []string{"/pkg/a/a.go", "/pkg/b/b.go", "/pkg/c/c.go"},
+		validateResult: true,
+		commits:        []git.Commit{{SHA: "bbb222", Message: "feat: touch a, b and c"}},
+		// b has local edits the user has not committed yet
+		worktreeFiles: []string{"/pkg/b/index.md", "/notes.txt"},
+		newCommitSHA:  "ccc333",
+	}
+	trackingSvc := &mockTrackingService{
+		tracking: doctracking.DocUpdateTracking{LastProcessedCommit: "aaa111"},
+	}
+	executor := &mockExecutor{
+		exitCodes: map[string]int{"/pkg/c/index.md": 1},
+		onExecute: func(job jobs.Job) {
+			gitSvc.worktreeFiles = append(gitSvc.worktreeFiles, job.Name)
+		},
+	}
+
+	config := RangeUpdaterConfig{SessionPath: "/session", AutoCommit: true, CommitMessage: "chore(docs): refresh indexes"}
+	updater := New(config, gitSvc, newMockLockService(), trackingSvc, executor, fs, &mockEnvironment{})
+	result, err := updater.Run()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(gitSvc.committedPaths) != 1 || gitSvc.committedPaths[0] != "/pkg/a/index.md" {
+		t.Errorf("expected only /pkg/a/index.md to be committed, got %v", gitSvc.committedPaths)
+	}
+	want := "chore(docs): refresh indexes [skip-docs]\n\nDocs-Source-Range: aaa111..bbb222"
+	if gitSvc.commitMessage != want {
+		t.Errorf("commit message = %q, want %q", gitSvc.commitMessage, want)
+	}
+	if result.DocsCommit != "ccc333" {
+		t.Errorf("expected DocsCommit ccc333, got %q", result.DocsCommit)
+	}
+
+	// Tracking moves past the docs commit so it is never processed as new work
+	if trackingSvc.tracking.LastProcessedCommit != "ccc333" {
+		t.Errorf("expected tracking at ccc333, got %s", trackingSvc.tracking.LastProcessedCommit)
+	}
+	if !slices.Contains(trackingSvc.tracking.SkippedCommits, "ccc333") {
+		t.Errorf("expected docs commit in skipped commits, got %v", trackingSvc.tracking.SkippedCommits)
+	}
+}
+
+func TestRangeUpdater_Run_AutoCommit_HeadMoved_LeavesDocsUncommitted(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	fs.MkdirAll("/session", 0755)
+	fs.MkdirAll("/pkg/a", 0755)
+	afero.WriteFile(fs, "/pkg/a/index.md", []byte("# doc"), 0644)
+
+	gitSvc := &mockGitService{
+		currentSHA:     "bbb222",
+		changedFiles:   []string{"/pkg/a/a.go"},
+		validateResult: true,
+		commits:        []git.Commit{{SHA: "bbb222", Message: "feat: touch a"}},
+	}
+	trackingSvc := &mockTrackingService{
+		tracking: doctracking.DocUpdateTracking{LastProcessedCommit: "aaa111"},
+	}
+	executor := &mockExecutor{
+		onExecute: func(job jobs.Job) {
+			gitSvc.worktreeFiles = []string{job.Name}
+			// The user commits something else while Claude runs
+			gitSvc.currentSHA = "fff999"
+		},
+	}
+
+	config := RangeUpdaterConfig{SessionPath: "/session", AutoCommit: true}
+	updater := New(config, gitSvc, newMockLockService(), trackingSvc, executor, fs, &mockEnvironment{})
+	result, err := updater.Run()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gitSvc.committedPaths != nil {
+		t.Errorf("expected no commit, got %v", gitSvc.committedPaths)
+	}
+	if result.DocsCommit != "" {
+		t.Errorf("expected no DocsCommit, got %q", result.DocsCommit)
+	}
+	if trackingSvc.tracking.LastProcessedCommit != "bbb222" {
+		t.Errorf("expected tracking at bbb222, got %s", trackingSvc.tracking.LastProcessedCommit)
+	}
+}
+
+func TestAutoCommitMessage(t *testing.T) {
+	tests := []struct {
+		message, base, head, want string
+	}{
+		{"", "aaa", "bbb", DefaultCommitMessage + " [skip-docs]\n\nDocs-Source-Range: aaa..bbb"},
+		{"docs: sync [skip-docs]", "aaa", "bbb", "docs: sync [skip-docs]\n\nDocs-Source-Range: aaa..bbb"},
+		{"docs: sync\n\nGenerated by claudex", "", "bbb", "docs: sync [skip-docs]\n\nGenerated by claudex\n\nDocs-Source-Range: bbb"},
+	}
+	for _, tt := range tests {
+		if got := autoCommitMessage(tt.message, tt.base, tt.head); got != tt.want {
+			t.Errorf("autoCommitMessage(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package skeleton
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// commentStyle describes how a language writes comments
+type commentStyle struct {
+	// line holds line comment prefixes, longest first
+	line []string
+
+	// block is true for languages with /* ... */ comments
+	block bool
+
+	// docstring is true for languages with """ module docstrings
+	docstring bool
+}
+
+var (
+	cStyle      = commentStyle{line: []string{"///", "//!", "//"}, block: true}
+	hashStyle   = commentStyle{line: []string{"#"}}
+	pythonStyle = commentStyle{line: []string{"#"}, docstring: true}
+)
+
+// commentStyles maps the non-Go source extensions the skeleton lists to their comment syntax
+var commentStyles = map[string]commentStyle{
+	".ts": cStyle, ".tsx": cStyle, ".js": cStyle, ".jsx": cStyle,
+	".rs": cStyle, ".java": cStyle, ".kt": cStyle, ".swift": cStyle,
+	".c": cStyle, ".cpp": cStyle, ".h": cStyle, ".php": cStyle, ".cs": cStyle,
+	".py": pythonStyle, ".rb": hashStyle, ".sh": hashStyle,
+}
+
+// analyzeFile lists a non-Go source file with the first sentence of its leading comment
+func analyzeFile(fs afero.Fs, path string, style commentStyle) File {
+	name := filepath.Base(path)
+	return File{
+		Name:    name,
+		Summary: firstSentence(leadingComment(readFile(fs, path), style)),
+		Test:    isTestFile(name),
+	}
+}
+
+// leadingComment returns the text of the first comment block at the top of a
+// file, skipping shebangs, blank lines, "use strict"-style directives and
+// license headers
+func leadingComment(src string, style commentStyle) string {
+	lines := strings.Split(src, "\n")
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#!") || isDirective(trimmed):
+			continue
+		case style.docstring && (strings.HasPrefix(trimmed, `"""`) || strings.HasPrefix(trimmed, `'''`)):
+			text, next := docstring(lines, i)
+			if !isLicense(text) {
+				return text
+			}
+			i = next
+		case style.block && strings.HasPrefix(trimmed, "/*"):
+			text, next := blockComment(lines, i)
+			if !isLicense(text) {
+				return text
+			}
+			i = next
+		case linePrefix(trimmed, style) != "":
+			text, next := lineComments(lines, i, style)
+			if !isLicense(text) {
+				return text
+			}
+			i = next
+		default:
+			// Code before any comment: no leading comment
+			return ""
+		}
+	}
+	return ""
+}
+
+// lineComments joins consecutive line comments starting at lines[start] and
+// returns the text and the index of the last comment line
+func lineComments(lines []string, start int, style commentStyle) (string, int) {
+	var text []string
+	i := start
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		prefix := linePrefix(trimmed, style)
+		if prefix == "" {
+			break
+		}
+		text = append(text, strings.TrimSpace(strings.TrimPrefix(trimmed, prefix)))
+	}
+	return strings.Join(text, " "), i - 1
+}
+
+// blockComment returns the text of a /* ... */ comment starting at lines[start]
+func blockComment(lines []string, start int) (string, int) {
+	var text []string
+	for i := start; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		end := strings.Contains(line, "*/")
+		line = strings.TrimSuffix(strings.SplitN(line, "*/", 2)[0], " ")
+		line = strings.TrimLeft(line, "/*! ")
+		text = append(text, line)
+		if end {
+			return strings.Join(text, " "), i
+		}
+	}
+	return strings.Join(text, " "), len(lines) - 1
+}
+
+// docstring returns the text of a Python triple-quoted string starting at lines[start]
+func docstring(lines []string, start int) (string, int) {
+	trimmed := strings.TrimSpace(lines[start])
+	quote := trimmed[:3]
+	rest := trimmed[3:]
+	if i := strings.Index(rest, quote); i >= 0 {
+		return rest[:i], start
+	}
+
+	text := []string{rest}
+	for i := start + 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if j := strings.Index(line, quote); j >= 0 {
+			text = append(text, line[:j])
+			return strings.Join(text, " "), i
+		}
+		text = append(text, line)
+	}
+	return strings.Join(text, " "), len(lines) - 1
+}
+
+// linePrefix returns the line comment prefix line starts with, if any
+func linePrefix(line string, style commentStyle) string {
+	for _, prefix := range style.line {
+		if strings.HasPrefix(line, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// isDirective reports whether line is a file-level directive rather than code
+func isDirective(line string) bool {
+	return line == `"use strict";` || line == `'use strict';` || line == `"use client";` ||
+		strings.HasPrefix(line, "<?php") || strings.HasPrefix(line, "# -*-") ||
+		strings.HasPrefix(line, "# frozen_string_literal")
+}
+
+// isLicense reports whether a comment is a license or copyright header
+func isLicense(text string) bool {
+	lower := strings.ToLower(text)
+	return strings.Contains(lower, "copyright") || strings.Contains(lower, "license") ||
+		strings.Contains(lower, "spdx-")
+}
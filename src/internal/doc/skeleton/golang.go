@@ -0,0 +1,157 @@
+package skeleton
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// analyzeGoPackage parses the Go files of a directory and fills in the title,
+// package summary, per-file exports and exported API
+func analyzeGoPackage(fs afero.Fs, dir string, names []string, s *Skeleton) error {
+	fset := token.NewFileSet()
+	types := map[string]*Symbol{}
+	var typeOrder []string
+	var funcs, values []Symbol
+	methods := map[string][]Symbol{}
+
+	sort.Strings(names)
+	for _, name := range names {
+		src, err := afero.ReadFile(fs, filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+		if err != nil {
+			// A file that does not parse still belongs in the listing
+			s.Files = append(s.Files, File{Name: name, Test: isTestFile(name)})
+			continue
+		}
+
+		f := File{Name: name, Test: strings.HasSuffix(name, "_test.go")}
+		if !f.Test {
+			if s.Title == filepath.Base(dir) && file.Name.Name != "main" {
+				s.Title = file.Name.Name
+			}
+			if file.Doc != nil && s.Summary == "" {
+				s.Summary = firstParagraph(file.Doc.Text())
+			}
+		}
+
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if !d.Name.IsExported() || f.Test {
+					continue
+				}
+				sym := Symbol{Kind: "func", Name: d.Name.Name, Signature: funcSignature(fset, d), Doc: docSentence(d.Doc)}
+				if d.Recv != nil && len(d.Recv.List) > 0 {
+					recv := receiverType(d.Recv.List[0].Type)
+					if !ast.IsExported(recv) {
+						continue
+					}
+					methods[recv] = append(methods[recv], sym)
+					continue
+				}
+				funcs = append(funcs, sym)
+				f.Exported = append(f.Exported, d.Name.Name)
+			case *ast.GenDecl:
+				if f.Test {
+					continue
+				}
+				for _, spec := range d.Specs {
+					switch sp := spec.(type) {
+					case *ast.TypeSpec:
+						if !sp.Name.IsExported() {
+							continue
+						}
+						doc := sp.Doc
+						if doc == nil {
+							doc = d.Doc
+						}
+						types[sp.Name.Name] = &Symbol{Kind: "type", Name: sp.Name.Name, Doc: docSentence(doc)}
+						typeOrder = append(typeOrder, sp.Name.Name)
+						f.Exported = append(f.Exported, sp.Name.Name)
+					case *ast.ValueSpec:
+						doc := sp.Doc
+						if doc == nil && len(d.Specs) == 1 {
+							doc = d.Doc
+						}
+						for _, ident := range sp.Names {
+							if !ident.IsExported() {
+								continue
+							}
+							values = append(values, Symbol{Kind: d.Tok.String(), Name: ident.Name, Doc: docSentence(doc)})
+							f.Exported = append(f.Exported, ident.Name)
+						}
+					}
+				}
+			}
+		}
+
+		s.Files = append(s.Files, f)
+	}
+
+	// Types first (with their methods), then functions, then constants and variables
+	for _, name := range typeOrder {
+		t := *types[name]
+		t.Methods = methods[name]
+		s.API = append(s.API, t)
+	}
+	s.API = append(s.API, funcs...)
+	s.API = append(s.API, values...)
+	return nil
+}
+
+// funcSignature prints a function declaration without its doc comment and body
+func funcSignature(fset *token.FileSet, d *ast.FuncDecl) string {
+	decl := *d
+	decl.Doc = nil
+	decl.Body = nil
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, &decl); err != nil {
+		return "func " + d.Name.Name
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// receiverType returns the type name of a method receiver
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	default:
+		return fmt.Sprint(expr)
+	}
+}
+
+// docSentence returns the first sentence of a doc comment
+func docSentence(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	return firstSentence(doc.Text())
+}
+
+// firstParagraph returns the first paragraph of a comment on a single line
+func firstParagraph(text string) string {
+	if i := strings.Index(text, "\n\n"); i >= 0 {
+		text = text[:i]
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
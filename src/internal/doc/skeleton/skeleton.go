@@ -0,0 +1,288 @@
+// Package skeleton builds index.md skeletons from static analysis, without an
+// LLM. Go packages are read with go/parser (package docs and exported API);
+// other languages contribute a file listing and their leading comments. The
+// result has a fixed structure and TODO placeholders where prose is missing,
+// so it can be committed as-is (e.g. in CI) or handed to Claude to fill in.
+package skeleton
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"claudex/internal/services/docfiles"
+	"claudex/internal/services/ignore"
+
+	"github.com/spf13/afero"
+)
+
+// Placeholder starts every line that still needs prose
+const Placeholder = "TODO"
+
+// maxSentence bounds descriptions taken from comments
+const maxSentence = 160
+
+// File describes one source file in the directory
+type File struct {
+	Name string
+
+	// Summary is the first sentence of the file's leading comment, if any
+	Summary string
+
+	// Exported lists the exported Go symbols the file declares
+	Exported []string
+
+	Test bool
+}
+
+// Symbol is an exported Go declaration
+type Symbol struct {
+	Kind string // type, func, const or var
+	Name string
+
+	// Signature is the declaration without body (functions and methods only)
+	Signature string
+
+	// Doc is the first sentence of the doc comment
+	Doc string
+
+	// Methods holds the exported methods of a type
+	Methods []Symbol
+}
+
+// Subdir describes a subdirectory and its doc file, if it has one
+type Subdir struct {
+	Name    string
+	DocFile string
+	Summary string
+}
+
+// Skeleton is the static analysis of a directory
+type Skeleton struct {
+	Title   string
+	Summary string
+	Files   []File
+	API     []Symbol
+	Subdirs []Subdir
+}
+
+// Analyze reads dir and collects its files, exported Go API and subdirectories,
+// leaving out hidden and ignored entries.
+// names decides which files count as subdirectory docs.
+func Analyze(fs afero.Fs, dir string, names docfiles.Names) (*Skeleton, error) {
+	entries, err := ignore.New(fs, dir).ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Skeleton{Title: filepath.Base(dir)}
+	var goFiles []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if entry.IsDir() {
+			s.Subdirs = append(s.Subdirs, analyzeSubdir(fs, filepath.Join(dir, name), names))
+			continue
+		}
+		if filepath.Ext(name) == ".go" {
+			goFiles = append(goFiles, name)
+			continue
+		}
+		if style, ok := commentStyles[filepath.Ext(name)]; ok {
+			s.Files = append(s.Files, analyzeFile(fs, filepath.Join(dir, name), style))
+		}
+	}
+
+	if len(goFiles) > 0 {
+		if err := analyzeGoPackage(fs, dir, goFiles, s); err != nil {
+			return nil, err
+		}
+	}
+	if s.Summary == "" {
+		s.Summary = moduleSummary(s.Files)
+	}
+
+	sort.Slice(s.Files, func(i, j int) bool { return s.Files[i].Name < s.Files[j].Name })
+	return s, nil
+}
+
+// Generate returns the markdown skeleton for dir
+func Generate(fs afero.Fs, dir string, names docfiles.Names) (string, error) {
+	s, err := Analyze(fs, dir, names)
+	if err != nil {
+		return "", err
+	}
+	return s.Markdown(), nil
+}
+
+// Markdown renders the skeleton in the layout of the repository's index.md files
+func (s *Skeleton) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", s.Title)
+	if s.Summary != "" {
+		b.WriteString(s.Summary + "\n")
+	} else {
+		b.WriteString(Placeholder + ": summarize the purpose of this directory.\n")
+	}
+
+	var sources, tests []File
+	for _, f := range s.Files {
+		if f.Test {
+			tests = append(tests, f)
+		} else {
+			sources = append(sources, f)
+		}
+	}
+
+	if len(sources) > 0 {
+		b.WriteString("\n## Files\n\n")
+		for _, f := range sources {
+			fmt.Fprintf(&b, "- `%s` - %s\n", f.Name, fileDescription(f))
+		}
+	}
+
+	if len(s.API) > 0 {
+		b.WriteString("\n## API\n\n")
+		for _, sym := range s.API {
+			writeSymbol(&b, sym, "")
+			for _, m := range sym.Methods {
+				writeSymbol(&b, m, "  ")
+			}
+		}
+	}
+
+	if len(s.Subdirs) > 0 {
+		b.WriteString("\n## Subdirectories\n\n")
+		for _, d := range s.Subdirs {
+			summary := d.Summary
+			if summary == "" {
+				summary = Placeholder
+			}
+			if d.DocFile != "" {
+				fmt.Fprintf(&b, "- [%s/](./%s/%s) - %s\n", d.Name, d.Name, d.DocFile, summary)
+			} else {
+				fmt.Fprintf(&b, "- `%s/` - %s\n", d.Name, summary)
+			}
+		}
+	}
+
+	if len(tests) > 0 {
+		b.WriteString("\n## Tests\n\n")
+		for _, f := range tests {
+			fmt.Fprintf(&b, "- `%s` - %s\n", f.Name, fileDescription(f))
+		}
+	}
+
+	return b.String()
+}
+
+// fileDescription is the leading comment, else the exported symbols, else a placeholder
+func fileDescription(f File) string {
+	switch {
+	case f.Summary != "":
+		return f.Summary
+	case len(f.Exported) > 0:
+		return "Declares " + strings.Join(f.Exported, ", ")
+	default:
+		return Placeholder
+	}
+}
+
+// writeSymbol renders one API entry
+func writeSymbol(b *strings.Builder, sym Symbol, indent string) {
+	name := sym.Kind + " " + sym.Name
+	if sym.Signature != "" {
+		name = sym.Signature
+	}
+	if sym.Doc != "" {
+		fmt.Fprintf(b, "%s- `%s` - %s\n", indent, name, sym.Doc)
+	} else {
+		fmt.Fprintf(b, "%s- `%s`\n", indent, name)
+	}
+}
+
+// analyzeSubdir finds a subdirectory's doc file and the first sentence of its prose
+func analyzeSubdir(fs afero.Fs, dir string, names docfiles.Names) Subdir {
+	d := Subdir{Name: filepath.Base(dir)}
+	docPath := names.Find(fs, dir)
+	if docPath == "" {
+		return d
+	}
+	d.DocFile = filepath.Base(docPath)
+	if content, err := afero.ReadFile(fs, docPath); err == nil {
+		d.Summary = markdownSummary(string(content))
+	}
+	return d
+}
+
+// markdownSummary returns the first sentence of the first prose paragraph
+func markdownSummary(content string) string {
+	var para []string
+	inFence := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "-") ||
+			strings.HasPrefix(trimmed, "*") || strings.HasPrefix(trimmed, ">") || strings.HasPrefix(trimmed, "|") {
+			if len(para) > 0 {
+				break
+			}
+			continue
+		}
+		para = append(para, trimmed)
+	}
+	return firstSentence(strings.Join(para, " "))
+}
+
+// moduleSummary uses the leading comment of a module entry file (index.ts,
+// __init__.py, mod.rs, ...) as the directory summary
+func moduleSummary(files []File) string {
+	for _, f := range files {
+		stem := strings.TrimSuffix(f.Name, filepath.Ext(f.Name))
+		switch stem {
+		case "index", "__init__", "mod", "lib", "main":
+			if f.Summary != "" {
+				return f.Summary
+			}
+		}
+	}
+	return ""
+}
+
+// isTestFile reports whether name follows a common test file convention
+func isTestFile(name string) bool {
+	stem := strings.TrimSuffix(name, filepath.Ext(name))
+	return strings.HasSuffix(stem, "_test") || strings.HasPrefix(stem, "test_") ||
+		strings.HasSuffix(stem, ".test") || strings.HasSuffix(stem, ".spec") ||
+		strings.HasSuffix(stem, "Test") || strings.HasSuffix(stem, "Tests")
+}
+
+// firstSentence collapses whitespace and cuts text after its first sentence
+func firstSentence(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if i := strings.Index(text, ". "); i >= 0 {
+		text = text[:i+1]
+	}
+	if len(text) > maxSentence {
+		text = strings.TrimSpace(text[:maxSentence]) + "..."
+	}
+	return text
+}
+
+// readFile reads a file, treating unreadable files as empty
+func readFile(fs afero.Fs, path string) string {
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,134 @@
+package skeleton
+
+import (
+	"strings"
+	"testing"
+
+	"claudex/internal/services/docfiles"
+
+	"github.com/spf13/afero"
+)
+
+func writeFiles(t *testing.T, fs afero.Fs, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		if err := afero.WriteFile(fs, path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGenerate_GoPackage(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeFiles(t, fs, map[string]string{
+		"/repo/store/store.go": `// Package store persists sessions on disk.
+// It is safe for concurrent use.
+package store
+
+// Store reads and writes sessions
+type Store struct{ dir string }
+
+type cache struct{}
+
+// New creates a Store rooted at dir. The directory is created lazily.
+func New(dir string) *Store { return &Store{dir: dir} }
+
+// Load reads a session by name
+func (s *Store) Load(name string) ([]byte, error) { return nil, nil }
+
+func (s *Store) path(name string) string { return "" }
+
+func (c cache) Get() {}
+
+// MaxSize bounds a stored session
+const MaxSize = 1 << 20
+`,
+		"/repo/store/errors.go":     "package store\n\nvar ErrNotFound = errNotFound()\n\nfunc errNotFound() error { return nil }\n",
+		"/repo/store/helpers.go":    "package store\n\nfunc helper() {}\n",
+		"/repo/store/store_test.go": "package store\n\nfunc TestNew(t *testing.T) {}\n",
+		"/repo/store/disk/index.md": "# Disk\n\nLow-level file access for the store. Uses atomic renames.\n\n## Files\n",
+		"/repo/store/mem/mem.go":    "package mem\n",
+		"/repo/store/notes.txt":     "not source",
+	})
+
+	got, err := Generate(fs, "/repo/store", docfiles.Default())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"# store\n\nPackage store persists sessions on disk. It is safe for concurrent use.\n",
+		"- `errors.go` - Declares ErrNotFound\n",
+		"- `helpers.go` - " + Placeholder + "\n",
+		"- `store.go` - Declares Store, New, MaxSize\n",
+		"- `type Store` - Store reads and writes sessions\n",
+		"  - `func (s *Store) Load(name string) ([]byte, error)` - Load reads a session by name\n",
+		"- `func New(dir string) *Store` - New creates a Store rooted at dir.\n",
+		"- `const MaxSize` - MaxSize bounds a stored session\n",
+		"- [disk/](./disk/index.md) - Low-level file access for the store.\n",
+		"- `mem/` - " + Placeholder + "\n",
+		"## Tests\n\n- `store_test.go` - " + Placeholder + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("skeleton missing %q\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"cache", "path(", "helper()", "notes.txt", "TestNew"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("skeleton should not mention %q\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestGenerate_OtherLanguagesUseLeadingComments(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeFiles(t, fs, map[string]string{
+		"/repo/web/index.ts":      "/**\n * Entry point for the web client. Wires routes.\n */\nexport {}\n",
+		"/repo/web/api.ts":        "// Copyright 2024 Example\n\n// HTTP client for the backend API\nexport const get = 1\n",
+		"/repo/web/util.js":       "'use strict';\nmodule.exports = {}\n",
+		"/repo/web/app.spec.ts":   "// Tests for the app shell\n",
+		"/repo/tools/__init__.py": "#!/usr/bin/env python\n\"\"\"Build tooling helpers.\n\nMore detail.\n\"\"\"\n",
+		"/repo/tools/run.py":      "# Runs the build\nimport os\n",
+	})
+
+	web, err := Generate(fs, "/repo/web", docfiles.Default())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	for _, want := range []string{
+		"# web\n\nEntry point for the web client.\n",
+		"- `api.ts` - HTTP client for the backend API\n",
+		"- `util.js` - " + Placeholder + "\n",
+		"## Tests\n\n- `app.spec.ts` - Tests for the app shell\n",
+	} {
+		if !strings.Contains(web, want) {
+			t.Errorf("web skeleton missing %q\n%s", want, web)
+		}
+	}
+
+	tools, err := Generate(fs, "/repo/tools", docfiles.Default())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	for _, want := range []string{
+		"# tools\n\nBuild tooling helpers.\n",
+		"- `run.py` - Runs the build\n",
+	} {
+		if !strings.Contains(tools, want) {
+			t.Errorf("tools skeleton missing %q\n%s", want, tools)
+		}
+	}
+}
+
+func TestGenerate_NoSourcesUsesPlaceholderSummary(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeFiles(t, fs, map[string]string{"/repo/empty/data.json": "{}"})
+
+	got, err := Generate(fs, "/repo/empty", docfiles.Default())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if got != "# empty\n\n"+Placeholder+": summarize the purpose of this directory.\n" {
+		t.Errorf("unexpected skeleton:\n%s", got)
+	}
+}
@@ -5,6 +5,7 @@ package doc
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -18,7 +19,16 @@ type TranscriptEntry struct {
 	Type      string   `json:"type"`      // "assistant_message" or "agent_result"
 	Timestamp string   `json:"timestamp"` // ISO 8601 timestamp
 	AgentID   string   `json:"agentId,omitempty"`
-	Content   []string `json:"content"` // Text content extracted
+	Content   []string `json:"content"`        // Text content extracted
+	Line      int      `json:"line,omitempty"` // 1-indexed line in the transcript
+}
+
+// ToolUse is a tool call found in a transcript
+type ToolUse struct {
+	Line  int                    `json:"line"`
+	ID    string                 `json:"id"`
+	Name  string                 `json:"name"`
+	Input map[string]interface{} `json:"input"`
 }
 
 // rawTranscriptLine represents the raw JSONL structure we're parsing
@@ -40,8 +50,11 @@ type rawToolUseResult struct {
 }
 
 type rawContent struct {
-	Type string `json:"type"`
-	Text string `json:"text,omitempty"`
+	Type  string                 `json:"type"`
+	Text  string                 `json:"text,omitempty"`
+	ID    string                 `json:"id,omitempty"`
+	Name  string                 `json:"name,omitempty"`
+	Input map[string]interface{} `json:"input,omitempty"`
 }
 
 // ParseTranscript reads JSONL transcript and extracts relevant entries.
@@ -94,6 +107,7 @@ func parseTranscriptFromReader(r io.Reader, startLine int) ([]TranscriptEntry, i
 		// Extract relevant entries based on type
 		entry := extractEntry(&raw)
 		if entry != nil {
+			entry.Line = lineNum
 			entries = append(entries, *entry)
 		}
 	}
@@ -105,6 +119,54 @@ func parseTranscriptFromReader(r io.Reader, startLine int) ([]TranscriptEntry, i
 	return entries, lineNum, nil
 }
 
+// FindToolUse locates the assistant tool call with the given tool_use ID.
+// Returns nil when the transcript has no such call. Lines are read whole,
+// however long: a Write of a large file is one large line.
+func FindToolUse(fs afero.Fs, transcriptPath, toolUseID string) (*ToolUse, error) {
+	file, err := fs.Open(transcriptPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	lineNum := 0
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			lineNum++
+			if use := toolUseInLine(line, toolUseID); use != nil {
+				use.Line = lineNum
+				return use, nil
+			}
+		}
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading transcript: %w", err)
+		}
+	}
+}
+
+// toolUseInLine returns the tool call with the given ID if the transcript
+// line is an assistant message holding it
+func toolUseInLine(line []byte, toolUseID string) *ToolUse {
+	if toolUseID == "" || !bytes.Contains(line, []byte(toolUseID)) {
+		return nil
+	}
+	var raw rawTranscriptLine
+	if err := json.Unmarshal(line, &raw); err != nil || raw.Type != "assistant" || raw.Message == nil {
+		return nil
+	}
+	for _, c := range raw.Message.Content {
+		if c.Type == "tool_use" && c.ID == toolUseID {
+			return &ToolUse{ID: c.ID, Name: c.Name, Input: c.Input}
+		}
+	}
+	return nil
+}
+
 // extractEntry converts a raw transcript line to a TranscriptEntry if relevant
 // Returns nil if the line should be filtered out
 func extractEntry(raw *rawTranscriptLine) *TranscriptEntry {
@@ -182,6 +182,62 @@ func TestParseTranscript_EmptyTextContent(t *testing.T) {
 	assert.Equal(t, "Valid", entries[0].Content[0])
 }
 
+func TestParseTranscript_LineNumbers(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	transcriptPath := "/test/transcript.jsonl"
+
+	content := `{"type":"user","timestamp":"2024-01-15T10:29:00Z","message":"Fix the lock"}
+{"type":"assistant","timestamp":"2024-01-15T10:30:00Z","message":{"content":[{"type":"text","text":"Checking PID liveness."}]}}
+{"type":"assistant","timestamp":"2024-01-15T10:30:05Z","message":{"content":[{"type":"tool_use","id":"toolu_1","name":"Edit","input":{"file_path":"/repo/lock.go","new_string":"alive()"}}]}}
+{"type":"assistant","timestamp":"2024-01-15T10:31:00Z","message":{"content":[{"type":"text","text":"Done."}]}}
+`
+	afero.WriteFile(fs, transcriptPath, []byte(content), 0644)
+
+	entries, _, err := ParseTranscript(fs, transcriptPath, 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, 2, entries[0].Line)
+	assert.Equal(t, 4, entries[1].Line)
+
+	use, err := FindToolUse(fs, transcriptPath, "toolu_1")
+	require.NoError(t, err)
+	require.NotNil(t, use)
+	assert.Equal(t, 3, use.Line)
+	assert.Equal(t, "Edit", use.Name)
+	assert.Equal(t, "alive()", use.Input["new_string"])
+
+	use, err = FindToolUse(fs, transcriptPath, "toolu_missing")
+	require.NoError(t, err)
+	assert.Nil(t, use)
+
+	_, err = FindToolUse(fs, "/nonexistent.jsonl", "toolu_1")
+	assert.Error(t, err)
+}
+
+func TestFindToolUse_LongLines(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	transcriptPath := "/test/transcript.jsonl"
+
+	big := strings.Repeat("x", 2*1024*1024)
+	content := `{"type":"assistant","message":{"content":[{"type":"text","text":"` + big + `"}]}}
+{"type":"assistant","message":{"content":[{"type":"tool_use","id":"toolu_big","name":"Write","input":{"file_path":"/repo/big.txt","content":"` + big + `"}}]}}
+{"type":"assistant","message":{"content":[{"type":"tool_use","id":"toolu_2","name":"Edit","input":{"file_path":"/repo/a.go"}}]}}`
+	require.NoError(t, afero.WriteFile(fs, transcriptPath, []byte(content), 0644))
+
+	use, err := FindToolUse(fs, transcriptPath, "toolu_big")
+	require.NoError(t, err)
+	require.NotNil(t, use)
+	assert.Equal(t, 2, use.Line)
+	assert.Len(t, use.Input["content"], len(big))
+
+	// The last line has no trailing newline
+	use, err = FindToolUse(fs, transcriptPath, "toolu_2")
+	require.NoError(t, err)
+	require.NotNil(t, use)
+	assert.Equal(t, 3, use.Line)
+	assert.Equal(t, "Edit", use.Name)
+}
+
 func TestFormatTranscriptForPrompt_Empty(t *testing.T) {
 	entries := []TranscriptEntry{}
 
@@ -2,14 +2,20 @@ package doc
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"syscall"
+	"time"
 
 	"claudex/internal/services/commander"
 	"claudex/internal/services/env"
+	"claudex/internal/services/lock"
+	"claudex/internal/services/session"
 
 	"github.com/spf13/afero"
 )
@@ -23,8 +29,40 @@ type UpdaterConfig struct {
 	SessionContext string // Additional session context to include
 	Model          string // Claude model to use (e.g., "haiku")
 	StartLine      int    // Line number to start reading transcript (1-indexed)
+
+	// Timeout stops the Claude invocation of a synchronous Run after this
+	// long (0 = no limit); the processed line marker is then left unchanged.
+	// With a Timeout, Run does not wait for another update of the session
+	Timeout time.Duration
 }
 
+// OverviewConfig returns the config of a session-overview.md update from the
+// transcript lines after lastLine, using the project's documenter prompt
+func OverviewConfig(projectRoot, sessionPath, transcriptPath string, lastLine int) UpdaterConfig {
+	return UpdaterConfig{
+		SessionPath:    sessionPath,
+		TranscriptPath: transcriptPath,
+		OutputFile:     "session-overview.md",
+		PromptTemplate: filepath.Join(projectRoot, ".claude", "hooks", "prompts", "session-overview-documenter.md"),
+		Model:          "haiku",
+		StartLine:      lastLine + 1, // Start from next line (1-indexed)
+	}
+}
+
+// ErrTimeout is returned by Run when the Claude invocation exceeded Timeout
+var ErrTimeout = errors.New("doc update timed out")
+
+// ErrUpdateRunning is returned by Run when another update of the session is
+// running and the config has a Timeout, so Run does not wait for it
+var ErrUpdateRunning = errors.New("another doc update of the session is running")
+
+// LockFile is the file in the session folder held while an update runs, so
+// synchronous checkpoints and background updates never overlap
+const LockFile = ".doc-update.lock"
+
+// lockPollInterval is how often Run retries a session lock held elsewhere
+const lockPollInterval = 100 * time.Millisecond
+
 // Updater handles background Claude invocations for doc updates
 type Updater struct {
 	fs  afero.Fs
@@ -127,15 +165,29 @@ type docUpdateInput struct {
 }
 
 // Run executes doc update synchronously (for testing)
-// This is the main implementation that does the actual work
+// This is the main implementation that does the actual work. It holds the
+// session's LockFile throughout, waiting for a running update unless the
+// config has a Timeout.
 func (u *Updater) Run(config UpdaterConfig) error {
 	// Check recursion guard before doing any work
 	if u.env.Get("CLAUDE_HOOK_INTERNAL") == "1" {
 		return fmt.Errorf("recursion guard: CLAUDE_HOOK_INTERNAL is set")
 	}
 
+	l, err := u.acquireSessionLock(config.SessionPath, config.Timeout == 0)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	// An update that held the lock meanwhile may have processed these lines
+	startLine := config.StartLine
+	if last, err := session.ReadLastProcessedLine(u.fs, config.SessionPath); err == nil && last >= startLine {
+		startLine = last + 1
+	}
+
 	// Parse transcript from startLine
-	entries, lastLine, err := ParseTranscript(u.fs, config.TranscriptPath, config.StartLine)
+	entries, lastLine, err := ParseTranscript(u.fs, config.TranscriptPath, startLine)
 	if err != nil {
 		return fmt.Errorf("failed to parse transcript: %w", err)
 	}
@@ -158,7 +210,7 @@ func (u *Updater) Run(config UpdaterConfig) error {
 	prompt := BuildDocumentationPrompt(template, transcriptContent, config.SessionContext, config.SessionPath)
 
 	// Invoke Claude with recursion guard
-	if err := u.invokeClaude(prompt, config.Model); err != nil {
+	if err := u.invokeClaude(prompt, config.Model, config.Timeout); err != nil {
 		return fmt.Errorf("failed to invoke Claude: %w", err)
 	}
 
@@ -171,6 +223,26 @@ func (u *Updater) Run(config UpdaterConfig) error {
 	return nil
 }
 
+// acquireSessionLock takes the LockFile of the session at sessionPath. While
+// another process holds it, it waits when wait is set and returns
+// ErrUpdateRunning otherwise.
+func (u *Updater) acquireSessionLock(sessionPath string, wait bool) (*lock.Lock, error) {
+	svc := lock.New(u.fs)
+	path := filepath.Join(sessionPath, LockFile)
+	for {
+		l, err := svc.Acquire(path)
+		switch {
+		case err == nil:
+			return l, nil
+		case !errors.Is(err, os.ErrExist):
+			return nil, fmt.Errorf("failed to acquire %s: %w", LockFile, err)
+		case !wait:
+			return nil, ErrUpdateRunning
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
 // validateConfig checks that all required configuration fields are present
 func (u *Updater) validateConfig(config UpdaterConfig) error {
 	if config.SessionPath == "" {
@@ -191,9 +263,9 @@ func (u *Updater) validateConfig(config UpdaterConfig) error {
 	return nil
 }
 
-// invokeClaude calls the claude CLI with the given prompt
-// Sets CLAUDE_HOOK_INTERNAL=1 to prevent recursion
-func (u *Updater) invokeClaude(prompt string, model string) error {
+// invokeClaude calls the claude CLI with the given prompt, killing it after
+// timeout when non-zero. Sets CLAUDE_HOOK_INTERNAL=1 to prevent recursion
+func (u *Updater) invokeClaude(prompt string, model string, timeout time.Duration) error {
 	// Set recursion guard in environment
 	originalValue := u.env.Get("CLAUDE_HOOK_INTERNAL")
 	u.env.Set("CLAUDE_HOOK_INTERNAL", "1")
@@ -209,7 +281,13 @@ func (u *Updater) invokeClaude(prompt string, model string) error {
 	// Create command with recursion guard via actual exec.Command
 	// We need to use exec.Command directly here to set custom environment
 	// Note: We don't use --output-format stream-json as it requires --verbose with -p
-	cmd := exec.Command("claude", "-p", prompt, "--model", model)
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, "claude", "-p", prompt, "--model", model)
 
 	// Set environment with recursion guard
 	cmdEnv := os.Environ()
@@ -223,6 +301,9 @@ func (u *Updater) invokeClaude(prompt string, model string) error {
 
 	// Execute command
 	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%w after %s", ErrTimeout, timeout)
+	}
 	if err != nil {
 		return fmt.Errorf("claude command failed: %w (stderr: %s)", err, stderr.String())
 	}
@@ -1,8 +1,11 @@
 package doc
 
 import (
+	"path/filepath"
 	"testing"
+	"time"
 
+	"claudex/internal/services/lock"
 	"claudex/internal/testutil"
 
 	"github.com/spf13/afero"
@@ -121,6 +124,58 @@ func TestRun_NoNewContent(t *testing.T) {
 	assert.Len(t, h.Commander.Invocations, 0)
 }
 
+func TestRun_RunningUpdate(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	sessionPath := "/test/session"
+	transcriptPath := "/test/transcript.jsonl"
+	templatePath := "/test/template.md"
+
+	h.CreateDir(sessionPath)
+	h.WriteFile(transcriptPath, `{"type":"assistant","timestamp":"2024-01-15T10:30:00Z","message":{"content":[{"type":"text","text":"Hello world"}]}}
+`)
+	h.WriteFile(templatePath, "Template")
+
+	held, err := lock.New(h.FS).Acquire(filepath.Join(sessionPath, LockFile))
+	require.NoError(t, err)
+
+	updater := NewUpdater(h.FS, h.Commander, h.Env)
+	config := UpdaterConfig{
+		SessionPath:    sessionPath,
+		TranscriptPath: transcriptPath,
+		PromptTemplate: templatePath,
+		Model:          "haiku",
+		StartLine:      1,
+		Timeout:        time.Second,
+	}
+
+	// A checkpoint with a budget does not wait
+	assert.ErrorIs(t, updater.Run(config), ErrUpdateRunning)
+
+	// A background update waits; the holder processed the line meanwhile, so
+	// nothing is left for it
+	config.Timeout = 0
+	done := make(chan error, 1)
+	go func() { done <- updater.Run(config) }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Run returned while the session lock was held: %v", err)
+	case <-time.After(3 * lockPollInterval):
+	}
+	h.WriteFile(filepath.Join(sessionPath, ".last-processed-line-overview"), "1")
+	require.NoError(t, held.Release())
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not proceed after the session lock was released")
+	}
+	exists, _ := afero.Exists(h.FS, filepath.Join(sessionPath, LockFile))
+	assert.False(t, exists)
+}
+
 func TestRun_TranscriptNotFound(t *testing.T) {
 	h := testutil.NewTestHarness()
 
@@ -228,6 +283,17 @@ func TestRunBackground_Success(t *testing.T) {
 	// The goroutine may or may not have completed by the time we check
 }
 
+func TestOverviewConfig(t *testing.T) {
+	config := OverviewConfig("/project", "/project/.claudex/sessions/s", "/t.jsonl", 41)
+	assert.Equal(t, "/project/.claude/hooks/prompts/session-overview-documenter.md", config.PromptTemplate)
+	assert.Equal(t, "session-overview.md", config.OutputFile)
+	assert.Equal(t, "haiku", config.Model)
+	assert.Equal(t, 42, config.StartLine)
+
+	h := testutil.NewTestHarness()
+	assert.NoError(t, NewUpdater(h.FS, h.Commander, h.Env).validateConfig(config))
+}
+
 func TestValidateConfig_AllValid(t *testing.T) {
 	h := testutil.NewTestHarness()
 	updater := NewUpdater(h.FS, h.Commander, h.Env)
@@ -2,7 +2,6 @@ package posttooluse
 
 import (
 	"fmt"
-	"path/filepath"
 	"strings"
 
 	"claudex/internal/doc"
@@ -78,16 +77,13 @@ func (h *AutoDocHandler) Handle(input *shared.PostToolUseInput) (*shared.HookOut
 		startLine = 0 // Start from beginning if we can't read the marker
 	}
 
-	// Find project root to build absolute template path
-	projectRoot, err := h.findProjectRoot(sessionPath)
+	// Find project root for the absolute template path
+	projectRoot, err := session.FindProjectRoot(h.fs, sessionPath)
 	if err != nil {
 		_ = h.logger.LogError(fmt.Errorf("failed to find project root: %w", err))
 		return h.allowOutput(), nil
 	}
 
-	// Build absolute path to template
-	templatePath := filepath.Join(projectRoot, ".claude", "hooks", "prompts", "session-overview-documenter.md")
-
 	// Read existing session context
 	sessionContext, err := h.readSessionContext(sessionPath)
 	if err != nil {
@@ -96,15 +92,8 @@ func (h *AutoDocHandler) Handle(input *shared.PostToolUseInput) (*shared.HookOut
 	}
 
 	// Trigger documentation update (background, non-blocking)
-	config := doc.UpdaterConfig{
-		SessionPath:    sessionPath,
-		TranscriptPath: input.TranscriptPath,
-		OutputFile:     "session-overview.md",
-		PromptTemplate: templatePath,
-		SessionContext: sessionContext,
-		Model:          "haiku",
-		StartLine:      startLine + 1, // Start from next line (1-indexed)
-	}
+	config := doc.OverviewConfig(projectRoot, sessionPath, input.TranscriptPath, startLine)
+	config.SessionContext = sessionContext
 
 	if err := h.updater.RunBackground(config); err != nil {
 		_ = h.logger.LogError(fmt.Errorf("failed to start background doc update: %w", err))
@@ -124,25 +113,6 @@ func (h *AutoDocHandler) allowOutput() *shared.HookOutput {
 	}
 }
 
-// findProjectRoot walks up from sessionPath to find the project root (where .claude directory exists)
-func (h *AutoDocHandler) findProjectRoot(sessionPath string) (string, error) {
-	current := sessionPath
-	for {
-		claudeDir := filepath.Join(current, ".claude")
-		exists, err := afero.DirExists(h.fs, claudeDir)
-		if err == nil && exists {
-			return current, nil
-		}
-
-		parent := filepath.Dir(current)
-		if parent == current {
-			// Reached filesystem root
-			return "", fmt.Errorf("could not find .claude directory in any parent of %s", sessionPath)
-		}
-		current = parent
-	}
-}
-
 // readSessionContext reads existing markdown files from session folder and builds context string
 func (h *AutoDocHandler) readSessionContext(sessionPath string) (string, error) {
 	files, err := afero.ReadDir(h.fs, sessionPath)
@@ -43,7 +43,7 @@ func TestAutoDocHandler_UsesAbsoluteTemplatePath(t *testing.T) {
 		".last-processed-line-overview": "0",
 	})
 
-	// Create .claude directory structure for findProjectRoot to work
+	// Create .claude directory structure for session.FindProjectRoot to work
 	projectRoot := "/Users/test"
 	claudeDir := filepath.Join(projectRoot, ".claude", "hooks", "prompts")
 	h.CreateDir(claudeDir)
@@ -102,7 +102,7 @@ func TestAutoDocHandler_PopulatesSessionContext(t *testing.T) {
 		"implementation-plan.md":        "# Plan\nSteps to take...",
 	})
 
-	// Create .claude directory structure for findProjectRoot to work
+	// Create .claude directory structure for session.FindProjectRoot to work
 	projectRoot := "/Users/test"
 	claudeDir := filepath.Join(projectRoot, ".claude", "hooks", "prompts")
 	h.CreateDir(claudeDir)
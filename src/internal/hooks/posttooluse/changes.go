@@ -0,0 +1,46 @@
+package posttooluse
+
+import (
+	"fmt"
+	"time"
+
+	"claudex/internal/hooks/shared"
+	"claudex/internal/services/ledger"
+	"claudex/internal/services/session"
+)
+
+// recordChanges appends the files the tool changed to the session ledger
+// (changes.jsonl). Failures are logged: the ledger must never block a tool.
+func (h *Handler) recordChanges(input *shared.PostToolUseInput) {
+	// Changes made by claudex's own Claude invocations (doc updates) are not
+	// the session's
+	if h.env.Get("CLAUDE_HOOK_INTERNAL") == "1" || input.CWD == "" {
+		return
+	}
+
+	entries := ledger.Entries(h.fs, ledger.Call{
+		Tool:       input.ToolName,
+		Input:      input.ToolInput,
+		CWD:        input.CWD,
+		AgentID:    input.AgentID,
+		AgentType:  input.AgentType,
+		ToolUseID:  input.ToolUseID,
+		Transcript: input.TranscriptPath,
+		Time:       time.Now().UTC(),
+	})
+	if len(entries) == 0 {
+		return
+	}
+
+	projectDir := session.FindProjectDir(h.fs, h.env, input.CWD)
+	sessionPath, err := session.FindSessionFolderWithCwd(h.fs, h.env, input.SessionID, projectDir)
+	if err != nil {
+		_ = h.logger.LogError(fmt.Errorf("failed to find session folder for the change ledger: %w", err))
+		return
+	}
+	if err := ledger.Append(h.fs, sessionPath, entries); err != nil {
+		_ = h.logger.LogError(fmt.Errorf("failed to record changes: %w", err))
+		return
+	}
+	_ = h.logger.LogInfo(fmt.Sprintf("Recorded %d changed file(s) from %s", len(entries), input.ToolName))
+}
@@ -0,0 +1,94 @@
+package posttooluse
+
+import (
+	"testing"
+
+	"claudex/internal/hooks/shared"
+	"claudex/internal/services/ledger"
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandler_RecordsChanges tests that file changes land in the session ledger
+func TestHandler_RecordsChanges(t *testing.T) {
+	h := testutil.NewTestHarness()
+	logger := shared.NewLogger(h.FS, h.Env, "posttooluse-test")
+	sessionPath := "/project/.claudex/sessions/fix-locks-abc"
+	h.CreateDir(sessionPath)
+	h.WriteFile("/project/main.go", "package main\n")
+	handler := NewHandler(h.FS, h.Env, logger)
+
+	call := func(tool string, input map[string]interface{}) {
+		t.Helper()
+		output, err := handler.Handle(&shared.PostToolUseInput{
+			HookInput: shared.HookInput{SessionID: "abc", CWD: "/project", TranscriptPath: "/claude/abc.jsonl"},
+			ToolName:  tool,
+			ToolInput: input,
+			ToolUseID: "toolu_" + tool,
+			AgentID:   "agent-1",
+			AgentType: "Plan",
+			Status:    "success",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "allow", output.HookSpecificOutput.PermissionDecision)
+	}
+	call("Edit", map[string]interface{}{"file_path": "/project/main.go"})
+	call("Read", map[string]interface{}{"file_path": "/project/other.go"})
+	call("Bash", map[string]interface{}{"command": "rm gone.go"})
+
+	entries, err := ledger.Read(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "/project/main.go", entries[0].Path)
+	assert.Equal(t, "Edit", entries[0].Tool)
+	assert.Equal(t, "agent-1", entries[0].AgentID)
+	assert.Equal(t, "Plan", entries[0].AgentType)
+	assert.Equal(t, "toolu_Edit", entries[0].ToolUseID)
+	assert.Equal(t, "/claude/abc.jsonl", entries[0].Transcript)
+	assert.False(t, entries[0].Time.IsZero())
+	hash, _ := ledger.Hash(h.FS, "/project/main.go")
+	assert.Equal(t, hash, entries[0].Hash)
+	assert.Equal(t, "/project/gone.go", entries[1].Path)
+	assert.True(t, entries[1].Deleted)
+	assert.Equal(t, "rm gone.go", entries[1].Command)
+
+	// Calls made after `cd src` still reach the session
+	_, err = handler.Handle(&shared.PostToolUseInput{
+		HookInput: shared.HookInput{SessionID: "abc", CWD: "/project/src"},
+		ToolName:  "Bash",
+		ToolInput: map[string]interface{}{"command": "touch new.go"},
+	})
+	require.NoError(t, err)
+	entries, err = ledger.Read(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "/project/src/new.go", entries[2].Path)
+
+	// Internal invocations are not recorded
+	h.Env.Set("CLAUDE_HOOK_INTERNAL", "1")
+	call("Write", map[string]interface{}{"file_path": "/project/main.go"})
+	entries, err = ledger.Read(h.FS, sessionPath)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
+
+// TestHandler_NoSessionStillAllows tests that a missing session is not an error
+func TestHandler_NoSessionStillAllows(t *testing.T) {
+	h := testutil.NewTestHarness()
+	handler := NewHandler(h.FS, h.Env, shared.NewLogger(h.FS, h.Env, "posttooluse-test"))
+
+	output, err := handler.Handle(&shared.PostToolUseInput{
+		HookInput: shared.HookInput{SessionID: "missing", CWD: "/project"},
+		ToolName:  "Write",
+		ToolInput: map[string]interface{}{"file_path": "/project/a.go"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "allow", output.HookSpecificOutput.PermissionDecision)
+
+	exists, err := afero.Exists(h.FS, "/project/.claudex")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -4,23 +4,32 @@ import (
 	"fmt"
 
 	"claudex/internal/hooks/shared"
+	"claudex/internal/services/env"
+
+	"github.com/spf13/afero"
 )
 
 // Handler handles PostToolUse hook events.
-// It logs tool completion information and always returns an "allow" decision.
+// It logs tool completion information, records file changes in the session
+// ledger and always returns an "allow" decision.
 type Handler struct {
+	fs     afero.Fs
+	env    env.Environment
 	logger *shared.Logger
 }
 
-// NewHandler creates a new Handler with the provided logger.
-func NewHandler(logger *shared.Logger) *Handler {
+// NewHandler creates a new Handler with the provided dependencies.
+func NewHandler(fs afero.Fs, env env.Environment, logger *shared.Logger) *Handler {
 	return &Handler{
+		fs:     fs,
+		env:    env,
 		logger: logger,
 	}
 }
 
-// Handle processes a PostToolUse event by logging tool completion details
-// and returning an "allow" permission decision.
+// Handle processes a PostToolUse event by logging tool completion details,
+// recording the files the tool changed and returning an "allow" permission
+// decision.
 func (h *Handler) Handle(input *shared.PostToolUseInput) (*shared.HookOutput, error) {
 	if input == nil {
 		return nil, fmt.Errorf("input cannot be nil")
@@ -33,6 +42,8 @@ func (h *Handler) Handle(input *shared.PostToolUseInput) (*shared.HookOutput, er
 		_ = h.logger.LogError(fmt.Errorf("failed to log tool completion: %w", err))
 	}
 
+	h.recordChanges(input)
+
 	// Always return "allow" decision
 	return &shared.HookOutput{
 		HookSpecificOutput: shared.HookSpecificOutput{
@@ -0,0 +1,131 @@
+// Package precompact handles the PreCompact hook, fired before Claude Code
+// compacts the conversation, manually (/compact) or automatically. It brings
+// session-overview.md up to date first, so the overview reflects all work at
+// the moment the context is summarized.
+package precompact
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"claudex/internal/doc"
+	"claudex/internal/hooks/shared"
+	"claudex/internal/services/env"
+	"claudex/internal/services/session"
+
+	"github.com/spf13/afero"
+)
+
+// DefaultBudget bounds the synchronous checkpoint; Claude Code stops hooks
+// after 60 seconds
+const DefaultBudget = 45 * time.Second
+
+// BudgetEnvVar overrides the budget with a Go duration; "0" disables the checkpoint
+const BudgetEnvVar = "CLAUDEX_AUTODOC_PRECOMPACT_TIMEOUT"
+
+// Handler runs a synchronous session-overview checkpoint before compaction
+type Handler struct {
+	fs      afero.Fs
+	env     env.Environment
+	updater doc.DocumentationUpdater
+	logger  *shared.Logger
+	budget  time.Duration
+}
+
+// NewHandler creates a new Handler instance; a budget <= 0 disables the checkpoint
+func NewHandler(fs afero.Fs, env env.Environment, updater doc.DocumentationUpdater, logger *shared.Logger, budget time.Duration) *Handler {
+	return &Handler{
+		fs:      fs,
+		env:     env,
+		updater: updater,
+		logger:  logger,
+		budget:  budget,
+	}
+}
+
+// Budget reads the checkpoint budget from BudgetEnvVar, falling back to
+// DefaultBudget when unset or invalid
+func Budget(environ env.Environment) time.Duration {
+	value := environ.Get(BudgetEnvVar)
+	if value == "" {
+		return DefaultBudget
+	}
+	budget, err := time.ParseDuration(value)
+	if err != nil {
+		return DefaultBudget
+	}
+	return budget
+}
+
+// Handle updates session-overview.md from the transcript lines not processed
+// yet, waiting at most the budget. When the budget runs out, or a background
+// update of the session is already running, the update is handed to a
+// background process so the work is not lost. No JSON output is
+// needed for PreCompact hooks, and compaction is never blocked by a failure.
+func (h *Handler) Handle(input *shared.PreCompactInput) error {
+	// Skip processing for internal Claude invocations (e.g., from doc-update subprocess)
+	if h.env.Get("CLAUDE_HOOK_INTERNAL") == "1" {
+		return nil
+	}
+
+	_ = h.logger.LogInfo(fmt.Sprintf("Compacting conversation (trigger: %s)", input.Trigger))
+
+	if h.budget <= 0 {
+		_ = h.logger.LogInfo("Pre-compaction checkpoint disabled")
+		return nil
+	}
+
+	sessionPath, err := session.FindSessionFolderWithCwd(h.fs, h.env, input.SessionID, input.CWD)
+	if err != nil {
+		_ = h.logger.LogError(fmt.Errorf("failed to find session folder: %w", err))
+		return nil
+	}
+
+	startLine, err := session.ReadLastProcessedLine(h.fs, sessionPath)
+	if err != nil {
+		_ = h.logger.LogError(fmt.Errorf("failed to read last processed line: %w", err))
+		startLine = 0 // Start from beginning if we can't read the marker
+	}
+
+	projectRoot, err := session.FindProjectRoot(h.fs, sessionPath)
+	if err != nil {
+		_ = h.logger.LogError(fmt.Errorf("failed to find project root: %w", err))
+		return nil
+	}
+
+	config := doc.OverviewConfig(projectRoot, sessionPath, input.TranscriptPath, startLine)
+	config.Timeout = h.budget
+
+	start := time.Now()
+	err = h.updater.Run(config)
+	switch {
+	case errors.Is(err, doc.ErrTimeout):
+		_ = h.logger.LogInfo(fmt.Sprintf("Checkpoint exceeded %s, finishing in background", h.budget))
+		h.continueInBackground(config)
+		return nil
+	case errors.Is(err, doc.ErrUpdateRunning):
+		_ = h.logger.LogInfo("A doc update of the session is running, checkpoint continues in background after it")
+		h.continueInBackground(config)
+		return nil
+	case err != nil:
+		_ = h.logger.LogError(fmt.Errorf("checkpoint failed: %w", err))
+		return nil
+	}
+
+	// The overview is current, so the auto-doc counter starts over
+	if err := session.ResetCounter(h.fs, sessionPath); err != nil {
+		_ = h.logger.LogError(fmt.Errorf("failed to reset counter: %w", err))
+	}
+	_ = h.logger.LogInfo(fmt.Sprintf("Checkpoint completed in %s", time.Since(start).Round(time.Millisecond)))
+	return nil
+}
+
+// continueInBackground hands the checkpoint to a background update without a
+// time limit, which waits for any running update of the session
+func (h *Handler) continueInBackground(config doc.UpdaterConfig) {
+	config.Timeout = 0
+	if err := h.updater.RunBackground(config); err != nil {
+		_ = h.logger.LogError(fmt.Errorf("failed to start background doc update: %w", err))
+	}
+}
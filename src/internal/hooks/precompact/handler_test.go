@@ -0,0 +1,130 @@
+package precompact
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"claudex/internal/doc"
+	"claudex/internal/hooks/shared"
+	"claudex/internal/services/session"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sessionPath = "/project/.claudex/sessions/feature-abc-123"
+
+// mockUpdater records synchronous and background runs
+type mockUpdater struct {
+	runConfigs        []doc.UpdaterConfig
+	backgroundConfigs []doc.UpdaterConfig
+	runErr            error
+}
+
+func (m *mockUpdater) RunBackground(config doc.UpdaterConfig) error {
+	m.backgroundConfigs = append(m.backgroundConfigs, config)
+	return nil
+}
+
+func (m *mockUpdater) Run(config doc.UpdaterConfig) error {
+	m.runConfigs = append(m.runConfigs, config)
+	return m.runErr
+}
+
+func setup(t *testing.T) (afero.Fs, *shared.MockEnv) {
+	t.Helper()
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/project/.claude", 0755))
+	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
+	require.NoError(t, session.WriteLastProcessedLine(fs, sessionPath, 41))
+	require.NoError(t, session.WriteCounter(fs, sessionPath, 3))
+	return fs, shared.NewMockEnv()
+}
+
+func input() *shared.PreCompactInput {
+	return &shared.PreCompactInput{
+		HookInput: shared.HookInput{SessionID: "abc-123", CWD: "/project", TranscriptPath: "/tmp/t.jsonl"},
+		Trigger:   "auto",
+	}
+}
+
+func TestHandle_RunsSynchronousCheckpointFromLastProcessedLine(t *testing.T) {
+	fs, env := setup(t)
+	updater := &mockUpdater{}
+	handler := NewHandler(fs, env, updater, shared.NewLogger(fs, env, "test"), 30*time.Second)
+
+	require.NoError(t, handler.Handle(input()))
+
+	require.Len(t, updater.runConfigs, 1)
+	assert.Empty(t, updater.backgroundConfigs)
+	config := updater.runConfigs[0]
+	assert.Equal(t, 42, config.StartLine)
+	assert.Equal(t, 30*time.Second, config.Timeout)
+	assert.Equal(t, "session-overview.md", config.OutputFile)
+	assert.Equal(t, "/project/.claude/hooks/prompts/session-overview-documenter.md", config.PromptTemplate)
+
+	count, err := session.ReadCounter(fs, sessionPath)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count, "counter should restart after a checkpoint")
+}
+
+func TestHandle_TimeoutContinuesInBackground(t *testing.T) {
+	fs, env := setup(t)
+	updater := &mockUpdater{runErr: fmt.Errorf("%w after 1s", doc.ErrTimeout)}
+	handler := NewHandler(fs, env, updater, shared.NewLogger(fs, env, "test"), time.Second)
+
+	require.NoError(t, handler.Handle(input()))
+
+	require.Len(t, updater.backgroundConfigs, 1)
+	assert.Equal(t, 42, updater.backgroundConfigs[0].StartLine)
+	assert.Zero(t, updater.backgroundConfigs[0].Timeout)
+
+	count, err := session.ReadCounter(fs, sessionPath)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count, "counter is kept until the overview is updated")
+}
+
+func TestHandle_RunningUpdateContinuesInBackground(t *testing.T) {
+	fs, env := setup(t)
+	updater := &mockUpdater{runErr: doc.ErrUpdateRunning}
+	handler := NewHandler(fs, env, updater, shared.NewLogger(fs, env, "test"), time.Second)
+
+	require.NoError(t, handler.Handle(input()))
+
+	require.Len(t, updater.backgroundConfigs, 1)
+	assert.Equal(t, 42, updater.backgroundConfigs[0].StartLine)
+	assert.Zero(t, updater.backgroundConfigs[0].Timeout, "the background update waits for the running one")
+
+	count, err := session.ReadCounter(fs, sessionPath)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
+
+func TestHandle_SkipsWhenDisabledOrInternal(t *testing.T) {
+	fs, env := setup(t)
+	updater := &mockUpdater{}
+
+	require.NoError(t, NewHandler(fs, env, updater, shared.NewLogger(fs, env, "test"), 0).Handle(input()))
+
+	env.Set("CLAUDE_HOOK_INTERNAL", "1")
+	require.NoError(t, NewHandler(fs, env, updater, shared.NewLogger(fs, env, "test"), time.Second).Handle(input()))
+
+	assert.Empty(t, updater.runConfigs)
+	assert.Empty(t, updater.backgroundConfigs)
+}
+
+func TestBudget(t *testing.T) {
+	env := shared.NewMockEnv()
+	assert.Equal(t, DefaultBudget, Budget(env))
+
+	env.Set(BudgetEnvVar, "20s")
+	assert.Equal(t, 20*time.Second, Budget(env))
+
+	env.Set(BudgetEnvVar, "0")
+	assert.Equal(t, time.Duration(0), Budget(env))
+
+	env.Set(BudgetEnvVar, "soon")
+	assert.Equal(t, DefaultBudget, Budget(env))
+}
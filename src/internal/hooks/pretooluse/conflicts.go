@@ -0,0 +1,66 @@
+package pretooluse
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"claudex/internal/hooks/shared"
+	"claudex/internal/services/activity"
+	"claudex/internal/services/ledger"
+	"claudex/internal/services/paths"
+	"claudex/internal/services/policy"
+	"claudex/internal/services/session"
+)
+
+// checkConflicts asks before a file tool writes a file that another active
+// session changed recently, returning nil when there is no conflict
+func (h *Handler) checkConflicts(input *shared.PreToolUseInput, projectDir string) *shared.HookOutput {
+	if h.env.Get("CLAUDE_HOOK_INTERNAL") == "1" || input.CWD == "" || !ledger.IsFileTool(input.ToolName) {
+		return nil
+	}
+
+	window, ok := activity.Window(h.env)
+	if !ok && h.logger != nil {
+		_ = h.logger.LogError(fmt.Errorf("invalid %s %q, using %s", activity.WindowEnvVar, h.env.Get(activity.WindowEnvVar), window))
+	}
+	if window <= 0 {
+		return nil
+	}
+
+	sessionsDir := filepath.Join(projectDir, paths.SessionsDir)
+	ownSessionPath, err := session.FindSessionFolderWithCwd(h.fs, h.env, input.SessionID, projectDir)
+	if err == nil {
+		sessionsDir = filepath.Dir(ownSessionPath)
+	} else {
+		ownSessionPath = ""
+	}
+
+	targets := ledger.Targets(input.ToolName, input.ToolInput, input.CWD)
+	now := time.Now()
+	conflicts := activity.Conflicts(h.fs, sessionsDir, ownSessionPath, targets, window, now)
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	if h.logger != nil {
+		_ = h.logger.Logf("%s conflicts with %d recent change(s) by other active sessions", input.ToolName, len(conflicts))
+	}
+	return policyOutput(policy.Ask, FormatConflictReason(conflicts, projectDir, now))
+}
+
+// FormatConflictReason explains which active sessions changed the files
+// and where to review their changes
+func FormatConflictReason(conflicts []activity.Conflict, projectDir string, now time.Time) string {
+	var parts []string
+	for _, c := range conflicts {
+		path := c.Change.Path
+		if rel, err := filepath.Rel(projectDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+		parts = append(parts, fmt.Sprintf("%s was changed %s ago by active claudex session %s (%s by %s); review it with `claudex sessions files %s`",
+			path, now.Sub(c.Change.Time).Round(time.Second), c.Session, c.Change.Tool, c.Change.Agent(), c.Session))
+	}
+	return strings.Join(parts, "; ") + ". Confirm to overwrite."
+}
@@ -2,14 +2,16 @@ package pretooluse
 
 import (
 	"fmt"
-	"io/fs"
-	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
 	"claudex"
 	"claudex/internal/hooks/shared"
+	"claudex/internal/services/agentcontext"
+	"claudex/internal/services/docfiles"
+	"claudex/internal/services/docmap"
+	"claudex/internal/services/policy"
 	"claudex/internal/services/session"
 	"claudex/internal/services/stackdetect"
 
@@ -17,7 +19,8 @@ import (
 )
 
 // Handler processes PreToolUse hook events
-// It injects session context into Task tool invocations
+// It applies the tool policy, asks before overwriting files other active
+// sessions just changed, and injects agent context into Task tool invocations
 type Handler struct {
 	fs     afero.Fs
 	env    shared.Environment
@@ -34,25 +37,39 @@ func NewHandler(fs afero.Fs, env shared.Environment, logger *shared.Logger) *Han
 }
 
 // Handle processes PreToolUse events
-// Returns updatedInput for Task tools with session context injected
-// Returns allow with no modification for non-Task tools
+// Returns updatedInput for Task tools with the matching context rule applied
+// Returns no permission decision for other calls, leaving them to Claude
+// Code's own permission handling, unless a policy rule allowed them
 func (h *Handler) Handle(input *shared.PreToolUseInput) (*shared.HookOutput, error) {
 	// Log the tool being invoked
 	if h.logger != nil {
 		_ = h.logger.Logf("Processing PreToolUse for tool: %s", input.ToolName)
 	}
 
+	// The agent may have changed into a subdirectory of the project
+	projectDir := session.FindProjectDir(h.fs, h.env, input.CWD)
+
+	// Policy rules can deny the call or ask the user first
+	output, allowed := h.checkPolicy(input, projectDir)
+	if output != nil {
+		return output, nil
+	}
+	decision := ""
+	if allowed {
+		decision = string(policy.Allow)
+	}
+
+	// Files another active session just changed need confirmation
+	if output := h.checkConflicts(input, projectDir); output != nil {
+		return output, nil
+	}
+
 	// Only modify Task tool invocations
 	if input.ToolName != "Task" {
 		if h.logger != nil {
 			_ = h.logger.Logf("Tool %s is not Task, passing through unchanged", input.ToolName)
 		}
-		return &shared.HookOutput{
-			HookSpecificOutput: shared.HookSpecificOutput{
-				HookEventName:      "PreToolUse",
-				PermissionDecision: "allow",
-			},
-		}, nil
+		return passThrough(decision), nil
 	}
 
 	// Find session folder
@@ -62,12 +79,7 @@ func (h *Handler) Handle(input *shared.PreToolUseInput) (*shared.HookOutput, err
 		if h.logger != nil {
 			_ = h.logger.Logf("No session folder found: %v", err)
 		}
-		return &shared.HookOutput{
-			HookSpecificOutput: shared.HookSpecificOutput{
-				HookEventName:      "PreToolUse",
-				PermissionDecision: "allow",
-			},
-		}, nil
+		return passThrough(decision), nil
 	}
 
 	if h.logger != nil {
@@ -80,183 +92,192 @@ func (h *Handler) Handle(input *shared.PreToolUseInput) (*shared.HookOutput, err
 		if h.logger != nil {
 			_ = h.logger.LogInfo("No prompt found in tool_input, passing through unchanged")
 		}
-		return &shared.HookOutput{
-			HookSpecificOutput: shared.HookSpecificOutput{
-				HookEventName:      "PreToolUse",
-				PermissionDecision: "allow",
-			},
-		}, nil
+		return passThrough(decision), nil
 	}
 
-	// Check if this is an Explore agent - they get specialized context
+	// Pick the context rule for the agent type
 	subagentType, _ := input.ToolInput["subagent_type"].(string)
-	if strings.EqualFold(subagentType, "Explore") {
+	rules := h.loadRules(projectDir)
+	rule := rules.Match(subagentType)
+	if rule == nil {
 		if h.logger != nil {
-			_ = h.logger.Logf("Explore agent detected, injecting MCP/LSP instructions")
+			_ = h.logger.Logf("No context rule for agent %q, passing through unchanged", subagentType)
 		}
-
-		exploreContext := h.buildExploreContext()
-		modifiedPrompt := fmt.Sprintf("%s\n\n---\n\n## ORIGINAL REQUEST\n\n%s", exploreContext, originalPrompt)
-
-		updatedInput := make(map[string]interface{})
-		for k, v := range input.ToolInput {
-			updatedInput[k] = v
-		}
-		updatedInput["prompt"] = modifiedPrompt
-
-		return &shared.HookOutput{
-			HookSpecificOutput: shared.HookSpecificOutput{
-				HookEventName:      "PreToolUse",
-				PermissionDecision: "allow",
-				UpdatedInput:       updatedInput,
-			},
-		}, nil
+		return passThrough(decision), nil
 	}
 
-	// Check if this is a Plan agent - they get planning context + stack skills
-	if strings.EqualFold(subagentType, "Plan") {
-		if h.logger != nil {
-			_ = h.logger.Logf("Plan agent detected, injecting planning context + stack skills")
+	description, _ := input.ToolInput["description"].(string)
+	query := description + "\n" + originalPrompt
+	agentContext, err := h.buildContext(rules, rule, sessionPath, rule.ResolveDocPaths(h.env), projectDir, query)
+	if err != nil || agentContext == "" {
+		if err != nil && h.logger != nil {
+			_ = h.logger.LogError(fmt.Errorf("failed to build context for rule %q (%s): %w", rule.Name, rule.Source(), err))
 		}
+		// On error or an empty rule, pass through without modification
+		return passThrough(decision), nil
+	}
 
-		// Detect tech stacks
-		stacks := stackdetect.Detect(h.fs, input.CWD)
-
-		planContext := h.buildPlanContext(stacks)
-		modifiedPrompt := fmt.Sprintf("%s\n\n---\n\n## ORIGINAL REQUEST\n\n%s", planContext, originalPrompt)
-
-		updatedInput := make(map[string]interface{})
-		for k, v := range input.ToolInput {
-			updatedInput[k] = v
-		}
-		updatedInput["prompt"] = modifiedPrompt
+	// Create updated input with modified prompt
+	updatedInput := make(map[string]interface{})
+	for k, v := range input.ToolInput {
+		updatedInput[k] = v
+	}
+	updatedInput["prompt"] = rule.Apply(agentContext, originalPrompt)
 
-		return &shared.HookOutput{
-			HookSpecificOutput: shared.HookSpecificOutput{
-				HookEventName:      "PreToolUse",
-				PermissionDecision: "allow",
-				UpdatedInput:       updatedInput,
-			},
-		}, nil
+	if h.logger != nil {
+		_ = h.logger.Logf("Injected context from rule %q (%s) into Task tool prompt", rule.Name, rule.Source())
 	}
 
-	// Get doc paths from environment
-	docPathsStr := h.env.Get("CLAUDEX_DOC_PATHS")
-	var docPaths []string
-	if docPathsStr != "" {
-		docPaths = strings.Split(docPathsStr, ":")
+	// Claude Code applies updatedInput together with a decision; Task calls
+	// need no permission prompt, and deny or ask rules returned above
+	return &shared.HookOutput{
+		HookSpecificOutput: shared.HookSpecificOutput{
+			HookEventName:      "PreToolUse",
+			PermissionDecision: "allow",
+			UpdatedInput:       updatedInput,
+		},
+	}, nil
+}
+
+// checkPolicy evaluates the project and global policy files. It returns a
+// deny or ask response, or nil when handling continues; allowed reports
+// whether an allow rule matched, as opposed to no rule at all. An invalid
+// policy file turns every decision into ask, so it cannot silently stop
+// protecting anything.
+func (h *Handler) checkPolicy(input *shared.PreToolUseInput, projectDir string) (output *shared.HookOutput, allowed bool) {
+	// claudex's own Claude invocations (doc updates) are not subject to policies
+	if h.env.Get("CLAUDE_HOOK_INTERNAL") == "1" || input.CWD == "" {
+		return nil, false
 	}
 
-	// Build session context
-	sessionContext, err := h.buildSessionContext(sessionPath, docPaths, input.CWD)
+	p, err := policy.Load(h.fs, projectDir, h.env)
 	if err != nil {
 		if h.logger != nil {
-			_ = h.logger.LogError(fmt.Errorf("failed to build session context: %w", err))
+			_ = h.logger.LogError(fmt.Errorf("invalid policy: %w", err))
 		}
-		// On error, pass through without modification
-		return &shared.HookOutput{
-			HookSpecificOutput: shared.HookSpecificOutput{
-				HookEventName:      "PreToolUse",
-				PermissionDecision: "allow",
-			},
-		}, nil
+		return policyOutput(policy.Ask, fmt.Sprintf("claudex policy could not be loaded (%v); confirm this call manually", err)), false
 	}
 
-	// Build the modified prompt
-	modifiedPrompt := fmt.Sprintf("%s\n\n---\n\n## ORIGINAL REQUEST\n\n%s", sessionContext, originalPrompt)
-
-	// Create updated input with modified prompt
-	updatedInput := make(map[string]interface{})
-	for k, v := range input.ToolInput {
-		updatedInput[k] = v
+	sessionPath, _ := session.FindSessionFolderWithCwd(h.fs, h.env, input.SessionID, projectDir)
+	result := p.Evaluate(policy.Request{
+		Tool:        input.ToolName,
+		Input:       input.ToolInput,
+		Agent:       input.AgentType,
+		CWD:         input.CWD,
+		ProjectDir:  projectDir,
+		SessionPath: sessionPath,
+	})
+	if result.Decision == policy.Allow {
+		return nil, result.Matched()
 	}
-	updatedInput["prompt"] = modifiedPrompt
 
 	if h.logger != nil {
-		_ = h.logger.Logf("Injected session context into Task tool prompt")
+		_ = h.logger.Logf("Policy rule %q (%s) decided %s for %s", result.Rule, result.Source, result.Decision, input.ToolName)
 	}
+	return policyOutput(result.Decision, FormatPolicyReason(result)), false
+}
+
+// FormatPolicyReason explains a policy decision, naming the rule behind it
+func FormatPolicyReason(result policy.Result) string {
+	if !result.Matched() {
+		return "no policy rule matched"
+	}
+	origin := fmt.Sprintf("claudex policy rule %q in %s", result.Rule, result.Source)
+	if result.Reason == "" {
+		return origin
+	}
+	return fmt.Sprintf("%s (%s)", result.Reason, origin)
+}
 
+// passThrough builds a PreToolUse response that leaves the call unchanged;
+// decision is empty unless a policy rule allowed the call, so Claude Code
+// still asks for permission where it normally would
+func passThrough(decision string) *shared.HookOutput {
 	return &shared.HookOutput{
 		HookSpecificOutput: shared.HookSpecificOutput{
 			HookEventName:      "PreToolUse",
-			PermissionDecision: "allow",
-			UpdatedInput:       updatedInput,
+			PermissionDecision: decision,
 		},
-	}, nil
+	}
 }
 
-// buildSessionContext creates the markdown context block
-func (h *Handler) buildSessionContext(sessionPath string, docPaths []string, projectRoot string) (string, error) {
-	var sb strings.Builder
-
-	// Header
-	sb.WriteString("## SESSION CONTEXT (CRITICAL)\n\n")
-	sb.WriteString("You are working within an active Claudex session. ")
-	sb.WriteString("ALL documentation, plans, and artifacts MUST be created in the session folder.\n\n")
-	sb.WriteString(fmt.Sprintf("**Session Folder (Absolute Path)**: `%s`\n\n", sessionPath))
-
-	// Mandatory rules
-	sb.WriteString("### MANDATORY RULES for Documentation:\n")
-	sb.WriteString("1. ✅ ALWAYS save documentation to the session folder above\n")
-	sb.WriteString("2. ✅ Use absolute paths when creating files (Write/Edit tools)\n")
-	sb.WriteString("3. ✅ Before exploring the codebase, check the session folder for existing context\n")
-	sb.WriteString("4. ❌ NEVER save documentation to project root or arbitrary locations\n")
-	sb.WriteString("5. ❌ NEVER use relative paths for documentation files\n\n")
-
-	// Check for session-overview.md - if exists, use pointer; otherwise fallback to enumeration
+// policyOutput builds a PreToolUse response carrying a policy decision
+func policyOutput(decision policy.Decision, reason string) *shared.HookOutput {
+	return &shared.HookOutput{
+		HookSpecificOutput: shared.HookSpecificOutput{
+			HookEventName:            "PreToolUse",
+			PermissionDecision:       string(decision),
+			PermissionDecisionReason: reason,
+		},
+	}
+}
+
+// loadRules loads the project and global context rules ahead of the
+// embedded defaults; an invalid rules file is logged and only the defaults
+// apply
+func (h *Handler) loadRules(projectDir string) *agentcontext.Rules {
+	if projectDir != "" {
+		rules, err := agentcontext.Load(h.fs, claudex.Profiles, projectDir, h.env)
+		if err == nil {
+			return rules
+		}
+		if h.logger != nil {
+			_ = h.logger.LogError(fmt.Errorf("invalid context rules, using defaults: %w", err))
+		}
+	}
+	rules, err := agentcontext.Defaults(h.fs, claudex.Profiles)
+	if err != nil {
+		// The defaults are embedded, so this only fails on a broken build
+		if h.logger != nil {
+			_ = h.logger.LogError(err)
+		}
+		return &agentcontext.Rules{}
+	}
+	return rules
+}
+
+// buildContext renders a context rule for the session; query is the Task
+// description and prompt, used to rank relevant docs
+func (h *Handler) buildContext(rules *agentcontext.Rules, rule *agentcontext.Rule, sessionPath string, docPaths []string, projectRoot, query string) (string, error) {
+	data := agentcontext.Data{
+		SessionPath: sessionPath,
+		DocNames:    h.docNames().String(),
+		DocPaths:    docPaths,
+	}
+
+	// Point at session-overview.md when it exists; otherwise list the files
 	overviewPath := filepath.Join(sessionPath, "session-overview.md")
 	overviewExists, err := afero.Exists(h.fs, overviewPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to check for session-overview.md: %w", err)
 	}
-
-	sb.WriteString("### Session Folder Contents:\n")
 	if overviewExists {
-		// Pointer-based approach: just reference the overview file
-		sb.WriteString(fmt.Sprintf("- %s\n", overviewPath))
+		data.Overview = overviewPath
 	} else {
-		// Fallback to file enumeration for backward compatibility
 		files, err := h.listSessionFiles(sessionPath)
 		if err != nil {
 			return "", fmt.Errorf("failed to list session files: %w", err)
 		}
-
-		if len(files) == 0 {
-			sb.WriteString("(empty)\n")
-		} else {
-			for _, file := range files {
-				sb.WriteString(fmt.Sprintf("- %s\n", file))
-			}
-		}
+		data.SessionFiles = files
 	}
 
-	// Add activation procedure for documentation loading
-	sb.WriteString("\n### ACTIVATION PROCEDURE (Execute on Session Start)\n\n")
-	sb.WriteString("Before beginning any task work, execute this mandatory 3-step loading sequence:\n\n")
-
-	sb.WriteString("**STEP 1: Load Session Context**\n")
-	sb.WriteString(fmt.Sprintf("- Read `%s/session-overview.md` using the Read tool\n", sessionPath))
-
-	sb.WriteString("**STEP 2: Load Root Doc Files**\n")
-	sb.WriteString("- Read ALL files listed under \"Root Documentation Entry Points\" below\n")
-	sb.WriteString("- Use Read tool for each file (do NOT use Glob/Grep for discovery)\n")
-
-	sb.WriteString("**STEP 3: Recursive Index Traversal (Task-Driven)**\n")
-	sb.WriteString("- Each doc file contains links to other doc files in subdirectories\n")
-	sb.WriteString("- CRITICAL: Load only the files that are directly related and relevant to the task at hand\n")
+	if rule.StackSkills {
+		data.Stacks = stackdetect.Detect(h.fs, projectRoot)
+	}
+	if rule.RelevantDocs > 0 {
+		data.RelevantDocs = h.relevantDocs(projectRoot, query, rule.RelevantDocs)
+	}
 
-	// Add doc paths as root entry points
-	if len(docPaths) > 0 {
-		sb.WriteString("**Root Documentation Entry Points:**\n")
-		for _, docPath := range docPaths {
-			if docPath != "" {
-				sb.WriteString(fmt.Sprintf("- %s\n", docPath))
-			}
+	content, trimmed, err := rules.Render(rule, data)
+	if err != nil {
+		return "", err
+	}
+	if h.logger != nil {
+		for _, t := range trimmed {
+			_ = h.logger.Logf("Context budget of rule %q (%d tokens): trimmed %s by ~%d tokens (full text: %s)", rule.Name, rule.MaxTokens, t.Part, t.Tokens, t.Pointer)
 		}
-		sb.WriteString("\n")
 	}
-
-	return sb.String(), nil
+	return content, nil
 }
 
 // listSessionFiles returns markdown list of files in session folder
@@ -267,10 +288,10 @@ func (h *Handler) listSessionFiles(sessionPath string) ([]string, error) {
 		return nil, fmt.Errorf("failed to read session directory: %w", err)
 	}
 
-	// Collect file names (exclude directories)
+	// Collect file names (exclude directories and claudex bookkeeping)
 	var files []string
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		if !entry.IsDir() && !session.IsBookkeeping(entry.Name()) {
 			files = append(files, entry.Name())
 		}
 	}
@@ -281,138 +302,37 @@ func (h *Handler) listSessionFiles(sessionPath string) ([]string, error) {
 	return files, nil
 }
 
-// hasIndexMdFiles checks if any index.md files exist in the project directory tree
-func (h *Handler) hasIndexMdFiles(projectRoot string) bool {
-	// Empty project root - graceful degradation
-	if projectRoot == "" {
-		return false
+// relevantDocs ranks the project's doc files against the query and returns
+// the top n with their summaries
+func (h *Handler) relevantDocs(projectRoot, query string, n int) []agentcontext.RelevantDoc {
+	if projectRoot == "" || strings.TrimSpace(query) == "" {
+		return nil
 	}
-
-	// Use afero.Walk to traverse directory tree
-	found := false
-	afero.Walk(h.fs, projectRoot, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			// Continue walking even if we encounter errors
-			return nil
-		}
-
-		// Check if this is an index.md file
-		if !info.IsDir() && info.Name() == "index.md" {
-			found = true
-			// Early exit - we found one
-			return filepath.SkipDir
+	m, err := docmap.Build(h.fs, projectRoot, h.docNames())
+	if err != nil {
+		if h.logger != nil {
+			_ = h.logger.LogError(fmt.Errorf("failed to map docs: %w", err))
 		}
-
 		return nil
-	})
-
-	return found
-}
-
-// buildExploreContext creates the Explore-specific context with MCP/LSP instructions
-func (h *Handler) buildExploreContext() string {
-	var sb strings.Builder
-
-	sb.WriteString("## EXPLORE AGENT ENHANCEMENTS\n\n")
-	sb.WriteString("You have access to powerful tools for codebase exploration. Use them effectively.\n\n")
-
-	// LSP Instructions
-	sb.WriteString("### LSP Tool (PREFERRED for code navigation)\n")
-	sb.WriteString("Use LSP instead of brute-force Glob/Grep when possible:\n")
-	sb.WriteString("- `goToDefinition`: Jump to where a symbol is defined\n")
-	sb.WriteString("- `findReferences`: Find all usages of a symbol\n")
-	sb.WriteString("- `hover`: Get documentation and type info for a symbol\n")
-	sb.WriteString("- `documentSymbol`: List all symbols in a file\n")
-	sb.WriteString("- `workspaceSymbol`: Search symbols across the codebase\n")
-	sb.WriteString("- `incomingCalls`/`outgoingCalls`: Trace call hierarchy\n\n")
-	sb.WriteString("**Parameters**: `operation`, `filePath` (absolute), `line`, `character`\n\n")
-
-	// Context7 MCP Instructions
-	sb.WriteString("### Context7 MCP (for library documentation)\n")
-	sb.WriteString("Before making assumptions about libraries/frameworks, query current docs:\n")
-	sb.WriteString("1. `mcp__context7__resolve-library-id`: Get library ID (e.g., \"redis\" → \"/redis/redis\")\n")
-	sb.WriteString("2. `mcp__context7__query-docs`: Query specific documentation\n")
-	sb.WriteString("**Constraint**: Max 3 calls per question\n\n")
-
-	// Sequential Thinking MCP Instructions
-	sb.WriteString("### Sequential Thinking MCP (for complex analysis)\n")
-	sb.WriteString("Use `mcp__sequential-thinking__sequentialthinking` for:\n")
-	sb.WriteString("- Multi-step problem solving\n")
-	sb.WriteString("- Trade-off analysis\n")
-	sb.WriteString("- Complex architectural decisions\n\n")
-
-	// Best Practices
-	sb.WriteString("### Exploration Best Practices\n")
-	sb.WriteString("1. Start with LSP `workspaceSymbol` to find entry points\n")
-	sb.WriteString("2. Use `goToDefinition` to trace implementations\n")
-	sb.WriteString("3. Use `findReferences` to understand usage patterns\n")
-	sb.WriteString("4. Fall back to Glob/Grep only for pattern-based searches\n")
-	sb.WriteString("5. Cite findings with file:line format\n")
-
-	return sb.String()
-}
+	}
 
-// buildPlanContext creates Plan-specific context with MCP tools and stack skills
-func (h *Handler) buildPlanContext(stacks []string) string {
-	var sb strings.Builder
-
-	sb.WriteString("## PLAN AGENT ENHANCEMENTS\n\n")
-	sb.WriteString("You are creating an execution plan. Use these tools and practices.\n\n")
-
-	// MCP Tools (MANDATORY)
-	sb.WriteString("### MCP Tools (MANDATORY)\n\n")
-	sb.WriteString("**Context7 MCP** - Query documentation for all libraries/frameworks:\n")
-	sb.WriteString("1. `mcp__context7__resolve-library-id`: Get library ID\n")
-	sb.WriteString("2. `mcp__context7__query-docs`: Query specific documentation\n\n")
-	sb.WriteString("**Sequential Thinking MCP** - Use for parallelization analysis:\n")
-	sb.WriteString("- Component boundary identification\n")
-	sb.WriteString("- Dependency mapping (what blocks what)\n")
-	sb.WriteString("- Shared contract discovery\n")
-	sb.WriteString("- Parallel opportunity grouping (Track A/B/C)\n")
-	sb.WriteString("- Sequential constraint justification\n\n")
-
-	// Execution Plan Structure
-	sb.WriteString("### Execution Plan Structure\n\n")
-	sb.WriteString("**Phase Labeling** (MANDATORY):\n")
-	sb.WriteString("- `### Phase N: [Name] (Parallel: X independent tracks)`\n")
-	sb.WriteString("- `### Phase N: [Name] (Sequential)` with justification\n\n")
-	sb.WriteString("**Track Groupings** for parallel phases:\n")
-	sb.WriteString("```\n")
-	sb.WriteString("Track A: [task1, task2]\n")
-	sb.WriteString("Track B: [task3, task4]\n")
-	sb.WriteString("```\n\n")
-	sb.WriteString("**Architect Boundaries**:\n")
-	sb.WriteString("- Define WHAT to build and HOW to approach it\n")
-	sb.WriteString("- Code snippets: Max 15 lines for patterns, NOT full implementations\n")
-	sb.WriteString("- Use file:line pointers when referencing existing code\n\n")
-
-	// Inject stack-specific skills
-	if len(stacks) > 0 {
-		sb.WriteString("### Detected Tech Stack Skills\n\n")
-		for _, stack := range stacks {
-			skillContent := h.loadSkillContent(stack)
-			if skillContent != "" {
-				sb.WriteString(fmt.Sprintf("#### %s\n\n", strings.Title(stack)))
-				sb.WriteString(skillContent)
-				sb.WriteString("\n\n")
-			}
+	var docs []agentcontext.RelevantDoc
+	for _, match := range m.Search(query, n) {
+		summary := match.Summary
+		if summary == "" {
+			summary = match.Title
 		}
+		docs = append(docs, agentcontext.RelevantDoc{Path: filepath.Join(projectRoot, match.Path), Summary: summary})
 	}
-
-	return sb.String()
+	if h.logger != nil && len(docs) > 0 {
+		_ = h.logger.Logf("Ranked %d relevant docs, best: %s", len(docs), docs[0].Path)
+	}
+	return docs
 }
 
-// loadSkillContent reads skill file from embedded profiles
-func (h *Handler) loadSkillContent(stack string) string {
-	skillPath := fmt.Sprintf("profiles/skills/%s.md", stack)
-	content, err := fs.ReadFile(claudex.Profiles, skillPath)
-	if err != nil {
-		if h.logger != nil {
-			_ = h.logger.Logf("Could not load skill for %s: %v", stack, err)
-		}
-		return ""
-	}
-	return string(content)
+// docNames returns the documentation file names exported by claudex
+func (h *Handler) docNames() docfiles.Names {
+	return docfiles.FromEnv(h.env.Get(docfiles.EnvVar))
 }
 
 // HandleFromBuilder is a convenience wrapper that returns the built output
@@ -1,10 +1,16 @@
 package pretooluse
 
 import (
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
+	"claudex"
 	"claudex/internal/hooks/shared"
+	"claudex/internal/services/activity"
+	"claudex/internal/services/agentcontext"
+	"claudex/internal/services/ledger"
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
@@ -34,7 +40,7 @@ func TestHandler_NonTaskTool(t *testing.T) {
 	// Assert
 	require.NoError(t, err)
 	assert.Equal(t, "PreToolUse", output.HookSpecificOutput.HookEventName)
-	assert.Equal(t, "allow", output.HookSpecificOutput.PermissionDecision)
+	assert.Empty(t, output.HookSpecificOutput.PermissionDecision)
 	assert.Nil(t, output.HookSpecificOutput.UpdatedInput)
 }
 
@@ -63,7 +69,7 @@ func TestHandler_NoSessionFolder(t *testing.T) {
 	// Assert
 	require.NoError(t, err)
 	assert.Equal(t, "PreToolUse", output.HookSpecificOutput.HookEventName)
-	assert.Equal(t, "allow", output.HookSpecificOutput.PermissionDecision)
+	assert.Empty(t, output.HookSpecificOutput.PermissionDecision)
 	assert.Nil(t, output.HookSpecificOutput.UpdatedInput)
 }
 
@@ -100,7 +106,7 @@ func TestHandler_EmptyPrompt(t *testing.T) {
 	// Assert
 	require.NoError(t, err)
 	assert.Equal(t, "PreToolUse", output.HookSpecificOutput.HookEventName)
-	assert.Equal(t, "allow", output.HookSpecificOutput.PermissionDecision)
+	assert.Empty(t, output.HookSpecificOutput.PermissionDecision)
 	assert.Nil(t, output.HookSpecificOutput.UpdatedInput)
 }
 
@@ -136,7 +142,7 @@ func TestHandler_MissingPrompt(t *testing.T) {
 	// Assert
 	require.NoError(t, err)
 	assert.Equal(t, "PreToolUse", output.HookSpecificOutput.HookEventName)
-	assert.Equal(t, "allow", output.HookSpecificOutput.PermissionDecision)
+	assert.Empty(t, output.HookSpecificOutput.PermissionDecision)
 	assert.Nil(t, output.HookSpecificOutput.UpdatedInput)
 }
 
@@ -383,6 +389,9 @@ func TestHandler_SessionFolderWithDirectories(t *testing.T) {
 	afero.WriteFile(fs, sessionPath+"/file1.md", []byte("content"), 0644)
 	afero.WriteFile(fs, sessionPath+"/file2.txt", []byte("content"), 0644)
 	afero.WriteFile(fs, sessionPath+"/subdir/nested.md", []byte("content"), 0644)
+	afero.WriteFile(fs, sessionPath+"/session.lock", []byte("42\n"), 0644)
+	afero.WriteFile(fs, sessionPath+"/changes.jsonl", []byte("{}\n"), 0644)
+	afero.WriteFile(fs, sessionPath+"/.doc-update-counter", []byte("3"), 0644)
 
 	env.Set("CLAUDEX_SESSION_PATH", sessionPath)
 
@@ -412,6 +421,10 @@ func TestHandler_SessionFolderWithDirectories(t *testing.T) {
 	assert.Contains(t, modifiedPrompt, "- file2.txt")
 	assert.NotContains(t, modifiedPrompt, "- subdir")
 	assert.NotContains(t, modifiedPrompt, "- nested.md") // Should not list files in subdirs
+	// Nor claudex bookkeeping
+	assert.NotContains(t, modifiedPrompt, "session.lock")
+	assert.NotContains(t, modifiedPrompt, "changes.jsonl")
+	assert.NotContains(t, modifiedPrompt, ".doc-update-counter")
 }
 
 func TestBuildSessionContext(t *testing.T) {
@@ -434,7 +447,7 @@ func TestBuildSessionContext(t *testing.T) {
 	docPaths := []string{"research-{topic}.md", "execution-plan-{feature}.md"}
 
 	// Act
-	context, err := handler.buildSessionContext(sessionPath, docPaths, "")
+	context, err := buildSessionContext(t, handler, sessionPath, docPaths, "")
 
 	// Assert
 	require.NoError(t, err)
@@ -518,7 +531,7 @@ func TestBuildSessionContext_WithOverview(t *testing.T) {
 	handler := NewHandler(fs, env, logger)
 
 	// Act
-	context, err := handler.buildSessionContext(sessionPath, nil, "")
+	context, err := buildSessionContext(t, handler, sessionPath, nil, "")
 
 	// Assert
 	require.NoError(t, err)
@@ -547,7 +560,7 @@ func TestBuildSessionContext_WithoutOverview(t *testing.T) {
 	handler := NewHandler(fs, env, logger)
 
 	// Act
-	context, err := handler.buildSessionContext(sessionPath, nil, "")
+	context, err := buildSessionContext(t, handler, sessionPath, nil, "")
 
 	// Assert
 	require.NoError(t, err)
@@ -579,7 +592,7 @@ func TestBuildSessionContext_WithIndexMdHint(t *testing.T) {
 	handler := NewHandler(fs, env, logger)
 
 	// Act
-	context, err := handler.buildSessionContext(sessionPath, nil, projectRoot)
+	context, err := buildSessionContext(t, handler, sessionPath, nil, projectRoot)
 
 	// Assert
 	require.NoError(t, err)
@@ -609,7 +622,7 @@ func TestBuildSessionContext_NoIndexMdHint(t *testing.T) {
 	handler := NewHandler(fs, env, logger)
 
 	// Act
-	context, err := handler.buildSessionContext(sessionPath, nil, projectRoot)
+	context, err := buildSessionContext(t, handler, sessionPath, nil, projectRoot)
 
 	// Assert
 	require.NoError(t, err)
@@ -617,65 +630,28 @@ func TestBuildSessionContext_NoIndexMdHint(t *testing.T) {
 	assert.NotContains(t, context, "index.md files")
 }
 
-func TestHasIndexMdFiles_Found(t *testing.T) {
+func TestBuildSessionContext_ConfiguredDocNames(t *testing.T) {
 	// Arrange
 	fs := afero.NewMemMapFs()
 	env := shared.NewMockEnv()
+	env.Set("CLAUDEX_DOC_FILENAMES", "AGENTS.md:README.md")
 
 	projectRoot := "/workspace/project"
-	err := fs.MkdirAll(projectRoot+"/src/internal/hooks", 0755)
+	err := fs.MkdirAll(projectRoot+"/src", 0755)
 	require.NoError(t, err)
-
-	// Create nested index.md file
-	afero.WriteFile(fs, projectRoot+"/src/internal/hooks/index.md", []byte("content"), 0644)
-	afero.WriteFile(fs, projectRoot+"/src/main.go", []byte("code"), 0644)
-
-	logger := shared.NewLogger(fs, env, "test")
-	handler := NewHandler(fs, env, logger)
-
-	// Act
-	found := handler.hasIndexMdFiles(projectRoot)
-
-	// Assert
-	assert.True(t, found)
-}
-
-func TestHasIndexMdFiles_NotFound(t *testing.T) {
-	// Arrange
-	fs := afero.NewMemMapFs()
-	env := shared.NewMockEnv()
-
-	projectRoot := "/workspace/project"
-	err := fs.MkdirAll(projectRoot+"/src/internal", 0755)
+	err = fs.MkdirAll("/workspace/.claudex/sessions/s1", 0755)
 	require.NoError(t, err)
-
-	// Create files but NO index.md
-	afero.WriteFile(fs, projectRoot+"/src/main.go", []byte("code"), 0644)
-	afero.WriteFile(fs, projectRoot+"/README.md", []byte("readme"), 0644)
-
-	logger := shared.NewLogger(fs, env, "test")
-	handler := NewHandler(fs, env, logger)
-
-	// Act
-	found := handler.hasIndexMdFiles(projectRoot)
-
-	// Assert
-	assert.False(t, found)
-}
-
-func TestHasIndexMdFiles_EmptyProjectRoot(t *testing.T) {
-	// Arrange
-	fs := afero.NewMemMapFs()
-	env := shared.NewMockEnv()
+	afero.WriteFile(fs, projectRoot+"/src/README.md", []byte("readme"), 0644)
 
 	logger := shared.NewLogger(fs, env, "test")
 	handler := NewHandler(fs, env, logger)
 
 	// Act
-	found := handler.hasIndexMdFiles("")
+	context, err := buildSessionContext(t, handler, "/workspace/.claudex/sessions/s1", nil, projectRoot)
+	require.NoError(t, err)
 
 	// Assert
-	assert.False(t, found, "Empty project root should return false for graceful degradation")
+	assert.Contains(t, context, "Doc files in this project are named: AGENTS.md, README.md")
 }
 
 func TestHandler_ExploreAgent_InjectsMCPLSPContext(t *testing.T) {
@@ -1224,3 +1200,355 @@ func TestHandler_PlanAgent_NoSessionContext(t *testing.T) {
 	// Should contain Plan-specific context
 	assert.Contains(t, modifiedPrompt, "## PLAN AGENT ENHANCEMENTS")
 }
+
+func TestHandler_PolicyDecisions(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	env := shared.NewMockEnv()
+	handler := NewHandler(fs, env, shared.NewLogger(fs, env, "test"))
+	require.NoError(t, afero.WriteFile(fs, "/repo/.claudex/policy.toml", []byte(`
+[[rule]]
+name = "ask-push"
+tools = ["Bash"]
+command = '^git push'
+decision = "ask"
+reason = "Pushing publishes commits"
+
+[[rule]]
+name = "no-env"
+tools = ["Write"]
+paths = [".env"]
+decision = "deny"
+
+[[rule]]
+name = "tests"
+tools = ["Bash"]
+command = '^go test'
+decision = "allow"
+`), 0644))
+
+	bash := func(command string) *shared.PreToolUseInput {
+		return &shared.PreToolUseInput{
+			HookInput: shared.HookInput{SessionID: "s1", CWD: "/repo"},
+			ToolName:  "Bash",
+			ToolInput: map[string]interface{}{"command": command},
+		}
+	}
+
+	output, err := handler.Handle(bash("git push origin main"))
+	require.NoError(t, err)
+	assert.Equal(t, "ask", output.HookSpecificOutput.PermissionDecision)
+	assert.Equal(t, `Pushing publishes commits (claudex policy rule "ask-push" in /repo/.claudex/policy.toml)`, output.HookSpecificOutput.PermissionDecisionReason)
+
+	output, err = handler.Handle(&shared.PreToolUseInput{
+		HookInput: shared.HookInput{SessionID: "s1", CWD: "/repo"},
+		ToolName:  "Write",
+		ToolInput: map[string]interface{}{"file_path": "/repo/.env"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "deny", output.HookSpecificOutput.PermissionDecision)
+
+	// Only a matching allow rule skips Claude Code's own permission prompt
+	output, err = handler.Handle(bash("go test ./..."))
+	require.NoError(t, err)
+	assert.Equal(t, "allow", output.HookSpecificOutput.PermissionDecision)
+
+	output, err = handler.Handle(bash("git status"))
+	require.NoError(t, err)
+	assert.Empty(t, output.HookSpecificOutput.PermissionDecision)
+
+	// claudex's own doc-update runs are exempt
+	env.Set("CLAUDE_HOOK_INTERNAL", "1")
+	output, err = handler.Handle(bash("git push"))
+	require.NoError(t, err)
+	assert.Empty(t, output.HookSpecificOutput.PermissionDecision)
+	output, err = handler.Handle(bash("go test ./..."))
+	require.NoError(t, err)
+	assert.Empty(t, output.HookSpecificOutput.PermissionDecision)
+}
+
+func TestHandler_PolicyFromSubdirectory(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	env := shared.NewMockEnv()
+	handler := NewHandler(fs, env, shared.NewLogger(fs, env, "test"))
+	require.NoError(t, fs.MkdirAll("/repo/src", 0755))
+	require.NoError(t, afero.WriteFile(fs, "/repo/.claudex/policy.toml", []byte(`
+[[rule]]
+name = "no-env"
+tools = ["Write"]
+paths = [".env"]
+decision = "deny"
+
+[[rule]]
+name = "stay-inside"
+tools = ["Write"]
+outside_project = true
+decision = "deny"
+`), 0644))
+
+	write := func(path string) *shared.PreToolUseInput {
+		return &shared.PreToolUseInput{
+			HookInput: shared.HookInput{SessionID: "s1", CWD: "/repo/src"},
+			ToolName:  "Write",
+			ToolInput: map[string]interface{}{"file_path": path},
+		}
+	}
+
+	// After `cd src` the project policy still applies, relative to /repo
+	output, err := handler.Handle(write("../.env"))
+	require.NoError(t, err)
+	assert.Equal(t, "deny", output.HookSpecificOutput.PermissionDecision)
+	assert.Contains(t, output.HookSpecificOutput.PermissionDecisionReason, `"no-env"`)
+
+	output, err = handler.Handle(write("/repo/docs/notes.md"))
+	require.NoError(t, err)
+	assert.Empty(t, output.HookSpecificOutput.PermissionDecision)
+
+	output, err = handler.Handle(write("/tmp/notes.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "deny", output.HookSpecificOutput.PermissionDecision)
+}
+
+func TestHandler_InvalidPolicyAsks(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	env := shared.NewMockEnv()
+	handler := NewHandler(fs, env, shared.NewLogger(fs, env, "test"))
+	require.NoError(t, afero.WriteFile(fs, "/repo/.claudex/policy.toml", []byte("[[rule]]\ndecision = \"never\"\n"), 0644))
+
+	output, err := handler.Handle(&shared.PreToolUseInput{
+		HookInput: shared.HookInput{SessionID: "s1", CWD: "/repo"},
+		ToolName:  "Read",
+		ToolInput: map[string]interface{}{"file_path": "/repo/main.go"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ask", output.HookSpecificOutput.PermissionDecision)
+	assert.Contains(t, output.HookSpecificOutput.PermissionDecisionReason, "could not be loaded")
+}
+
+func TestHandler_AsksBeforeOverwritingActiveSessionChanges(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	env := shared.NewMockEnv()
+	handler := NewHandler(fs, env, shared.NewLogger(fs, env, "test"))
+	mine := "/repo/.claudex/sessions/mine-s1"
+	other := "/repo/.claudex/sessions/other-s2"
+	require.NoError(t, fs.MkdirAll(mine, 0755))
+	require.NoError(t, fs.MkdirAll(other, 0755))
+	held, err := activity.Hold(fs, other, false)
+	require.NoError(t, err)
+	require.NoError(t, ledger.Append(fs, other, []ledger.Entry{
+		{Time: time.Now().Add(-5 * time.Minute), Tool: "Write", Path: "/repo/lock.go", AgentType: "Plan"},
+	}))
+
+	edit := func(tool, path string) *shared.PreToolUseInput {
+		return &shared.PreToolUseInput{
+			HookInput: shared.HookInput{SessionID: "s1", CWD: "/repo"},
+			ToolName:  tool,
+			ToolInput: map[string]interface{}{"file_path": path},
+		}
+	}
+
+	output, err := handler.Handle(edit("Edit", "/repo/lock.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "ask", output.HookSpecificOutput.PermissionDecision)
+	assert.Equal(t, "lock.go was changed 5m0s ago by active claudex session other-s2 (Write by Plan); "+
+		"review it with `claudex sessions files other-s2`. Confirm to overwrite.", output.HookSpecificOutput.PermissionDecisionReason)
+
+	// The same call after `cd src`
+	fromSrc := edit("Edit", "../lock.go")
+	fromSrc.CWD = "/repo/src"
+	output, err = handler.Handle(fromSrc)
+	require.NoError(t, err)
+	assert.Equal(t, "ask", output.HookSpecificOutput.PermissionDecision)
+	assert.Contains(t, output.HookSpecificOutput.PermissionDecisionReason, "lock.go was changed")
+
+	// Reads, and files the other session did not change, are allowed
+	output, err = handler.Handle(edit("Read", "/repo/lock.go"))
+	require.NoError(t, err)
+	assert.Empty(t, output.HookSpecificOutput.PermissionDecision)
+	output, err = handler.Handle(edit("Write", "/repo/main.go"))
+	require.NoError(t, err)
+	assert.Empty(t, output.HookSpecificOutput.PermissionDecision)
+
+	// A zero window disables the check
+	env.Set(activity.WindowEnvVar, "0")
+	output, err = handler.Handle(edit("Edit", "/repo/lock.go"))
+	require.NoError(t, err)
+	assert.Empty(t, output.HookSpecificOutput.PermissionDecision)
+
+	// Once the other session exits, its changes no longer conflict
+	env.Set(activity.WindowEnvVar, "")
+	require.NoError(t, held.Release())
+	output, err = handler.Handle(edit("Edit", "/repo/lock.go"))
+	require.NoError(t, err)
+	assert.Empty(t, output.HookSpecificOutput.PermissionDecision)
+}
+
+func TestHandler_CustomContextRule(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	env := shared.NewMockEnv()
+	sessionPath := "/workspace/.claudex/sessions/s1"
+	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
+	env.Set("CLAUDEX_SESSION_PATH", sessionPath)
+	require.NoError(t, afero.WriteFile(fs, "/workspace/.claudex/context.toml", []byte(`
+[[rule]]
+name = "reviewer"
+agents = ["code-reviewer"]
+fragments = ["review.md"]
+position = "append"
+
+[[rule]]
+name = "bare"
+agents = ["general-purpose"]
+`), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/workspace/.claudex/context/review.md", []byte("## REVIEW RULES\n\nWrite findings to {{.SessionPath}}\n"), 0644))
+	handler := NewHandler(fs, env, shared.NewLogger(fs, env, "test"))
+
+	task := func(agent string) *shared.PreToolUseInput {
+		return &shared.PreToolUseInput{
+			HookInput: shared.HookInput{SessionID: "s1", CWD: "/workspace"},
+			ToolName:  "Task",
+			ToolInput: map[string]interface{}{"prompt": "Review the diff", "subagent_type": agent},
+		}
+	}
+
+	output, err := handler.Handle(task("code-reviewer"))
+	require.NoError(t, err)
+	assert.Equal(t, "Review the diff\n\n---\n\n## REVIEW RULES\n\nWrite findings to "+sessionPath+"\n",
+		output.HookSpecificOutput.UpdatedInput["prompt"])
+
+	// A rule without fragments leaves the prompt alone
+	output, err = handler.Handle(task("general-purpose"))
+	require.NoError(t, err)
+	assert.Nil(t, output.HookSpecificOutput.UpdatedInput)
+
+	// Agents without a project rule still get the defaults
+	output, err = handler.Handle(task("Explore"))
+	require.NoError(t, err)
+	assert.Contains(t, output.HookSpecificOutput.UpdatedInput["prompt"], "## EXPLORE AGENT ENHANCEMENTS")
+
+	// The project's rules still apply after the agent changed into a subdirectory
+	fromSubdir := task("code-reviewer")
+	fromSubdir.CWD = "/workspace/internal/lock"
+	output, err = handler.Handle(fromSubdir)
+	require.NoError(t, err)
+	assert.Contains(t, output.HookSpecificOutput.UpdatedInput["prompt"], "## REVIEW RULES")
+}
+
+func TestHandler_InvalidContextRulesUseDefaults(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	env := shared.NewMockEnv()
+	sessionPath := "/workspace/.claudex/sessions/s1"
+	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
+	env.Set("CLAUDEX_SESSION_PATH", sessionPath)
+	require.NoError(t, afero.WriteFile(fs, "/workspace/.claudex/context.toml", []byte("[[rule]]\nname = \"no agents\"\n"), 0644))
+	handler := NewHandler(fs, env, shared.NewLogger(fs, env, "test"))
+
+	output, err := handler.Handle(&shared.PreToolUseInput{
+		HookInput: shared.HookInput{SessionID: "s1", CWD: "/workspace"},
+		ToolName:  "Task",
+		ToolInput: map[string]interface{}{"prompt": "Do it", "subagent_type": "general-purpose"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, output.HookSpecificOutput.UpdatedInput["prompt"], "## SESSION CONTEXT (CRITICAL)")
+}
+
+func TestHandler_ContextBudgetTrimsAndLogs(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	env := shared.NewMockEnv()
+	sessionPath := "/workspace/.claudex/sessions/s1"
+	for i := 0; i < 300; i++ {
+		require.NoError(t, afero.WriteFile(fs, fmt.Sprintf("%s/research-%03d.md", sessionPath, i), []byte("x"), 0644))
+	}
+	env.Set("CLAUDEX_SESSION_PATH", sessionPath)
+	env.Set("CLAUDEX_LOG_FILE", "/logs/hooks.log")
+	require.NoError(t, afero.WriteFile(fs, "/workspace/.claudex/context.toml", []byte(`
+[[rule]]
+name = "session"
+agents = ["*"]
+fragments = ["session.md"]
+max_tokens = 1000
+`), 0644))
+	handler := NewHandler(fs, env, shared.NewLogger(fs, env, "test"))
+
+	output, err := handler.Handle(&shared.PreToolUseInput{
+		HookInput: shared.HookInput{SessionID: "s1", CWD: "/workspace"},
+		ToolName:  "Task",
+		ToolInput: map[string]interface{}{"prompt": "Do it", "subagent_type": "general-purpose"},
+	})
+	require.NoError(t, err)
+
+	prompt := output.HookSpecificOutput.UpdatedInput["prompt"].(string)
+	assert.Contains(t, prompt, "- research-000.md")
+	assert.NotContains(t, prompt, "- research-299.md")
+	assert.Contains(t, prompt, "more, see "+sessionPath)
+	assert.Contains(t, prompt, "### ACTIVATION PROCEDURE")
+
+	log, err := afero.ReadFile(fs, "/logs/hooks.log")
+	require.NoError(t, err)
+	assert.Contains(t, string(log), `Context budget of rule "session" (1000 tokens): trimmed session files`)
+}
+
+func TestHandler_InjectsRelevantDocs(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	env := shared.NewMockEnv()
+	sessionPath := "/workspace/.claudex/sessions/s1"
+	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
+	env.Set("CLAUDEX_SESSION_PATH", sessionPath)
+	docs := map[string]string{
+		"/workspace/index.md":               "# Workspace\n\nTop-level project docs.\n",
+		"/workspace/internal/lock/index.md": "# Lock\n\nCross-process file locks with stale lock recovery.\n",
+		"/workspace/internal/ui/index.md":   "# UI\n\nTerminal rendering with Bubble Tea.\n",
+	}
+	for path, content := range docs {
+		require.NoError(t, afero.WriteFile(fs, path, []byte(content), 0644))
+	}
+	handler := NewHandler(fs, env, shared.NewLogger(fs, env, "test"))
+
+	task := func(agent, prompt string) string {
+		output, err := handler.Handle(&shared.PreToolUseInput{
+			HookInput: shared.HookInput{SessionID: "s1", CWD: "/workspace"},
+			ToolName:  "Task",
+			ToolInput: map[string]interface{}{"prompt": prompt, "description": "Investigate", "subagent_type": agent},
+		})
+		require.NoError(t, err)
+		return output.HookSpecificOutput.UpdatedInput["prompt"].(string)
+	}
+
+	prompt := task("Explore", "Why do stale locks survive a crash?")
+	assert.Contains(t, prompt, "### Relevant Project Docs")
+	assert.Contains(t, prompt, "- /workspace/internal/lock/index.md - Cross-process file locks with stale lock recovery.\n")
+	assert.NotContains(t, prompt, "internal/ui/index.md")
+
+	prompt = task("general-purpose", "Restyle the terminal rendering")
+	assert.Contains(t, prompt, "**Docs Relevant to This Task**")
+	assert.Contains(t, prompt, "- /workspace/internal/ui/index.md - Terminal rendering with Bubble Tea.\n")
+
+	// Nothing matches: no section at all
+	prompt = task("Plan", "Add Kubernetes manifests")
+	assert.NotContains(t, prompt, "### Relevant Project Docs")
+
+	// The map is cached for the next Task
+	exists, err := afero.Exists(fs, "/workspace/.claudex/docmap.json")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// A Task started after `cd internal/lock` still searches the whole project
+	output, err := handler.Handle(&shared.PreToolUseInput{
+		HookInput: shared.HookInput{SessionID: "s1", CWD: "/workspace/internal/lock"},
+		ToolName:  "Task",
+		ToolInput: map[string]interface{}{"prompt": "Restyle the terminal rendering", "description": "Investigate", "subagent_type": "Explore"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, output.HookSpecificOutput.UpdatedInput["prompt"], "- /workspace/internal/ui/index.md - Terminal rendering with Bubble Tea.\n")
+	exists, err = afero.Exists(fs, "/workspace/internal/lock/.claudex")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+// buildSessionContext renders the default session rule, as injected for
+// general-purpose agents
+func buildSessionContext(t *testing.T, handler *Handler, sessionPath string, docPaths []string, projectRoot string) (string, error) {
+	t.Helper()
+	rules, err := agentcontext.Defaults(handler.fs, claudex.Profiles)
+	require.NoError(t, err)
+	return handler.buildContext(rules, rules.Match("general-purpose"), sessionPath, docPaths, projectRoot, "")
+}
@@ -0,0 +1,145 @@
+package sessionstart
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"claudex/internal/services/docfiles"
+	"claudex/internal/services/session"
+
+	"github.com/spf13/afero"
+)
+
+// OverviewFile is the session overview maintained by the doc updater
+const OverviewFile = "session-overview.md"
+
+// MaxOverviewChars bounds the overview injected verbatim; larger overviews
+// are summarized to their headings and the first line under each
+const MaxOverviewChars = 6000
+
+// sourceNotes explains why the context is injected, by SessionStart source
+var sourceNotes = map[string]string{
+	"startup": "This session was started by claudex.",
+	"resume":  "This session was resumed; earlier work is recorded in the session folder.",
+	"clear":   "The conversation was cleared; earlier work is recorded in the session folder.",
+	"compact": "The conversation was compacted; details of earlier work are recorded in the session folder.",
+}
+
+// buildContext renders the session context for the main agent
+func (h *Handler) buildContext(sessionPath, source, cwd string) (string, error) {
+	var sb strings.Builder
+
+	sb.WriteString("## CLAUDEX SESSION CONTEXT\n\n")
+	if note, ok := sourceNotes[source]; ok {
+		sb.WriteString(note + " ")
+	}
+	sb.WriteString("ALL documentation, plans, and artifacts MUST be created in the session folder.\n\n")
+	sb.WriteString(fmt.Sprintf("**Session Folder (Absolute Path)**: `%s`\n\n", sessionPath))
+
+	overviewPath := filepath.Join(sessionPath, OverviewFile)
+	overview, err := afero.ReadFile(h.fs, overviewPath)
+	if err == nil {
+		if strings.TrimSpace(string(overview)) != "" {
+			summary, truncated := summarize(string(overview), MaxOverviewChars)
+			sb.WriteString("### Session Overview\n\n")
+			if truncated {
+				sb.WriteString(fmt.Sprintf("(Summary of `%s`; read the full file before relying on details.)\n\n", overviewPath))
+			}
+			sb.WriteString(strings.TrimSpace(summary) + "\n\n")
+		}
+	} else {
+		// No overview yet: point at whatever the session folder holds
+		files, err := h.listSessionFiles(sessionPath)
+		if err != nil {
+			return "", err
+		}
+		if len(files) > 0 {
+			sb.WriteString("### Session Folder Contents\n\n")
+			for _, file := range files {
+				sb.WriteString(fmt.Sprintf("- %s\n", file))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if entries := h.docEntryPoints(cwd); len(entries) > 0 {
+		sb.WriteString("### Documentation Entry Points\n\n")
+		for _, entry := range entries {
+			sb.WriteString(fmt.Sprintf("- %s\n", entry))
+		}
+		sb.WriteString("\nLoad only the docs related to the task at hand, following links from these entry points.\n")
+	}
+
+	return strings.TrimRight(sb.String(), "\n") + "\n", nil
+}
+
+// docEntryPoints returns the root docs passed by claudex (CLAUDEX_DOC_PATHS),
+// or the project's top-level doc file when none were passed
+func (h *Handler) docEntryPoints(cwd string) []string {
+	var entries []string
+	for _, path := range strings.Split(h.env.Get("CLAUDEX_DOC_PATHS"), ":") {
+		if path != "" {
+			entries = append(entries, path)
+		}
+	}
+	if len(entries) == 0 && cwd != "" {
+		if doc := docfiles.FromEnv(h.env.Get(docfiles.EnvVar)).Find(h.fs, cwd); doc != "" {
+			entries = append(entries, doc)
+		}
+	}
+	return entries
+}
+
+// listSessionFiles returns the file names in the session folder
+func (h *Handler) listSessionFiles(sessionPath string) ([]string, error) {
+	entries, err := afero.ReadDir(h.fs, sessionPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session directory: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && !session.IsBookkeeping(entry.Name()) {
+			files = append(files, entry.Name())
+		}
+	}
+	return files, nil
+}
+
+// summarize returns markdown unchanged when it fits in limit. Otherwise it
+// keeps every heading and the first non-empty line of each section, which
+// preserves the structure of the overview, and cuts at limit.
+func summarize(markdown string, limit int) (string, bool) {
+	if len(markdown) <= limit {
+		return markdown, false
+	}
+
+	var sb strings.Builder
+	wantLine := true // the first line of the document counts as a section start
+	inFence := false
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			continue
+		}
+		var keep string
+		switch {
+		case inFence || trimmed == "":
+			continue
+		case strings.HasPrefix(trimmed, "#"):
+			keep = "\n" + trimmed
+			wantLine = true
+		case wantLine:
+			keep = line
+			wantLine = false
+		default:
+			continue
+		}
+		if sb.Len()+len(keep)+1 > limit {
+			break
+		}
+		sb.WriteString(keep + "\n")
+	}
+	return strings.TrimSpace(sb.String()) + "\n\n[...]\n", true
+}
@@ -0,0 +1,65 @@
+// Package sessionstart handles the SessionStart hook, fired when Claude Code
+// starts, resumes, clears or compacts a session. It re-injects the claudex
+// session context so the main agent keeps its pointer to the session folder.
+package sessionstart
+
+import (
+	"fmt"
+
+	"claudex/internal/hooks/shared"
+	"claudex/internal/services/env"
+	"claudex/internal/services/session"
+
+	"github.com/spf13/afero"
+)
+
+// Handler processes SessionStart events
+type Handler struct {
+	fs     afero.Fs
+	env    env.Environment
+	logger *shared.Logger
+}
+
+// NewHandler creates a new Handler instance
+func NewHandler(fs afero.Fs, env env.Environment, logger *shared.Logger) *Handler {
+	return &Handler{
+		fs:     fs,
+		env:    env,
+		logger: logger,
+	}
+}
+
+// Handle returns the session context as additionalContext: the session
+// folder, its overview (summarized when large) and the doc entry points.
+// Sessions without a claudex session folder get no context.
+func (h *Handler) Handle(input *shared.SessionStartInput) (*shared.HookOutput, error) {
+	output := &shared.HookOutput{
+		HookSpecificOutput: shared.HookSpecificOutput{
+			HookEventName: "SessionStart",
+		},
+	}
+
+	// Internal Claude invocations (e.g. the doc-update subprocess) get no context
+	if h.env.Get("CLAUDE_HOOK_INTERNAL") == "1" {
+		return output, nil
+	}
+
+	_ = h.logger.LogInfo(fmt.Sprintf("Session starting (source: %s)", input.Source))
+
+	sessionPath, err := session.FindSessionFolderWithCwd(h.fs, h.env, input.SessionID, input.CWD)
+	if err != nil {
+		_ = h.logger.LogDebug(fmt.Sprintf("No claudex session folder, skipping context: %v", err))
+		return output, nil
+	}
+
+	sessionContext, err := h.buildContext(sessionPath, input.Source, input.CWD)
+	if err != nil {
+		// Never fail the session start over missing context
+		_ = h.logger.LogError(fmt.Errorf("failed to build session context: %w", err))
+		return output, nil
+	}
+
+	output.HookSpecificOutput.AdditionalContext = sessionContext
+	_ = h.logger.LogInfo(fmt.Sprintf("Injected session context for %s (%d chars)", sessionPath, len(sessionContext)))
+	return output, nil
+}
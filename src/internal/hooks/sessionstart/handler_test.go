@@ -0,0 +1,106 @@
+package sessionstart
+
+import (
+	"strings"
+	"testing"
+
+	"claudex/internal/hooks/shared"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sessionPath = "/project/.claudex/sessions/feature-abc-123"
+
+func newHandler(t *testing.T, files map[string]string) (*Handler, *shared.MockEnv) {
+	t.Helper()
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(fs, path, []byte(content), 0644))
+	}
+	env := shared.NewMockEnv()
+	return NewHandler(fs, env, shared.NewLogger(fs, env, "test")), env
+}
+
+func TestHandle_InjectsOverviewAndEntryPoints(t *testing.T) {
+	handler, env := newHandler(t, map[string]string{
+		sessionPath + "/session-overview.md": "# Feature ABC\n\nAdding retries to the client.\n",
+		"/project/index.md":                  "# Project\n",
+	})
+
+	output, err := handler.Handle(&shared.SessionStartInput{
+		HookInput: shared.HookInput{SessionID: "abc-123", CWD: "/project"},
+		Source:    "compact",
+	})
+	require.NoError(t, err)
+
+	ctx := output.HookSpecificOutput.AdditionalContext
+	assert.Equal(t, "SessionStart", output.HookSpecificOutput.HookEventName)
+	assert.Contains(t, ctx, "The conversation was compacted")
+	assert.Contains(t, ctx, "**Session Folder (Absolute Path)**: `"+sessionPath+"`")
+	assert.Contains(t, ctx, "### Session Overview\n\n# Feature ABC\n\nAdding retries to the client.\n")
+	assert.Contains(t, ctx, "### Documentation Entry Points\n\n- /project/index.md\n")
+
+	// Doc paths passed by claudex take precedence over the project doc
+	env.Set("CLAUDEX_DOC_PATHS", "/project/docs/a.md:/project/docs/b.md")
+	output, err = handler.Handle(&shared.SessionStartInput{
+		HookInput: shared.HookInput{SessionID: "abc-123", CWD: "/project"},
+		Source:    "resume",
+	})
+	require.NoError(t, err)
+	ctx = output.HookSpecificOutput.AdditionalContext
+	assert.Contains(t, ctx, "- /project/docs/a.md\n- /project/docs/b.md\n")
+	assert.NotContains(t, ctx, "/project/index.md")
+}
+
+func TestHandle_ListsFilesWithoutOverview(t *testing.T) {
+	handler, _ := newHandler(t, map[string]string{
+		sessionPath + "/plan.md":       "plan",
+		sessionPath + "/.counter":      "3",
+		sessionPath + "/notes.txt":     "notes",
+		sessionPath + "/session.lock":  "42\n",
+		sessionPath + "/changes.jsonl": "{}\n",
+	})
+
+	output, err := handler.Handle(&shared.SessionStartInput{
+		HookInput: shared.HookInput{SessionID: "abc-123", CWD: "/project"},
+		Source:    "startup",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, output.HookSpecificOutput.AdditionalContext, "### Session Folder Contents\n\n- notes.txt\n- plan.md\n")
+}
+
+func TestHandle_NoContextOutsideSessionsOrForInternalRuns(t *testing.T) {
+	handler, env := newHandler(t, nil)
+
+	output, err := handler.Handle(&shared.SessionStartInput{
+		HookInput: shared.HookInput{SessionID: "unknown", CWD: "/project"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, output.HookSpecificOutput.AdditionalContext)
+
+	env.Set("CLAUDE_HOOK_INTERNAL", "1")
+	output, err = handler.Handle(&shared.SessionStartInput{
+		HookInput: shared.HookInput{SessionID: "abc-123", CWD: "/project"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, output.HookSpecificOutput.AdditionalContext)
+}
+
+func TestSummarize_KeepsHeadingsAndFirstLines(t *testing.T) {
+	long := strings.Repeat("detail line\n", 50)
+	overview := "# Session\n\nGoal: ship retries.\n" + long +
+		"\n## Decisions\n\nUse exponential backoff.\n" + long +
+		"```go\n// code is dropped\n```\n" +
+		"\n## Next Steps\n\nAdd metrics.\n" + long
+
+	got, truncated := summarize(overview, 400)
+	require.True(t, truncated)
+	assert.Equal(t, "# Session\nGoal: ship retries.\n\n## Decisions\nUse exponential backoff.\n\n## Next Steps\nAdd metrics.\n\n[...]\n", got)
+
+	short, truncated := summarize("# Small\n", 400)
+	assert.False(t, truncated)
+	assert.Equal(t, "# Small\n", short)
+}
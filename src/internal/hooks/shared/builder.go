@@ -73,6 +73,17 @@ func (b *Builder) BuildEmpty(hookEventName string) error {
 	return b.write(output)
 }
 
+// BuildWithContext builds a response that adds text to Claude's context
+func (b *Builder) BuildWithContext(hookEventName, additionalContext string) error {
+	output := HookOutput{
+		HookSpecificOutput: HookSpecificOutput{
+			HookEventName:     hookEventName,
+			AdditionalContext: additionalContext,
+		},
+	}
+	return b.write(output)
+}
+
 // BuildCustom builds a response with custom hook-specific output
 func (b *Builder) BuildCustom(output HookOutput) error {
 	return b.write(output)
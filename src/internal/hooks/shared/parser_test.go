@@ -2,6 +2,7 @@ package shared
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -193,3 +194,40 @@ func TestPostToolUseInput_ToolResponseMarshalRoundtrip(t *testing.T) {
 		})
 	}
 }
+
+// TestParseLifecycleEvents verifies the SessionStart, UserPromptSubmit, Stop and PreCompact parsers
+func TestParseLifecycleEvents(t *testing.T) {
+	sessionStart, err := NewParser(strings.NewReader(`{"session_id": "s1", "hook_event_name": "SessionStart", "source": "resume"}`)).ParseSessionStart()
+	require.NoError(t, err)
+	assert.Equal(t, "resume", sessionStart.Source)
+
+	prompt, err := NewParser(strings.NewReader(`{"session_id": "s1", "hook_event_name": "UserPromptSubmit", "prompt": "fix the build"}`)).ParseUserPromptSubmit()
+	require.NoError(t, err)
+	assert.Equal(t, "fix the build", prompt.Prompt)
+
+	stop, err := NewParser(strings.NewReader(`{"session_id": "s1", "hook_event_name": "Stop", "stop_hook_active": true}`)).ParseStop()
+	require.NoError(t, err)
+	assert.True(t, stop.StopHookActive)
+
+	preCompact, err := NewParser(strings.NewReader(`{"session_id": "s1", "hook_event_name": "PreCompact", "trigger": "auto", "custom_instructions": ""}`)).ParsePreCompact()
+	require.NoError(t, err)
+	assert.Equal(t, "auto", preCompact.Trigger)
+
+	// session_id is required for every event
+	_, err = NewParser(strings.NewReader(`{"hook_event_name": "Stop"}`)).ParseStop()
+	assert.Error(t, err)
+	_, err = NewParser(strings.NewReader(`not json`)).ParsePreCompact()
+	assert.Error(t, err)
+}
+
+// TestBuildWithContext verifies additionalContext is emitted for context-injecting events
+func TestBuildWithContext(t *testing.T) {
+	var buf strings.Builder
+	require.NoError(t, NewBuilder(&buf).BuildWithContext("SessionStart", "hello"))
+
+	var output HookOutput
+	require.NoError(t, json.Unmarshal([]byte(buf.String()), &output))
+	assert.Equal(t, "SessionStart", output.HookSpecificOutput.HookEventName)
+	assert.Equal(t, "hello", output.HookSpecificOutput.AdditionalContext)
+	assert.NotContains(t, buf.String(), "permissionDecision")
+}
@@ -18,6 +18,7 @@ type PreToolUseInput struct {
 	ToolInput map[string]interface{} `json:"tool_input"`
 	ToolUseID string                 `json:"tool_use_id"`
 	AgentID   string                 `json:"agent_id,omitempty"`
+	AgentType string                 `json:"agent_type,omitempty"`
 }
 
 // PostToolUseInput extends HookInput for PostToolUse events
@@ -29,6 +30,7 @@ type PostToolUseInput struct {
 	ToolUseID    string                 `json:"tool_use_id"`
 	Status       string                 `json:"status"`
 	AgentID      string                 `json:"agent_id,omitempty"`
+	AgentType    string                 `json:"agent_type,omitempty"`
 }
 
 // NotificationInput represents input for notification hook
@@ -52,6 +54,35 @@ type SubagentStopInput struct {
 	CompletionReason    string `json:"completion_reason,omitempty"`
 }
 
+// SessionStartInput extends HookInput for SessionStart events
+type SessionStartInput struct {
+	HookInput
+	// Source is "startup", "resume", "clear" or "compact"
+	Source string `json:"source,omitempty"`
+}
+
+// UserPromptSubmitInput extends HookInput for UserPromptSubmit events
+type UserPromptSubmitInput struct {
+	HookInput
+	Prompt string `json:"prompt"`
+}
+
+// StopInput extends HookInput for Stop events
+type StopInput struct {
+	HookInput
+	// StopHookActive is true when Claude is already continuing because of a
+	// Stop hook; blocking again would loop
+	StopHookActive bool `json:"stop_hook_active"`
+}
+
+// PreCompactInput extends HookInput for PreCompact events
+type PreCompactInput struct {
+	HookInput
+	// Trigger is "manual" (/compact) or "auto" (context window full)
+	Trigger            string `json:"trigger"`
+	CustomInstructions string `json:"custom_instructions,omitempty"`
+}
+
 // DocUpdateInput represents input for the doc-update command
 // This is used to pass configuration to the detached subprocess
 type DocUpdateInput struct {
@@ -75,4 +106,6 @@ type HookSpecificOutput struct {
 	PermissionDecision       string                 `json:"permissionDecision,omitempty"`
 	PermissionDecisionReason string                 `json:"permissionDecisionReason,omitempty"`
 	UpdatedInput             map[string]interface{} `json:"updatedInput,omitempty"`
+	// AdditionalContext is added to Claude's context (SessionStart, UserPromptSubmit)
+	AdditionalContext string `json:"additionalContext,omitempty"`
 }
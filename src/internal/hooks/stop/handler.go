@@ -0,0 +1,39 @@
+// Package stop handles the Stop hook, fired when the main agent finishes
+// responding.
+package stop
+
+import (
+	"claudex/internal/hooks/shared"
+	"claudex/internal/services/env"
+
+	"github.com/spf13/afero"
+)
+
+// Handler processes Stop events
+type Handler struct {
+	fs     afero.Fs
+	env    env.Environment
+	logger *shared.Logger
+}
+
+// NewHandler creates a new Handler instance
+func NewHandler(fs afero.Fs, env env.Environment, logger *shared.Logger) *Handler {
+	return &Handler{
+		fs:     fs,
+		env:    env,
+		logger: logger,
+	}
+}
+
+// Handle logs the end of the turn. It never blocks Claude from stopping, so
+// no JSON output is needed.
+func (h *Handler) Handle(input *shared.StopInput) error {
+	if input.StopHookActive {
+		// Claude is already continuing because of a Stop hook
+		_ = h.logger.LogDebug("Stop hook already active, skipping")
+		return nil
+	}
+
+	_ = h.logger.LogInfo("Agent turn finished")
+	return nil
+}
@@ -0,0 +1,40 @@
+// Package userpromptsubmit handles the UserPromptSubmit hook, fired before
+// Claude processes a prompt the user submitted.
+package userpromptsubmit
+
+import (
+	"fmt"
+
+	"claudex/internal/hooks/shared"
+	"claudex/internal/services/env"
+
+	"github.com/spf13/afero"
+)
+
+// Handler processes UserPromptSubmit events
+type Handler struct {
+	fs     afero.Fs
+	env    env.Environment
+	logger *shared.Logger
+}
+
+// NewHandler creates a new Handler instance
+func NewHandler(fs afero.Fs, env env.Environment, logger *shared.Logger) *Handler {
+	return &Handler{
+		fs:     fs,
+		env:    env,
+		logger: logger,
+	}
+}
+
+// Handle logs the prompt and returns the context to add alongside it, which
+// is empty when there is nothing to inject. The prompt is never blocked.
+func (h *Handler) Handle(input *shared.UserPromptSubmitInput) (*shared.HookOutput, error) {
+	_ = h.logger.LogInfo(fmt.Sprintf("Prompt submitted (%d chars)", len(input.Prompt)))
+
+	return &shared.HookOutput{
+		HookSpecificOutput: shared.HookSpecificOutput{
+			HookEventName: "UserPromptSubmit",
+		},
+	}, nil
+}
@@ -0,0 +1,157 @@
+// Package activity tracks the sessions active in a checkout and the files
+// they recently changed, so that a session can be warned before it
+// overwrites another one's work. A session is active while the claudex
+// process that launched Claude holds the session's session.lock; its recent
+// changes come from the session's change ledger.
+package activity
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+
+	"claudex/internal/services/env"
+	"claudex/internal/services/ledger"
+	"claudex/internal/services/lock"
+
+	"github.com/spf13/afero"
+)
+
+// LockFileName is the session lock, in the session folder
+const LockFileName = "session.lock"
+
+// DefaultWindow is how long a change by another active session counts as
+// recent
+const DefaultWindow = 30 * time.Minute
+
+// WindowEnvVar passes the conflict window to the hooks, as a Go duration;
+// "0" disables conflict warnings
+const WindowEnvVar = "CLAUDEX_CONFLICT_WINDOW"
+
+// Conflict is a recent change to a file by another active session
+type Conflict struct {
+	// Session is the other session's folder name
+	Session string
+
+	// Change is that session's latest change to the file
+	Change ledger.Entry
+}
+
+// LockPath returns the session lock path of a session
+func LockPath(sessionPath string) string {
+	return filepath.Join(sessionPath, LockFileName)
+}
+
+// lockService never expires session locks by age: a session lasts as long
+// as its Claude run, which can be days
+func lockService(fs afero.Fs, flock bool) lock.LockService {
+	return lock.NewWithOptions(fs, lock.Options{TTL: -1, Flock: flock})
+}
+
+// Hold marks a session active by taking its lock; release it when Claude
+// exits. It fails when another live process already holds the session.
+func Hold(fs afero.Fs, sessionPath string, flock bool) (*lock.Lock, error) {
+	return lockService(fs, flock).Acquire(LockPath(sessionPath))
+}
+
+// Holder inspects the lock of a session, returning nil when none is held
+func Holder(fs afero.Fs, sessionPath string) (*lock.Info, error) {
+	return lockService(fs, false).Inspect(LockPath(sessionPath))
+}
+
+// IsActive reports whether a live process holds the session's lock
+func IsActive(fs afero.Fs, sessionPath string) bool {
+	info, err := Holder(fs, sessionPath)
+	return err == nil && info != nil && !info.Stale
+}
+
+// Window returns the conflict window set in the environment, DefaultWindow
+// when it is unset, and zero when it is "0" (disabled). An invalid value
+// returns DefaultWindow and ok false.
+func Window(environ env.Environment) (window time.Duration, ok bool) {
+	value := environ.Get(WindowEnvVar)
+	if value == "" {
+		return DefaultWindow, true
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return DefaultWindow, false
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
+
+// Conflicts returns the targets that another active session under
+// sessionsDir changed within window before now, and more recently than the
+// own session did, one conflict per target. ownSessionPath may be empty.
+func Conflicts(fs afero.Fs, sessionsDir, ownSessionPath string, targets []string, window time.Duration, now time.Time) []Conflict {
+	if len(targets) == 0 || window <= 0 {
+		return nil
+	}
+	since := now.Add(-window)
+
+	wanted := make(map[string]bool)
+	for _, t := range targets {
+		wanted[t] = true
+	}
+
+	dirs, err := afero.ReadDir(fs, sessionsDir)
+	if err != nil {
+		return nil
+	}
+
+	latest := make(map[string]Conflict)
+	for _, dir := range dirs {
+		sessionPath := filepath.Join(sessionsDir, dir.Name())
+		if !dir.IsDir() || sessionPath == ownSessionPath || !IsActive(fs, sessionPath) {
+			continue
+		}
+		for path, change := range lastChanges(fs, sessionPath, wanted) {
+			if change.Time.Before(since) {
+				continue
+			}
+			if c, ok := latest[path]; !ok || change.Time.After(c.Change.Time) {
+				latest[path] = Conflict{Session: dir.Name(), Change: change}
+			}
+		}
+	}
+	if len(latest) == 0 {
+		return nil
+	}
+
+	// Changes the own session made afterwards were already made knowingly
+	var own map[string]ledger.Entry
+	if ownSessionPath != "" {
+		own = lastChanges(fs, ownSessionPath, wanted)
+	}
+
+	var conflicts []Conflict
+	for path, c := range latest {
+		if mine, ok := own[path]; ok && !mine.Time.Before(c.Change.Time) {
+			continue
+		}
+		conflicts = append(conflicts, c)
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Change.Path < conflicts[j].Change.Path })
+	return conflicts
+}
+
+// lastChanges returns a session's latest ledger entry for each wanted path
+func lastChanges(fs afero.Fs, sessionPath string, wanted map[string]bool) map[string]ledger.Entry {
+	entries, err := ledger.Read(fs, sessionPath)
+	if err != nil {
+		return nil
+	}
+	last := make(map[string]ledger.Entry)
+	for _, e := range entries {
+		if !wanted[e.Path] {
+			continue
+		}
+		if prev, ok := last[e.Path]; !ok || !e.Time.Before(prev.Time) {
+			last[e.Path] = e
+		}
+	}
+	return last
+}
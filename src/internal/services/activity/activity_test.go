@@ -0,0 +1,105 @@
+package activity
+
+import (
+	"testing"
+	"time"
+
+	"claudex/internal/services/ledger"
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sessionsDir = "/repo/.claudex/sessions"
+
+var now = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+// change records a ledger entry minutes before now
+func change(t *testing.T, fs afero.Fs, session, path string, minutesAgo int) {
+	t.Helper()
+	require.NoError(t, ledger.Append(fs, sessionsDir+"/"+session, []ledger.Entry{
+		{Time: now.Add(-time.Duration(minutesAgo) * time.Minute), Tool: "Edit", Path: path},
+	}))
+}
+
+func TestHoldAndIsActive(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := sessionsDir + "/fix-locks"
+	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
+	assert.False(t, IsActive(fs, sessionPath))
+
+	held, err := Hold(fs, sessionPath, false)
+	require.NoError(t, err)
+	assert.True(t, IsActive(fs, sessionPath))
+
+	// A second claudex process cannot hold the same session
+	_, err = Hold(fs, sessionPath, false)
+	assert.Error(t, err)
+
+	require.NoError(t, held.Release())
+	assert.False(t, IsActive(fs, sessionPath))
+
+	// A lock left by a dead process is not active
+	require.NoError(t, afero.WriteFile(fs, LockPath(sessionPath), []byte("0\n"), 0644))
+	assert.False(t, IsActive(fs, sessionPath))
+}
+
+func TestConflicts(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	for _, s := range []string{"mine", "other", "newer", "idle"} {
+		require.NoError(t, fs.MkdirAll(sessionsDir+"/"+s, 0755))
+	}
+	for _, s := range []string{"mine", "other", "newer"} {
+		held, err := Hold(fs, sessionsDir+"/"+s, false)
+		require.NoError(t, err)
+		defer held.Release()
+	}
+
+	change(t, fs, "other", "/repo/a.go", 5)
+	change(t, fs, "newer", "/repo/a.go", 2)
+	change(t, fs, "other", "/repo/b.go", 45) // outside the window
+	change(t, fs, "idle", "/repo/c.go", 1)   // session not active
+	change(t, fs, "other", "/repo/d.go", 10)
+	change(t, fs, "mine", "/repo/d.go", 3) // already taken over
+	change(t, fs, "mine", "/repo/e.go", 1) // own changes never conflict
+
+	targets := []string{"/repo/a.go", "/repo/b.go", "/repo/c.go", "/repo/d.go", "/repo/e.go"}
+	conflicts := Conflicts(fs, sessionsDir, sessionsDir+"/mine", targets, 30*time.Minute, now)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "newer", conflicts[0].Session)
+	assert.Equal(t, "/repo/a.go", conflicts[0].Change.Path)
+
+	// Without an own session, its changes conflict too
+	conflicts = Conflicts(fs, sessionsDir, "", []string{"/repo/e.go"}, 30*time.Minute, now)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "mine", conflicts[0].Session)
+
+	// A wider window reaches older changes; a zero window disables checks
+	assert.Len(t, Conflicts(fs, sessionsDir, sessionsDir+"/mine", targets, time.Hour, now), 2)
+	assert.Empty(t, Conflicts(fs, sessionsDir, sessionsDir+"/mine", targets, 0, now))
+	assert.Empty(t, Conflicts(fs, "/missing", "", targets, time.Hour, now))
+}
+
+func TestWindow(t *testing.T) {
+	environ := testutil.NewMockEnv()
+	window, ok := Window(environ)
+	assert.True(t, ok)
+	assert.Equal(t, DefaultWindow, window)
+
+	environ.Set(WindowEnvVar, "10m")
+	window, ok = Window(environ)
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Minute, window)
+
+	environ.Set(WindowEnvVar, "0")
+	window, ok = Window(environ)
+	assert.True(t, ok)
+	assert.Zero(t, window)
+
+	environ.Set(WindowEnvVar, "soon")
+	window, ok = Window(environ)
+	assert.False(t, ok)
+	assert.Equal(t, DefaultWindow, window)
+}
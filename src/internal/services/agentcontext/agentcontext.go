@@ -0,0 +1,336 @@
+// Package agentcontext maps Task subagent types to the context injected into
+// their prompts. Rules live in .claudex/context.toml (project) and
+// ~/.config/claudex/context.toml (global), ahead of embedded defaults; each
+// names markdown fragments, whether stack skills are added, which doc entry
+// points apply and where the content goes.
+package agentcontext
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"claudex/internal/services/env"
+	"claudex/internal/services/paths"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/afero"
+)
+
+const (
+	// FileName is the rules file name, in .claudex/ and in the global config dir
+	FileName = "context.toml"
+
+	// FragmentDir holds custom fragments next to each rules file
+	FragmentDir = "context"
+
+	// DefaultsDir holds the embedded default rules and fragments
+	DefaultsDir = "profiles/context"
+
+	// AnyAgent matches every subagent type
+	AnyAgent = "*"
+)
+
+// Position is where injected context goes relative to the original prompt
+type Position string
+
+const (
+	Prepend Position = "prepend"
+	Append  Position = "append"
+)
+
+// Rule is one [[rule]] entry
+type Rule struct {
+	Name string `toml:"name"`
+
+	// Agents are subagent_type values, compared case-insensitively; "*"
+	// matches any agent
+	Agents []string `toml:"agents"`
+
+	// Fragments are markdown files rendered as Go templates (see Data), looked
+	// up in the project and global context/ directories, then the defaults
+	Fragments []string `toml:"fragments"`
+
+	// StackSkills appends the skills of the detected tech stacks
+	StackSkills bool `toml:"stack_skills"`
+
+	// DocPaths are the root documentation entry points; environment variables
+	// are expanded and colon-separated values split ("$CLAUDEX_DOC_PATHS")
+	DocPaths []string `toml:"doc_paths"`
+
+	// Position is prepend (default) or append
+	Position Position `toml:"position"`
+
+	// RelevantDocs is how many of the project's doc files most relevant to
+	// the Task prompt are listed, with a one-line summary each; zero lists none
+	RelevantDocs int `toml:"relevant_docs"`
+
+	// MaxTokens bounds the injected context in estimated tokens; stack
+	// skills are cut first, then the session file list, then the fragments.
+	// Zero means no limit.
+	MaxTokens int `toml:"max_tokens"`
+
+	source string
+}
+
+// Source returns the file the rule was loaded from
+func (r *Rule) Source() string {
+	return r.source
+}
+
+// Matches reports whether the rule applies to the subagent type
+func (r *Rule) Matches(agent string) bool {
+	for _, candidate := range r.Agents {
+		if candidate == AnyAgent || strings.EqualFold(candidate, agent) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveDocPaths expands and splits DocPaths, dropping empty entries
+func (r *Rule) ResolveDocPaths(environ env.Environment) []string {
+	var resolved []string
+	for _, entry := range r.DocPaths {
+		expanded := os.Expand(entry, environ.Get)
+		for _, p := range strings.Split(expanded, ":") {
+			if p != "" {
+				resolved = append(resolved, p)
+			}
+		}
+	}
+	return resolved
+}
+
+// Apply places rendered content before or after the original prompt
+func (r *Rule) Apply(content, prompt string) string {
+	if r.Position == Append {
+		return fmt.Sprintf("%s\n\n---\n\n%s", prompt, content)
+	}
+	return fmt.Sprintf("%s\n\n---\n\n## ORIGINAL REQUEST\n\n%s", content, prompt)
+}
+
+// Data is the template data available to fragments
+type Data struct {
+	SessionPath string
+
+	// Overview is the session-overview.md path when it exists; otherwise
+	// SessionFiles lists the files in the session folder
+	Overview     string
+	SessionFiles []string
+
+	// DocNames are the configured per-directory doc file names, comma-separated
+	DocNames string
+	DocPaths []string
+
+	// RelevantDocs are the doc files ranked most relevant to the prompt
+	RelevantDocs []RelevantDoc
+
+	Stacks []string
+}
+
+// RelevantDoc is a doc file recommended for the task
+type RelevantDoc struct {
+	// Path is absolute, so agents can read it directly
+	Path    string
+	Summary string
+}
+
+// Rules is the ordered rule list: project, global, then defaults
+type Rules struct {
+	Rules []Rule `toml:"rule"`
+
+	fs       afero.Fs
+	defaults fs.FS
+	dirs     []string
+}
+
+// Load reads the project and global rules files, followed by the embedded
+// defaults from defaults (the claudex profiles FS); missing files are skipped
+func Load(afs afero.Fs, defaults fs.FS, projectDir string, environ env.Environment) (*Rules, error) {
+	rules := &Rules{fs: afs, defaults: defaults}
+	configDirs := []string{filepath.Join(projectDir, paths.ClaudexDir)}
+	if global := paths.GlobalConfigDir(environ); global != "" {
+		configDirs = append(configDirs, global)
+	}
+
+	for _, dir := range configDirs {
+		rules.dirs = append(rules.dirs, filepath.Join(dir, FragmentDir))
+		file := filepath.Join(dir, FileName)
+		data, err := afero.ReadFile(afs, file)
+		if err != nil {
+			if exists, _ := afero.Exists(afs, file); !exists {
+				continue
+			}
+			return nil, err
+		}
+		parsed, err := Parse(data, file)
+		if err != nil {
+			return nil, err
+		}
+		rules.Rules = append(rules.Rules, parsed...)
+	}
+
+	builtin, err := defaultRules(defaults)
+	if err != nil {
+		return nil, err
+	}
+	rules.Rules = append(rules.Rules, builtin...)
+	return rules, nil
+}
+
+// Defaults returns only the embedded default rules
+func Defaults(afs afero.Fs, defaults fs.FS) (*Rules, error) {
+	builtin, err := defaultRules(defaults)
+	if err != nil {
+		return nil, err
+	}
+	return &Rules{Rules: builtin, fs: afs, defaults: defaults}, nil
+}
+
+// defaultRules parses the embedded rules file
+func defaultRules(defaults fs.FS) ([]Rule, error) {
+	file := path.Join(DefaultsDir, FileName)
+	data, err := fs.ReadFile(defaults, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read default context rules: %w", err)
+	}
+	return Parse(data, file)
+}
+
+// Parse parses and validates one rules file; source names it in errors
+func Parse(data []byte, source string) ([]Rule, error) {
+	var file struct {
+		Rules []Rule `toml:"rule"`
+	}
+	if _, err := toml.Decode(string(data), &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	for i := range file.Rules {
+		r := &file.Rules[i]
+		r.source = source
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		if len(r.Agents) == 0 {
+			return nil, fmt.Errorf("%s: %s: agents must not be empty", source, r.Name)
+		}
+		if r.RelevantDocs < 0 {
+			return nil, fmt.Errorf("%s: %s: relevant_docs must not be negative", source, r.Name)
+		}
+		if r.MaxTokens < 0 {
+			return nil, fmt.Errorf("%s: %s: max_tokens must not be negative", source, r.Name)
+		}
+		switch r.Position {
+		case "":
+			r.Position = Prepend
+		case Prepend, Append:
+		default:
+			return nil, fmt.Errorf("%s: %s: position must be prepend or append, got %q", source, r.Name, r.Position)
+		}
+	}
+	return file.Rules, nil
+}
+
+// Match returns the first rule for the subagent type, or nil
+func (rs *Rules) Match(agent string) *Rule {
+	for i := range rs.Rules {
+		if rs.Rules[i].Matches(agent) {
+			return &rs.Rules[i]
+		}
+	}
+	return nil
+}
+
+// Find returns the first rule with the given name, or nil
+func (rs *Rules) Find(name string) *Rule {
+	for i := range rs.Rules {
+		if rs.Rules[i].Name == name {
+			return &rs.Rules[i]
+		}
+	}
+	return nil
+}
+
+// Render executes the rule's fragments with data and appends the stack
+// skills when the rule asks for them, trimming the result to the rule's token
+// budget. An empty result means nothing to inject.
+func (rs *Rules) Render(r *Rule, data Data) (string, []Trimmed, error) {
+	fragments, err := rs.renderFragments(r, data)
+	if err != nil {
+		return "", nil, err
+	}
+	var skills []part
+	if r.StackSkills {
+		skills = rs.stackSkills(data.Stacks)
+	}
+	if r.MaxTokens <= 0 {
+		return join(fragments) + joinSkills(skills), nil, nil
+	}
+	return rs.fit(r, data, fragments, skills)
+}
+
+// renderFragments executes each fragment template
+func (rs *Rules) renderFragments(r *Rule, data Data) ([]part, error) {
+	parts := make([]part, 0, len(r.Fragments))
+	for _, name := range r.Fragments {
+		text, err := rs.fragment(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Name, err)
+		}
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Name, err)
+		}
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, data); err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Name, err)
+		}
+		parts = append(parts, part{name: "fragment " + name, file: filepath.Base(name), text: sb.String()})
+	}
+	return parts, nil
+}
+
+// fragment reads a fragment: absolute paths as given, otherwise from the
+// project and global context/ directories, then the embedded defaults
+func (rs *Rules) fragment(name string) (string, error) {
+	if filepath.IsAbs(name) {
+		data, err := afero.ReadFile(rs.fs, name)
+		if err != nil {
+			return "", fmt.Errorf("failed to read fragment: %w", err)
+		}
+		return string(data), nil
+	}
+	for _, dir := range rs.dirs {
+		if data, err := afero.ReadFile(rs.fs, filepath.Join(dir, name)); err == nil {
+			return string(data), nil
+		}
+	}
+	data, err := fs.ReadFile(rs.defaults, path.Join(DefaultsDir, filepath.ToSlash(name)))
+	if err != nil {
+		return "", fmt.Errorf("fragment %q not found", name)
+	}
+	return string(data), nil
+}
+
+// stackSkills returns the embedded skill of each stack that has one
+func (rs *Rules) stackSkills(stacks []string) []part {
+	var parts []part
+	for _, stack := range stacks {
+		content, err := fs.ReadFile(rs.defaults, fmt.Sprintf("profiles/skills/%s.md", stack))
+		if err != nil {
+			continue
+		}
+		parts = append(parts, part{
+			name:    "skill " + stack,
+			file:    "skill-" + stack + ".md",
+			heading: fmt.Sprintf("#### %s\n\n", strings.Title(stack)),
+			text:    string(content),
+		})
+	}
+	return parts
+}
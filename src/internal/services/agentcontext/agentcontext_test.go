@@ -0,0 +1,103 @@
+package agentcontext
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultsFS mimics the embedded profiles with one catch-all rule
+func defaultsFS() fstest.MapFS {
+	return fstest.MapFS{
+		"profiles/context/context.toml": {Data: []byte(`
+[[rule]]
+name = "session"
+agents = ["*"]
+fragments = ["session.md"]
+doc_paths = ["$CLAUDEX_DOC_PATHS"]
+`)},
+		"profiles/context/session.md": {Data: []byte("Session: {{.SessionPath}}\n{{range .DocPaths}}- {{.}}\n{{end}}")},
+		"profiles/context/review.md":  {Data: []byte("Review checklist\n")},
+		"profiles/skills/go.md":       {Data: []byte("Go skill")},
+	}
+}
+
+func TestLoad_ProjectRulesBeforeDefaults(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	env := testutil.NewMockEnv()
+	env.Set("HOME", "/home/u")
+	require.NoError(t, afero.WriteFile(fs, "/repo/.claudex/context.toml", []byte(`
+[[rule]]
+name = "reviewer"
+agents = ["code-reviewer"]
+fragments = ["review.md", "extra.md"]
+stack_skills = true
+position = "append"
+
+[[rule]]
+name = "silent"
+agents = ["quiet"]
+`), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/repo/.claudex/context/extra.md", []byte("Extra for {{.SessionPath}}\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/home/u/.config/claudex/context/review.md", []byte("Global review\n"), 0644))
+
+	rules, err := Load(fs, defaultsFS(), "/repo", env)
+	require.NoError(t, err)
+	require.Len(t, rules.Rules, 3)
+
+	reviewer := rules.Match("Code-Reviewer")
+	require.NotNil(t, reviewer)
+	assert.Equal(t, "reviewer", reviewer.Name)
+	assert.Equal(t, "/repo/.claudex/context.toml", reviewer.Source())
+
+	// Fragments resolve project, then global, then embedded
+	content, _, err := rules.Render(reviewer, Data{SessionPath: "/s", Stacks: []string{"go", "cobol"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Global review\n\nExtra for /s\n\n### Detected Tech Stack Skills\n\n#### Go\n\nGo skill\n\n", content)
+	assert.Equal(t, "do it\n\n---\n\nctx", reviewer.Apply("ctx", "do it"))
+
+	// A rule without fragments injects nothing
+	silent := rules.Match("quiet")
+	content, _, err = rules.Render(silent, Data{})
+	require.NoError(t, err)
+	assert.Empty(t, content)
+
+	// Everything else falls through to the defaults
+	session := rules.Match("general-purpose")
+	require.NotNil(t, session)
+	assert.Equal(t, "session", session.Name)
+	assert.Equal(t, "ctx\n\n---\n\n## ORIGINAL REQUEST\n\ndo it", session.Apply("ctx", "do it"))
+}
+
+func TestRule_ResolveDocPaths(t *testing.T) {
+	env := testutil.NewMockEnv()
+	env.Set("CLAUDEX_DOC_PATHS", "docs/a.md::docs/b.md")
+	r := Rule{DocPaths: []string{"$CLAUDEX_DOC_PATHS", "ARCHITECTURE.md", "$UNSET"}}
+
+	assert.Equal(t, []string{"docs/a.md", "docs/b.md", "ARCHITECTURE.md"}, r.ResolveDocPaths(env))
+}
+
+func TestParse_Validates(t *testing.T) {
+	_, err := Parse([]byte("[[rule]]\nname = \"x\"\n"), "context.toml")
+	assert.EqualError(t, err, "context.toml: x: agents must not be empty")
+
+	_, err = Parse([]byte("[[rule]]\nagents = [\"*\"]\nposition = \"middle\"\n"), "context.toml")
+	assert.EqualError(t, err, `context.toml: rule 1: position must be prepend or append, got "middle"`)
+
+	rules, err := Parse([]byte("[[rule]]\nagents = [\"*\"]\n"), "context.toml")
+	require.NoError(t, err)
+	assert.Equal(t, Prepend, rules[0].Position)
+}
+
+func TestRender_MissingFragment(t *testing.T) {
+	rules, err := Defaults(afero.NewMemMapFs(), defaultsFS())
+	require.NoError(t, err)
+
+	_, _, err = rules.Render(&Rule{Name: "broken", Fragments: []string{"nope.md"}}, Data{})
+	assert.EqualError(t, err, `broken: fragment "nope.md" not found`)
+}
@@ -0,0 +1,220 @@
+package agentcontext
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"claudex/internal/services/tokens"
+
+	"github.com/spf13/afero"
+)
+
+// SpillDir is the session subdirectory holding the full text of context
+// trimmed to fit a budget; the dot keeps it out of session file listings
+const SpillDir = ".context"
+
+// skillsHeading introduces the stack skills section
+const skillsHeading = "\n### Detected Tech Stack Skills\n\n"
+
+// minKeepTokens is the smallest useful remainder of a truncated part; below
+// it the part is replaced by its pointer alone
+const minKeepTokens = 50
+
+// Trimmed records context left out to fit a rule's token budget
+type Trimmed struct {
+	// Part names what was cut, e.g. "skill go" or "session files"
+	Part string
+
+	// Tokens is the estimated number of tokens removed
+	Tokens int
+
+	// Pointer is where the full text can be read, when available
+	Pointer string
+}
+
+// part is one budgeted piece of rendered context
+type part struct {
+	name    string
+	file    string
+	heading string
+	text    string
+}
+
+// String renders the part with its heading
+func (p part) String() string {
+	return p.heading + p.text
+}
+
+// join concatenates fragments, separating them by a blank line
+func join(parts []part) string {
+	var sb strings.Builder
+	for _, p := range parts {
+		if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n\n") {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(p.String())
+	}
+	return sb.String()
+}
+
+// joinSkills renders the stack skills section
+func joinSkills(skills []part) string {
+	if len(skills) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(skillsHeading)
+	for _, s := range skills {
+		sb.WriteString(s.String())
+		sb.WriteString("\n\n")
+	}
+	return sb.String()
+}
+
+// fit trims rendered context to the rule's budget in priority order: the
+// fragments claim the budget first, shrinking the session file list before
+// their own text is cut, and the stack skills share what is left
+func (rs *Rules) fit(r *Rule, data Data, fragments, skills []part) (string, []Trimmed, error) {
+	var trimmed []Trimmed
+	budget := r.MaxTokens
+
+	over := tokens.Estimate(join(fragments)) - budget
+	if over > 0 && len(data.SessionFiles) > 0 {
+		before := tokens.Estimate(join(fragments))
+		target := over
+		for {
+			shrunk, kept := shrinkFiles(data, target)
+			rerendered, err := rs.renderFragments(r, shrunk)
+			if err != nil {
+				return "", nil, err
+			}
+			fragments = rerendered
+			over = tokens.Estimate(join(fragments)) - budget
+			if over <= 0 || kept == 0 {
+				break
+			}
+			target += over
+		}
+		trimmed = append(trimmed, Trimmed{Part: "session files", Tokens: before - tokens.Estimate(join(fragments)), Pointer: data.SessionPath})
+	}
+
+	// Cut fragments from the last one backwards
+	for i := len(fragments) - 1; i >= 0; i-- {
+		over = tokens.Estimate(join(fragments)) - budget
+		if over <= 0 {
+			break
+		}
+		var t Trimmed
+		fragments[i], t = rs.truncate(data.SessionPath, fragments[i], tokens.Estimate(fragments[i].text)-over)
+		trimmed = append(trimmed, t)
+	}
+
+	// Keep skills in order while they fit, leaving room for the omission
+	// notes of the skills after them
+	remaining := budget - tokens.Estimate(join(fragments)+skillsHeading)
+	for i := range skills {
+		reserved := 0
+		for _, later := range skills[i+1:] {
+			reserved += tokens.Estimate(later.heading + omittedNote(rs.pointer(data.SessionPath, later.file)) + "\n\n")
+		}
+		cost := tokens.Estimate(skills[i].String() + "\n\n")
+		if cost <= remaining-reserved {
+			remaining -= cost
+			continue
+		}
+		var t Trimmed
+		skills[i], t = rs.truncate(data.SessionPath, skills[i], remaining-reserved-tokens.Estimate(skills[i].heading+"\n\n\n"))
+		trimmed = append(trimmed, t)
+		remaining -= tokens.Estimate(skills[i].String() + "\n\n")
+	}
+
+	return join(fragments) + joinSkills(skills), trimmed, nil
+}
+
+// truncate cuts a part to roughly keep tokens, ending it with a pointer to
+// its full text, which is written to the session's spill directory
+func (rs *Rules) truncate(sessionPath string, p part, keep int) (part, Trimmed) {
+	pointer := rs.spill(sessionPath, p.file, p.text)
+	before := tokens.Estimate(p.text)
+	note := fmt.Sprintf("[... trimmed to fit the context budget; %s]\n", see(pointer))
+	keep -= tokens.Estimate(note)
+	if keep < minKeepTokens {
+		p.text = omittedNote(pointer)
+	} else {
+		p.text = cutLines(p.text, keep) + "\n" + note
+	}
+	return p, Trimmed{Part: p.name, Tokens: before - tokens.Estimate(p.text), Pointer: pointer}
+}
+
+// omittedNote replaces a part that does not fit at all
+func omittedNote(pointer string) string {
+	return fmt.Sprintf("[Omitted to fit the context budget; %s]\n", see(pointer))
+}
+
+// see tells where the full text of a trimmed part is
+func see(pointer string) string {
+	if pointer == "" {
+		return "full text omitted"
+	}
+	return "see " + pointer
+}
+
+// pointer returns the spill file path for a part, or "" without a session
+func (rs *Rules) pointer(sessionPath, name string) string {
+	if sessionPath == "" || name == "" {
+		return ""
+	}
+	return filepath.Join(sessionPath, SpillDir, name)
+}
+
+// spill writes the full text of a trimmed part under the session folder and
+// returns its path, or "" when it cannot be written
+func (rs *Rules) spill(sessionPath, name, text string) string {
+	path := rs.pointer(sessionPath, name)
+	if path == "" {
+		return ""
+	}
+	if err := rs.fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return ""
+	}
+	if err := afero.WriteFile(rs.fs, path, []byte(text), 0644); err != nil {
+		return ""
+	}
+	return path
+}
+
+// cutLines keeps whole lines of text within roughly budget tokens, counted
+// the way tokens.Estimate does
+func cutLines(text string, budget int) string {
+	limit := budget * tokens.CharsPerToken
+	if limit >= len(text) {
+		return text
+	}
+	cut := text[:limit]
+	if i := strings.LastIndexByte(cut, '\n'); i > 0 {
+		return cut[:i+1]
+	}
+	for !utf8.ValidString(cut) {
+		cut = cut[:len(cut)-1]
+	}
+	return cut + "\n"
+}
+
+// shrinkFiles drops session files from the end of the list until about
+// target tokens are saved, naming how many were left out; it returns the
+// number of files still listed
+func shrinkFiles(data Data, target int) (Data, int) {
+	files := data.SessionFiles
+	saved := -tokens.Estimate(fmt.Sprintf("- ... %d more, see %s\n", len(files), data.SessionPath))
+	n := len(files)
+	for n > 0 && saved < target {
+		n--
+		saved += tokens.Estimate("- " + files[n] + "\n")
+	}
+
+	shrunk := data
+	shrunk.SessionFiles = append(append([]string{}, files[:n]...), fmt.Sprintf("... %d more, see %s", len(files)-n, data.SessionPath))
+	return shrunk, n
+}
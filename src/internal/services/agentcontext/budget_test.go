@@ -0,0 +1,104 @@
+package agentcontext
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"claudex/internal/services/tokens"
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// budgetFS provides a short fragment, a file-listing fragment and three
+// skills of about 500 tokens each
+func budgetFS() fstest.MapFS {
+	skill := strings.Repeat("A line of skill guidance text.\n", 64)
+	return fstest.MapFS{
+		"profiles/context/context.toml": {Data: []byte("[[rule]]\nagents = [\"*\"]\n")},
+		"profiles/context/intro.md":     {Data: []byte("## INTRO\n\nPlan carefully.\n")},
+		"profiles/context/files.md":     {Data: []byte("## FILES\n{{range .SessionFiles}}- {{.}}\n{{end}}")},
+		"profiles/skills/go.md":         {Data: []byte(skill)},
+		"profiles/skills/python.md":     {Data: []byte(skill)},
+		"profiles/skills/php.md":        {Data: []byte(skill)},
+	}
+}
+
+func TestRender_BudgetTrimsSkillsFirst(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	rules, err := Defaults(fs, budgetFS())
+	require.NoError(t, err)
+	rule := &Rule{Name: "plan", Fragments: []string{"intro.md"}, StackSkills: true, MaxTokens: 800}
+
+	content, trimmed, err := rules.Render(rule, Data{SessionPath: "/s", Stacks: []string{"go", "python", "php"}})
+	require.NoError(t, err)
+
+	assert.LessOrEqual(t, tokens.Estimate(content), 800)
+	assert.Contains(t, content, "## INTRO")
+	assert.Contains(t, content, "#### Go\n\nA line of skill guidance text.")
+	assert.Contains(t, content, "#### Python\n\nA line of skill guidance text.")
+	assert.Contains(t, content, "[... trimmed to fit the context budget; see /s/.context/skill-python.md]")
+	assert.Contains(t, content, "#### Php\n\n[Omitted to fit the context budget; see /s/.context/skill-php.md]")
+
+	require.Len(t, trimmed, 2)
+	assert.Equal(t, "skill python", trimmed[0].Part)
+	assert.Equal(t, "/s/.context/skill-python.md", trimmed[0].Pointer)
+	assert.Equal(t, "skill php", trimmed[1].Part)
+	assert.Greater(t, trimmed[1].Tokens, 400)
+
+	// The full text of trimmed parts is kept for the pointer
+	full, err := afero.ReadFile(fs, "/s/.context/skill-php.md")
+	require.NoError(t, err)
+	assert.Equal(t, string(budgetFS()["profiles/skills/php.md"].Data), string(full))
+}
+
+func TestRender_BudgetShrinksSessionFiles(t *testing.T) {
+	rules, err := Defaults(afero.NewMemMapFs(), budgetFS())
+	require.NoError(t, err)
+	rule := &Rule{Name: "session", Fragments: []string{"intro.md", "files.md"}, MaxTokens: 100}
+
+	var files []string
+	for i := 0; i < 100; i++ {
+		files = append(files, fmt.Sprintf("notes-%03d.md", i))
+	}
+	content, trimmed, err := rules.Render(rule, Data{SessionPath: "/s", SessionFiles: files})
+	require.NoError(t, err)
+
+	assert.LessOrEqual(t, tokens.Estimate(content), 100)
+	assert.Contains(t, content, "## INTRO\n\nPlan carefully.\n")
+	assert.Contains(t, content, "- notes-000.md\n")
+	assert.NotContains(t, content, "notes-099.md")
+	assert.Regexp(t, `- \.\.\. \d+ more, see /s\n$`, content)
+	require.Len(t, trimmed, 1)
+	assert.Equal(t, "session files", trimmed[0].Part)
+}
+
+func TestRender_BudgetTruncatesFragments(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/repo/.claudex/context/long.md", []byte(strings.Repeat("Guidance line.\n", 200)), 0644))
+	rules, err := Load(fs, budgetFS(), "/repo", testutil.NewMockEnv())
+	require.NoError(t, err)
+	rule := &Rule{Name: "custom", Fragments: []string{"intro.md", "long.md"}, MaxTokens: 200}
+
+	// Without a session folder there is nowhere to keep the full text
+	content, trimmed, err := rules.Render(rule, Data{})
+	require.NoError(t, err)
+
+	assert.LessOrEqual(t, tokens.Estimate(content), 200)
+	assert.True(t, strings.HasPrefix(content, "## INTRO\n\nPlan carefully.\n\nGuidance line.\n"))
+	assert.True(t, strings.HasSuffix(content, "Guidance line.\n\n[... trimmed to fit the context budget; full text omitted]\n"))
+	require.Len(t, trimmed, 1)
+	assert.Equal(t, "fragment long.md", trimmed[0].Part)
+	assert.Empty(t, trimmed[0].Pointer)
+
+	// Without a budget everything is kept
+	rule.MaxTokens = 0
+	content, trimmed, err = rules.Render(rule, Data{})
+	require.NoError(t, err)
+	assert.Empty(t, trimmed)
+	assert.Equal(t, 200, strings.Count(content, "Guidance line."))
+}
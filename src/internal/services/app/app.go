@@ -10,6 +10,7 @@ import (
 
 	"claudex"
 	"claudex/internal/services/config"
+	"claudex/internal/services/docfiles"
 	"claudex/internal/services/mcpconfig"
 	"claudex/internal/services/paths"
 	"claudex/internal/services/profile"
@@ -44,6 +45,35 @@ type SessionInfo struct {
 	OriginalName string // For fork/fresh operations
 }
 
+// DocsFlags holds the CLI flags that refine --update-docs and hook setup.
+// Nil fields are treated as false.
+type DocsFlags struct {
+	Staged    *bool
+	Worktree  *bool
+	AddDocs   *bool
+	SetupHook *bool
+	PreCommit *bool
+}
+
+// updateDocsOptions converts the flags into update-docs usecase options
+func (f DocsFlags) updateDocsOptions() (updatedocsuc.Options, error) {
+	staged, worktree, addDocs := boolValue(f.Staged), boolValue(f.Worktree), boolValue(f.AddDocs)
+
+	opts := updatedocsuc.Options{Mode: updatedocsuc.ModeCommits, AddDocs: addDocs}
+	switch {
+	case staged && worktree:
+		return opts, fmt.Errorf("--staged and --worktree are mutually exclusive")
+	case staged:
+		opts.Mode = updatedocsuc.ModeStaged
+	case worktree:
+		opts.Mode = updatedocsuc.ModeWorktree
+	}
+	if addDocs && !staged {
+		return opts, fmt.Errorf("--add-docs requires --staged")
+	}
+	return opts, nil
+}
+
 // App is the main application container
 type App struct {
 	deps            *Dependencies
@@ -55,6 +85,10 @@ type App struct {
 	updateDocs      bool
 	setupMCP        bool
 	createIndex     string
+	updateDocsOpts  updatedocsuc.Options
+	setupHook       bool
+	preCommit       bool
+	args            []string
 	logFile         afero.File
 	logFilePath     string
 	version         string
@@ -64,10 +98,11 @@ type App struct {
 	setupMCPFlag    *bool
 	createIndexFlag *string
 	docPathsFlag    []string
+	docsFlags       DocsFlags
 }
 
 // New creates a new App instance with production dependencies
-func New(version string, showVersion *bool, noOverwrite *bool, updateDocs *bool, setupMCP *bool, createIndex *string, docPaths []string) *App {
+func New(version string, showVersion *bool, noOverwrite *bool, updateDocs *bool, setupMCP *bool, createIndex *string, docPaths []string, docsFlags DocsFlags) *App {
 	return &App{
 		deps:            NewDependencies(),
 		version:         version,
@@ -77,6 +112,7 @@ func New(version string, showVersion *bool, noOverwrite *bool, updateDocs *bool,
 		setupMCPFlag:    setupMCP,
 		createIndexFlag: createIndex,
 		docPathsFlag:    docPaths,
+		docsFlags:       docsFlags,
 	}
 }
 
@@ -117,6 +153,18 @@ func (a *App) Init() error {
 	a.updateDocs = *a.updateDocsFlag
 	a.setupMCP = *a.setupMCPFlag
 	a.createIndex = *a.createIndexFlag
+	a.args = flag.Args()
+	a.setupHook = boolValue(a.docsFlags.SetupHook)
+	a.preCommit = boolValue(a.docsFlags.PreCommit)
+
+	updateDocsOpts, err := a.docsFlags.updateDocsOptions()
+	if err != nil {
+		return err
+	}
+	a.updateDocsOpts = updateDocsOpts
+	if a.preCommit && !a.setupHook {
+		return fmt.Errorf("--pre-commit requires --setup-hook")
+	}
 
 	projectDir, err := os.Getwd()
 	if err != nil {
@@ -234,6 +282,11 @@ func (a *App) renameLogFileForSession(si SessionInfo) {
 
 // Run executes the main application logic
 func (a *App) Run() error {
+	// Subcommands (e.g. "claudex docs update") bypass the interactive flow
+	if len(a.args) > 0 {
+		return a.runCommand(a.args)
+	}
+
 	// Check if Claude CLI is installed
 	if !a.isClaudeInstalled() {
 		fmt.Println("\n❌ Claude Code CLI not found")
@@ -263,14 +316,27 @@ func (a *App) Run() error {
 
 	// Early exit for --update-docs mode
 	if a.updateDocs {
-		uc := updatedocsuc.New(a.deps.FS, a.deps.Cmd, a.deps.Env)
-		return uc.Execute(a.projectDir)
+		lockOpts, err := a.lockOptions()
+		if err != nil {
+			return err
+		}
+		uc := updatedocsuc.New(a.deps.FS, a.deps.Cmd, a.deps.Env, a.cfg.Docs, lockOpts)
+		return uc.Execute(a.projectDir, a.updateDocsOpts)
+	}
+
+	// Early exit for --setup-hook mode
+	if a.setupHook {
+		return a.installHooks()
 	}
 
 	// Early exit for --create-index mode
 	if a.createIndex != "" {
-		uc := createindexuc.New(a.deps.FS, a.deps.Cmd, a.deps.Env)
-		return uc.Execute(a.createIndex)
+		lockOpts, err := a.lockOptions()
+		if err != nil {
+			return err
+		}
+		uc := createindexuc.New(a.deps.FS, a.deps.Cmd, a.deps.Env, docfiles.New(a.cfg.Docs.Filenames), lockOpts)
+		return uc.Execute(a.createIndex, createindexuc.Options{})
 	}
 
 	// Early exit for --setup-mcp mode
@@ -380,6 +446,35 @@ func (a *App) promptHookSetup() {
 	fmt.Println()
 }
 
+// installHooks installs the doc update git hooks without prompting
+func (a *App) installHooks() error {
+	uc := setuphookuc.New(a.deps.FS, a.projectDir, a.deps.Cmd)
+
+	switch uc.ShouldPrompt() {
+	case setuphookuc.ResultNotGitRepo:
+		return fmt.Errorf("not a git repository")
+	case setuphookuc.ResultAlreadyInstalled:
+		if !a.preCommit {
+			fmt.Println("✓ Git hook already installed.")
+			return nil
+		}
+	}
+
+	if a.preCommit {
+		if err := uc.InstallPreCommit(); err != nil {
+			return fmt.Errorf("failed to install hooks: %w", err)
+		}
+		fmt.Println("✓ Git hooks installed. Docs for staged changes will be added to each commit.")
+		return nil
+	}
+
+	if err := uc.Install(); err != nil {
+		return fmt.Errorf("failed to install hook: %w", err)
+	}
+	fmt.Println("✓ Git hook installed. Docs will auto-update after commits.")
+	return nil
+}
+
 // promptMCPSetup checks if we should offer MCP configuration
 func (a *App) promptMCPSetup() {
 	uc := setupmcpuc.New(a.deps.FS)
@@ -478,6 +573,11 @@ func isFlagSet(name string) bool {
 	return found
 }
 
+// boolValue dereferences an optional flag, treating nil as false
+func boolValue(p *bool) bool {
+	return p != nil && *p
+}
+
 // resolveDocPaths converts a list of documentation paths to absolute paths
 // and joins them with colon separators (Unix PATH convention)
 func resolveDocPaths(paths []string) string {
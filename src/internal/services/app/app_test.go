@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"claudex/internal/testutil"
+	updatedocsuc "claudex/internal/usecases/updatedocs"
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
@@ -709,3 +710,61 @@ func TestInit_MigrationIdempotent(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, exists, ".claudex/sessions should still exist")
 }
+
+// TestDocsFlags_UpdateDocsOptions verifies flag combinations map to usecase options
+func TestDocsFlags_UpdateDocsOptions(t *testing.T) {
+	yes := true
+
+	tests := []struct {
+		name     string
+		flags    DocsFlags
+		wantMode updatedocsuc.Mode
+		wantErr  bool
+	}{
+		{name: "no flags defaults to commits", flags: DocsFlags{}, wantMode: updatedocsuc.ModeCommits},
+		{name: "staged", flags: DocsFlags{Staged: &yes}, wantMode: updatedocsuc.ModeStaged},
+		{name: "worktree", flags: DocsFlags{Worktree: &yes}, wantMode: updatedocsuc.ModeWorktree},
+		{name: "staged with add-docs", flags: DocsFlags{Staged: &yes, AddDocs: &yes}, wantMode: updatedocsuc.ModeStaged},
+		{name: "staged and worktree conflict", flags: DocsFlags{Staged: &yes, Worktree: &yes}, wantErr: true},
+		{name: "add-docs without staged", flags: DocsFlags{Worktree: &yes, AddDocs: &yes}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := tt.flags.updateDocsOptions()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMode, opts.Mode)
+		})
+	}
+}
+
+// TestRunCommand_Unknown verifies unknown subcommands are rejected with usage
+func TestRunCommand_Unknown(t *testing.T) {
+	app := &App{}
+
+	err := app.runCommand([]string{"bogus"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Usage: claudex")
+
+	err = app.runCommand([]string{"docs"})
+	require.Error(t, err)
+
+	err = app.runCommand([]string{"docs", "bogus"})
+	require.Error(t, err)
+
+	err = app.runCommand([]string{"hooks", "svn"})
+	require.Error(t, err)
+
+	err = app.runCommand([]string{"policy", "test"})
+	require.Error(t, err)
+
+	err = app.runCommand([]string{"sessions", "files"})
+	require.Error(t, err)
+
+	err = app.runCommand([]string{"why"})
+	require.Error(t, err)
+}
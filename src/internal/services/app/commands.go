@@ -0,0 +1,367 @@
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"claudex/internal/services/docfiles"
+	"claudex/internal/services/lock"
+	checkpolicyuc "claudex/internal/usecases/checkpolicy"
+	createindexuc "claudex/internal/usecases/createindex"
+	docjobsuc "claudex/internal/usecases/docjobs"
+	doclintuc "claudex/internal/usecases/doclint"
+	githooksuc "claudex/internal/usecases/githooks"
+	locksuc "claudex/internal/usecases/locks"
+	sessionfilesuc "claudex/internal/usecases/sessionfiles"
+	updatedocsuc "claudex/internal/usecases/updatedocs"
+	whyuc "claudex/internal/usecases/why"
+)
+
+// commandUsage lists the available subcommands
+const commandUsage = `Usage: claudex <command> [options]
+
+Commands:
+  docs update    Update index.md files (see "claudex docs update -h")
+  docs create    Create index.md files (--recursive for every undocumented directory, --offline without Claude, --force over an existing doc)
+  docs jobs      Show running and failed index.md update jobs
+  docs lint      Check doc links, references and coverage (--json, --strict)
+  hooks git      Manage claudex git hooks: install|uninstall|status (--hooks post-commit,post-merge,...)
+  locks list     Show lock files under .claudex and whether they are stale
+  locks clear    Remove stale locks (--force also removes live ones)
+  policy test    Check policy rules against a fixture of tool calls (claudex policy test <fixture>)
+  sessions files List the files a session changed and whether they changed since (claudex sessions files <name>, --json)
+  why            Show the sessions, transcript moments and reasoning behind a file (claudex why <path>[:line], --json)`
+
+// parseCommandFlags parses subcommand flags. done is true when the command
+// should stop: on a parse error, or after -h printed the usage.
+func parseCommandFlags(fs *flag.FlagSet, args []string) (done bool, err error) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return true, nil
+		}
+		return true, err
+	}
+	return false, nil
+}
+
+// runCommand dispatches a subcommand given as positional arguments
+func (a *App) runCommand(args []string) error {
+	switch args[0] {
+	case "docs":
+		return a.runDocsCommand(args[1:])
+	case "hooks":
+		return a.runHooksCommand(args[1:])
+	case "locks":
+		return a.runLocksCommand(args[1:])
+	case "policy":
+		return a.runPolicyCommand(args[1:])
+	case "sessions":
+		return a.runSessionsCommand(args[1:])
+	case "why":
+		return a.runWhy(args[1:])
+	case "help":
+		fmt.Println(commandUsage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], commandUsage)
+	}
+}
+
+// runDocsCommand dispatches "claudex docs <subcommand>"
+func (a *App) runDocsCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing docs subcommand\n%s", commandUsage)
+	}
+
+	switch args[0] {
+	case "update":
+		return a.runDocsUpdate(args[1:])
+	case "create":
+		return a.runDocsCreate(args[1:])
+	case "jobs":
+		return a.runDocsJobs(args[1:])
+	case "lint":
+		return a.runDocsLint(args[1:])
+	default:
+		return fmt.Errorf("unknown docs subcommand %q\n%s", args[0], commandUsage)
+	}
+}
+
+// runDocsUpdate implements "claudex docs update"
+func (a *App) runDocsUpdate(args []string) error {
+	fs := flag.NewFlagSet("docs update", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	staged := fs.Bool("staged", false, "use staged changes instead of commits")
+	worktree := fs.Bool("worktree", false, "use uncommitted working tree changes instead of commits")
+	addDocs := fs.Bool("add-docs", false, "with --staged, wait for updates and stage the regenerated docs")
+	dryRun := fs.Bool("dry-run", false, "print proposed changes as a unified diff without editing any file")
+	patchFile := fs.String("patch", "", "with --dry-run, write the diff to this file")
+	applyFile := fs.String("apply", "", "apply and commit a patch written by --dry-run --patch")
+	if done, err := parseCommandFlags(fs, args); done {
+		return err
+	}
+
+	lockOpts, err := a.lockOptions()
+	if err != nil {
+		return err
+	}
+	uc := updatedocsuc.New(a.deps.FS, a.deps.Cmd, a.deps.Env, a.cfg.Docs, lockOpts)
+
+	if *applyFile != "" {
+		if *dryRun || *staged || *worktree || *addDocs || *patchFile != "" {
+			return fmt.Errorf("--apply cannot be combined with other options")
+		}
+		return uc.Apply(a.projectDir, *applyFile)
+	}
+
+	opts, err := DocsFlags{Staged: staged, Worktree: worktree, AddDocs: addDocs}.updateDocsOptions()
+	if err != nil {
+		return err
+	}
+	opts.DryRun = *dryRun
+	opts.PatchFile = *patchFile
+	return uc.Execute(a.projectDir, opts)
+}
+
+// runDocsCreate implements "claudex docs create [--recursive] [dir]"
+func (a *App) runDocsCreate(args []string) error {
+	fs := flag.NewFlagSet("docs create", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	recursive := fs.Bool("recursive", false, "create docs for every directory under dir that lacks one, deepest first")
+	maxDepth := fs.Int("max-depth", 0, "with --recursive, only consider directories this many levels below dir (0 means unlimited)")
+	minFiles := fs.Int("min-files", createindexuc.DefaultMinFiles, "with --recursive, code files a directory needs to get its own doc")
+	maxJobs := fs.Int("jobs", a.cfg.Docs.MaxJobs, "with --recursive, Claude processes to run at once (default from docs.max_jobs)")
+	resume := fs.Bool("resume", false, "with --recursive, continue an interrupted run")
+	restart := fs.Bool("restart", false, "with --recursive, discard an interrupted run and plan again")
+	dryRun := fs.Bool("dry-run", false, "with --recursive, list the directories without creating anything")
+	offline := fs.Bool("offline", false, "write a skeleton from static analysis without running Claude")
+	force := fs.Bool("force", false, "with --offline, write the skeleton even if the directory has a doc file")
+	if done, err := parseCommandFlags(fs, args); done {
+		return err
+	}
+
+	dir := a.projectDir
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	lockOpts, err := a.lockOptions()
+	if err != nil {
+		return err
+	}
+	uc := createindexuc.New(a.deps.FS, a.deps.Cmd, a.deps.Env, docfiles.New(a.cfg.Docs.Filenames), lockOpts)
+
+	if !*recursive {
+		if *resume || *restart || *dryRun {
+			return fmt.Errorf("--resume, --restart and --dry-run require --recursive")
+		}
+		if *force && !*offline {
+			return fmt.Errorf("--force requires --offline")
+		}
+		return uc.Execute(dir, createindexuc.Options{Offline: *offline, Force: *force})
+	}
+	if *resume && *restart {
+		return fmt.Errorf("--resume cannot be combined with --restart")
+	}
+	if *force {
+		return fmt.Errorf("--force cannot be combined with --recursive, which skips documented directories")
+	}
+
+	timeout, err := a.cfg.Docs.JobTimeoutDuration()
+	if err != nil {
+		return err
+	}
+	return uc.ExecuteRecursive(a.projectDir, dir, createindexuc.RecursiveOptions{
+		MaxDepth:   *maxDepth,
+		MinFiles:   *minFiles,
+		MaxJobs:    *maxJobs,
+		JobTimeout: timeout,
+		Resume:     *resume,
+		Restart:    *restart,
+		DryRun:     *dryRun,
+		Offline:    *offline,
+	})
+}
+
+// runDocsJobs implements "claudex docs jobs"
+func (a *App) runDocsJobs(args []string) error {
+	fs := flag.NewFlagSet("docs jobs", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	all := fs.Bool("all", false, "include succeeded jobs")
+	if done, err := parseCommandFlags(fs, args); done {
+		return err
+	}
+
+	return docjobsuc.New(a.deps.FS).Execute(a.projectDir, *all)
+}
+
+// runDocsLint implements "claudex docs lint [root]"
+func (a *App) runDocsLint(args []string) error {
+	fs := flag.NewFlagSet("docs lint", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	jsonOutput := fs.Bool("json", false, "print the report as JSON")
+	strict := fs.Bool("strict", false, "fail on warnings (undocumented directories, orphan docs) too")
+	if done, err := parseCommandFlags(fs, args); done {
+		return err
+	}
+
+	root := a.projectDir
+	if fs.NArg() > 0 {
+		root = fs.Arg(0)
+	}
+	uc := doclintuc.New(a.deps.FS, docfiles.New(a.cfg.Docs.Filenames))
+	return uc.Execute(root, doclintuc.Options{JSON: *jsonOutput, Strict: *strict}, os.Stdout)
+}
+
+// runHooksCommand dispatches "claudex hooks git <subcommand>"
+func (a *App) runHooksCommand(args []string) error {
+	if len(args) == 0 || args[0] != "git" {
+		return fmt.Errorf("usage: claudex hooks git install|uninstall|status\n%s", commandUsage)
+	}
+	args = args[1:]
+	if len(args) == 0 {
+		return fmt.Errorf("missing hooks git subcommand\n%s", commandUsage)
+	}
+
+	uc := githooksuc.New(a.deps.FS, a.projectDir, a.deps.Cmd)
+
+	switch args[0] {
+	case "install", "uninstall":
+		fs := flag.NewFlagSet("hooks git "+args[0], flag.ContinueOnError)
+		fs.SetOutput(os.Stderr)
+		hooks := fs.String("hooks", "", "comma-separated hooks (post-commit, pre-commit, post-merge, post-checkout, pre-push)")
+		if done, err := parseCommandFlags(fs, args[1:]); done {
+			return err
+		}
+		names := append(splitList(*hooks), fs.Args()...)
+		if args[0] == "install" {
+			return uc.Install(names)
+		}
+		return uc.Uninstall(names)
+	case "status":
+		fs := flag.NewFlagSet("hooks git status", flag.ContinueOnError)
+		fs.SetOutput(os.Stderr)
+		if done, err := parseCommandFlags(fs, args[1:]); done {
+			return err
+		}
+		return uc.Status()
+	default:
+		return fmt.Errorf("unknown hooks git subcommand %q\n%s", args[0], commandUsage)
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// runLocksCommand dispatches "claudex locks <subcommand>"
+func (a *App) runLocksCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing locks subcommand\n%s", commandUsage)
+	}
+
+	lockOpts, err := a.lockOptions()
+	if err != nil {
+		return err
+	}
+	uc := locksuc.New(a.deps.FS, lockOpts)
+
+	switch args[0] {
+	case "list":
+		fs := flag.NewFlagSet("locks list", flag.ContinueOnError)
+		fs.SetOutput(os.Stderr)
+		if done, err := parseCommandFlags(fs, args[1:]); done {
+			return err
+		}
+		return uc.List(a.projectDir)
+	case "clear":
+		fs := flag.NewFlagSet("locks clear", flag.ContinueOnError)
+		fs.SetOutput(os.Stderr)
+		force := fs.Bool("force", false, "also remove locks held by running processes")
+		if done, err := parseCommandFlags(fs, args[1:]); done {
+			return err
+		}
+		return uc.Clear(a.projectDir, fs.Args(), *force)
+	default:
+		return fmt.Errorf("unknown locks subcommand %q\n%s", args[0], commandUsage)
+	}
+}
+
+// runPolicyCommand dispatches "claudex policy <subcommand>"
+func (a *App) runPolicyCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing policy subcommand\n%s", commandUsage)
+	}
+
+	switch args[0] {
+	case "test":
+		fs := flag.NewFlagSet("policy test", flag.ContinueOnError)
+		fs.SetOutput(os.Stderr)
+		if done, err := parseCommandFlags(fs, args[1:]); done {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: claudex policy test <fixture>")
+		}
+		return checkpolicyuc.New(a.deps.FS, a.deps.Env).Execute(a.projectDir, fs.Arg(0))
+	default:
+		return fmt.Errorf("unknown policy subcommand %q\n%s", args[0], commandUsage)
+	}
+}
+
+// runSessionsCommand dispatches "claudex sessions <subcommand>"
+func (a *App) runSessionsCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing sessions subcommand\n%s", commandUsage)
+	}
+
+	switch args[0] {
+	case "files":
+		fs := flag.NewFlagSet("sessions files", flag.ContinueOnError)
+		fs.SetOutput(os.Stderr)
+		jsonOutput := fs.Bool("json", false, "print the report as JSON")
+		if done, err := parseCommandFlags(fs, args[1:]); done {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: claudex sessions files [--json] <name>")
+		}
+		uc := sessionfilesuc.New(a.deps.FS)
+		return uc.Execute(a.projectDir, fs.Arg(0), sessionfilesuc.Options{JSON: *jsonOutput}, os.Stdout)
+	default:
+		return fmt.Errorf("unknown sessions subcommand %q\n%s", args[0], commandUsage)
+	}
+}
+
+// runWhy implements "claudex why <path>[:line]"
+func (a *App) runWhy(args []string) error {
+	fs := flag.NewFlagSet("why", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	jsonOutput := fs.Bool("json", false, "print the report as JSON")
+	if done, err := parseCommandFlags(fs, args); done {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: claudex why [--json] <path>[:line]")
+	}
+	uc := whyuc.New(a.deps.FS, a.deps.Env)
+	return uc.Execute(a.projectDir, fs.Arg(0), whyuc.Options{JSON: *jsonOutput}, os.Stdout)
+}
+
+// lockOptions builds stale lock detection options from the [locks] config
+func (a *App) lockOptions() (lock.Options, error) {
+	ttl, err := a.cfg.Locks.TTLDuration()
+	if err != nil {
+		return lock.Options{}, err
+	}
+	return lock.Options{TTL: ttl, Flock: a.cfg.Locks.Flock}, nil
+}
@@ -7,7 +7,9 @@ import (
 	"strconv"
 	"time"
 
+	"claudex/internal/services/activity"
 	"claudex/internal/services/config"
+	"claudex/internal/services/docfiles"
 	"claudex/internal/services/session"
 	"claudex/internal/ui"
 )
@@ -19,6 +21,7 @@ func (a *App) setEnvironment(si SessionInfo, cfg *config.Config) {
 	if len(a.docPaths) > 0 {
 		os.Setenv("CLAUDEX_DOC_PATHS", resolveDocPaths(a.docPaths))
 	}
+	os.Setenv(docfiles.EnvVar, docfiles.New(cfg.Docs.Filenames).Env())
 
 	// Export feature toggles with env var override support
 	// Env vars take precedence over config values
@@ -29,6 +32,12 @@ func (a *App) setEnvironment(si SessionInfo, cfg *config.Config) {
 	os.Setenv("CLAUDEX_AUTODOC_SESSION_PROGRESS", strconv.FormatBool(sessionProgress))
 	os.Setenv("CLAUDEX_AUTODOC_SESSION_END", strconv.FormatBool(sessionEnd))
 	os.Setenv("CLAUDEX_AUTODOC_FREQUENCY", strconv.Itoa(frequency))
+	if os.Getenv("CLAUDEX_AUTODOC_PRECOMPACT_TIMEOUT") == "" && cfg.Features.AutodocPreCompactTimeout != "" {
+		os.Setenv("CLAUDEX_AUTODOC_PRECOMPACT_TIMEOUT", cfg.Features.AutodocPreCompactTimeout)
+	}
+	if os.Getenv(activity.WindowEnvVar) == "" && cfg.Sessions.ConflictWindow != "" {
+		os.Setenv(activity.WindowEnvVar, cfg.Sessions.ConflictWindow)
+	}
 }
 
 // getEnvBool returns env var value if set, otherwise returns default
@@ -56,6 +65,17 @@ func (a *App) launch(si SessionInfo) error {
 		fmt.Fprintf(os.Stderr, "Warning: Could not update last used timestamp: %v\n", err)
 	}
 
+	// Mark the session active while Claude runs, so other sessions are asked
+	// before overwriting its changes
+	if si.Mode != LaunchModeEphemeral && si.Path != "" {
+		held, err := activity.Hold(a.deps.FS, si.Path, a.cfg != nil && a.cfg.Locks.Flock)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: session %s is already active in another claudex process; edits may conflict\n", si.Name)
+		} else {
+			defer held.Release()
+		}
+	}
+
 	// Give terminal a moment to settle
 	time.Sleep(100 * time.Millisecond)
 
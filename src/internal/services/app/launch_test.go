@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"claudex/internal/services/activity"
 	"claudex/internal/services/config"
 	"claudex/internal/testutil"
 
@@ -731,3 +732,23 @@ func TestSetEnvironment_InvalidEnvVarValues(t *testing.T) {
 	require.Equal(t, "false", os.Getenv("CLAUDEX_AUTODOC_SESSION_PROGRESS")) // "not-a-bool" != "true" = false
 	require.Equal(t, "5", os.Getenv("CLAUDEX_AUTODOC_FREQUENCY"))            // Invalid int, uses config default
 }
+
+// TestSetEnvironment_ConflictWindow verifies the sessions conflict window is
+// exported unless the environment already sets it
+func TestSetEnvironment_ConflictWindow(t *testing.T) {
+	orig := os.Getenv(activity.WindowEnvVar)
+	defer os.Setenv(activity.WindowEnvVar, orig)
+
+	h := testutil.NewTestHarness()
+	app := &App{deps: &Dependencies{FS: h.FS, Cmd: h.Commander, Clock: h, UUID: h, Env: h.Env}}
+	si := SessionInfo{Name: "test-session", Path: "/project/.claudex/sessions/test-session", Mode: LaunchModeNew}
+	cfg := &config.Config{Sessions: config.Sessions{ConflictWindow: "10m"}}
+
+	os.Setenv(activity.WindowEnvVar, "")
+	app.setEnvironment(si, cfg)
+	require.Equal(t, "10m", os.Getenv(activity.WindowEnvVar))
+
+	os.Setenv(activity.WindowEnvVar, "0")
+	app.setEnvironment(si, cfg)
+	require.Equal(t, "0", os.Getenv(activity.WindowEnvVar))
+}
@@ -4,6 +4,9 @@
 package config
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/BurntSushi/toml"
 	"github.com/spf13/afero"
 )
@@ -13,12 +16,94 @@ type Features struct {
 	AutodocSessionProgress bool `toml:"autodoc_session_progress"`
 	AutodocSessionEnd      bool `toml:"autodoc_session_end"`
 	AutodocFrequency       int  `toml:"autodoc_frequency"`
+
+	// AutodocPreCompactTimeout bounds the synchronous session-overview update
+	// run before Claude Code compacts the context, as a Go duration (e.g.
+	// "45s"); "0" disables the checkpoint and empty uses the default
+	AutodocPreCompactTimeout string `toml:"autodoc_precompact_timeout"`
+}
+
+// Docs controls the git-driven index.md update workflow
+type Docs struct {
+	// DefaultBranch overrides default branch detection for merge-base fallback
+	DefaultBranch string `toml:"default_branch"`
+
+	// Filenames lists the per-directory documentation file names in priority
+	// order (e.g. ["README.md", "index.md"]); empty means index.md only
+	Filenames []string `toml:"filenames"`
+
+	// MaxJobs bounds how many Claude processes update index.md files at once
+	MaxJobs int `toml:"max_jobs"`
+
+	// JobTimeout bounds each Claude process, as a Go duration (e.g. "10m")
+	JobTimeout string `toml:"job_timeout"`
+
+	// DiffTokenBudget bounds the diff hunks sent per index.md, in approximate
+	// tokens; zero uses the default and a negative value sends file names only
+	DiffTokenBudget int `toml:"diff_token_budget"`
+
+	// AutoCommit commits regenerated index.md files after a post-commit update
+	AutoCommit bool `toml:"auto_commit"`
+
+	// CommitMessage overrides the auto-commit message; [skip-docs] and a
+	// source range trailer are always added
+	CommitMessage string `toml:"commit_message"`
+}
+
+// JobTimeoutDuration parses JobTimeout; an empty value returns zero (use the default)
+func (d Docs) JobTimeoutDuration() (time.Duration, error) {
+	if d.JobTimeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(d.JobTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid docs.job_timeout %q: %w", d.JobTimeout, err)
+	}
+	return timeout, nil
+}
+
+// Locks controls stale lock detection for .claudex lock files
+type Locks struct {
+	// TTL is the age after which a lock whose holder cannot be confirmed
+	// running is considered stale, as a Go duration (e.g. "30m"); "0"
+	// disables expiry and empty uses the default
+	TTL string `toml:"ttl"`
+
+	// Flock additionally holds a flock(2) advisory lock (Linux only)
+	Flock bool `toml:"flock"`
+}
+
+// TTLDuration parses TTL; an empty value returns zero (use the default) and
+// "0" returns a negative duration (expiry disabled)
+func (l Locks) TTLDuration() (time.Duration, error) {
+	if l.TTL == "" {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(l.TTL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid locks.ttl %q: %w", l.TTL, err)
+	}
+	if ttl <= 0 {
+		return -1, nil
+	}
+	return ttl, nil
+}
+
+// Sessions controls how concurrent claudex sessions in a checkout interact
+type Sessions struct {
+	// ConflictWindow is how long a file another active session changed stays
+	// protected: writing it asks first. A Go duration (e.g. "10m"); "0"
+	// disables the check and empty uses the default
+	ConflictWindow string `toml:"conflict_window"`
 }
 
 type Config struct {
 	Doc         []string `toml:"doc"`
 	NoOverwrite bool     `toml:"no_overwrite"`
 	Features    Features `toml:"features"`
+	Docs        Docs     `toml:"docs"`
+	Locks       Locks    `toml:"locks"`
+	Sessions    Sessions `toml:"sessions"`
 }
 
 // Load loads configuration from the specified path using the provided filesystem
@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
@@ -227,6 +228,7 @@ no_overwrite = true
 autodoc_session_progress = false
 autodoc_session_end = true
 autodoc_frequency = 15
+autodoc_precompact_timeout = "20s"
 `
 
 	fs := afero.NewMemMapFs()
@@ -246,6 +248,105 @@ autodoc_frequency = 15
 	require.False(t, cfg.Features.AutodocSessionProgress)
 	require.True(t, cfg.Features.AutodocSessionEnd)
 	require.Equal(t, 15, cfg.Features.AutodocFrequency)
+	require.Equal(t, "20s", cfg.Features.AutodocPreCompactTimeout)
+}
+
+// TestLoad_DocsSection_ParsesDefaultBranch verifies the [docs] branch and filename settings are parsed
+func TestLoad_DocsSection_ParsesDefaultBranch(t *testing.T) {
+	content := `
+[docs]
+default_branch = "develop"
+filenames = ["README.md", "index.md"]
+`
+
+	fs := afero.NewMemMapFs()
+	configPath := "/test/.claudex/config.toml"
+
+	err := afero.WriteFile(fs, configPath, []byte(content), 0644)
+	require.NoError(t, err)
+
+	cfg, err := Load(fs, configPath)
+	require.NoError(t, err)
+
+	require.Equal(t, "develop", cfg.Docs.DefaultBranch)
+	require.Equal(t, []string{"README.md", "index.md"}, cfg.Docs.Filenames)
+}
+
+// TestLoad_DocsSection_ParsesJobLimits verifies job concurrency, timeout and diff budget settings
+func TestLoad_DocsSection_ParsesJobLimits(t *testing.T) {
+	content := `
+[docs]
+max_jobs = 2
+job_timeout = "90s"
+diff_token_budget = 1500
+auto_commit = true
+commit_message = "docs: refresh"
+`
+
+	fs := afero.NewMemMapFs()
+	configPath := "/test/.claudex/config.toml"
+
+	err := afero.WriteFile(fs, configPath, []byte(content), 0644)
+	require.NoError(t, err)
+
+	cfg, err := Load(fs, configPath)
+	require.NoError(t, err)
+
+	require.Equal(t, 2, cfg.Docs.MaxJobs)
+	require.Equal(t, 1500, cfg.Docs.DiffTokenBudget)
+	require.True(t, cfg.Docs.AutoCommit)
+	require.Equal(t, "docs: refresh", cfg.Docs.CommitMessage)
+	timeout, err := cfg.Docs.JobTimeoutDuration()
+	require.NoError(t, err)
+	require.Equal(t, 90*time.Second, timeout)
+
+	_, err = Docs{JobTimeout: "soon"}.JobTimeoutDuration()
+	require.Error(t, err)
+}
+
+// TestLoad_LocksSection_ParsesTTLAndFlock verifies stale lock settings
+func TestLoad_LocksSection_ParsesTTLAndFlock(t *testing.T) {
+	content := `
+[locks]
+ttl = "30m"
+flock = true
+`
+
+	fs := afero.NewMemMapFs()
+	configPath := "/test/.claudex/config.toml"
+
+	err := afero.WriteFile(fs, configPath, []byte(content), 0644)
+	require.NoError(t, err)
+
+	cfg, err := Load(fs, configPath)
+	require.NoError(t, err)
+
+	require.True(t, cfg.Locks.Flock)
+	ttl, err := cfg.Locks.TTLDuration()
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Minute, ttl)
+
+	ttl, err = Locks{}.TTLDuration()
+	require.NoError(t, err)
+	require.Zero(t, ttl, "empty ttl should use the default")
+
+	ttl, err = Locks{TTL: "0"}.TTLDuration()
+	require.NoError(t, err)
+	require.Negative(t, ttl, "zero ttl should disable expiry")
+
+	_, err = Locks{TTL: "later"}.TTLDuration()
+	require.Error(t, err)
+}
+
+// TestLoad_SessionsSection_ParsesConflictWindow verifies the conflict window setting
+func TestLoad_SessionsSection_ParsesConflictWindow(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	configPath := "/test/.claudex/config.toml"
+	require.NoError(t, afero.WriteFile(fs, configPath, []byte("[sessions]\nconflict_window = \"10m\"\n"), 0644))
+
+	cfg, err := Load(fs, configPath)
+	require.NoError(t, err)
+	require.Equal(t, "10m", cfg.Sessions.ConflictWindow)
 }
 
 // TestLoad_MalformedTOML_ReturnsError verifies malformed TOML returns an error
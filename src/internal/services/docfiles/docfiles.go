@@ -0,0 +1,89 @@
+// Package docfiles defines which file names count as a directory's
+// documentation index (index.md, README.md, CLAUDE.md, AGENTS.md, ...).
+// Names are ordered by priority: when a directory has several, the first
+// configured name wins, and new indexes are created with the first name.
+package docfiles
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// DefaultName is the documentation file name used when none is configured
+const DefaultName = "index.md"
+
+// EnvVar carries the configured names to hook processes, separated by ":"
+const EnvVar = "CLAUDEX_DOC_FILENAMES"
+
+// Names is a priority-ordered list of documentation file names
+type Names []string
+
+// Default returns the default name list
+func Default() Names {
+	return Names{DefaultName}
+}
+
+// New normalizes configured names: blanks and duplicates are dropped and an
+// empty list falls back to Default
+func New(names []string) Names {
+	var result Names
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	if len(result) == 0 {
+		return Default()
+	}
+	return result
+}
+
+// FromEnv reads names from the EnvVar format (":"-separated)
+func FromEnv(value string) Names {
+	return New(strings.Split(value, ":"))
+}
+
+// Env renders names in the EnvVar format
+func (n Names) Env() string {
+	return strings.Join(n, ":")
+}
+
+// Primary returns the name used when creating a new documentation file
+func (n Names) Primary() string {
+	if len(n) == 0 {
+		return DefaultName
+	}
+	return n[0]
+}
+
+// Match reports whether a file name (not a path) is a documentation file name
+func (n Names) Match(name string) bool {
+	for _, candidate := range n {
+		if name == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// Find returns the highest-priority documentation file in dir, or "" if none exists
+func (n Names) Find(fs afero.Fs, dir string) string {
+	for _, name := range n {
+		path := filepath.Join(dir, name)
+		if info, err := fs.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// String lists the names for prompts and messages, e.g. "README.md, index.md"
+func (n Names) String() string {
+	return strings.Join(n, ", ")
+}
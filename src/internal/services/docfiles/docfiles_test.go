@@ -0,0 +1,30 @@
+package docfiles
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_NormalizesNames(t *testing.T) {
+	assert.Equal(t, Names{"README.md", "index.md"}, New([]string{" README.md", "", "index.md", "README.md"}))
+	assert.Equal(t, Default(), New(nil))
+	assert.Equal(t, Names{"AGENTS.md", "CLAUDE.md"}, FromEnv("AGENTS.md:CLAUDE.md"))
+	assert.Equal(t, Default(), FromEnv(""))
+}
+
+func TestNames_FindUsesPriority(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "/pkg/index.md", []byte("# pkg"), 0644)
+	afero.WriteFile(fs, "/pkg/README.md", []byte("# pkg"), 0644)
+	fs.MkdirAll("/other/CLAUDE.md", 0755)
+
+	names := New([]string{"README.md", "index.md"})
+	assert.Equal(t, "/pkg/README.md", names.Find(fs, "/pkg"))
+	assert.Equal(t, "/pkg/index.md", New([]string{"index.md", "README.md"}).Find(fs, "/pkg"))
+	assert.Empty(t, New([]string{"CLAUDE.md"}).Find(fs, "/other"), "directories are not doc files")
+	assert.Equal(t, "README.md", names.Primary())
+	assert.True(t, names.Match("index.md"))
+	assert.False(t, names.Match("AGENTS.md"))
+}
@@ -0,0 +1,256 @@
+// Package docmap builds a lightweight map of a project's documentation tree
+// (the per-directory index.md files): titles, headings, links and a one-line
+// summary per file, cached in .claudex/docmap.json and reparsed only when a
+// file's mtime or size changes. Search ranks the files against free text with
+// BM25 so agents can be pointed at the docs relevant to their task.
+package docmap
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"claudex/internal/services/docfiles"
+	"claudex/internal/services/ignore"
+	"claudex/internal/services/paths"
+
+	"github.com/spf13/afero"
+)
+
+// CacheFileName is the cache file, under .claudex/
+const CacheFileName = "docmap.json"
+
+// cacheVersion invalidates caches written by older parsers
+const cacheVersion = 1
+
+// maxSummary bounds the one-line summary, in bytes
+const maxSummary = 160
+
+// Field weights: a term in the title counts as much as three in the body
+const (
+	titleWeight   = 3
+	headingWeight = 2
+	pathWeight    = 2
+)
+
+// Doc is one documentation file in the map
+type Doc struct {
+	// Path is relative to the project directory
+	Path     string    `json:"path"`
+	ModTime  time.Time `json:"mod_time"`
+	Size     int64     `json:"size"`
+	Title    string    `json:"title"`
+	Summary  string    `json:"summary"`
+	Headings []string  `json:"headings,omitempty"`
+
+	// Links are the relative markdown link targets, resolved against the project
+	Links []string `json:"links,omitempty"`
+
+	// Terms are weighted term frequencies; Length is their sum
+	Terms  map[string]int `json:"terms"`
+	Length int            `json:"length"`
+}
+
+// Map is the documentation map of a project
+type Map struct {
+	Version int   `json:"version"`
+	Docs    []Doc `json:"docs"`
+}
+
+// CachePath returns the cache file path for a project
+func CachePath(projectDir string) string {
+	return filepath.Join(projectDir, paths.ClaudexDir, CacheFileName)
+}
+
+// Build maps the documentation files named by names under projectDir,
+// skipping ignored paths and .claudex. Entries whose mtime and size are
+// unchanged come from the cache, which is rewritten when anything changed.
+func Build(fs afero.Fs, projectDir string, names docfiles.Names) (*Map, error) {
+	cached := make(map[string]Doc)
+	if old, err := loadCache(fs, projectDir); err == nil {
+		for _, d := range old.Docs {
+			cached[d.Path] = d
+		}
+	}
+
+	m := &Map{Version: cacheVersion}
+	changed := false
+	claudexDir := filepath.Join(projectDir, paths.ClaudexDir)
+	err := ignore.Walk(fs, projectDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path == claudexDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !names.Match(info.Name()) {
+			return nil
+		}
+		rel, err := filepath.Rel(projectDir, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d, ok := cached[rel]; ok && d.ModTime.Equal(info.ModTime()) && d.Size == info.Size() {
+			m.Docs = append(m.Docs, d)
+			delete(cached, rel)
+			return nil
+		}
+		data, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return nil
+		}
+		d := Parse(rel, data)
+		d.ModTime = info.ModTime()
+		d.Size = info.Size()
+		m.Docs = append(m.Docs, d)
+		delete(cached, rel)
+		changed = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Entries left in the cache belong to deleted files
+	if changed || len(cached) > 0 {
+		_ = saveCache(fs, projectDir, m)
+	}
+	return m, nil
+}
+
+// loadCache reads the cache, rejecting other versions
+func loadCache(fs afero.Fs, projectDir string) (*Map, error) {
+	data, err := afero.ReadFile(fs, CachePath(projectDir))
+	if err != nil {
+		return nil, err
+	}
+	var m Map
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if m.Version != cacheVersion {
+		return nil, os.ErrNotExist
+	}
+	return &m, nil
+}
+
+// saveCache writes the cache atomically. Hooks running in parallel each
+// write their own temporary file next to the cache and rename it into place.
+func saveCache(fs afero.Fs, projectDir string, m *Map) error {
+	path := CachePath(projectDir)
+	if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	file, err := afero.TempFile(fs, filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = fs.Rename(file.Name(), path)
+	}
+	if err != nil {
+		fs.Remove(file.Name())
+	}
+	return err
+}
+
+// linkPattern matches markdown links, capturing the text and the target
+var linkPattern = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]+)[^)]*\)`)
+
+// Parse extracts the title, headings, links, summary and terms of a doc
+// file; rel is its project-relative path
+func Parse(rel string, data []byte) Doc {
+	d := Doc{Path: rel, Terms: make(map[string]int)}
+	add := func(text string, weight int) {
+		for _, term := range Tokenize(text) {
+			d.Terms[term] += weight
+			d.Length += weight
+		}
+	}
+	add(strings.ReplaceAll(filepath.Dir(rel), "/", " "), pathWeight)
+
+	inFence := false
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			heading := strings.TrimSpace(strings.TrimLeft(line, "#"))
+			if d.Title == "" && strings.HasPrefix(line, "# ") {
+				d.Title = heading
+				add(heading, titleWeight)
+			} else {
+				d.Headings = append(d.Headings, heading)
+				add(heading, headingWeight)
+			}
+			continue
+		}
+
+		for _, match := range linkPattern.FindAllStringSubmatch(line, -1) {
+			if target := resolveLink(rel, match[2]); target != "" {
+				d.Links = append(d.Links, target)
+			}
+		}
+		text := linkPattern.ReplaceAllString(line, "$1")
+		add(text, 1)
+		if d.Summary == "" && !strings.HasPrefix(line, "|") {
+			d.Summary = summarize(text)
+		}
+	}
+	return d
+}
+
+// resolveLink resolves a relative markdown link against the doc's directory;
+// external links and anchors return ""
+func resolveLink(rel, target string) string {
+	if strings.Contains(target, "://") || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "mailto:") {
+		return ""
+	}
+	if i := strings.IndexByte(target, '#'); i >= 0 {
+		target = target[:i]
+	}
+	if target == "" || filepath.IsAbs(target) {
+		return ""
+	}
+	return filepath.ToSlash(filepath.Join(filepath.Dir(rel), target))
+}
+
+// summarize turns a markdown line into plain one-line text
+func summarize(line string) string {
+	line = strings.TrimLeft(line, "-*+> ")
+	line = strings.NewReplacer("**", "", "__", "", "`", "").Replace(line)
+	line = strings.TrimSpace(line)
+	if len(line) > maxSummary {
+		cut := strings.LastIndexByte(line[:maxSummary], ' ')
+		if cut <= 0 {
+			cut = maxSummary
+		}
+		line = strings.TrimRight(line[:cut], " ,;:-") + "..."
+	}
+	return line
+}
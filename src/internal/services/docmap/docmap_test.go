@@ -0,0 +1,156 @@
+package docmap
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"claudex/internal/services/docfiles"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newProject writes a small documented project
+func newProject(t *testing.T) afero.Fs {
+	t.Helper()
+	fs := afero.NewMemMapFs()
+	files := map[string]string{
+		"/repo/index.md": "# Project\n\nCLI that manages sessions.\n\n- [hooks](internal/hooks/index.md)\n- [services](internal/services/index.md)\n",
+		"/repo/internal/hooks/index.md": "# Hooks\n\nClaude Code hook handlers for tool calls.\n\n## PreToolUse\n\nPolicy checks and context injection.\n\n" +
+			"```\n# not a heading\n```\n",
+		"/repo/internal/services/index.md":      "# Services\n\nReusable services: git, locks, sessions.\n\n## Locking\n\nStale lock recovery with PID liveness.\n",
+		"/repo/internal/services/lock/index.md": "# Lock\n\nFile-based cross-process locking.\n",
+		"/repo/internal/services/README.md":     "# Not a doc file by default\n",
+		"/repo/node_modules/pkg/index.md":       "# Vendored\n",
+		"/repo/.claudex/sessions/s1/index.md":   "# Session notes\n",
+	}
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(fs, path, []byte(content), 0644))
+	}
+	return fs
+}
+
+// docPaths lists the mapped paths
+func docPaths(m *Map) []string {
+	var paths []string
+	for _, d := range m.Docs {
+		paths = append(paths, d.Path)
+	}
+	return paths
+}
+
+func TestBuild_FindsDocFiles(t *testing.T) {
+	fs := newProject(t)
+
+	m, err := Build(fs, "/repo", docfiles.Default())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"index.md",
+		"internal/hooks/index.md",
+		"internal/services/index.md",
+		"internal/services/lock/index.md",
+	}, docPaths(m))
+
+	// Configured names replace index.md
+	m, err = Build(fs, "/repo", docfiles.Names{"README.md"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"internal/services/README.md"}, docPaths(m))
+
+	// A project without docs maps nothing
+	empty := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(empty, "/other/main.go", []byte("package main"), 0644))
+	m, err = Build(empty, "/other", docfiles.Default())
+	require.NoError(t, err)
+	assert.Empty(t, m.Docs)
+}
+
+func TestBuild_CacheInvalidatedByMtime(t *testing.T) {
+	fs := newProject(t)
+	_, err := Build(fs, "/repo", docfiles.Default())
+	require.NoError(t, err)
+
+	// Tamper with the cached title: unchanged files are served from the cache
+	data, err := afero.ReadFile(fs, CachePath("/repo"))
+	require.NoError(t, err)
+	var cached Map
+	require.NoError(t, json.Unmarshal(data, &cached))
+	for i := range cached.Docs {
+		cached.Docs[i].Title = "cached " + cached.Docs[i].Title
+	}
+	data, err = json.Marshal(cached)
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, CachePath("/repo"), data, 0644))
+
+	// Touch one file and delete another
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, fs.Chtimes("/repo/internal/hooks/index.md", later, later))
+	require.NoError(t, fs.Remove("/repo/internal/services/lock/index.md"))
+
+	m, err := Build(fs, "/repo", docfiles.Default())
+	require.NoError(t, err)
+	titles := make(map[string]string)
+	for _, d := range m.Docs {
+		titles[d.Path] = d.Title
+	}
+	assert.Equal(t, map[string]string{
+		"index.md":                   "cached Project",
+		"internal/hooks/index.md":    "Hooks",
+		"internal/services/index.md": "cached Services",
+	}, titles)
+
+	// The rewritten cache drops the deleted file
+	reloaded, err := loadCache(fs, "/repo")
+	require.NoError(t, err)
+	assert.Len(t, reloaded.Docs, 3)
+}
+
+func TestSaveCache_ParallelWriters(t *testing.T) {
+	fs := afero.NewOsFs()
+	projectDir := t.TempDir()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m := &Map{Version: cacheVersion, Docs: []Doc{{Path: strings.Repeat("doc/", i+1) + "index.md"}}}
+			assert.NoError(t, saveCache(fs, projectDir, m))
+		}(i)
+	}
+	wg.Wait()
+
+	// The cache is one writer's complete map, and no temporary file is left
+	m, err := loadCache(fs, projectDir)
+	require.NoError(t, err)
+	assert.Len(t, m.Docs, 1)
+	entries, err := afero.ReadDir(fs, filepath.Dir(CachePath(projectDir)))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, filepath.Base(CachePath(projectDir)), entries[0].Name())
+}
+
+func TestParse(t *testing.T) {
+	d := Parse("internal/hooks/index.md", []byte("# Hooks\n\n"+
+		"**Claude Code** hook handlers for `tool` calls, see [the policy](../services/policy.md#rules) and [site](https://example.com).\n\n"+
+		"## PreToolUse\n\n```go\n## not a heading\n```\n\n### Details\n"))
+
+	assert.Equal(t, "Hooks", d.Title)
+	assert.Equal(t, []string{"PreToolUse", "Details"}, d.Headings)
+	assert.Equal(t, []string{"internal/services/policy.md"}, d.Links)
+	assert.Equal(t, "Claude Code hook handlers for tool calls, see the policy and site.", d.Summary)
+	assert.Equal(t, pathWeight+titleWeight+1, d.Terms["hook"], "path, title and one body occurrence")
+	assert.Equal(t, pathWeight, d.Terms["internal"])
+	assert.Zero(t, d.Terms["heading"])
+}
+
+func TestParse_LongSummary(t *testing.T) {
+	d := Parse("index.md", []byte("# T\n\n"+
+		"This summary keeps going well past the limit because it describes every single module of the project in one very long sentence without stopping, then keeps adding more and more words.\n"))
+	assert.LessOrEqual(t, len(d.Summary), maxSummary+3)
+	assert.Equal(t, "...", d.Summary[len(d.Summary)-3:])
+}
@@ -0,0 +1,146 @@
+package docmap
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// BM25 parameters: term frequency saturation and length normalization
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// stopWords are dropped from documents and queries
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "can": true, "do": true, "for": true, "from": true, "how": true, "in": true,
+	"into": true, "is": true, "it": true, "its": true, "of": true, "on": true, "or": true,
+	"our": true, "should": true, "so": true, "that": true, "the": true, "their": true,
+	"then": true, "there": true, "these": true, "this": true, "to": true, "use": true,
+	"was": true, "we": true, "what": true, "when": true, "where": true, "which": true,
+	"will": true, "with": true, "you": true, "your": true, "md": true, "index": true,
+}
+
+// Match is a doc ranked against a query
+type Match struct {
+	Path    string
+	Title   string
+	Summary string
+	Score   float64
+}
+
+// Search ranks the docs against query with BM25 and returns at most n
+// matches with a positive score, best first
+func (m *Map) Search(query string, n int) []Match {
+	if m == nil || len(m.Docs) == 0 || n <= 0 {
+		return nil
+	}
+
+	terms := make(map[string]bool)
+	for _, term := range Tokenize(query) {
+		terms[term] = true
+	}
+	if len(terms) == 0 {
+		return nil
+	}
+
+	total := 0
+	df := make(map[string]int)
+	for _, d := range m.Docs {
+		total += d.Length
+		for term := range terms {
+			if d.Terms[term] > 0 {
+				df[term]++
+			}
+		}
+	}
+	avgLength := float64(total) / float64(len(m.Docs))
+	if avgLength == 0 {
+		return nil
+	}
+
+	count := float64(len(m.Docs))
+	var matches []Match
+	for _, d := range m.Docs {
+		score := 0.0
+		for term := range terms {
+			tf := float64(d.Terms[term])
+			if tf == 0 {
+				continue
+			}
+			idf := math.Log(1 + (count-float64(df[term])+0.5)/(float64(df[term])+0.5))
+			norm := bm25K1 * (1 - bm25B + bm25B*float64(d.Length)/avgLength)
+			score += idf * tf * (bm25K1 + 1) / (tf + norm)
+		}
+		if score > 0 {
+			matches = append(matches, Match{Path: d.Path, Title: d.Title, Summary: d.Summary, Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Path < matches[j].Path
+	})
+	if len(matches) > n {
+		matches = matches[:n]
+	}
+	return matches
+}
+
+// Tokenize lowercases text and splits it into terms: words are split on
+// non-alphanumerics and camelCase boundaries, stop words and single
+// characters are dropped and plurals are reduced
+func Tokenize(text string) []string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		parts := splitCamel(word)
+		if len(parts) > 1 {
+			parts = append(parts, word)
+		}
+		for _, part := range parts {
+			if term := normalize(part); term != "" {
+				terms = append(terms, term)
+			}
+		}
+	}
+	return terms
+}
+
+// splitCamel splits camelCase and PascalCase words ("parseHTTPRequest" gives
+// parse, HTTP, Request)
+func splitCamel(word string) []string {
+	runes := []rune(word)
+	var parts []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		lowerToUpper := unicode.IsLower(runes[i-1]) && unicode.IsUpper(runes[i])
+		acronymEnd := i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i+1])
+		if lowerToUpper || acronymEnd {
+			parts = append(parts, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(parts, string(runes[start:]))
+}
+
+// normalize lowercases a word, drops stop words and strips plural endings
+func normalize(word string) string {
+	word = strings.ToLower(word)
+	if len(word) < 2 || stopWords[word] {
+		return ""
+	}
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		word = word[:len(word)-3] + "y"
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us"):
+		word = word[:len(word)-1]
+	}
+	return word
+}
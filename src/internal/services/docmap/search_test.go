@@ -0,0 +1,37 @@
+package docmap
+
+import (
+	"testing"
+
+	"claudex/internal/services/docfiles"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch_RanksRelevantDocs(t *testing.T) {
+	m, err := Build(newProject(t), "/repo", docfiles.Default())
+	require.NoError(t, err)
+
+	matches := m.Search("Fix stale locks left by crashed processes", 2)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "internal/services/index.md", matches[0].Path)
+	assert.Equal(t, "internal/services/lock/index.md", matches[1].Path)
+	assert.Equal(t, "Reusable services: git, locks, sessions.", matches[0].Summary)
+	assert.Greater(t, matches[0].Score, matches[1].Score)
+
+	matches = m.Search("Add a new PreToolUse hook", 5)
+	require.NotEmpty(t, matches)
+	assert.Equal(t, "internal/hooks/index.md", matches[0].Path)
+
+	// Nothing relevant, or nothing to search for
+	assert.Empty(t, m.Search("kubernetes helm charts", 5))
+	assert.Empty(t, m.Search("the and of", 5))
+	assert.Empty(t, (*Map)(nil).Search("locks", 5))
+}
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t,
+		[]string{"parse", "http", "request", "parsehttprequest", "session", "lock", "library", "status", "x1"},
+		Tokenize("parseHTTPRequest: the Sessions' locks, libraries and status a x1"))
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/spf13/afero"
@@ -11,9 +12,24 @@ import (
 
 const (
 	trackingFileName = "doc_update_tracking.json"
-	strategyVersion  = "v1"
+
+	// StrategyVersion is the current tracking format: entries keyed by branch
+	StrategyVersion = "v2"
+
+	// legacyStrategyVersion is the single-branch format migrated on first read
+	legacyStrategyVersion = "v1"
 )
 
+// trackingFile is the on-disk layout of the tracking file
+type trackingFile struct {
+	StrategyVersion string                       `json:"strategy_version"`
+	Branches        map[string]DocUpdateTracking `json:"branches"`
+
+	// LastProcessedCommit and UpdatedAt are only present in v1 files
+	LastProcessedCommit string `json:"last_processed_commit,omitempty"`
+	UpdatedAt           string `json:"updated_at,omitempty"`
+}
+
 // FileTrackingService is the production implementation of TrackingService
 type FileTrackingService struct {
 	fs          afero.Fs
@@ -28,34 +44,126 @@ func New(fs afero.Fs, sessionPath string) TrackingService {
 	}
 }
 
-// Read loads the current tracking state from storage
-func (fts *FileTrackingService) Read() (DocUpdateTracking, error) {
+// Read loads the tracking state for the given branch
+func (fts *FileTrackingService) Read(branch string) (DocUpdateTracking, error) {
+	file, err := fts.load()
+	if err != nil {
+		return DocUpdateTracking{}, err
+	}
+
+	// Migrate v1: the single legacy entry belongs to the first branch that asks for it
+	if legacy, ok := legacyEntry(file); ok {
+		file = &trackingFile{
+			StrategyVersion: StrategyVersion,
+			Branches:        map[string]DocUpdateTracking{branch: legacy},
+		}
+		if err := fts.save(file); err != nil {
+			return DocUpdateTracking{}, err
+		}
+	}
+
+	// Zero value if the branch has no entry yet
+	return file.Branches[branch], nil
+}
+
+// ReadAll loads the tracking state for every tracked branch
+func (fts *FileTrackingService) ReadAll() (map[string]DocUpdateTracking, error) {
+	file, err := fts.load()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]DocUpdateTracking, len(file.Branches))
+	for branch, tracking := range file.Branches {
+		result[branch] = tracking
+	}
+	return result, nil
+}
+
+// Write persists the tracking state for the given branch atomically
+func (fts *FileTrackingService) Write(branch string, tracking DocUpdateTracking) error {
+	file, err := fts.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := legacyEntry(file); ok {
+		// Writing supersedes the legacy entry; the branch now owns the state
+		file = &trackingFile{Branches: make(map[string]DocUpdateTracking)}
+	}
+
+	file.Branches[branch] = tracking
+	return fts.save(file)
+}
+
+// Initialize creates initial tracking state for the branch with HEAD commit
+func (fts *FileTrackingService) Initialize(branch, headSHA string) error {
+	tracking := DocUpdateTracking{
+		LastProcessedCommit: headSHA,
+		UpdatedAt:           time.Now().Format(time.RFC3339),
+		StrategyVersion:     StrategyVersion,
+	}
+
+	return fts.Write(branch, tracking)
+}
+
+// Prune removes entries for branches not listed in liveBranches
+func (fts *FileTrackingService) Prune(liveBranches []string) ([]string, error) {
+	file, err := fts.load()
+	if err != nil {
+		return nil, err
+	}
+
+	live := make(map[string]bool, len(liveBranches))
+	for _, b := range liveBranches {
+		live[b] = true
+	}
+
+	var removed []string
+	for branch := range file.Branches {
+		if !live[branch] {
+			delete(file.Branches, branch)
+			removed = append(removed, branch)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	sort.Strings(removed)
+
+	return removed, fts.save(file)
+}
+
+// load reads the tracking file, returning an empty v2 layout if it doesn't exist
+func (fts *FileTrackingService) load() (*trackingFile, error) {
 	trackingPath := filepath.Join(fts.sessionPath, trackingFileName)
 
+	file := &trackingFile{}
 	data, err := afero.ReadFile(fts.fs, trackingPath)
 	if err != nil {
-		if os.IsNotExist(err) {
-			// Return zero value if file doesn't exist
-			return DocUpdateTracking{}, nil
+		if !os.IsNotExist(err) {
+			return nil, err
 		}
-		return DocUpdateTracking{}, err
+	} else if err := json.Unmarshal(data, file); err != nil {
+		return nil, err
 	}
 
-	var tracking DocUpdateTracking
-	if err := json.Unmarshal(data, &tracking); err != nil {
-		return DocUpdateTracking{}, err
+	if file.Branches == nil {
+		file.Branches = make(map[string]DocUpdateTracking)
 	}
-
-	return tracking, nil
+	return file, nil
 }
 
-// Write persists the tracking state to storage atomically
-func (fts *FileTrackingService) Write(tracking DocUpdateTracking) error {
+// save persists the tracking file atomically in the current format
+func (fts *FileTrackingService) save(file *trackingFile) error {
 	trackingPath := filepath.Join(fts.sessionPath, trackingFileName)
 	tempPath := trackingPath + ".tmp"
 
+	file.StrategyVersion = StrategyVersion
+	file.LastProcessedCommit = ""
+	file.UpdatedAt = ""
+
 	// Marshal to JSON with indentation for readability
-	data, err := json.MarshalIndent(tracking, "", "  ")
+	data, err := json.MarshalIndent(file, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -69,13 +177,14 @@ func (fts *FileTrackingService) Write(tracking DocUpdateTracking) error {
 	return fts.fs.Rename(tempPath, trackingPath)
 }
 
-// Initialize creates initial tracking state with HEAD commit
-func (fts *FileTrackingService) Initialize(headSHA string) error {
-	tracking := DocUpdateTracking{
-		LastProcessedCommit: headSHA,
-		UpdatedAt:           time.Now().Format(time.RFC3339),
-		StrategyVersion:     strategyVersion,
+// legacyEntry extracts the single-branch state from a v1 tracking file
+func legacyEntry(file *trackingFile) (DocUpdateTracking, bool) {
+	if file.LastProcessedCommit == "" {
+		return DocUpdateTracking{}, false
 	}
-
-	return fts.Write(tracking)
+	return DocUpdateTracking{
+		LastProcessedCommit: file.LastProcessedCommit,
+		UpdatedAt:           file.UpdatedAt,
+		StrategyVersion:     legacyStrategyVersion,
+	}, true
 }
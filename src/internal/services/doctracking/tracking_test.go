@@ -18,7 +18,7 @@ func TestFileTrackingService_Read_MissingFile(t *testing.T) {
 	service := New(fs, sessionPath)
 
 	// Execute
-	tracking, err := service.Read()
+	tracking, err := service.Read("main")
 
 	// Verify
 	require.NoError(t, err)
@@ -39,7 +39,10 @@ func TestFileTrackingService_Read_ValidJSON(t *testing.T) {
 		StrategyVersion:     "v1",
 	}
 
-	data, err := json.Marshal(expectedTracking)
+	data, err := json.Marshal(trackingFile{
+		StrategyVersion: StrategyVersion,
+		Branches:        map[string]DocUpdateTracking{"main": expectedTracking},
+	})
 	require.NoError(t, err)
 
 	trackingPath := filepath.Join(sessionPath, trackingFileName)
@@ -48,7 +51,7 @@ func TestFileTrackingService_Read_ValidJSON(t *testing.T) {
 	service := New(fs, sessionPath)
 
 	// Execute
-	tracking, err := service.Read()
+	tracking, err := service.Read("main")
 
 	// Verify
 	require.NoError(t, err)
@@ -69,7 +72,7 @@ func TestFileTrackingService_Read_InvalidJSON(t *testing.T) {
 	service := New(fs, sessionPath)
 
 	// Execute
-	_, err := service.Read()
+	_, err := service.Read("main")
 
 	// Verify
 	require.Error(t, err)
@@ -90,7 +93,7 @@ func TestFileTrackingService_Write_CreatesFile(t *testing.T) {
 	}
 
 	// Execute
-	err := service.Write(tracking)
+	err := service.Write("main", tracking)
 
 	// Verify
 	require.NoError(t, err)
@@ -105,8 +108,10 @@ func TestFileTrackingService_Write_CreatesFile(t *testing.T) {
 	data, err := afero.ReadFile(fs, trackingPath)
 	require.NoError(t, err)
 
-	var readTracking DocUpdateTracking
-	require.NoError(t, json.Unmarshal(data, &readTracking))
+	var file trackingFile
+	require.NoError(t, json.Unmarshal(data, &file))
+	assert.Equal(t, StrategyVersion, file.StrategyVersion)
+	readTracking := file.Branches["main"]
 	assert.Equal(t, tracking.LastProcessedCommit, readTracking.LastProcessedCommit)
 	assert.Equal(t, tracking.UpdatedAt, readTracking.UpdatedAt)
 	assert.Equal(t, tracking.StrategyVersion, readTracking.StrategyVersion)
@@ -126,7 +131,7 @@ func TestFileTrackingService_Write_UpdatesFile(t *testing.T) {
 		UpdatedAt:           "2025-12-13T09:00:00Z",
 		StrategyVersion:     "v1",
 	}
-	require.NoError(t, service.Write(initialTracking))
+	require.NoError(t, service.Write("main", initialTracking))
 
 	// Update tracking
 	updatedTracking := DocUpdateTracking{
@@ -136,13 +141,13 @@ func TestFileTrackingService_Write_UpdatesFile(t *testing.T) {
 	}
 
 	// Execute
-	err := service.Write(updatedTracking)
+	err := service.Write("main", updatedTracking)
 
 	// Verify
 	require.NoError(t, err)
 
 	// Verify updated content
-	readTracking, err := service.Read()
+	readTracking, err := service.Read("main")
 	require.NoError(t, err)
 	assert.Equal(t, updatedTracking.LastProcessedCommit, readTracking.LastProcessedCommit)
 	assert.Equal(t, updatedTracking.UpdatedAt, readTracking.UpdatedAt)
@@ -164,7 +169,7 @@ func TestFileTrackingService_Write_NoTempFileRemains(t *testing.T) {
 	}
 
 	// Execute
-	err := service.Write(tracking)
+	err := service.Write("main", tracking)
 	require.NoError(t, err)
 
 	// Verify temp file doesn't exist
@@ -215,11 +220,11 @@ func TestFileTrackingService_JSONRoundTrip(t *testing.T) {
 			service := New(fs, sessionPath)
 
 			// Execute write
-			err := service.Write(tt.tracking)
+			err := service.Write("main", tt.tracking)
 			require.NoError(t, err)
 
 			// Execute read
-			readTracking, err := service.Read()
+			readTracking, err := service.Read("main")
 			require.NoError(t, err)
 
 			// Verify
@@ -240,17 +245,17 @@ func TestFileTrackingService_Initialize(t *testing.T) {
 	headSHA := "abc123def456"
 
 	// Execute
-	err := service.Initialize(headSHA)
+	err := service.Initialize("main", headSHA)
 
 	// Verify
 	require.NoError(t, err)
 
 	// Read and verify created tracking
-	tracking, err := service.Read()
+	tracking, err := service.Read("main")
 	require.NoError(t, err)
 
 	assert.Equal(t, headSHA, tracking.LastProcessedCommit)
-	assert.Equal(t, "v1", tracking.StrategyVersion)
+	assert.Equal(t, StrategyVersion, tracking.StrategyVersion)
 
 	// Verify timestamp is valid RFC3339
 	_, err = time.Parse(time.RFC3339, tracking.UpdatedAt)
@@ -272,19 +277,127 @@ func TestFileTrackingService_Initialize_OverwritesExisting(t *testing.T) {
 
 	// Create initial tracking
 	initialSHA := "initial123"
-	require.NoError(t, service.Initialize(initialSHA))
+	require.NoError(t, service.Initialize("main", initialSHA))
 
 	// Wait a bit to ensure different timestamp
 	time.Sleep(10 * time.Millisecond)
 
 	// Initialize with new SHA
 	newSHA := "new456"
-	err := service.Initialize(newSHA)
+	err := service.Initialize("main", newSHA)
 	require.NoError(t, err)
 
 	// Verify
-	tracking, err := service.Read()
+	tracking, err := service.Read("main")
 	require.NoError(t, err)
 	assert.Equal(t, newSHA, tracking.LastProcessedCommit)
-	assert.Equal(t, "v1", tracking.StrategyVersion)
+	assert.Equal(t, StrategyVersion, tracking.StrategyVersion)
+}
+
+func TestFileTrackingService_Branches_AreIndependent(t *testing.T) {
+	// Setup
+	fs := afero.NewMemMapFs()
+	sessionPath := "/test/session"
+	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
+
+	service := New(fs, sessionPath)
+
+	// Execute
+	require.NoError(t, service.Initialize("main", "main123"))
+	require.NoError(t, service.Initialize("feature/x", "feat456"))
+
+	// Verify
+	mainTracking, err := service.Read("main")
+	require.NoError(t, err)
+	assert.Equal(t, "main123", mainTracking.LastProcessedCommit)
+
+	featureTracking, err := service.Read("feature/x")
+	require.NoError(t, err)
+	assert.Equal(t, "feat456", featureTracking.LastProcessedCommit)
+
+	unknown, err := service.Read("other")
+	require.NoError(t, err)
+	assert.Equal(t, "", unknown.LastProcessedCommit)
+
+	all, err := service.ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+}
+
+func TestFileTrackingService_Read_MigratesV1(t *testing.T) {
+	// Setup
+	fs := afero.NewMemMapFs()
+	sessionPath := "/test/session"
+	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
+
+	legacy := `{"last_processed_commit": "legacy123", "updated_at": "2025-12-13T10:00:00Z", "strategy_version": "v1"}`
+	trackingPath := filepath.Join(sessionPath, trackingFileName)
+	require.NoError(t, afero.WriteFile(fs, trackingPath, []byte(legacy), 0644))
+
+	service := New(fs, sessionPath)
+
+	// Execute
+	tracking, err := service.Read("develop")
+
+	// Verify - legacy state is attributed to the reading branch
+	require.NoError(t, err)
+	assert.Equal(t, "legacy123", tracking.LastProcessedCommit)
+	assert.Equal(t, "2025-12-13T10:00:00Z", tracking.UpdatedAt)
+
+	// Verify - file was rewritten in the v2 layout
+	data, err := afero.ReadFile(fs, trackingPath)
+	require.NoError(t, err)
+
+	var file trackingFile
+	require.NoError(t, json.Unmarshal(data, &file))
+	assert.Equal(t, StrategyVersion, file.StrategyVersion)
+	assert.Equal(t, "", file.LastProcessedCommit)
+	assert.Equal(t, "legacy123", file.Branches["develop"].LastProcessedCommit)
+
+	// Verify - other branches don't inherit the migrated state
+	other, err := service.Read("main")
+	require.NoError(t, err)
+	assert.Equal(t, "", other.LastProcessedCommit)
+}
+
+func TestFileTrackingService_Prune_RemovesDeletedBranches(t *testing.T) {
+	// Setup
+	fs := afero.NewMemMapFs()
+	sessionPath := "/test/session"
+	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
+
+	service := New(fs, sessionPath)
+	require.NoError(t, service.Initialize("main", "aaa"))
+	require.NoError(t, service.Initialize("feature/gone", "bbb"))
+	require.NoError(t, service.Initialize("hotfix", "ccc"))
+
+	// Execute
+	removed, err := service.Prune([]string{"main", "hotfix"})
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, []string{"feature/gone"}, removed)
+
+	all, err := service.ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+	assert.Contains(t, all, "main")
+	assert.Contains(t, all, "hotfix")
+}
+
+func TestFileTrackingService_Prune_NothingToRemove(t *testing.T) {
+	// Setup
+	fs := afero.NewMemMapFs()
+	sessionPath := "/test/session"
+	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
+
+	service := New(fs, sessionPath)
+	require.NoError(t, service.Initialize("main", "aaa"))
+
+	// Execute
+	removed, err := service.Prune([]string{"main"})
+
+	// Verify
+	require.NoError(t, err)
+	assert.Empty(t, removed)
 }
@@ -1,8 +1,9 @@
 // Package doctracking provides tracking services for documentation update state.
-// It manages persistent state for the last processed commit and strategy version.
+// It manages persistent per-branch state for the last processed commit, the
+// commits considered in that update, and the strategy version.
 package doctracking
 
-// DocUpdateTracking represents the state of documentation updates
+// DocUpdateTracking represents the state of documentation updates for one branch
 type DocUpdateTracking struct {
 	// LastProcessedCommit is the SHA of the last commit that was processed
 	LastProcessedCommit string `json:"last_processed_commit"`
@@ -13,18 +14,32 @@ type DocUpdateTracking struct {
 	// StrategyVersion tracks the version of the update strategy used
 	// Allows future migrations if the update logic changes
 	StrategyVersion string `json:"strategy_version"`
+
+	// ProcessedCommits lists the SHAs whose changes were considered in the last update
+	ProcessedCommits []string `json:"processed_commits,omitempty"`
+
+	// SkippedCommits lists the SHAs excluded from the last update via [skip-docs]
+	SkippedCommits []string `json:"skipped_commits,omitempty"`
 }
 
 // TrackingService abstracts documentation tracking persistence for testability
 type TrackingService interface {
-	// Read loads the current tracking state from storage
-	// Returns zero-value DocUpdateTracking if file doesn't exist
-	Read() (DocUpdateTracking, error)
+	// Read loads the tracking state for the given branch
+	// Returns zero-value DocUpdateTracking if no state exists for the branch
+	// A v1 (single-branch) tracking file is migrated and attributed to this branch
+	Read(branch string) (DocUpdateTracking, error)
 
-	// Write persists the tracking state to storage atomically
-	Write(tracking DocUpdateTracking) error
+	// ReadAll loads the tracking state for every tracked branch
+	ReadAll() (map[string]DocUpdateTracking, error)
 
-	// Initialize creates initial tracking state with HEAD commit
+	// Write persists the tracking state for the given branch atomically
+	Write(branch string, tracking DocUpdateTracking) error
+
+	// Initialize creates initial tracking state for the branch with HEAD commit
 	// Used for first-time setup
-	Initialize(headSHA string) error
+	Initialize(branch, headSHA string) error
+
+	// Prune removes entries for branches not listed in liveBranches
+	// Returns the names of the removed branches
+	Prune(liveBranches []string) ([]string, error)
 }
@@ -4,6 +4,7 @@
 package git
 
 import (
+	"fmt"
 	"strings"
 
 	"claudex/internal/services/commander"
@@ -25,6 +26,75 @@ type GitService interface {
 	// GetMergeBase returns the merge base between HEAD and the specified branch
 	// Used as fallback when base commit is unreachable (e.g., after rebase)
 	GetMergeBase(branch string) (string, error)
+
+	// GetCommits returns the commits in base..head, oldest first
+	// Each commit carries its full message so callers can honor tags like [skip-docs]
+	// An empty base lists all commits reachable from head; commits reachable
+	// from any of the exclude SHAs are omitted
+	GetCommits(base, head string, exclude ...string) ([]Commit, error)
+
+	// GetCommitFiles returns the list of files changed by a single commit
+	// Merge commits only report files that differ from every parent (conflict
+	// resolutions); the merged changes belong to their original commits
+	GetCommitFiles(sha string) ([]string, error)
+
+	// GetDefaultBranch detects the repository's default branch
+	// Tries origin/HEAD first, then init.defaultBranch; returns the branch
+	// and the source it was detected from (see DefaultBranchSource* constants)
+	GetDefaultBranch() (branch, source string, err error)
+
+	// GetCurrentBranch returns the short name of the checked-out branch
+	// Returns "HEAD" when HEAD is detached (e.g., during a rebase)
+	GetCurrentBranch() (string, error)
+
+	// ListBranches returns the short names of all local branches
+	ListBranches() ([]string, error)
+
+	// GetStagedFiles returns the files staged in the index
+	// Uses git diff --cached --name-only
+	GetStagedFiles() ([]string, error)
+
+	// GetWorktreeFiles returns files changed in the working tree relative to HEAD,
+	// including untracked files that are not ignored
+	GetWorktreeFiles() ([]string, error)
+
+	// StageFiles adds the given paths to the index
+	StageFiles(paths ...string) error
+
+	// ApplyPatch applies a unified diff file to the working tree
+	// Nothing is changed if any hunk fails to apply
+	ApplyPatch(patchPath string) error
+
+	// CommitFiles commits the current content of the given paths only
+	// Other staged changes stay staged and are not part of the commit
+	CommitFiles(message string, paths ...string) error
+
+	// GetDiff returns the unified diff from base to head, limited to paths when given
+	// An empty head compares base with the working tree and IndexRef compares
+	// base with the staged content; an empty base with IndexRef diffs the
+	// index of an unborn branch
+	GetDiff(base, head string, paths ...string) (string, error)
+}
+
+// IndexRef passed as head to GetDiff selects the staged content
+const IndexRef = ":index"
+
+// Sources reported by GetDefaultBranch
+const (
+	// DefaultBranchSourceRemote means the branch came from refs/remotes/origin/HEAD
+	DefaultBranchSourceRemote = "origin/HEAD"
+
+	// DefaultBranchSourceInit means the branch came from git config init.defaultBranch
+	DefaultBranchSourceInit = "init.defaultBranch"
+)
+
+// Commit describes a single commit within a processed range
+type Commit struct {
+	// SHA is the full commit hash
+	SHA string
+
+	// Message is the full commit message (subject and body)
+	Message string
 }
 
 // OsGitService is the production implementation of GitService
@@ -75,6 +145,172 @@ func (s *OsGitService) GetMergeBase(branch string) (string, error) {
 	return trimOutput(output), nil
 }
 
+// GetCommits returns the commits in base..head, oldest first
+func (s *OsGitService) GetCommits(base, head string, exclude ...string) ([]Commit, error) {
+	// Fields are separated by US (0x1f) and records by RS (0x1e) so that
+	// multi-line commit messages survive parsing intact
+	args := []string{"log", "--reverse", "--format=%H%x1f%B%x1e"}
+	if base != "" {
+		args = append(args, base+".."+head)
+	} else {
+		args = append(args, head)
+	}
+	for _, sha := range exclude {
+		args = append(args, "^"+sha)
+	}
+	output, err := s.cmdr.Run("git", args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseCommits(output), nil
+}
+
+// GetCommitFiles returns the list of files changed by a single commit
+func (s *OsGitService) GetCommitFiles(sha string) ([]string, error) {
+	output, err := s.cmdr.Run("git", "diff-tree", "--no-commit-id", "--name-only", "-r", "--root", "--cc", sha)
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(output), nil
+}
+
+// GetDefaultBranch detects the repository's default branch
+func (s *OsGitService) GetDefaultBranch() (string, string, error) {
+	// origin/HEAD reflects the remote's default branch (set by clone or remote set-head)
+	if output, err := s.cmdr.Run("git", "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		if branch := trimOutput(output); branch != "" {
+			return branch, DefaultBranchSourceRemote, nil
+		}
+	}
+
+	// init.defaultBranch is the local preference for new repositories
+	if output, err := s.cmdr.Run("git", "config", "--get", "init.defaultBranch"); err == nil {
+		if branch := trimOutput(output); branch != "" {
+			return branch, DefaultBranchSourceInit, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("could not detect default branch from origin/HEAD or init.defaultBranch")
+}
+
+// GetCurrentBranch returns the short name of the checked-out branch
+func (s *OsGitService) GetCurrentBranch() (string, error) {
+	output, err := s.cmdr.Run("git", "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return trimOutput(output), nil
+}
+
+// ListBranches returns the short names of all local branches
+func (s *OsGitService) ListBranches() ([]string, error) {
+	output, err := s.cmdr.Run("git", "for-each-ref", "--format=%(refname:short)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(output), nil
+}
+
+// GetStagedFiles returns the files staged in the index
+func (s *OsGitService) GetStagedFiles() ([]string, error) {
+	output, err := s.cmdr.Run("git", "diff", "--cached", "--name-only")
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(output), nil
+}
+
+// GetWorktreeFiles returns files changed in the working tree relative to HEAD
+func (s *OsGitService) GetWorktreeFiles() ([]string, error) {
+	output, err := s.cmdr.Run("git", "diff", "HEAD", "--name-only")
+	if err != nil {
+		return nil, err
+	}
+	files := splitLines(output)
+
+	untracked, err := s.cmdr.Run("git", "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	return append(files, splitLines(untracked)...), nil
+}
+
+// StageFiles adds the given paths to the index
+func (s *OsGitService) StageFiles(paths ...string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	args := append([]string{"add", "--"}, paths...)
+	_, err := s.cmdr.Run("git", args...)
+	return err
+}
+
+// ApplyPatch applies a unified diff file to the working tree
+func (s *OsGitService) ApplyPatch(patchPath string) error {
+	if output, err := s.cmdr.Run("git", "apply", patchPath); err != nil {
+		return fmt.Errorf("git apply failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// CommitFiles commits the current content of the given paths only
+func (s *OsGitService) CommitFiles(message string, paths ...string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("no files to commit")
+	}
+	// New files must be known to git before --only can commit them
+	if err := s.StageFiles(paths...); err != nil {
+		return err
+	}
+	args := append([]string{"commit", "--only", "-m", message, "--"}, paths...)
+	if output, err := s.cmdr.Run("git", args...); err != nil {
+		return fmt.Errorf("git commit failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// GetDiff returns the unified diff from base to head, limited to paths when given
+func (s *OsGitService) GetDiff(base, head string, paths ...string) (string, error) {
+	// External diff drivers and color would make the output unparseable
+	args := []string{"diff", "--no-color", "--no-ext-diff"}
+	switch head {
+	case IndexRef:
+		args = append(args, "--cached")
+		if base != "" {
+			args = append(args, base)
+		}
+	case "":
+		args = append(args, base)
+	default:
+		args = append(args, base, head)
+	}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	output, err := s.cmdr.Run("git", args...)
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// parseCommits parses git log output produced with the %H%x1f%B%x1e format
+func parseCommits(output []byte) []Commit {
+	var commits []Commit
+	for _, record := range strings.Split(string(output), "\x1e") {
+		record = strings.TrimSpace(record)
+		if record == "" {
+			continue
+		}
+		sha, message, _ := strings.Cut(record, "\x1f")
+		commits = append(commits, Commit{
+			SHA:     strings.TrimSpace(sha),
+			Message: strings.TrimSpace(message),
+		})
+	}
+	return commits
+}
+
 // trimOutput removes leading and trailing whitespace from command output
 func trimOutput(output []byte) string {
 	return strings.TrimSpace(string(output))
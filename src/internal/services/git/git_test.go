@@ -3,6 +3,7 @@ package git
 import (
 	"errors"
 	"io"
+	"strings"
 	"testing"
 )
 
@@ -338,6 +339,312 @@ func TestGetMergeBase_TrimWhitespace(t *testing.T) {
 	}
 }
 
+func TestGetCommits_Success(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			if name != "git" {
+				t.Errorf("expected command 'git', got '%s'", name)
+			}
+			expectedArgs := []string{"log", "--reverse", "--format=%H%x1f%B%x1e", "abc123..def456"}
+			if len(args) != len(expectedArgs) {
+				t.Errorf("expected %d args, got %d", len(expectedArgs), len(args))
+			}
+			for i, arg := range expectedArgs {
+				if i < len(args) && args[i] != arg {
+					t.Errorf("arg %d: expected '%s', got '%s'", i, arg, args[i])
+				}
+			}
+			return []byte("sha1\x1ffeat: add parser\n\nLonger body\n\x1e\nsha2\x1ffix: typo [skip-docs]\n\x1e\n"), nil
+		},
+	}
+
+	svc := New(mock)
+	commits, err := svc.GetCommits("abc123", "def456")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("expected 2 commits, got %d", len(commits))
+	}
+	if commits[0].SHA != "sha1" || commits[0].Message != "feat: add parser\n\nLonger body" {
+		t.Errorf("unexpected first commit: %+v", commits[0])
+	}
+	if commits[1].SHA != "sha2" || commits[1].Message != "fix: typo [skip-docs]" {
+		t.Errorf("unexpected second commit: %+v", commits[1])
+	}
+}
+
+func TestGetCommits_EmptyRange(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			return []byte(""), nil
+		},
+	}
+
+	svc := New(mock)
+	commits, err := svc.GetCommits("abc123", "abc123")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(commits) != 0 {
+		t.Errorf("expected no commits, got %d", len(commits))
+	}
+}
+
+func TestGetCommits_Error(t *testing.T) {
+	expectedErr := errors.New("bad revision")
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			return nil, expectedErr
+		},
+	}
+
+	svc := New(mock)
+	_, err := svc.GetCommits("abc123", "def456")
+
+	if err != expectedErr {
+		t.Errorf("expected error '%v', got '%v'", expectedErr, err)
+	}
+}
+
+func TestGetCommitFiles_Success(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			if name != "git" {
+				t.Errorf("expected command 'git', got '%s'", name)
+			}
+			if len(args) == 0 || args[0] != "diff-tree" || args[len(args)-1] != "abc123" {
+				t.Errorf("expected diff-tree for abc123, got %v", args)
+			}
+			return []byte("src/a.go\nsrc/b.go\n"), nil
+		},
+	}
+
+	svc := New(mock)
+	files, err := svc.GetCommitFiles("abc123")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"src/a.go", "src/b.go"}
+	if len(files) != len(expected) {
+		t.Fatalf("expected %d files, got %d", len(expected), len(files))
+	}
+	for i, file := range expected {
+		if files[i] != file {
+			t.Errorf("file %d: expected '%s', got '%s'", i, file, files[i])
+		}
+	}
+}
+
+func TestGetCommitFiles_Error(t *testing.T) {
+	expectedErr := errors.New("unknown revision")
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			return nil, expectedErr
+		},
+	}
+
+	svc := New(mock)
+	_, err := svc.GetCommitFiles("abc123")
+
+	if err != expectedErr {
+		t.Errorf("expected error '%v', got '%v'", expectedErr, err)
+	}
+}
+
+func TestGetDefaultBranch_FromOriginHEAD(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			if len(args) > 0 && args[0] == "symbolic-ref" {
+				return []byte("origin/develop\n"), nil
+			}
+			return nil, errors.New("unexpected call")
+		},
+	}
+
+	svc := New(mock)
+	branch, source, err := svc.GetDefaultBranch()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if branch != "origin/develop" {
+		t.Errorf("expected 'origin/develop', got '%s'", branch)
+	}
+	if source != DefaultBranchSourceRemote {
+		t.Errorf("expected source '%s', got '%s'", DefaultBranchSourceRemote, source)
+	}
+}
+
+func TestGetDefaultBranch_FromInitDefaultBranch(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			if len(args) > 0 && args[0] == "config" {
+				return []byte("trunk\n"), nil
+			}
+			return nil, errors.New("ref refs/remotes/origin/HEAD is not a symbolic ref")
+		},
+	}
+
+	svc := New(mock)
+	branch, source, err := svc.GetDefaultBranch()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if branch != "trunk" {
+		t.Errorf("expected 'trunk', got '%s'", branch)
+	}
+	if source != DefaultBranchSourceInit {
+		t.Errorf("expected source '%s', got '%s'", DefaultBranchSourceInit, source)
+	}
+}
+
+func TestGetDefaultBranch_NotDetected(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			return nil, errors.New("exit status 1")
+		},
+	}
+
+	svc := New(mock)
+	_, _, err := svc.GetDefaultBranch()
+
+	if err == nil {
+		t.Error("expected error when default branch cannot be detected")
+	}
+}
+
+func TestGetStagedFiles_Success(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			if len(args) < 2 || args[0] != "diff" || args[1] != "--cached" {
+				t.Errorf("expected diff --cached, got %v", args)
+			}
+			return []byte("src/a.go\n"), nil
+		},
+	}
+
+	svc := New(mock)
+	files, err := svc.GetStagedFiles()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 1 || files[0] != "src/a.go" {
+		t.Errorf("expected [src/a.go], got %v", files)
+	}
+}
+
+func TestGetWorktreeFiles_IncludesUntracked(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			switch args[0] {
+			case "diff":
+				return []byte("src/a.go\n"), nil
+			case "ls-files":
+				return []byte("src/new.go\n"), nil
+			}
+			return nil, errors.New("unexpected call")
+		},
+	}
+
+	svc := New(mock)
+	files, err := svc.GetWorktreeFiles()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"src/a.go", "src/new.go"}
+	if len(files) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, files)
+	}
+	for i, file := range expected {
+		if files[i] != file {
+			t.Errorf("file %d: expected '%s', got '%s'", i, file, files[i])
+		}
+	}
+}
+
+func TestStageFiles_NoPaths_NoCommand(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			t.Errorf("expected no git command, got %v", args)
+			return nil, nil
+		},
+	}
+
+	svc := New(mock)
+	if err := svc.StageFiles(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestStageFiles_AddsPaths(t *testing.T) {
+	var got []string
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			got = args
+			return nil, nil
+		},
+	}
+
+	svc := New(mock)
+	if err := svc.StageFiles("pkg/index.md"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"add", "--", "pkg/index.md"}
+	if len(got) != len(expected) {
+		t.Fatalf("expected args %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("arg %d: expected '%s', got '%s'", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestGetDiff_Args(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		head     string
+		paths    []string
+		expected []string
+	}{
+		{"range", "abc", "def", []string{"a.go"}, []string{"diff", "--no-color", "--no-ext-diff", "abc", "def", "--", "a.go"}},
+		{"working tree", "HEAD", "", nil, []string{"diff", "--no-color", "--no-ext-diff", "HEAD"}},
+		{"staged", "HEAD", IndexRef, nil, []string{"diff", "--no-color", "--no-ext-diff", "--cached", "HEAD"}},
+		{"staged on unborn branch", "", IndexRef, nil, []string{"diff", "--no-color", "--no-ext-diff", "--cached"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			mock := &mockCommander{
+				runFunc: func(name string, args ...string) ([]byte, error) {
+					got = args
+					return []byte("diff --git a/a.go b/a.go\n"), nil
+				},
+			}
+
+			diff, err := New(mock).GetDiff(tt.base, tt.head, tt.paths...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff != "diff --git a/a.go b/a.go\n" {
+				t.Errorf("expected raw diff output, got %q", diff)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("expected args %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
 func TestSplitLines_EdgeCases(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1,6 +1,7 @@
 // Package hooksetup provides Git hook installation for Claudex.
-// It safely installs a post-commit hook to trigger documentation updates
-// without breaking existing hooks.
+// It safely installs guarded blocks into the post-commit, pre-commit,
+// post-merge, post-checkout and pre-push hooks to trigger documentation
+// updates without breaking existing hooks, and removes them again.
 package hooksetup
 
 import (
@@ -18,9 +19,95 @@ const (
 	hookContent = `
 # claudex-docs-hook
 claudex --update-docs &
+# claudex-docs-hook end
 `
+
+	// The pre-commit hook runs synchronously so regenerated docs can be staged
+	// into the commit being created; failures never block the commit
+	preCommitGuardMarker = "# claudex-docs-precommit-hook"
+	preCommitHookContent = `
+# claudex-docs-precommit-hook
+claudex --update-docs --staged --add-docs || true
+# claudex-docs-precommit-hook end
+`
+
+	// endSuffix closes a guarded block; blocks written before end markers
+	// existed are the marker line plus a single command line
+	endSuffix = " end"
+)
+
+// Hook names managed by claudex
+const (
+	PostCommit   = "post-commit"
+	PreCommit    = "pre-commit"
+	PostMerge    = "post-merge"
+	PostCheckout = "post-checkout"
+	PrePush      = "pre-push"
+)
+
+// Hook managers detected by Manager
+const (
+	ManagerHusky    = "husky"
+	ManagerLefthook = "lefthook"
 )
 
+// hookBlock is the guarded claudex block of one hook
+type hookBlock struct {
+	marker  string
+	content string
+}
+
+// hookBlocks maps each supported hook to its block
+var hookBlocks = map[string]hookBlock{
+	PostCommit: {guardMarker, hookContent},
+	PreCommit:  {preCommitGuardMarker, preCommitHookContent},
+	PostMerge: {"# claudex-docs-post-merge-hook", `
+# claudex-docs-post-merge-hook
+claudex --update-docs &
+# claudex-docs-post-merge-hook end
+`},
+	// $3 is 1 for branch checkouts and 0 for file checkouts
+	PostCheckout: {"# claudex-docs-post-checkout-hook", `
+# claudex-docs-post-checkout-hook
+[ "$3" = "1" ] && claudex --update-docs &
+# claudex-docs-post-checkout-hook end
+`},
+	// Reports stale docs before they are pushed; never blocks the push
+	PrePush: {"# claudex-docs-pre-push-hook", `
+# claudex-docs-pre-push-hook
+claudex docs lint || true
+# claudex-docs-pre-push-hook end
+`},
+}
+
+// HookNames lists the supported hooks in display order
+var HookNames = []string{PostCommit, PreCommit, PostMerge, PostCheckout, PrePush}
+
+// IsSupported reports whether name is a hook claudex can manage
+func IsSupported(name string) bool {
+	_, ok := hookBlocks[name]
+	return ok
+}
+
+// Command returns the shell command claudex runs in the named hook, for
+// hook managers that are configured rather than given a script
+func Command(name string) string {
+	block, ok := hookBlocks[name]
+	if !ok {
+		return ""
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(block.content), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lefthookConfigs are the config files that mark a lefthook-managed repo
+var lefthookConfigs = []string{"lefthook.yml", ".lefthook.yml", "lefthook.yaml", ".lefthook.yaml"}
+
 // FileService is the production implementation of Service
 type FileService struct {
 	fs         afero.Fs
@@ -37,25 +124,141 @@ func New(fs afero.Fs, projectDir string, cmdr commander.Commander) Service {
 	}
 }
 
-// IsGitRepo checks if .git directory exists
+// IsGitRepo checks if .git exists: a directory, or a file in worktrees
 func (s *FileService) IsGitRepo() bool {
-	info, err := s.fs.Stat(filepath.Join(s.projectDir, ".git"))
-	return err == nil && info.IsDir()
+	_, err := s.fs.Stat(filepath.Join(s.projectDir, ".git"))
+	return err == nil
 }
 
 // IsInstalled checks for guard marker in post-commit hook
 func (s *FileService) IsInstalled() bool {
-	hookPath := filepath.Join(s.projectDir, ".git", "hooks", "post-commit")
+	return s.IsHookInstalled(PostCommit)
+}
+
+// Install writes the claudex block to post-commit (creates if not exists)
+func (s *FileService) Install() error {
+	return s.InstallHook(PostCommit)
+}
+
+// IsPreCommitInstalled checks for guard marker in pre-commit hook
+func (s *FileService) IsPreCommitInstalled() bool {
+	return s.IsHookInstalled(PreCommit)
+}
+
+// InstallPreCommit writes the staged docs update to pre-commit (creates if not exists)
+func (s *FileService) InstallPreCommit() error {
+	return s.InstallHook(PreCommit)
+}
+
+// HooksDir resolves the directory git runs hooks from with
+// `git rev-parse --git-path hooks`, which honours core.hooksPath and
+// worktrees, falling back to .git/hooks. For husky v9 the generated .husky/_
+// directory is replaced by .husky, where husky expects user hook scripts.
+func (s *FileService) HooksDir() string {
+	dir := filepath.Join(s.projectDir, ".git", "hooks")
+	if output, err := s.cmdr.Run("git", "-C", s.projectDir, "rev-parse", "--git-path", "hooks"); err == nil {
+		if resolved := strings.TrimSpace(string(output)); resolved != "" {
+			if !filepath.IsAbs(resolved) {
+				resolved = filepath.Join(s.projectDir, resolved)
+			}
+			dir = filepath.Clean(resolved)
+		}
+	}
+	if filepath.Base(dir) == "_" && filepath.Base(filepath.Dir(dir)) == ".husky" {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+// Manager reports which hook manager owns the hooks: ManagerHusky when the
+// hooks dir lives under .husky, ManagerLefthook when a lefthook config exists,
+// or "" for plain git hooks
+func (s *FileService) Manager() string {
+	if strings.Contains(filepath.ToSlash(s.HooksDir())+"/", "/.husky/") {
+		return ManagerHusky
+	}
+	for _, name := range lefthookConfigs {
+		if _, err := s.fs.Stat(filepath.Join(s.projectDir, name)); err == nil {
+			return ManagerLefthook
+		}
+	}
+	return ""
+}
+
+// IsHookInstalled checks for the guard marker of the named hook
+func (s *FileService) IsHookInstalled(name string) bool {
+	block, ok := hookBlocks[name]
+	return ok && s.hasMarker(name, block.marker)
+}
+
+// InstallHook writes the claudex block into the named hook. An existing block
+// is replaced, which repairs outdated or hand-edited blocks, and the hook is
+// made executable.
+func (s *FileService) InstallHook(name string) error {
+	block, ok := hookBlocks[name]
+	if !ok {
+		return unsupportedHookError(name)
+	}
+	if _, err := s.UninstallHook(name); err != nil {
+		return err
+	}
+	return s.appendHook(name, block.content)
+}
+
+// UninstallHook removes only the claudex block from the named hook and deletes
+// the hook when nothing but a shebang is left. removed is false when the hook
+// had no claudex block.
+func (s *FileService) UninstallHook(name string) (removed bool, err error) {
+	block, ok := hookBlocks[name]
+	if !ok {
+		return false, unsupportedHookError(name)
+	}
+
+	hookPath := filepath.Join(s.HooksDir(), name)
+	data, err := afero.ReadFile(s.fs, hookPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	content, removed := removeBlock(string(data), block.marker)
+	if !removed {
+		return false, nil
+	}
+	if isEmptyHook(content) {
+		return true, s.fs.Remove(hookPath)
+	}
+	return true, afero.WriteFile(s.fs, hookPath, []byte(content), 0755)
+}
+
+// HookStatus inspects the named hook
+func (s *FileService) HookStatus(name string) HookStatus {
+	status := HookStatus{Name: name, Path: filepath.Join(s.HooksDir(), name)}
+	info, err := s.fs.Stat(status.Path)
+	if err != nil {
+		return status
+	}
+	status.Exists = true
+	status.Executable = info.Mode().Perm()&0111 != 0
+	status.Installed = s.IsHookInstalled(name)
+	return status
+}
+
+// hasMarker checks whether the named hook contains the given guard marker
+func (s *FileService) hasMarker(hookName, marker string) bool {
+	hookPath := filepath.Join(s.HooksDir(), hookName)
 	data, err := afero.ReadFile(s.fs, hookPath)
 	if err != nil {
 		return false
 	}
-	return strings.Contains(string(data), guardMarker)
+	return strings.Contains(string(data), marker)
 }
 
-// Install appends hook line to post-commit (creates if not exists)
-func (s *FileService) Install() error {
-	hookPath := filepath.Join(s.projectDir, ".git", "hooks", "post-commit")
+// appendHook appends content to the named hook (creates if not exists)
+func (s *FileService) appendHook(hookName, block string) error {
+	hookPath := filepath.Join(s.HooksDir(), hookName)
 
 	// Ensure hooks directory exists
 	hooksDir := filepath.Dir(hookPath)
@@ -72,10 +275,10 @@ func (s *FileService) Install() error {
 	var content string
 	if len(existing) == 0 {
 		// New file - add shebang
-		content = "#!/bin/sh\n" + hookContent
+		content = "#!/bin/sh\n" + block
 	} else {
 		// Append to existing
-		content = string(existing) + "\n" + hookContent
+		content = string(existing) + "\n" + block
 	}
 
 	// Write file
@@ -83,5 +286,57 @@ func (s *FileService) Install() error {
 		return err
 	}
 
-	return nil
+	// WriteFile keeps the mode of existing files
+	return s.fs.Chmod(hookPath, 0755)
+}
+
+// removeBlock cuts every block starting at marker, through its end marker or,
+// for blocks without one, the command line after it. Blank lines directly
+// above a block were added by appendHook and go with it.
+func removeBlock(content, marker string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	var kept []string
+	removed := false
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != marker {
+			kept = append(kept, lines[i])
+			continue
+		}
+		removed = true
+		for len(kept) > 0 && strings.TrimSpace(kept[len(kept)-1]) == "" {
+			kept = kept[:len(kept)-1]
+		}
+
+		end := -1
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == marker+endSuffix {
+				end = j
+				break
+			}
+		}
+		if end < 0 {
+			end = i + 1
+		}
+		i = end
+	}
+	if !removed {
+		return content, false
+	}
+
+	result := strings.TrimRight(strings.Join(kept, "\n"), "\n")
+	if result != "" {
+		result += "\n"
+	}
+	return result, true
+}
+
+// isEmptyHook reports whether a hook has nothing left but a shebang
+func isEmptyHook(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#!") {
+			return false
+		}
+	}
+	return true
 }
@@ -2,6 +2,7 @@ package hooksetup
 
 import (
 	"io"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -239,3 +240,152 @@ func TestInstall_CreatesHooksDirectoryIfMissing(t *testing.T) {
 	_, err = fs.Stat(hookPath)
 	assert.NoError(t, err, "Hook file should exist")
 }
+
+func TestInstallPreCommit_CreatesHookWithStagedUpdate(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	projectDir := "/test/project"
+	cmdr := &mockCommander{}
+
+	gitDir := filepath.Join(projectDir, ".git")
+	err := fs.MkdirAll(gitDir, 0755)
+	require.NoError(t, err)
+
+	service := New(fs, projectDir, cmdr)
+	assert.False(t, service.IsPreCommitInstalled())
+
+	err = service.InstallPreCommit()
+	require.NoError(t, err)
+
+	// Verify hook was created
+	hookPath := filepath.Join(projectDir, ".git", "hooks", "pre-commit")
+	data, err := afero.ReadFile(fs, hookPath)
+	require.NoError(t, err)
+
+	content := string(data)
+	assert.Contains(t, content, "#!/bin/sh", "Should include shebang")
+	assert.Contains(t, content, "# claudex-docs-precommit-hook", "Should include guard marker")
+	assert.Contains(t, content, "claudex --update-docs --staged --add-docs || true", "Should never block the commit")
+	assert.True(t, service.IsPreCommitInstalled())
+
+	// Post-commit hook is independent
+	assert.False(t, service.IsInstalled())
+}
+
+// gitPathCommander answers `git rev-parse --git-path hooks` with a fixed path
+type gitPathCommander struct {
+	hooksPath string
+}
+
+func (m *gitPathCommander) Run(name string, args ...string) ([]byte, error) {
+	return []byte(m.hooksPath + "\n"), nil
+}
+
+func (m *gitPathCommander) Start(name string, stdin io.Reader, stdout, stderr io.Writer, args ...string) error {
+	return nil
+}
+
+func TestHooksDir_ResolvesGitPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		hooksPath string
+		want      string
+		manager   string
+	}{
+		{"fallback", "", "/test/project/.git/hooks", ""},
+		{"core.hooksPath", ".githooks", "/test/project/.githooks", ""},
+		{"worktree", "/test/main/.git/hooks", "/test/main/.git/hooks", ""},
+		{"husky v9", ".husky/_", "/test/project/.husky", ManagerHusky},
+		{"husky v4", ".husky", "/test/project/.husky", ManagerHusky},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			service := New(fs, "/test/project", &gitPathCommander{hooksPath: tt.hooksPath})
+			assert.Equal(t, tt.want, service.HooksDir())
+			assert.Equal(t, tt.manager, service.Manager())
+		})
+	}
+}
+
+func TestManager_DetectsLefthook(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/test/project/lefthook.yml", []byte("pre-commit:\n"), 0644))
+
+	service := New(fs, "/test/project", &mockCommander{})
+	assert.Equal(t, ManagerLefthook, service.Manager())
+}
+
+func TestIsGitRepo_AcceptsWorktreeGitFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/test/wt/.git", []byte("gitdir: /test/main/.git/worktrees/wt\n"), 0644))
+
+	assert.True(t, New(fs, "/test/wt", &mockCommander{}).IsGitRepo())
+}
+
+func TestInstallHook_WritesIntoCoreHooksPathAndRepairs(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	service := New(fs, "/test/project", &gitPathCommander{hooksPath: ".githooks"})
+	hookPath := "/test/project/.githooks/post-merge"
+
+	require.NoError(t, afero.WriteFile(fs, hookPath, []byte("#!/bin/sh\necho merged\n\n# claudex-docs-post-merge-hook\nclaudex --old-flag\n"), 0644))
+	require.NoError(t, service.InstallHook(PostMerge))
+	require.NoError(t, service.InstallHook(PostMerge))
+
+	data, err := afero.ReadFile(fs, hookPath)
+	require.NoError(t, err)
+	content := string(data)
+	assert.Equal(t, 1, strings.Count(content, "# claudex-docs-post-merge-hook\n"), "block should not be duplicated")
+	assert.NotContains(t, content, "--old-flag", "outdated block should be replaced")
+	assert.Contains(t, content, "echo merged")
+
+	status := service.HookStatus(PostMerge)
+	assert.True(t, status.Installed)
+	assert.True(t, status.Executable, "install should make the hook executable")
+	assert.False(t, service.IsHookInstalled(PrePush))
+
+	assert.Error(t, service.InstallHook("commit-msg"))
+}
+
+func TestUninstallHook_RemovesOnlyTheClaudexBlock(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	service := New(fs, "/test/project", &mockCommander{})
+	hookPath := "/test/project/.git/hooks/post-commit"
+
+	existing := "#!/bin/sh\necho before\n"
+	require.NoError(t, afero.WriteFile(fs, hookPath, []byte(existing), 0755))
+	require.NoError(t, service.Install())
+	f, err := fs.OpenFile(hookPath, os.O_APPEND|os.O_WRONLY, 0755)
+	require.NoError(t, err)
+	_, err = f.WriteString("echo after\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	removed, err := service.UninstallHook(PostCommit)
+	require.NoError(t, err)
+	assert.True(t, removed)
+
+	data, err := afero.ReadFile(fs, hookPath)
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\necho before\necho after\n", string(data))
+
+	removed, err = service.UninstallHook(PostCommit)
+	require.NoError(t, err)
+	assert.False(t, removed, "second uninstall should be a no-op")
+}
+
+func TestUninstallHook_RemovesLegacyBlockAndEmptyHook(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	service := New(fs, "/test/project", &mockCommander{})
+	hookPath := "/test/project/.git/hooks/post-commit"
+
+	// Blocks written before end markers existed
+	require.NoError(t, afero.WriteFile(fs, hookPath, []byte("#!/bin/sh\n\n# claudex-docs-hook\nclaudex --update-docs &\n"), 0755))
+
+	removed, err := service.UninstallHook(PostCommit)
+	require.NoError(t, err)
+	assert.True(t, removed)
+
+	exists, err := afero.Exists(fs, hookPath)
+	require.NoError(t, err)
+	assert.False(t, exists, "a hook left with only a shebang should be deleted")
+}
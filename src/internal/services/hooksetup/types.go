@@ -1,5 +1,7 @@
 package hooksetup
 
+import "fmt"
+
 // Service defines the git hook setup interface
 type Service interface {
 	// IsGitRepo checks if the project directory is a git repository
@@ -8,4 +10,35 @@ type Service interface {
 	IsInstalled() bool
 	// Install adds the claudex hook to post-commit (append-safe)
 	Install() error
+	// IsPreCommitInstalled checks if the claudex pre-commit hook is installed
+	IsPreCommitInstalled() bool
+	// InstallPreCommit adds the staged docs update to pre-commit (append-safe)
+	InstallPreCommit() error
+	// HooksDir resolves the directory git runs hooks from
+	HooksDir() string
+	// Manager reports the hook manager in use ("husky", "lefthook" or "")
+	Manager() string
+	// IsHookInstalled checks if the claudex block is present in the named hook
+	IsHookInstalled(name string) bool
+	// InstallHook writes or repairs the claudex block in the named hook
+	InstallHook(name string) error
+	// UninstallHook removes the claudex block from the named hook
+	UninstallHook(name string) (bool, error)
+	// HookStatus inspects the named hook
+	HookStatus(name string) HookStatus
+}
+
+// HookStatus describes one hook file
+type HookStatus struct {
+	Name       string
+	Path       string
+	Exists     bool
+	Executable bool
+	// Installed is true when the hook contains the claudex block
+	Installed bool
+}
+
+// unsupportedHookError reports a hook name claudex does not manage
+func unsupportedHookError(name string) error {
+	return fmt.Errorf("unsupported hook %q (supported: %v)", name, HookNames)
 }
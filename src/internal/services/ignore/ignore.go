@@ -0,0 +1,191 @@
+// Package ignore decides which files Claudex scans. It applies .gitignore
+// semantics to the repository's .gitignore files, .git/info/exclude and
+// .claudexignore files (same syntax, for paths only Claudex should skip),
+// on top of a few built-in defaults such as node_modules. Walk is the shared
+// ignore-aware replacement for afero.Walk.
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+// FileName is the Claudex-specific ignore file, read like a .gitignore in
+// every directory; its rules take precedence over .gitignore in the same directory
+const FileName = ".claudexignore"
+
+// gitignoreName is the standard per-directory ignore file
+const gitignoreName = ".gitignore"
+
+// DefaultPatterns are ignored everywhere unless an ignore file re-includes them
+var DefaultPatterns = []string{".git/", "node_modules/", "vendor/"}
+
+// Matcher answers whether paths under a repository are ignored. Ignore files
+// are read lazily and cached, so a Matcher is meant for one scan.
+type Matcher struct {
+	fs   afero.Fs
+	root string
+
+	mu    sync.Mutex
+	rules map[string][]rule // by directory
+	base  []rule            // defaults and .git/info/exclude
+}
+
+// New creates a Matcher for the repository containing path. The repository
+// root is the nearest ancestor with a .git entry; without one, path itself
+// (or its directory, for a file) is the root.
+func New(fs afero.Fs, path string) *Matcher {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	if info, err := fs.Stat(abs); err == nil && !info.IsDir() {
+		abs = filepath.Dir(abs)
+	}
+
+	root := abs
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if _, err := fs.Stat(filepath.Join(dir, ".git")); err == nil {
+			root = dir
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	m := &Matcher{fs: fs, root: root, rules: map[string][]rule{}}
+	m.base = parseRules("", strings.Join(DefaultPatterns, "\n"))
+	if data, err := afero.ReadFile(fs, filepath.Join(root, ".git", "info", "exclude")); err == nil {
+		m.base = append(m.base, parseRules("", string(data))...)
+	}
+	return m
+}
+
+// Root returns the repository root the Matcher resolves rules against
+func (m *Matcher) Root() string {
+	return m.root
+}
+
+// Ignored reports whether path, or any directory above it, is ignored.
+// Paths outside the repository root are never ignored.
+func (m *Matcher) Ignored(path string, isDir bool) bool {
+	rel, ok := m.rel(path)
+	if !ok || rel == "" {
+		return false
+	}
+
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.match(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.match(rel, isDir)
+}
+
+// match reports whether rel (slash-separated, relative to the root) is
+// ignored by the rules that apply to it, without checking its parents
+func (m *Matcher) match(rel string, isDir bool) bool {
+	ignored := false
+	apply := func(rules []rule) {
+		for _, r := range rules {
+			if r.matches(rel, isDir) {
+				ignored = !r.negate
+			}
+		}
+	}
+
+	apply(m.base)
+	// Rules from shallower directories first, so deeper files win
+	apply(m.dirRules(""))
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		apply(m.dirRules(strings.Join(parts[:i], "/")))
+	}
+	return ignored
+}
+
+// dirRules returns the parsed .gitignore and .claudexignore rules of a
+// directory (relative to the root)
+func (m *Matcher) dirRules(dir string) []rule {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if rules, ok := m.rules[dir]; ok {
+		return rules
+	}
+
+	var rules []rule
+	abs := filepath.Join(m.root, filepath.FromSlash(dir))
+	for _, name := range []string{gitignoreName, FileName} {
+		if data, err := afero.ReadFile(m.fs, filepath.Join(abs, name)); err == nil {
+			rules = append(rules, parseRules(dir, string(data))...)
+		}
+	}
+	m.rules[dir] = rules
+	return rules
+}
+
+// rel converts path to a slash-separated path relative to the root
+func (m *Matcher) rel(path string) (string, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(m.root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if rel == "." {
+		return "", true
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// Walk is afero.Walk that skips ignored files and directories below root.
+// The walk root itself is always visited.
+func Walk(fs afero.Fs, root string, fn filepath.WalkFunc) error {
+	return New(fs, root).Walk(root, fn)
+}
+
+// Walk walks root with the Matcher's rules; see the package-level Walk
+func (m *Matcher) Walk(root string, fn filepath.WalkFunc) error {
+	return afero.Walk(m.fs, root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && path != root && m.skip(path, info) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return fn(path, info, err)
+	})
+}
+
+// skip checks a walked entry; its parents were already checked by the walk
+func (m *Matcher) skip(path string, info os.FileInfo) bool {
+	rel, ok := m.rel(path)
+	if !ok || rel == "" {
+		return false
+	}
+	return m.match(rel, info.IsDir())
+}
+
+// ReadDir is afero.ReadDir without the ignored entries
+func (m *Matcher) ReadDir(dir string) ([]os.FileInfo, error) {
+	entries, err := afero.ReadDir(m.fs, dir)
+	if err != nil {
+		return nil, err
+	}
+	kept := entries[:0]
+	for _, entry := range entries {
+		if !m.Ignored(filepath.Join(dir, entry.Name()), entry.IsDir()) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept, nil
+}
@@ -0,0 +1,175 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRepo(t *testing.T, files map[string]string) afero.Fs {
+	t.Helper()
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/repo/.git/info", 0755))
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(fs, filepath.Join("/repo", path), []byte(content), 0644))
+	}
+	return fs
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		anchored bool
+		want     bool
+	}{
+		{"*.log", "debug.log", false, true},
+		{"*.log", "logs/debug.log", false, true},
+		{"build", "src/build", false, true},
+		{"/build", "build", true, true},
+		{"/build", "src/build", true, false},
+		{"doc/*.txt", "doc/notes.txt", true, true},
+		{"doc/*.txt", "doc/server/arch.txt", true, false},
+		{"**/foo", "foo", true, true},
+		{"**/foo", "a/b/foo", true, true},
+		{"a/**/b", "a/b", true, true},
+		{"a/**/b", "a/x/y/b", true, true},
+		{"abc/**", "abc/x/y", true, true},
+		{"abc/**", "abc", true, false},
+		{"file?.go", "file1.go", false, true},
+		{"[a-c].txt", "b.txt", false, true},
+		{"[!a-c].txt", "b.txt", false, false},
+		{`\#literal`, "#literal", false, true},
+	}
+	for _, tt := range tests {
+		r, ok := parseRule("", tt.pattern)
+		require.True(t, ok, tt.pattern)
+		assert.Equal(t, tt.anchored, r.anchored, "anchored %s", tt.pattern)
+		assert.Equal(t, tt.want, r.matches(tt.path, false), "%s vs %s", tt.pattern, tt.path)
+	}
+}
+
+func TestMatcher_Ignored(t *testing.T) {
+	fs := newRepo(t, map[string]string{
+		".gitignore":            "# build output\ndist/\n*.log\n!keep.log\n/tmp\n",
+		".claudexignore":        "fixtures/\n",
+		".git/info/exclude":     "local.txt\n",
+		"web/.gitignore":        "generated/\n!important.log\n",
+		"web/generated/api.ts":  "",
+		"web/important.log":     "",
+		"vendor/.claudexignore": "",
+		"sub/.claudexignore":    "!vendor/\n",
+	})
+	m := New(fs, "/repo/web")
+	require.Equal(t, "/repo", m.Root())
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"/repo/dist", true, true},
+		{"/repo/dist/app.js", false, true},
+		{"/repo/src/dist", false, false}, // dir-only pattern
+		{"/repo/app.log", false, true},
+		{"/repo/keep.log", false, false},
+		{"/repo/tmp", true, true},
+		{"/repo/src/tmp", true, false},
+		{"/repo/fixtures/a.go", false, true},
+		{"/repo/local.txt", false, true},
+		{"/repo/web/generated/api.ts", false, true},
+		{"/repo/generated", true, false}, // web/.gitignore only applies under web/
+		{"/repo/web/important.log", false, false},
+		{"/repo/node_modules/x/index.js", false, true},
+		{"/repo/.git/config", false, true},
+		{"/repo/vendor", true, true},
+		{"/repo/sub/vendor", true, false}, // re-included by a deeper ignore file
+		{"/repo/main.go", false, false},
+		{"/elsewhere/dist", true, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, m.Ignored(tt.path, tt.isDir), tt.path)
+	}
+}
+
+func TestWalk_SkipsIgnored(t *testing.T) {
+	fs := newRepo(t, map[string]string{
+		".gitignore":          "dist/\n*.tmp\n",
+		"main.go":             "",
+		"scratch.tmp":         "",
+		"dist/bundle.js":      "",
+		"node_modules/m/i.js": "",
+		"pkg/a.go":            "",
+		"pkg/.claudexignore":  "testdata/\n",
+		"pkg/testdata/x.json": "",
+	})
+
+	var visited []string
+	err := Walk(fs, "/repo", func(path string, info os.FileInfo, err error) error {
+		require.NoError(t, err)
+		if !info.IsDir() {
+			visited = append(visited, path)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	sort.Strings(visited)
+	assert.Equal(t, []string{
+		"/repo/.gitignore",
+		"/repo/main.go",
+		"/repo/pkg/.claudexignore",
+		"/repo/pkg/a.go",
+	}, visited)
+}
+
+func TestMatcher_ReadDir(t *testing.T) {
+	fs := newRepo(t, map[string]string{
+		".gitignore": "*.out\n",
+		"a.go":       "",
+		"b.out":      "",
+		"dir/c.go":   "",
+	})
+
+	entries, err := New(fs, "/repo").ReadDir("/repo")
+	require.NoError(t, err)
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.ElementsMatch(t, []string{".gitignore", "a.go", "dir"}, names)
+}
+
+func TestCompileGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{".env", ".env", true},
+		{".env", "config/.env", true},
+		{".env.*", "config/.env.local", true},
+		{"secrets/", "secrets/key.pem", true},
+		{"secrets/", "secrets", false}, // dir-only patterns need a path below
+		{"/docs/*.md", "docs/a.md", true},
+		{"/docs/*.md", "web/docs/a.md", false},
+		{"**/*.md", "a/b/c.md", true},
+		{"*.go", "main.py", false},
+	}
+	for _, tt := range tests {
+		g, err := CompileGlob(tt.pattern)
+		require.NoError(t, err, tt.pattern)
+		assert.Equal(t, tt.want, g.Match(tt.path), "%s vs %s", tt.pattern, tt.path)
+	}
+
+	_, err := CompileGlob("!negated")
+	assert.Error(t, err)
+	_, err = CompileGlob("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,170 @@
+package ignore
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// rule is one pattern line of an ignore file
+type rule struct {
+	// base is the directory of the ignore file, relative to the root ("" for the root)
+	base string
+
+	// anchored patterns match the path relative to base; others match any base name
+	anchored bool
+	dirOnly  bool
+	negate   bool
+	pattern  *regexp.Regexp
+}
+
+// parseRules parses the lines of an ignore file located in base
+func parseRules(base, content string) []rule {
+	var rules []rule
+	for _, line := range strings.Split(content, "\n") {
+		if r, ok := parseRule(base, line); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// parseRule parses one line using gitignore syntax
+func parseRule(base, line string) (rule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	// Trailing spaces are ignored unless escaped
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule{}, false
+	}
+
+	r := rule{base: base}
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return rule{}, false
+	}
+
+	// A slash at the start or in the middle anchors the pattern to base
+	if strings.Contains(line, "/") {
+		r.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+
+	re, err := regexp.Compile("^" + globToRegexp(line) + "$")
+	if err != nil {
+		return rule{}, false
+	}
+	r.pattern = re
+	return r, true
+}
+
+// matches reports whether rel (slash-separated, relative to the root) matches the rule
+func (r rule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		if !strings.HasPrefix(rel, r.base+"/") {
+			return false
+		}
+		rel = rel[len(r.base)+1:]
+	}
+	if r.anchored {
+		return r.pattern.MatchString(rel)
+	}
+	return r.pattern.MatchString(path.Base(rel))
+}
+
+// globToRegexp translates a gitignore glob to a regular expression: * and ?
+// stay within one path segment, ** spans segments, and [...] is a character class
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				atStart := i == 0 || glob[i-1] == '/'
+				i++
+				switch {
+				case atStart && i+1 < len(glob) && glob[i+1] == '/':
+					// "**/" matches zero or more directories
+					b.WriteString("(?:.*/)?")
+					i++
+				default:
+					b.WriteString(".*")
+				}
+				continue
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// Glob is a single gitignore-style pattern for matching paths outside of
+// ignore files, such as policy rules
+type Glob struct {
+	rule rule
+}
+
+// CompileGlob compiles pattern with gitignore semantics: a pattern with a
+// slash matches the whole relative path, others match any path segment, and
+// a match on a directory covers everything below it
+func CompileGlob(pattern string) (Glob, error) {
+	r, ok := parseRule("", pattern)
+	if !ok || r.negate {
+		return Glob{}, fmt.Errorf("invalid glob %q", pattern)
+	}
+	return Glob{rule: r}, nil
+}
+
+// Match reports whether the slash-separated relative path, or one of its
+// parent directories, matches the glob
+func (g Glob) Match(rel string) bool {
+	rel = strings.Trim(rel, "/")
+	if rel == "" {
+		return false
+	}
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if g.rule.matches(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return g.rule.matches(rel, false)
+}
@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// waitDelay bounds how long Wait blocks on output pipes after a timeout kill
+const waitDelay = 5 * time.Second
+
+// OsExecutor is the production implementation of Executor
+type OsExecutor struct{}
+
+// NewExecutor creates an Executor that runs real processes
+func NewExecutor() Executor {
+	return &OsExecutor{}
+}
+
+// Execute runs the job process, killing it when ctx is done
+func (e *OsExecutor) Execute(ctx context.Context, job Job, started func(pid int)) Result {
+	cmd := exec.CommandContext(ctx, job.Command, job.Args...)
+	cmd.Env = append(os.Environ(), job.Env...)
+	cmd.WaitDelay = waitDelay
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return Result{ExitCode: -1, Err: err}
+	}
+	started(cmd.Process.Pid)
+
+	err := cmd.Wait()
+	return Result{
+		ExitCode: cmd.ProcessState.ExitCode(),
+		Stdout:   stdout.Bytes(),
+		Stderr:   stderr.Bytes(),
+		Err:      err,
+	}
+}
@@ -0,0 +1,150 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"claudex/internal/services/lock"
+
+	"github.com/spf13/afero"
+)
+
+// jobLog is the on-disk layout of the job log
+type jobLog struct {
+	Jobs []Record `json:"jobs"`
+}
+
+// Load reads all records from the job log at path, oldest first. Running
+// records whose claudex process is gone are returned as orphaned.
+// Returns an empty list if the log does not exist.
+func Load(fs afero.Fs, path string) ([]Record, error) {
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var log jobLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(log.Jobs, func(i, j int) bool {
+		return log.Jobs[i].StartedAt.Before(log.Jobs[j].StartedAt)
+	})
+	for i := range log.Jobs {
+		markOrphaned(&log.Jobs[i])
+	}
+	return log.Jobs, nil
+}
+
+// markOrphaned marks a running record orphaned when the process keeping it
+// current has exited. Records written before supervisors were recorded fall
+// back to the job process itself.
+func markOrphaned(r *Record) {
+	if r.Status != StatusRunning {
+		return
+	}
+	switch {
+	case r.SupervisorPID != 0:
+		if lock.ProcessAlive(r.SupervisorPID, r.SupervisorStart) {
+			return
+		}
+		r.Error = fmt.Sprintf("claudex process %d exited before the job finished", r.SupervisorPID)
+	case r.PID != 0:
+		if lock.ProcessAlive(r.PID, "") {
+			return
+		}
+		r.Error = fmt.Sprintf("process %d exited without its result being recorded", r.PID)
+	default:
+		return
+	}
+	r.Status = StatusOrphaned
+}
+
+// logLockWait bounds how long a write waits for another process that is
+// updating the same log
+const logLockWait = 5 * time.Second
+
+// upsert inserts or replaces record in the log at path, keeping at most
+// maxRecords entries (oldest finished records are dropped first). Several
+// claudex processes can share a log (post-commit updates and docs create
+// --recursive), so each read-modify-write holds path.lock.
+func upsert(fs afero.Fs, path string, record Record, maxRecords int) error {
+	l, err := acquireLogLock(fs, path)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	records, err := Load(fs, path)
+	if err != nil {
+		// A corrupt log should not block new jobs; start over
+		records = nil
+	}
+
+	replaced := false
+	for i := range records {
+		if records[i].ID == record.ID {
+			records[i] = record
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		records = append(records, record)
+	}
+
+	for len(records) > maxRecords {
+		drop := 0
+		for i, r := range records {
+			if r.Status != StatusRunning {
+				drop = i
+				break
+			}
+		}
+		records = append(records[:drop], records[drop+1:]...)
+	}
+
+	data, err := json.MarshalIndent(jobLog{Jobs: records}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file of our own first, then rename atomically
+	file, err := afero.TempFile(fs, filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = fs.Rename(file.Name(), path)
+	}
+	if err != nil {
+		fs.Remove(file.Name())
+	}
+	return err
+}
+
+// acquireLogLock takes the lock guarding the log at path, waiting up to
+// logLockWait while another process holds it
+func acquireLogLock(fs afero.Fs, path string) (*lock.Lock, error) {
+	svc := lock.New(fs)
+	deadline := time.Now().Add(logLockWait)
+	for {
+		l, err := svc.Acquire(path + ".lock")
+		if err == nil || !errors.Is(err, os.ErrExist) || time.Now().After(deadline) {
+			return l, err
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,158 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"claudex/internal/services/lock"
+
+	"github.com/spf13/afero"
+)
+
+// Supervisor runs jobs with bounded concurrency and records them in a job log
+type Supervisor struct {
+	fs       afero.Fs
+	logPath  string
+	opts     Options
+	executor Executor
+
+	mu  sync.Mutex // serializes job log writes
+	seq int
+
+	// pid and start identify this process in the records it writes
+	pid   int
+	start string
+}
+
+// New creates a Supervisor that persists its job log at logPath
+func New(fs afero.Fs, logPath string, opts Options, executor Executor) *Supervisor {
+	if opts.MaxConcurrent <= 0 {
+		opts.MaxConcurrent = DefaultMaxConcurrent
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.MaxRecords <= 0 {
+		opts.MaxRecords = DefaultMaxRecords
+	}
+	return &Supervisor{
+		fs:       fs,
+		logPath:  logPath,
+		opts:     opts,
+		executor: executor,
+		pid:      os.Getpid(),
+		start:    lock.ProcessStartTime(os.Getpid()),
+	}
+}
+
+// Run executes the jobs, at most MaxConcurrent at a time, and blocks until
+// every job has finished. Records are returned in the order of jobs.
+func (s *Supervisor) Run(jobs []Job) []Record {
+	records := make([]Record, len(jobs))
+	sem := make(chan struct{}, s.opts.MaxConcurrent)
+
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		go func(i int, job Job) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			records[i] = s.runOne(job)
+		}(i, job)
+	}
+	wg.Wait()
+
+	return records
+}
+
+// runOne executes a single job and keeps its log record current
+func (s *Supervisor) runOne(job Job) Record {
+	record := Record{
+		ID:        s.nextID(),
+		Name:      job.Name,
+		Command:   strings.TrimSpace(job.Command + " " + summarizeArgs(job.Args)),
+		Status:    StatusRunning,
+		StartedAt: time.Now(),
+
+		SupervisorPID:   s.pid,
+		SupervisorStart: s.start,
+	}
+	s.save(record)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.Timeout)
+	defer cancel()
+
+	result := s.executor.Execute(ctx, job, func(pid int) {
+		record.PID = pid
+		s.save(record)
+	})
+
+	record.FinishedAt = time.Now()
+	record.ExitCode = result.ExitCode
+	record.Stdout = tail(result.Stdout)
+	record.Stderr = tail(result.Stderr)
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		record.Status = StatusTimedOut
+		record.Error = fmt.Sprintf("timed out after %s", s.opts.Timeout)
+	case result.Err != nil:
+		record.Status = StatusFailed
+		record.Error = result.Err.Error()
+	case result.ExitCode != 0:
+		record.Status = StatusFailed
+		record.Error = fmt.Sprintf("exit status %d", result.ExitCode)
+	default:
+		record.Status = StatusSucceeded
+	}
+	s.save(record)
+
+	if record.Status != StatusSucceeded {
+		log.Printf("Job %s (%s) %s: %s", record.ID, record.Name, record.Status, record.Error)
+	}
+	return record
+}
+
+// save persists the record; log write failures never fail the job
+func (s *Supervisor) save(record Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := upsert(s.fs, s.logPath, record, s.opts.MaxRecords); err != nil {
+		log.Printf("Warning: could not write job log: %v", err)
+	}
+}
+
+// nextID returns a unique job ID for this supervisor
+func (s *Supervisor) nextID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	return fmt.Sprintf("%s-%d-%d", time.Now().Format("20060102-150405"), s.pid, s.seq)
+}
+
+// summarizeArgs renders args for the log, shortening long values (e.g., prompts)
+func summarizeArgs(args []string) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		if len(arg) > 40 || strings.Contains(arg, "\n") {
+			arg = "<" + fmt.Sprint(len(arg)) + " chars>"
+		}
+		parts[i] = arg
+	}
+	return strings.Join(parts, " ")
+}
+
+// tail keeps the last maxOutputBytes of captured output
+func tail(output []byte) string {
+	if len(output) > maxOutputBytes {
+		output = output[len(output)-maxOutputBytes:]
+	}
+	return string(output)
+}
@@ -0,0 +1,234 @@
+package jobs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// fakeExecutor simulates job processes without spawning them
+type fakeExecutor struct {
+	mu         sync.Mutex
+	running    int
+	maxRunning int
+	delay      time.Duration
+	results    map[string]Result
+}
+
+func (f *fakeExecutor) Execute(ctx context.Context, job Job, started func(pid int)) Result {
+	f.mu.Lock()
+	f.running++
+	if f.running > f.maxRunning {
+		f.maxRunning = f.running
+	}
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.running--
+		f.mu.Unlock()
+	}()
+
+	started(4242)
+
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return Result{ExitCode: -1, Err: ctx.Err()}
+	}
+	return f.results[job.Name]
+}
+
+func TestSupervisor_Run_LimitsConcurrency(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	executor := &fakeExecutor{delay: 10 * time.Millisecond}
+	sup := New(fs, "/jobs.json", Options{MaxConcurrent: 2}, executor)
+
+	var batch []Job
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		batch = append(batch, Job{Name: name, Command: "true"})
+	}
+	records := sup.Run(batch)
+
+	if executor.maxRunning > 2 {
+		t.Errorf("expected at most 2 concurrent jobs, got %d", executor.maxRunning)
+	}
+	for i, r := range records {
+		if r.Name != batch[i].Name {
+			t.Errorf("record %d: expected name %s, got %s", i, batch[i].Name, r.Name)
+		}
+		if r.Status != StatusSucceeded {
+			t.Errorf("record %d: expected status succeeded, got %s", i, r.Status)
+		}
+	}
+}
+
+func TestSupervisor_Run_RecordsFailuresAndTimeouts(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	executor := &fakeExecutor{
+		results: map[string]Result{
+			"fails": {ExitCode: 2, Stderr: []byte("boom")},
+		},
+	}
+	sup := New(fs, "/jobs.json", Options{Timeout: 50 * time.Millisecond}, executor)
+
+	records := sup.Run([]Job{{Name: "fails", Command: "claude"}})
+	if records[0].Status != StatusFailed || records[0].ExitCode != 2 || records[0].Stderr != "boom" {
+		t.Errorf("expected failed record with exit 2 and stderr, got %+v", records[0])
+	}
+
+	executor.delay = time.Second
+	records = sup.Run([]Job{{Name: "slow", Command: "claude"}})
+	if records[0].Status != StatusTimedOut {
+		t.Errorf("expected timed_out, got %s", records[0].Status)
+	}
+
+	logged, err := Load(fs, "/jobs.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(logged) != 2 {
+		t.Fatalf("expected 2 logged records, got %d", len(logged))
+	}
+	if logged[0].PID != 4242 || logged[0].FinishedAt.IsZero() {
+		t.Errorf("expected finished record with PID, got %+v", logged[0])
+	}
+}
+
+func TestSupervisor_Run_TrimsLog(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sup := New(fs, "/jobs.json", Options{MaxConcurrent: 1, MaxRecords: 3}, &fakeExecutor{})
+
+	for i := 0; i < 5; i++ {
+		sup.Run([]Job{{Name: "job", Command: "true"}})
+	}
+
+	logged, err := Load(fs, "/jobs.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(logged) != 3 {
+		t.Errorf("expected log trimmed to 3 records, got %d", len(logged))
+	}
+}
+
+func TestSupervisor_Run_SharedLog(t *testing.T) {
+	// Supervisors of separate claudex processes write the same log
+	fs := afero.NewOsFs()
+	logPath := t.TempDir() + "/jobs.json"
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sup := New(fs, logPath, Options{MaxConcurrent: 5}, &fakeExecutor{})
+			sup.seq = i * 100 // job IDs of separate processes differ by PID
+			jobs := make([]Job, 5)
+			for j := range jobs {
+				jobs[j] = Job{Name: "job", Command: "true"}
+			}
+			sup.Run(jobs)
+		}(i)
+	}
+	wg.Wait()
+
+	logged, err := Load(fs, logPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(logged) != 40 {
+		t.Fatalf("expected 40 records, got %d", len(logged))
+	}
+	for _, r := range logged {
+		if r.Status != StatusSucceeded {
+			t.Errorf("expected job %s to be recorded as succeeded, got %s", r.ID, r.Status)
+		}
+	}
+	if entries, _ := afero.ReadDir(fs, filepath.Dir(logPath)); len(entries) != 1 {
+		t.Errorf("expected only the log to remain, got %d files", len(entries))
+	}
+}
+
+func TestLoad_MissingLog(t *testing.T) {
+	records, err := Load(afero.NewMemMapFs(), "/missing.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("expected no records, got %d", len(records))
+	}
+}
+
+func TestLoad_MarksOrphanedJobs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process liveness is not checked on this platform")
+	}
+	fs := afero.NewMemMapFs()
+	const dead = 1<<31 - 1 // beyond any PID the system hands out
+	started := time.Now().Add(-time.Minute)
+	for _, r := range []Record{
+		{ID: "live", Status: StatusRunning, PID: dead, SupervisorPID: os.Getpid(), StartedAt: started},
+		{ID: "killed", Status: StatusRunning, PID: os.Getpid(), SupervisorPID: dead, StartedAt: started},
+		{ID: "legacy", Status: StatusRunning, PID: dead, StartedAt: started},
+		{ID: "done", Status: StatusSucceeded, SupervisorPID: dead, StartedAt: started},
+	} {
+		if err := upsert(fs, "/jobs.json", r, DefaultMaxRecords); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := Load(fs, "/jobs.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	status := make(map[string]Status)
+	for _, r := range records {
+		status[r.ID] = r.Status
+	}
+	want := map[string]Status{"live": StatusRunning, "killed": StatusOrphaned, "legacy": StatusOrphaned, "done": StatusSucceeded}
+	for id, s := range want {
+		if status[id] != s {
+			t.Errorf("%s: expected %s, got %s", id, s, status[id])
+		}
+	}
+}
+
+func TestSupervisor_Run_RecordsSupervisor(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sup := New(fs, "/jobs.json", Options{}, &fakeExecutor{})
+	records := sup.Run([]Job{{Name: "job", Command: "true"}})
+	if records[0].SupervisorPID != os.Getpid() {
+		t.Errorf("expected supervisor pid %d, got %d", os.Getpid(), records[0].SupervisorPID)
+	}
+}
+
+func TestOsExecutor_CapturesOutputAndExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	var pid int
+	result := NewExecutor().Execute(context.Background(), Job{
+		Command: "sh",
+		Args:    []string{"-c", "echo out; echo err >&2; echo $CLAUDEX_TEST_VAR; exit 3"},
+		Env:     []string{"CLAUDEX_TEST_VAR=set"},
+	}, func(p int) { pid = p })
+
+	if pid == 0 {
+		t.Error("expected started callback with PID")
+	}
+	if result.ExitCode != 3 {
+		t.Errorf("expected exit code 3, got %d", result.ExitCode)
+	}
+	if string(result.Stdout) != "out\nset\n" {
+		t.Errorf("expected stdout %q, got %q", "out\nset\n", result.Stdout)
+	}
+	if string(result.Stderr) != "err\n" {
+		t.Errorf("expected stderr %q, got %q", "err\n", result.Stderr)
+	}
+}
@@ -0,0 +1,103 @@
+// Package jobs provides a supervisor for external processes run on behalf of
+// Claudex (e.g., Claude invocations that regenerate index.md files).
+// It bounds concurrency, enforces per-job timeouts, captures output and exit
+// status, and persists a job log so running and failed jobs can be inspected.
+package jobs
+
+import (
+	"context"
+	"time"
+)
+
+// Status describes the state of a job
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusTimedOut  Status = "timed_out"
+
+	// StatusOrphaned marks a job still recorded as running whose claudex
+	// process exited (e.g., was killed) before recording how it ended
+	StatusOrphaned Status = "orphaned"
+)
+
+// Job describes a process to run
+type Job struct {
+	// Name identifies the job in the log (e.g., the index.md being updated)
+	Name string
+
+	// Command and Args form the process invocation
+	Command string
+	Args    []string
+
+	// Env holds extra KEY=VALUE entries added to the current environment
+	Env []string
+}
+
+// Record is the persisted state of one job run
+type Record struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Command string `json:"command"`
+	Status  Status `json:"status"`
+	PID     int    `json:"pid,omitempty"`
+
+	// SupervisorPID and SupervisorStart identify the claudex process that
+	// runs the job and keeps this record current
+	SupervisorPID   int    `json:"supervisor_pid,omitempty"`
+	SupervisorStart string `json:"supervisor_start,omitempty"`
+
+	ExitCode   int       `json:"exit_code"`
+	Error      string    `json:"error,omitempty"`
+	Stdout     string    `json:"stdout,omitempty"`
+	Stderr     string    `json:"stderr,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+}
+
+// Duration returns how long the job ran, or has been running so far
+func (r Record) Duration(now time.Time) time.Duration {
+	if r.FinishedAt.IsZero() {
+		return now.Sub(r.StartedAt)
+	}
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// Result is the outcome of executing a job process
+type Result struct {
+	ExitCode int
+	Stdout   []byte
+	Stderr   []byte
+
+	// Err is set when the process could not be started or did not exit cleanly
+	Err error
+}
+
+// Executor runs a job process until it exits or ctx is done.
+// started is called with the PID once the process is running.
+type Executor interface {
+	Execute(ctx context.Context, job Job, started func(pid int)) Result
+}
+
+// Options configures a Supervisor
+type Options struct {
+	// MaxConcurrent bounds how many jobs run at once (<= 0 means DefaultMaxConcurrent)
+	MaxConcurrent int
+
+	// Timeout bounds each job's run time (<= 0 means DefaultTimeout)
+	Timeout time.Duration
+
+	// MaxRecords bounds how many records the log keeps (<= 0 means DefaultMaxRecords)
+	MaxRecords int
+}
+
+const (
+	DefaultMaxConcurrent = 4
+	DefaultTimeout       = 10 * time.Minute
+	DefaultMaxRecords    = 200
+
+	// maxOutputBytes bounds the captured stdout/stderr kept per record (tail is kept)
+	maxOutputBytes = 4096
+)
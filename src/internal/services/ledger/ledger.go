@@ -0,0 +1,273 @@
+// Package ledger records the files a session changes. The PostToolUse hook
+// appends one entry per changed file to the session's changes.jsonl: the
+// tool, the agent that called it, when, and a hash of the content the change
+// left behind, so a session's footprint can be reviewed or rolled back.
+package ledger
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"claudex/internal/services/shellcmd"
+
+	"github.com/spf13/afero"
+)
+
+// FileName is the ledger file, in the session folder
+const FileName = "changes.jsonl"
+
+// fileTools are the tools whose path field names the file they change
+var fileTools = map[string]string{
+	"Write":        "file_path",
+	"Edit":         "file_path",
+	"MultiEdit":    "file_path",
+	"NotebookEdit": "notebook_path",
+}
+
+// Entry is one change to one file
+type Entry struct {
+	Time time.Time `json:"time"`
+	Tool string    `json:"tool"`
+
+	// Path is absolute
+	Path string `json:"path"`
+
+	// AgentID and AgentType identify the subagent that made the change; both
+	// are empty for the main agent
+	AgentID   string `json:"agent_id,omitempty"`
+	AgentType string `json:"agent_type,omitempty"`
+	ToolUseID string `json:"tool_use_id,omitempty"`
+
+	// Hash is the sha256 of the file content after the change; it is empty
+	// for directories and removed files
+	Hash    string `json:"hash,omitempty"`
+	Deleted bool   `json:"deleted,omitempty"`
+
+	// Command is the Bash command that made the change
+	Command string `json:"command,omitempty"`
+
+	// Transcript is the Claude Code transcript holding the tool call
+	Transcript string `json:"transcript,omitempty"`
+}
+
+// Call describes a completed tool call
+type Call struct {
+	Tool       string
+	Input      map[string]interface{}
+	CWD        string
+	AgentID    string
+	AgentType  string
+	ToolUseID  string
+	Transcript string
+	Time       time.Time
+}
+
+// Path returns the ledger path of a session
+func Path(sessionPath string) string {
+	return filepath.Join(sessionPath, FileName)
+}
+
+// IsFileTool reports whether a tool edits the file named by its input
+// (Write, Edit, MultiEdit, NotebookEdit)
+func IsFileTool(tool string) bool {
+	_, ok := fileTools[tool]
+	return ok
+}
+
+// Targets returns the absolute paths a tool call changed: the path field of
+// file editing tools, and the files a Bash command writes, moves or removes
+// where the command makes that apparent (see shellcmd.Writes)
+func Targets(tool string, input map[string]interface{}, cwd string) []string {
+	if key, ok := fileTools[tool]; ok {
+		path, _ := input[key].(string)
+		if path == "" {
+			return nil
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(cwd, path)
+		}
+		return []string{filepath.Clean(path)}
+	}
+	if tool == "Bash" {
+		command, _ := input["command"].(string)
+		return shellcmd.Writes(command, cwd)
+	}
+	return nil
+}
+
+// Entries builds the ledger entries of a call, hashing the current content
+// of each changed file
+func Entries(fs afero.Fs, call Call) []Entry {
+	var entries []Entry
+	for _, path := range Targets(call.Tool, call.Input, call.CWD) {
+		e := Entry{
+			Time:       call.Time,
+			Tool:       call.Tool,
+			Path:       path,
+			AgentID:    call.AgentID,
+			AgentType:  call.AgentType,
+			ToolUseID:  call.ToolUseID,
+			Transcript: call.Transcript,
+		}
+		if call.Tool == "Bash" {
+			e.Command, _ = call.Input["command"].(string)
+		}
+		hash, exists := Hash(fs, path)
+		e.Hash = hash
+		e.Deleted = !exists
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// Hash returns the sha256 of a file's content and whether the path exists;
+// directories and unreadable files hash to ""
+func Hash(fs afero.Fs, path string) (string, bool) {
+	info, err := fs.Stat(path)
+	if err != nil {
+		return "", !os.IsNotExist(err)
+	}
+	if info.IsDir() {
+		return "", true
+	}
+	f, err := fs.Open(path)
+	if err != nil {
+		return "", true
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := bufio.NewReader(f).WriteTo(h); err != nil {
+		return "", true
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), true
+}
+
+// Append adds entries to a session's ledger in a single write, so concurrent
+// hooks of the same session do not interleave lines
+func Append(fs afero.Fs, sessionPath string, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	for _, e := range entries {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		sb.Write(data)
+		sb.WriteByte('\n')
+	}
+
+	f, err := fs.OpenFile(Path(sessionPath), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write([]byte(sb.String())); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read returns a session's ledger entries in order; a missing ledger is
+// empty and malformed lines are skipped
+func Read(fs afero.Fs, sessionPath string) ([]Entry, error) {
+	f, err := fs.Open(Path(sessionPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || e.Path == "" {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// File summarizes the changes to one file
+type File struct {
+	Path    string
+	Changes int
+	Tools   []string
+
+	// Agents lists the agent types (or IDs) that changed the file, with
+	// MainAgent for the main agent
+	Agents []string
+	First  time.Time
+	Last   time.Time
+
+	// Hash and Deleted describe the file after the session's last change
+	Hash    string
+	Deleted bool
+}
+
+// MainAgent names the main agent in summaries
+const MainAgent = "main"
+
+// Files groups entries by path, sorted by path
+func Files(entries []Entry) []File {
+	index := make(map[string]int)
+	var files []File
+	for _, e := range entries {
+		i, ok := index[e.Path]
+		if !ok {
+			i = len(files)
+			index[e.Path] = i
+			files = append(files, File{Path: e.Path, First: e.Time})
+		}
+		f := &files[i]
+		f.Changes++
+		f.Tools = appendUnique(f.Tools, e.Tool)
+		f.Agents = appendUnique(f.Agents, e.Agent())
+		if e.Time.Before(f.First) {
+			f.First = e.Time
+		}
+		if !e.Time.Before(f.Last) {
+			f.Last = e.Time
+			f.Hash = e.Hash
+			f.Deleted = e.Deleted
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
+
+// Agent names the agent that made the change: its type, its ID, or
+// MainAgent
+func (e Entry) Agent() string {
+	switch {
+	case e.AgentType != "":
+		return e.AgentType
+	case e.AgentID != "":
+		return e.AgentID
+	default:
+		return MainAgent
+	}
+}
+
+// appendUnique appends value unless the list already holds it
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
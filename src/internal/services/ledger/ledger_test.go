@@ -0,0 +1,85 @@
+package ledger
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargets_FileTools(t *testing.T) {
+	assert.Equal(t, []string{"/repo/a.go"}, Targets("Write", map[string]interface{}{"file_path": "/repo/a.go"}, "/repo"))
+	assert.Equal(t, []string{"/repo/pkg/b.go"}, Targets("Edit", map[string]interface{}{"file_path": "pkg/b.go"}, "/repo"))
+	assert.Equal(t, []string{"/repo/n.ipynb"}, Targets("NotebookEdit", map[string]interface{}{"notebook_path": "/repo/n.ipynb"}, "/repo"))
+	assert.Equal(t, []string{"/repo/x"}, Targets("Bash", map[string]interface{}{"command": "touch x"}, "/repo"))
+	assert.Empty(t, Targets("Read", map[string]interface{}{"file_path": "/repo/a.go"}, "/repo"))
+	assert.Empty(t, Targets("Write", map[string]interface{}{}, "/repo"))
+
+	assert.True(t, IsFileTool("MultiEdit"))
+	assert.False(t, IsFileTool("Bash"))
+}
+
+func TestEntries_AppendAndRead(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/repo/.claudex/sessions/s1", 0755))
+	require.NoError(t, afero.WriteFile(fs, "/repo/kept.go", []byte("package kept\n"), 0644))
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	entries := Entries(fs, Call{
+		Tool:      "Bash",
+		Input:     map[string]interface{}{"command": "rm gone.go && touch kept.go"},
+		CWD:       "/repo",
+		AgentID:   "agent-1",
+		AgentType: "Explore",
+		ToolUseID: "toolu_1",
+		Time:      now,
+	})
+	require.Len(t, entries, 2)
+	assert.Equal(t, Entry{Time: now, Tool: "Bash", Path: "/repo/gone.go", AgentID: "agent-1", AgentType: "Explore",
+		ToolUseID: "toolu_1", Deleted: true, Command: "rm gone.go && touch kept.go"}, entries[0])
+	hash, exists := Hash(fs, "/repo/kept.go")
+	assert.True(t, exists)
+	assert.Equal(t, hash, entries[1].Hash)
+	assert.Len(t, hash, len("sha256:")+64)
+
+	sessionPath := "/repo/.claudex/sessions/s1"
+	require.NoError(t, Append(fs, sessionPath, entries))
+	require.NoError(t, Append(fs, sessionPath, nil))
+
+	// Malformed lines are skipped
+	f, err := fs.OpenFile(Path(sessionPath), os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("not json\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.NoError(t, Append(fs, sessionPath, Entries(fs, Call{Tool: "Write", Input: map[string]interface{}{"file_path": "/repo/kept.go"}, CWD: "/repo", Time: now.Add(time.Minute)})))
+
+	read, err := Read(fs, sessionPath)
+	require.NoError(t, err)
+	require.Len(t, read, 3)
+	assert.Equal(t, entries[0], read[0])
+	assert.Equal(t, "Write", read[2].Tool)
+
+	// A session without a ledger has no entries
+	read, err = Read(fs, "/repo/.claudex/sessions/none")
+	require.NoError(t, err)
+	assert.Empty(t, read)
+}
+
+func TestFiles(t *testing.T) {
+	base := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	files := Files([]Entry{
+		{Time: base, Tool: "Write", Path: "/repo/b.go", Hash: "sha256:1"},
+		{Time: base.Add(time.Minute), Tool: "Edit", Path: "/repo/b.go", AgentType: "Plan", Hash: "sha256:2"},
+		{Time: base.Add(2 * time.Minute), Tool: "Bash", Path: "/repo/a.go", AgentID: "agent-9", Deleted: true},
+		{Time: base.Add(3 * time.Minute), Tool: "Edit", Path: "/repo/b.go", Hash: "sha256:3"},
+	})
+	require.Len(t, files, 2)
+	assert.Equal(t, File{Path: "/repo/a.go", Changes: 1, Tools: []string{"Bash"}, Agents: []string{"agent-9"},
+		First: base.Add(2 * time.Minute), Last: base.Add(2 * time.Minute), Deleted: true}, files[0])
+	assert.Equal(t, File{Path: "/repo/b.go", Changes: 3, Tools: []string{"Write", "Edit"}, Agents: []string{MainAgent, "Plan"},
+		First: base, Last: base.Add(3 * time.Minute), Hash: "sha256:3"}, files[1])
+}
@@ -1,29 +1,80 @@
 package lock
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/afero"
 )
 
+// processTable abstracts process inspection for testability
+type processTable interface {
+	// Alive reports whether a process with the given PID exists
+	Alive(pid int) bool
+
+	// StartTime returns an opaque token identifying the process instance,
+	// or "" if unsupported or unknown
+	StartTime(pid int) string
+}
+
 // FileLock is the production implementation of LockService
 type FileLock struct {
-	fs afero.Fs
+	fs    afero.Fs
+	opts  Options
+	procs processTable
+	host  string
+	now   func() time.Time
 }
 
-// New creates a new LockService instance
+// New creates a new LockService instance with default stale detection
 func New(fs afero.Fs) LockService {
+	return NewWithOptions(fs, Options{})
+}
+
+// NewWithOptions creates a new LockService instance with the given options
+func NewWithOptions(fs afero.Fs, opts Options) LockService {
+	if opts.TTL == 0 {
+		opts.TTL = DefaultTTL
+	}
+	host, _ := os.Hostname()
 	return &FileLock{
-		fs: fs,
+		fs:    fs,
+		opts:  opts,
+		procs: osProcessTable{},
+		host:  host,
+		now:   time.Now,
 	}
 }
 
 // Acquire attempts to acquire a lock at the specified path.
 // Uses O_CREATE|O_EXCL flags for atomic acquisition to prevent race conditions.
-// Writes the current process PID to the lock file for debugging purposes.
+// Writes the current process PID (first line) and holder metadata to the lock file.
+// A stale lock is removed and acquisition retried once.
 // Returns a Lock object if successful, or an error if the lock is already held.
 func (fl *FileLock) Acquire(path string) (*Lock, error) {
+	lock, err := fl.create(path)
+	if err == nil || !errors.Is(err, os.ErrExist) {
+		return lock, err
+	}
+
+	info, inspectErr := fl.Inspect(path)
+	if inspectErr != nil || info == nil || !info.Stale {
+		return nil, err
+	}
+	if removeErr := fl.removeIfUnchanged(path, info); removeErr != nil {
+		return nil, err
+	}
+	return fl.create(path)
+}
+
+// create atomically creates the lock file and records the holder
+func (fl *FileLock) create(path string) (*Lock, error) {
 	// Use O_CREATE|O_EXCL for atomic lock acquisition
 	// This ensures only one process can create the file
 	file, err := fl.fs.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
@@ -32,10 +83,23 @@ func (fl *FileLock) Acquire(path string) (*Lock, error) {
 		return nil, fmt.Errorf("failed to acquire lock: %w", err)
 	}
 
-	// Write current PID to lock file for debugging
+	if fl.opts.Flock {
+		if err := tryFlock(file); err != nil && !errors.Is(err, errFlockUnsupported) {
+			file.Close()
+			fl.fs.Remove(path)
+			return nil, fmt.Errorf("failed to acquire lock: %w", err)
+		}
+	}
+
 	pid := os.Getpid()
-	if _, err := file.Write([]byte(fmt.Sprintf("%d\n", pid))); err != nil {
-		// Clean up the lock file if we can't write the PID
+	content := fl.encode(Info{
+		PID:       pid,
+		StartTime: fl.procs.StartTime(pid),
+		Host:      fl.host,
+		CreatedAt: fl.now(),
+	})
+	if _, err := file.Write(content); err != nil {
+		// Clean up the lock file if we can't write the holder
 		file.Close()
 		fl.fs.Remove(path)
 		return nil, fmt.Errorf("failed to write PID to lock file: %w", err)
@@ -48,15 +112,151 @@ func (fl *FileLock) Acquire(path string) (*Lock, error) {
 	}, nil
 }
 
-// IsLocked checks if a lock file exists at the given path.
-// Returns true if the lock file exists, false otherwise.
+// IsLocked checks if a live lock exists at the given path.
+// Returns false if no lock file exists or the existing lock is stale.
 func (fl *FileLock) IsLocked(path string) (bool, error) {
-	_, err := fl.fs.Stat(path)
+	info, err := fl.Inspect(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to check lock status: %w", err)
+	}
+	return info != nil && !info.Stale, nil
+}
+
+// Inspect reads the lock at path and evaluates whether it is stale
+func (fl *FileLock) Inspect(path string) (*Info, error) {
+	stat, err := fl.fs.Stat(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return false, nil
+			return nil, nil
+		}
+		return nil, err
+	}
+	data, err := afero.ReadFile(fl.fs, path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	info := decode(data)
+	info.Path = path
+	if info.CreatedAt.IsZero() {
+		info.CreatedAt = stat.ModTime()
+	}
+	info.Stale, info.Reason = fl.staleness(path, info)
+	return &info, nil
+}
+
+// Clear removes the lock at path if it is stale, or unconditionally when force is set
+func (fl *FileLock) Clear(path string, force bool) (*Info, error) {
+	info, err := fl.Inspect(path)
+	if err != nil || info == nil {
+		return info, err
+	}
+	if !info.Stale && !force {
+		return info, fmt.Errorf("lock %s is held by running process %d (use force to remove it anyway)", path, info.PID)
+	}
+	if err := fl.fs.Remove(path); err != nil && !os.IsNotExist(err) {
+		return info, err
+	}
+	return info, nil
+}
+
+// staleness decides whether the holder of a lock is gone
+func (fl *FileLock) staleness(path string, info Info) (bool, string) {
+	if fl.opts.Flock {
+		held, err := flockHeld(fl.fs, path)
+		if err == nil && !held {
+			return true, "no process holds the flock"
+		}
+	}
+
+	if info.PID <= 0 {
+		return true, "lock file has no valid PID"
+	}
+
+	// PIDs are only meaningful on the host that wrote them
+	if info.Host == "" || info.Host == fl.host {
+		if !fl.procs.Alive(info.PID) {
+			return true, fmt.Sprintf("process %d is not running", info.PID)
+		}
+		if info.StartTime != "" {
+			current := fl.procs.StartTime(info.PID)
+			if current != "" && current != info.StartTime {
+				return true, fmt.Sprintf("PID %d now belongs to another process", info.PID)
+			}
+			if current == info.StartTime {
+				// The holder is confirmed running, however long it takes
+				return false, ""
+			}
+		}
+	}
+
+	// The TTL only applies where the holder's liveness cannot be confirmed:
+	// another host, or no start time to rule out a reused PID
+	if fl.opts.TTL > 0 {
+		if age := fl.now().Sub(info.CreatedAt); age > fl.opts.TTL {
+			return true, fmt.Sprintf("older than %s (held for %s)", fl.opts.TTL, age.Round(time.Second))
+		}
+	}
+	return false, ""
+}
+
+// removeIfUnchanged removes a stale lock unless another process replaced it
+// since it was inspected
+func (fl *FileLock) removeIfUnchanged(path string, stale *Info) error {
+	current, err := fl.Inspect(path)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return nil
+	}
+	if current.PID != stale.PID || !current.CreatedAt.Equal(stale.CreatedAt) {
+		return fmt.Errorf("lock %s changed while being recovered", path)
+	}
+	return fl.fs.Remove(path)
+}
+
+// encode renders the lock file: the PID on the first line, then key=value metadata
+func (fl *FileLock) encode(info Info) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%d\n", info.PID)
+	if info.StartTime != "" {
+		fmt.Fprintf(&b, "start=%s\n", info.StartTime)
+	}
+	if info.Host != "" {
+		fmt.Fprintf(&b, "host=%s\n", info.Host)
+	}
+	fmt.Fprintf(&b, "created=%s\n", info.CreatedAt.Format(time.RFC3339Nano))
+	return b.Bytes()
+}
+
+// decode parses a lock file; files from older versions only contain the PID
+func decode(data []byte) Info {
+	var info Info
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	first := true
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if first {
+			info.PID, _ = strconv.Atoi(line)
+			first = false
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "start":
+			info.StartTime = value
+		case "host":
+			info.Host = value
+		case "created":
+			info.CreatedAt, _ = time.Parse(time.RFC3339Nano, value)
 		}
-		return false, fmt.Errorf("failed to check lock status: %w", err)
 	}
-	return true, nil
+	return info
 }
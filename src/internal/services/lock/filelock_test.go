@@ -1,7 +1,11 @@
 package lock
 
 import (
+	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/afero"
 )
@@ -217,3 +221,181 @@ func TestFileLock_IsLocked_ErrorHandling(t *testing.T) {
 		t.Error("expected non-existent lock to return false")
 	}
 }
+
+// fakeProcessTable reports processes from a fixed table
+type fakeProcessTable struct {
+	alive map[int]string // pid -> start time
+}
+
+func (f *fakeProcessTable) Alive(pid int) bool {
+	_, ok := f.alive[pid]
+	return ok
+}
+
+func (f *fakeProcessTable) StartTime(pid int) string {
+	return f.alive[pid]
+}
+
+func newTestLock(fs afero.Fs, procs *fakeProcessTable, now time.Time) *FileLock {
+	fl := NewWithOptions(fs, Options{}).(*FileLock)
+	fl.procs = procs
+	fl.host = "devbox"
+	fl.now = func() time.Time { return now }
+	return fl
+}
+
+func writeLockFile(t *testing.T, fs afero.Fs, path, content string) {
+	t.Helper()
+	if err := afero.WriteFile(fs, path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write lock file: %v", err)
+	}
+}
+
+func TestFileLock_Inspect_Staleness(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-time.Minute).Format(time.RFC3339Nano)
+	old := now.Add(-3 * time.Hour).Format(time.RFC3339Nano)
+
+	tests := []struct {
+		name      string
+		content   string
+		wantStale bool
+		wantIn    string
+	}{
+		{
+			name:      "live holder",
+			content:   "42\nstart=100\nhost=devbox\ncreated=" + created + "\n",
+			wantStale: false,
+		},
+		{
+			name:      "dead holder",
+			content:   "43\nstart=100\nhost=devbox\ncreated=" + created + "\n",
+			wantStale: true,
+			wantIn:    "not running",
+		},
+		{
+			name:      "reused PID",
+			content:   "42\nstart=99\nhost=devbox\ncreated=" + created + "\n",
+			wantStale: true,
+			wantIn:    "another process",
+		},
+		{
+			name:      "live holder older than TTL",
+			content:   "42\nstart=100\nhost=devbox\ncreated=" + old + "\n",
+			wantStale: false,
+		},
+		{
+			name:      "expired without start time",
+			content:   "42\nhost=devbox\ncreated=" + old + "\n",
+			wantStale: true,
+			wantIn:    "older than",
+		},
+		{
+			name:      "expired on foreign host",
+			content:   "42\nstart=100\nhost=buildbox\ncreated=" + old + "\n",
+			wantStale: true,
+			wantIn:    "older than",
+		},
+		{
+			name:      "foreign host within TTL",
+			content:   "43\nhost=buildbox\ncreated=" + created + "\n",
+			wantStale: false,
+		},
+		{
+			name:      "legacy PID-only file of dead process",
+			content:   "43\n",
+			wantStale: true,
+			wantIn:    "not running",
+		},
+		{
+			name:      "garbage",
+			content:   "not-a-pid\n",
+			wantStale: true,
+			wantIn:    "no valid PID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			fl := newTestLock(fs, &fakeProcessTable{alive: map[int]string{42: "100"}}, now)
+			writeLockFile(t, fs, "/test.lock", tt.content)
+
+			info, err := fl.Inspect("/test.lock")
+			if err != nil {
+				t.Fatalf("Inspect failed: %v", err)
+			}
+			if info == nil {
+				t.Fatal("expected lock info")
+			}
+			if info.Stale != tt.wantStale {
+				t.Errorf("expected stale=%v, got %v (%s)", tt.wantStale, info.Stale, info.Reason)
+			}
+			if !strings.Contains(info.Reason, tt.wantIn) {
+				t.Errorf("expected reason to contain %q, got %q", tt.wantIn, info.Reason)
+			}
+		})
+	}
+}
+
+func TestFileLock_Acquire_RecoversStaleLock(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	fl := newTestLock(fs, &fakeProcessTable{alive: map[int]string{}}, time.Now())
+	writeLockFile(t, fs, "/test.lock", "999999\n")
+
+	locked, err := fl.IsLocked("/test.lock")
+	if err != nil {
+		t.Fatalf("IsLocked failed: %v", err)
+	}
+	if locked {
+		t.Error("expected stale lock to be reported as unlocked")
+	}
+
+	l, err := fl.Acquire("/test.lock")
+	if err != nil {
+		t.Fatalf("expected stale lock to be recovered, got: %v", err)
+	}
+	defer l.Release()
+
+	content, _ := afero.ReadFile(fs, "/test.lock")
+	if !strings.HasPrefix(string(content), strconv.Itoa(os.Getpid())+"\n") {
+		t.Errorf("expected lock to be rewritten with own PID, got: %s", content)
+	}
+}
+
+func TestFileLock_Clear(t *testing.T) {
+	now := time.Now()
+	fs := afero.NewMemMapFs()
+	fl := newTestLock(fs, &fakeProcessTable{alive: map[int]string{42: ""}}, now)
+
+	// A live lock is kept unless forced
+	writeLockFile(t, fs, "/live.lock", "42\nhost=devbox\n")
+	if _, err := fl.Clear("/live.lock", false); err == nil {
+		t.Error("expected clearing a live lock to fail without force")
+	}
+	if exists, _ := afero.Exists(fs, "/live.lock"); !exists {
+		t.Error("expected live lock to remain")
+	}
+	if _, err := fl.Clear("/live.lock", true); err != nil {
+		t.Errorf("expected forced clear to succeed, got: %v", err)
+	}
+	if exists, _ := afero.Exists(fs, "/live.lock"); exists {
+		t.Error("expected forced clear to remove the lock")
+	}
+
+	// A stale lock is removed
+	writeLockFile(t, fs, "/stale.lock", "43\n")
+	info, err := fl.Clear("/stale.lock", false)
+	if err != nil {
+		t.Fatalf("expected stale lock to be cleared, got: %v", err)
+	}
+	if info == nil || !info.Stale {
+		t.Error("expected cleared lock info to be stale")
+	}
+
+	// A missing lock is not an error
+	info, err = fl.Clear("/missing.lock", false)
+	if err != nil || info != nil {
+		t.Errorf("expected nil info and error for missing lock, got %v, %v", info, err)
+	}
+}
@@ -0,0 +1,44 @@
+package lock
+
+import (
+	"errors"
+	"os"
+	"syscall"
+
+	"github.com/spf13/afero"
+)
+
+// fdFile is implemented by files backed by an OS file descriptor
+type fdFile interface {
+	Fd() uintptr
+}
+
+// tryFlock takes a non-blocking exclusive flock on file. The kernel releases
+// it when the file is closed or the process dies.
+func tryFlock(file afero.File) error {
+	f, ok := file.(fdFile)
+	if !ok {
+		return errFlockUnsupported
+	}
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+}
+
+// flockHeld reports whether some process holds a flock on the file at path
+func flockHeld(fs afero.Fs, path string) (bool, error) {
+	file, err := fs.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	err = tryFlock(file)
+	switch {
+	case err == nil:
+		// Nobody held it; closing the file drops our probe lock
+		return false, nil
+	case errors.Is(err, syscall.EWOULDBLOCK):
+		return true, nil
+	default:
+		return false, err
+	}
+}
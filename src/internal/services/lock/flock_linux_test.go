@@ -0,0 +1,37 @@
+package lock
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestFileLock_Flock_ReleasedOnClose(t *testing.T) {
+	fs := afero.NewOsFs()
+	lockPath := filepath.Join(t.TempDir(), "test.lock")
+	fl := NewWithOptions(fs, Options{Flock: true})
+
+	l, err := fl.Acquire(lockPath)
+	if err != nil {
+		t.Fatalf("lock acquisition failed: %v", err)
+	}
+
+	held, err := flockHeld(fs, lockPath)
+	if err != nil {
+		t.Fatalf("flockHeld failed: %v", err)
+	}
+	if !held {
+		t.Error("expected flock to be held while the lock file is open")
+	}
+
+	// Simulate a crashed holder: the descriptor is closed but the file remains
+	l.File.Close()
+	info, err := fl.Inspect(lockPath)
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	if info == nil || !info.Stale {
+		t.Error("expected lock without flock holder to be stale")
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package lock
+
+import "github.com/spf13/afero"
+
+// tryFlock is unsupported here; locking relies on O_EXCL and stale detection
+func tryFlock(file afero.File) error {
+	return errFlockUnsupported
+}
+
+// flockHeld is unsupported here
+func flockHeld(fs afero.Fs, path string) (bool, error) {
+	return false, errFlockUnsupported
+}
@@ -1,8 +1,25 @@
 // Package lock provides file-based locking for concurrent process coordination.
-// It enables cross-process synchronization using atomic file operations.
+// It enables cross-process synchronization using atomic file operations, and
+// recovers locks left behind by crashed or killed processes: a lock is stale
+// when its holder is no longer running, its PID was reused by another process,
+// or, when neither can be checked, it is older than a TTL. On Linux, flock(2) can additionally be used so
+// the kernel releases the lock when its holder dies.
 package lock
 
-import "github.com/spf13/afero"
+import (
+	"errors"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// DefaultTTL is the age after which a lock is considered stale when its
+// holder cannot be confirmed running: it was written on another host, or
+// without the start time that tells a live holder from a reused PID
+const DefaultTTL = 2 * time.Hour
+
+// errFlockUnsupported is returned where flock(2) is not available
+var errFlockUnsupported = errors.New("flock not supported")
 
 // Lock represents an acquired lock with its associated file handle
 type Lock struct {
@@ -26,13 +43,81 @@ func (l *Lock) Release() error {
 	return l.fs.Remove(l.Path)
 }
 
+// Info describes a lock file and whether its holder is still alive
+type Info struct {
+	// Path is the lock file path
+	Path string
+
+	// PID is the process ID of the holder
+	PID int
+
+	// StartTime identifies the holder process instance (empty if unsupported or
+	// written by an older version); guards against PID reuse
+	StartTime string
+
+	// Host is the hostname of the holder
+	Host string
+
+	// CreatedAt is when the lock was acquired (file modification time for
+	// locks written by older versions)
+	CreatedAt time.Time
+
+	// Stale is true when the lock can safely be removed
+	Stale bool
+
+	// Reason explains why the lock is stale
+	Reason string
+}
+
+// Options configures stale lock detection
+type Options struct {
+	// TTL is the age after which a lock whose holder cannot be confirmed
+	// running is stale; zero uses DefaultTTL and a negative value disables
+	// expiry
+	TTL time.Duration
+
+	// Flock additionally holds a flock(2) advisory lock on the lock file
+	// (Linux only; ignored elsewhere and on non-OS filesystems)
+	Flock bool
+}
+
 // LockService abstracts file-based locking for testability
 type LockService interface {
 	// Acquire attempts to acquire a lock at the specified path
 	// Returns Lock if successful, error if already locked or operation fails
-	// Uses O_CREATE|O_EXCL for atomic acquisition
+	// Uses O_CREATE|O_EXCL for atomic acquisition; a stale lock is removed first
 	Acquire(path string) (*Lock, error)
 
-	// IsLocked checks if a lock file exists at the given path
+	// IsLocked checks if a live (non-stale) lock exists at the given path
 	IsLocked(path string) (bool, error)
+
+	// Inspect reads the lock at path and evaluates whether it is stale
+	// Returns nil if no lock exists
+	Inspect(path string) (*Info, error)
+
+	// Clear removes the lock at path if it is stale, or unconditionally when force is set
+	// Returns the inspected lock, or nil if none existed
+	Clear(path string, force bool) (*Info, error)
+}
+
+// ProcessStartTime returns a token identifying the running instance of pid,
+// or "" where process start times are unknown
+func ProcessStartTime(pid int) string {
+	return osProcessTable{}.StartTime(pid)
+}
+
+// ProcessAlive reports whether pid still runs and, when startTime is set,
+// is still the process instance that ProcessStartTime returned it for. It
+// applies the liveness checks of stale lock detection to other records.
+func ProcessAlive(pid int, startTime string) bool {
+	procs := osProcessTable{}
+	if pid <= 0 || !procs.Alive(pid) {
+		return false
+	}
+	if startTime != "" {
+		if current := procs.StartTime(pid); current != "" && current != startTime {
+			return false
+		}
+	}
+	return true
 }
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package lock
+
+// osProcessTable cannot inspect processes on this platform; holders are
+// assumed alive and only TTL expiry applies
+type osProcessTable struct{}
+
+func (osProcessTable) Alive(pid int) bool       { return true }
+func (osProcessTable) StartTime(pid int) string { return "" }
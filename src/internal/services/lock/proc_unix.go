@@ -0,0 +1,23 @@
+//go:build unix
+
+package lock
+
+import (
+	"errors"
+	"syscall"
+)
+
+// osProcessTable inspects processes of the running system
+type osProcessTable struct{}
+
+// Alive reports whether a process with the given PID exists.
+// EPERM means the process exists but belongs to another user.
+func (osProcessTable) Alive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// StartTime returns the process start time token (see processStartTime)
+func (osProcessTable) StartTime(pid int) string {
+	return processStartTime(pid)
+}
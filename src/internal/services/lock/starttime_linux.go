@@ -0,0 +1,27 @@
+package lock
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// processStartTime returns the start time of pid in clock ticks since boot
+// (field 22 of /proc/<pid>/stat), or "" if it cannot be read
+func processStartTime(pid int) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return ""
+	}
+	// The command name (field 2) may contain spaces; fields resume after ')'
+	end := strings.LastIndexByte(string(data), ')')
+	if end < 0 {
+		return ""
+	}
+	fields := strings.Fields(string(data[end+1:]))
+	// fields[0] is field 3 (state), so field 22 is fields[19]
+	if len(fields) < 20 {
+		return ""
+	}
+	return fields[19]
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package lock
+
+// processStartTime is unsupported here; PID reuse is then caught by TTL expiry only
+func processStartTime(pid int) string {
+	return ""
+}
@@ -1,5 +1,11 @@
 package paths
 
+import (
+	"path/filepath"
+
+	"claudex/internal/services/env"
+)
+
 const (
 	// ClaudexDir is the root directory for all Claudex artifacts
 	ClaudexDir = ".claudex"
@@ -21,3 +27,18 @@ const (
 	LegacyLogsDir     = "logs"
 	LegacyConfigFile  = ".claudex.toml"
 )
+
+// GlobalConfigDir returns the user-wide claudex config directory
+// ($XDG_CONFIG_HOME/claudex or ~/.config/claudex), or "" when neither
+// variable is set
+func GlobalConfigDir(environ env.Environment) string {
+	configDir := environ.Get("XDG_CONFIG_HOME")
+	if configDir == "" {
+		home := environ.Get("HOME")
+		if home == "" {
+			return ""
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configDir, "claudex")
+}
@@ -0,0 +1,306 @@
+// Package policy evaluates declarative permission rules for tool calls. Rules
+// live in .claudex/policy.toml (project) and ~/.config/claudex/policy.toml
+// (global) and match on tool name, path globs, Bash command regexes and the
+// calling agent, returning allow, deny or ask with a reason.
+package policy
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"claudex/internal/services/env"
+	"claudex/internal/services/ignore"
+	"claudex/internal/services/ledger"
+	"claudex/internal/services/paths"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/afero"
+)
+
+// FileName is the policy file name, in .claudex/ and in the global config dir
+const FileName = "policy.toml"
+
+// Decision is a permission decision understood by Claude Code
+type Decision string
+
+const (
+	Allow Decision = "allow"
+	Deny  Decision = "deny"
+	Ask   Decision = "ask"
+)
+
+// strictness orders decisions: when several rules match, the strictest wins
+var strictness = map[Decision]int{Allow: 0, Ask: 1, Deny: 2}
+
+// Rule is one [[rule]] entry. Every condition that is set must hold; list
+// conditions match when any element does.
+type Rule struct {
+	Name string `toml:"name"`
+
+	// Tools are tool names or globs (e.g. "Write", "mcp__*")
+	Tools []string `toml:"tools"`
+
+	// Paths are gitignore-style globs, relative to the project, matched
+	// against the paths the tool touches; ExcludePaths exempt paths again
+	Paths        []string `toml:"paths"`
+	ExcludePaths []string `toml:"exclude_paths"`
+
+	// Command is a regular expression matched against Bash commands
+	Command string `toml:"command"`
+
+	// Agents are the calling agent types; "main" is the main agent
+	Agents []string `toml:"agents"`
+
+	// OutsideProject and OutsideSession match when a touched path lies
+	// outside the project directory or the claudex session folder
+	OutsideProject bool `toml:"outside_project"`
+	OutsideSession bool `toml:"outside_session"`
+
+	Decision Decision `toml:"decision"`
+	Reason   string   `toml:"reason"`
+
+	source   string
+	command  *regexp.Regexp
+	paths    []ignore.Glob
+	excludes []ignore.Glob
+}
+
+// Policy is the ordered list of rules from all policy files
+type Policy struct {
+	Rules []Rule `toml:"rule"`
+}
+
+// Request describes a tool call to evaluate
+type Request struct {
+	Tool  string
+	Input map[string]interface{}
+	// Agent is the calling agent type; empty means the main agent
+	Agent string
+	// CWD resolves relative paths; ProjectDir and SessionPath bound the
+	// outside_project and outside_session conditions
+	CWD         string
+	ProjectDir  string
+	SessionPath string
+}
+
+// Result is the outcome of an evaluation
+type Result struct {
+	Decision Decision
+	// Rule names the deciding rule and Source its file; both are empty when
+	// no rule matched and the call is allowed by default
+	Rule   string
+	Source string
+	Reason string
+}
+
+// Matched reports whether a rule decided the result
+func (r Result) Matched() bool {
+	return r.Source != ""
+}
+
+// ProjectPath returns the project policy file path
+func ProjectPath(projectDir string) string {
+	return filepath.Join(projectDir, paths.ClaudexDir, FileName)
+}
+
+// GlobalPath returns the global policy file path ($XDG_CONFIG_HOME/claudex
+// or ~/.config/claudex), or "" when neither variable is set
+func GlobalPath(environ env.Environment) string {
+	dir := paths.GlobalConfigDir(environ)
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, FileName)
+}
+
+// Load reads the project policy followed by the global one; missing files
+// are skipped
+func Load(fs afero.Fs, projectDir string, environ env.Environment) (*Policy, error) {
+	p := &Policy{}
+	for _, file := range []string{ProjectPath(projectDir), GlobalPath(environ)} {
+		if file == "" {
+			continue
+		}
+		data, err := afero.ReadFile(fs, file)
+		if err != nil {
+			if exists, _ := afero.Exists(fs, file); !exists {
+				continue
+			}
+			return nil, err
+		}
+		parsed, err := Parse(data, file)
+		if err != nil {
+			return nil, err
+		}
+		p.Rules = append(p.Rules, parsed.Rules...)
+	}
+	return p, nil
+}
+
+// Parse parses and validates one policy file; source names it in errors and results
+func Parse(data []byte, source string) (*Policy, error) {
+	var p Policy
+	if _, err := toml.Decode(string(data), &p); err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	for i := range p.Rules {
+		if err := p.Rules[i].compile(source, i); err != nil {
+			return nil, err
+		}
+	}
+	return &p, nil
+}
+
+// compile validates the rule and prepares its matchers
+func (r *Rule) compile(source string, index int) error {
+	r.source = source
+	if r.Name == "" {
+		r.Name = fmt.Sprintf("rule %d", index+1)
+	}
+	fail := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%s: %s: %s", source, r.Name, fmt.Sprintf(format, args...))
+	}
+
+	if _, ok := strictness[r.Decision]; !ok {
+		return fail("decision must be allow, deny or ask, got %q", r.Decision)
+	}
+	for _, tool := range r.Tools {
+		if _, err := path.Match(tool, ""); err != nil {
+			return fail("invalid tool pattern %q", tool)
+		}
+	}
+	if r.Command != "" {
+		re, err := regexp.Compile(r.Command)
+		if err != nil {
+			return fail("invalid command regex: %v", err)
+		}
+		r.command = re
+	}
+	for _, pattern := range r.Paths {
+		g, err := ignore.CompileGlob(pattern)
+		if err != nil {
+			return fail("%v", err)
+		}
+		r.paths = append(r.paths, g)
+	}
+	for _, pattern := range r.ExcludePaths {
+		g, err := ignore.CompileGlob(pattern)
+		if err != nil {
+			return fail("%v", err)
+		}
+		r.excludes = append(r.excludes, g)
+	}
+	return nil
+}
+
+// Evaluate returns the decision of the strictest matching rule (deny over ask
+// over allow, the earliest rule on a tie). Calls no rule matches are allowed.
+func (p *Policy) Evaluate(req Request) Result {
+	result := Result{Decision: Allow}
+	if p == nil {
+		return result
+	}
+	targets := Targets(req)
+	for _, r := range p.Rules {
+		if !r.matches(req, targets) {
+			continue
+		}
+		if !result.Matched() || strictness[r.Decision] > strictness[result.Decision] {
+			result = Result{Decision: r.Decision, Rule: r.Name, Source: r.source, Reason: r.Reason}
+		}
+	}
+	return result
+}
+
+// matches reports whether every condition of the rule holds for the call
+func (r *Rule) matches(req Request, targets []string) bool {
+	if len(r.Tools) > 0 && !matchesAny(r.Tools, req.Tool) {
+		return false
+	}
+	if len(r.Agents) > 0 {
+		agent := req.Agent
+		if agent == "" {
+			agent = ledger.MainAgent
+		}
+		if !matchesAny(r.Agents, agent) {
+			return false
+		}
+	}
+	if r.command != nil {
+		command, _ := req.Input["command"].(string)
+		if command == "" || !r.command.MatchString(command) {
+			return false
+		}
+	}
+
+	// Path conditions need at least one touched path satisfying all of them
+	if len(r.paths) == 0 && len(r.excludes) == 0 && !r.OutsideProject && !r.OutsideSession {
+		return true
+	}
+	for _, target := range targets {
+		if r.matchesPath(req, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPath checks the path conditions against one absolute path
+func (r *Rule) matchesPath(req Request, target string) bool {
+	rel, inProject := relative(req.ProjectDir, target)
+	if r.OutsideProject && inProject {
+		return false
+	}
+	if r.OutsideSession {
+		if req.SessionPath == "" {
+			return false
+		}
+		if _, inSession := relative(req.SessionPath, target); inSession {
+			return false
+		}
+	}
+	if !inProject {
+		// Globs are relative to the project; outside it they see the absolute path
+		rel = filepath.ToSlash(target)
+	}
+	if len(r.paths) > 0 && !matchesGlobs(r.paths, rel) {
+		return false
+	}
+	return !matchesGlobs(r.excludes, rel)
+}
+
+// matchesAny reports whether name matches one of the glob patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesGlobs reports whether rel matches one of the globs
+func matchesGlobs(globs []ignore.Glob, rel string) bool {
+	for _, g := range globs {
+		if g.Match(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// relative returns target relative to dir (slash-separated) and whether it
+// lies inside dir
+func relative(dir, target string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(dir, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
@@ -0,0 +1,165 @@
+package policy
+
+import (
+	"testing"
+
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const examplePolicy = `
+[[rule]]
+name = "no-rm-rf-outside"
+tools = ["Bash"]
+command = '\brm\s+-[a-zA-Z]*(rf|fr)'
+outside_project = true
+decision = "deny"
+reason = "rm -rf outside the repository"
+
+[[rule]]
+name = "ask-push"
+tools = ["Bash"]
+command = '^\s*git\s+push\b'
+decision = "ask"
+reason = "Pushing publishes commits"
+
+[[rule]]
+name = "no-env"
+tools = ["Write", "Edit", "MultiEdit"]
+paths = [".env", ".env.*"]
+exclude_paths = [".env.example"]
+decision = "deny"
+reason = "Secrets stay out of agent edits"
+
+[[rule]]
+name = "docs-in-session"
+tools = ["Write"]
+agents = ["Explore", "Plan"]
+paths = ["*.md"]
+outside_session = true
+decision = "deny"
+reason = "Write docs to the session folder"
+
+[[rule]]
+name = "allow-mcp"
+tools = ["mcp__*"]
+decision = "allow"
+reason = "MCP tools are trusted"
+`
+
+func request(tool string, input map[string]interface{}) Request {
+	return Request{
+		Tool:        tool,
+		Input:       input,
+		CWD:         "/repo",
+		ProjectDir:  "/repo",
+		SessionPath: "/repo/.claudex/sessions/s1",
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	p, err := Parse([]byte(examplePolicy), "policy.toml")
+	require.NoError(t, err)
+
+	explore := request("Write", map[string]interface{}{"file_path": "/repo/notes.md"})
+	explore.Agent = "Explore"
+	exploreInSession := request("Write", map[string]interface{}{"file_path": "/repo/.claudex/sessions/s1/findings.md"})
+	exploreInSession.Agent = "Explore"
+
+	tests := []struct {
+		name string
+		req  Request
+		want Decision
+		rule string
+	}{
+		{"rm -rf outside", request("Bash", map[string]interface{}{"command": "rm -rf /tmp/cache"}), Deny, "no-rm-rf-outside"},
+		{"rm -rf home", request("Bash", map[string]interface{}{"command": "cd build && rm -fr ~/old"}), Deny, "no-rm-rf-outside"},
+		{"rm -rf parent", request("Bash", map[string]interface{}{"command": `rm -rf "../sibling"`}), Deny, "no-rm-rf-outside"},
+		{"rm -rf inside", request("Bash", map[string]interface{}{"command": "rm -rf ./build dist/"}), Allow, ""},
+		{"rm -rf in heredoc body", request("Bash", map[string]interface{}{"command": "cat > notes.md <<'EOF'\nrm -rf /tmp/cache\nEOF\nrm -rf build/"}), Allow, ""},
+		{"rm -rf after unknown cd", request("Bash", map[string]interface{}{"command": `cd "$(mktemp -d)" && rm -rf out/`}), Deny, "no-rm-rf-outside"},
+		{"git push", request("Bash", map[string]interface{}{"command": "git push origin main"}), Ask, "ask-push"},
+		{"git status", request("Bash", map[string]interface{}{"command": "git status"}), Allow, ""},
+		{"write .env", request("Write", map[string]interface{}{"file_path": "/repo/.env"}), Deny, "no-env"},
+		{"edit nested .env.local", request("Edit", map[string]interface{}{"file_path": "/repo/api/.env.local"}), Deny, "no-env"},
+		{"write .env.example", request("Write", map[string]interface{}{"file_path": "/repo/.env.example"}), Allow, ""},
+		{"read .env", request("Read", map[string]interface{}{"file_path": "/repo/.env"}), Allow, ""},
+		{"explore doc outside session", explore, Deny, "docs-in-session"},
+		{"explore doc in session", exploreInSession, Allow, ""},
+		{"main agent doc", request("Write", map[string]interface{}{"file_path": "/repo/notes.md"}), Allow, ""},
+		{"mcp allow rule", request("mcp__context7__query-docs", nil), Allow, "allow-mcp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := p.Evaluate(tt.req)
+			assert.Equal(t, tt.want, got.Decision)
+			assert.Equal(t, tt.rule, got.Rule)
+		})
+	}
+}
+
+func TestEvaluate_StrictestRuleWins(t *testing.T) {
+	p, err := Parse([]byte(`
+[[rule]]
+name = "allow-git"
+command = '^git '
+decision = "allow"
+
+[[rule]]
+name = "ask-git"
+command = '^git '
+decision = "ask"
+
+[[rule]]
+name = "ask-git-again"
+command = '^git '
+decision = "ask"
+`), "policy.toml")
+	require.NoError(t, err)
+
+	got := p.Evaluate(request("Bash", map[string]interface{}{"command": "git push"}))
+	assert.Equal(t, Ask, got.Decision)
+	assert.Equal(t, "ask-git", got.Rule)
+	assert.True(t, got.Matched())
+
+	var none *Policy
+	assert.Equal(t, Allow, none.Evaluate(request("Bash", nil)).Decision)
+}
+
+func TestParse_Validates(t *testing.T) {
+	for name, content := range map[string]string{
+		"decision": "[[rule]]\ndecision = \"maybe\"\n",
+		"regex":    "[[rule]]\ncommand = '('\ndecision = \"deny\"\n",
+		"glob":     "[[rule]]\npaths = ['!x']\ndecision = \"deny\"\n",
+		"tool":     "[[rule]]\ntools = ['[']\ndecision = \"deny\"\n",
+		"toml":     "[[rule]\n",
+	} {
+		_, err := Parse([]byte(content), "policy.toml")
+		assert.Error(t, err, name)
+	}
+}
+
+func TestLoad_ProjectThenGlobal(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	env := testutil.NewMockEnv()
+	env.Set("HOME", "/home/dev")
+
+	p, err := Load(fs, "/repo", env)
+	require.NoError(t, err)
+	assert.Empty(t, p.Rules, "missing files are skipped")
+
+	require.NoError(t, afero.WriteFile(fs, "/repo/.claudex/policy.toml", []byte("[[rule]]\nname = \"project\"\ndecision = \"ask\"\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/home/dev/.config/claudex/policy.toml", []byte("[[rule]]\nname = \"global\"\ndecision = \"ask\"\n"), 0644))
+
+	p, err = Load(fs, "/repo", env)
+	require.NoError(t, err)
+	require.Len(t, p.Rules, 2)
+	assert.Equal(t, "project", p.Rules[0].Name)
+	assert.Equal(t, "global", p.Rules[1].Name)
+
+	got := p.Evaluate(request("Read", nil))
+	assert.Equal(t, "/repo/.claudex/policy.toml", got.Source)
+}
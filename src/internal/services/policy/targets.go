@@ -0,0 +1,58 @@
+package policy
+
+import (
+	"path/filepath"
+	"strings"
+
+	"claudex/internal/services/shellcmd"
+)
+
+// pathKeys are the tool input fields that hold file or directory paths
+var pathKeys = []string{"file_path", "notebook_path", "path"}
+
+// unknownDir stands for a directory only known at run time; no project
+// contains it
+var unknownDir = string(filepath.Separator) + "$PWD"
+
+// Targets returns the absolute paths a tool call touches: the path fields of
+// file tools, and for Bash the arguments and redirection targets that look
+// like paths (see shellcmd.Words). Arguments starting with ~ or $, and
+// relative paths after a cd to an unknown directory, are treated as outside
+// any directory.
+func Targets(req Request) []string {
+	var targets []string
+	add := func(dir, p string) {
+		if p == "" {
+			return
+		}
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		targets = append(targets, filepath.Clean(p))
+	}
+
+	for _, key := range pathKeys {
+		if value, ok := req.Input[key].(string); ok {
+			add(req.CWD, value)
+		}
+	}
+
+	if command, ok := req.Input["command"].(string); ok {
+		for _, word := range shellcmd.Words(command, req.CWD) {
+			switch {
+			case !word.Literal && (strings.HasPrefix(word.Text, "~") || strings.HasPrefix(word.Text, "$")):
+				// Unknown location: use a path no project contains
+				targets = append(targets, string(filepath.Separator)+word.Text)
+			case strings.HasPrefix(word.Text, "-"):
+				// Flags, including --opt=/path forms, are not paths
+			case strings.Contains(word.Text, "/"), word.Text == ".", word.Text == "..":
+				dir := word.Dir
+				if dir == "" {
+					dir = unknownDir
+				}
+				add(dir, word.Text)
+			}
+		}
+	}
+	return targets
+}
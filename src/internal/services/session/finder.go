@@ -74,6 +74,46 @@ func FindSessionFolderWithCwd(fs afero.Fs, environment env.Environment, sessionI
 	return matches[0], nil
 }
 
+// FindProjectRoot walks up from path to the project root, the nearest
+// directory holding a .claude directory
+func FindProjectRoot(fs afero.Fs, path string) (string, error) {
+	current := path
+	for {
+		exists, err := afero.DirExists(fs, filepath.Join(current, ".claude"))
+		if err == nil && exists {
+			return current, nil
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			// Reached filesystem root
+			return "", fmt.Errorf("could not find .claude directory in any parent of %s", path)
+		}
+		current = parent
+	}
+}
+
+// FindProjectDir returns the project a hook call belongs to: the
+// CLAUDE_PROJECT_DIR Claude Code sets, else the nearest directory at or above
+// cwd holding .claudex, else cwd itself
+func FindProjectDir(fs afero.Fs, environment env.Environment, cwd string) string {
+	if dir := environment.Get("CLAUDE_PROJECT_DIR"); dir != "" {
+		return dir
+	}
+	current := cwd
+	for {
+		exists, err := afero.DirExists(fs, filepath.Join(current, paths.ClaudexDir))
+		if err == nil && exists {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return cwd
+		}
+		current = parent
+	}
+}
+
 // GetSessionID extracts the session ID from a session folder path.
 // It expects paths in the format: .../session-name-{uuid}
 // Returns the UUID portion or empty string if not found.
@@ -99,3 +139,46 @@ func GetSessionID(sessionPath string) string {
 
 	return ""
 }
+
+// ResolveSessionFolder finds a session folder under {projectDir}/.claudex/sessions
+// by name: the full folder name, the name without its Claude session ID, the
+// Claude session ID alone, or an unambiguous prefix of the folder name.
+func ResolveSessionFolder(fs afero.Fs, projectDir, name string) (string, error) {
+	sessionsDir := filepath.Join(projectDir, paths.SessionsDir)
+	name = filepath.Base(name)
+	if name == "" || name == "." {
+		return "", fmt.Errorf("session name is empty")
+	}
+
+	entries, err := afero.ReadDir(fs, sessionsDir)
+	if err != nil {
+		return "", fmt.Errorf("no sessions in %s: %w", sessionsDir, err)
+	}
+
+	var exact, prefixed []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		folder := entry.Name()
+		switch {
+		case folder == name, StripClaudeSessionID(folder) == name, ExtractClaudeSessionID(folder) == name:
+			exact = append(exact, folder)
+		case strings.HasPrefix(folder, name):
+			prefixed = append(prefixed, folder)
+		}
+	}
+
+	matches := exact
+	if len(matches) == 0 {
+		matches = prefixed
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("session %q not found in %s", name, sessionsDir)
+	case 1:
+		return filepath.Join(sessionsDir, matches[0]), nil
+	default:
+		return "", fmt.Errorf("session %q is ambiguous: %s", name, strings.Join(matches, ", "))
+	}
+}
@@ -116,6 +116,34 @@ func Test_FindSessionFolderWithCwd_CustomCwd(t *testing.T) {
 	require.Equal(t, sessionPath, result)
 }
 
+// Test_FindProjectRoot tests walking up from a session folder to the .claude directory
+func Test_FindProjectRoot(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.CreateDir("/project/.claude/hooks")
+	h.CreateDir("/project/.claudex/sessions/fix-locks")
+
+	root, err := FindProjectRoot(h.FS, "/project/.claudex/sessions/fix-locks")
+	require.NoError(t, err)
+	require.Equal(t, "/project", root)
+
+	_, err = FindProjectRoot(h.FS, "/elsewhere/sessions/x")
+	require.ErrorContains(t, err, "could not find .claude directory")
+}
+
+// Test_FindProjectDir tests finding the project of a hook call made from a subdirectory
+func Test_FindProjectDir(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.CreateDir("/project/.claudex/sessions")
+	h.CreateDir("/project/src/pkg")
+
+	require.Equal(t, "/project", FindProjectDir(h.FS, h.Env, "/project/src/pkg"))
+	require.Equal(t, "/project", FindProjectDir(h.FS, h.Env, "/project"))
+	require.Equal(t, "/other/dir", FindProjectDir(h.FS, h.Env, "/other/dir"), "no .claudex above cwd")
+
+	h.Env.Set("CLAUDE_PROJECT_DIR", "/elsewhere")
+	require.Equal(t, "/elsewhere", FindProjectDir(h.FS, h.Env, "/project/src/pkg"))
+}
+
 // Test_FindSessionFolderWithCwd_EnvVarOverridesCwd tests that env var still has priority with custom cwd
 func Test_FindSessionFolderWithCwd_EnvVarOverridesCwd(t *testing.T) {
 	h := testutil.NewTestHarness()
@@ -174,3 +202,32 @@ func Test_GetSessionID_ValidUUID(t *testing.T) {
 		})
 	}
 }
+
+// Test_ResolveSessionFolder tests session lookup by name, Claude ID and prefix
+func Test_ResolveSessionFolder(t *testing.T) {
+	h := testutil.NewTestHarness()
+	sessionsDir := "/project/.claudex/sessions"
+	h.CreateDir(sessionsDir + "/fix-locks-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee")
+	h.CreateDir(sessionsDir + "/fix-docs")
+	h.CreateDir(sessionsDir + "/add-policy")
+	h.WriteFile(sessionsDir+"/fix-notes", "not a session")
+
+	for _, name := range []string{
+		"fix-locks-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee",
+		"fix-locks",
+		"aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee",
+		"fix-l",
+		sessionsDir + "/fix-locks-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee/",
+	} {
+		path, err := ResolveSessionFolder(h.FS, "/project", name)
+		require.NoError(t, err, name)
+		require.Equal(t, sessionsDir+"/fix-locks-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", path, name)
+	}
+
+	_, err := ResolveSessionFolder(h.FS, "/project", "fix-")
+	require.ErrorContains(t, err, "ambiguous")
+	_, err = ResolveSessionFolder(h.FS, "/project", "fix-notes")
+	require.ErrorContains(t, err, "not found")
+	_, err = ResolveSessionFolder(h.FS, "/other", "fix-locks")
+	require.ErrorContains(t, err, "no sessions")
+}
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"claudex/internal/services/ledger"
+
 	"github.com/spf13/afero"
 )
 
@@ -20,6 +22,12 @@ const (
 	LastUsedFile = ".last_used"
 )
 
+// IsBookkeeping reports whether a session folder entry is claudex's own state
+// (dotfiles, locks, the change ledger) rather than session work to show agents
+func IsBookkeeping(name string) bool {
+	return strings.HasPrefix(name, ".") || filepath.Ext(name) == ".lock" || name == ledger.FileName
+}
+
 // SessionMetadata represents metadata files stored in a session folder.
 type SessionMetadata struct {
 	Description string // Content of .description file
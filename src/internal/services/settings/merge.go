@@ -6,6 +6,14 @@ import (
 	"path/filepath"
 )
 
+// widenedMatchers lists, per hook type, hooks whose matcher earlier setups
+// wrote and the template no longer uses, by command basename. Merging moves
+// them to the template's matcher; other matchers are user choices and kept.
+var widenedMatchers = map[string]map[string]string{
+	// Policies and conflict checks need every tool call, not just Task
+	"PreToolUse": {"pre-tool-use.sh": "Task"},
+}
+
 // MergeSettings performs a smart merge of template and existing settings.
 // It adds missing hooks from the template while preserving all existing hooks
 // and user permissions. The merge is idempotent and identifies hooks by the
@@ -25,6 +33,7 @@ import (
 //   - Adds missing hooks from template to each hook type
 //   - Preserves all existing hooks (user customizations)
 //   - Deduplicates by command path within each hook type
+//   - Widens the matchers listed in widenedMatchers to the template's
 func MergeSettings(template, existing []byte) ([]byte, error) {
 	// Parse template settings first (always required for validation)
 	var templateSettings Settings
@@ -51,6 +60,9 @@ func MergeSettings(template, existing []byte) ([]byte, error) {
 		result.Hooks = make(map[string][]HookEntry)
 	}
 
+	// Hooks set up with a matcher the template has since widened
+	migrateMatchers(result.Hooks, templateSettings)
+
 	// For each hook type in template, add missing hooks
 	for hookType, templateEntries := range templateSettings.Hooks {
 		// Get existing hook entries for this type (may be empty)
@@ -112,3 +124,49 @@ func MergeSettings(template, existing []byte) ([]byte, error) {
 
 	return mergedJSON, nil
 }
+
+// migrateMatchers moves the hooks in widenedMatchers from their old matcher
+// to the template's, splitting them out of entries shared with other hooks
+func migrateMatchers(hooks map[string][]HookEntry, template Settings) {
+	for hookType, commands := range widenedMatchers {
+		entries := hooks[hookType]
+		for command, oldMatcher := range commands {
+			newMatcher, ok := templateMatcher(template, hookType, command)
+			if !ok || newMatcher == oldMatcher {
+				continue
+			}
+			for i := 0; i < len(entries); i++ {
+				if entries[i].Matcher != oldMatcher {
+					continue
+				}
+				for j, hook := range entries[i].Hooks {
+					if filepath.Base(hook.Command) != command {
+						continue
+					}
+					if len(entries[i].Hooks) == 1 {
+						entries[i].Matcher = newMatcher
+					} else {
+						entries[i].Hooks = append(entries[i].Hooks[:j:j], entries[i].Hooks[j+1:]...)
+						entries = append(entries, HookEntry{Matcher: newMatcher, Hooks: []Hook{hook}})
+					}
+					break
+				}
+			}
+		}
+		if entries != nil {
+			hooks[hookType] = entries
+		}
+	}
+}
+
+// templateMatcher returns the matcher of the template entry running command
+func templateMatcher(template Settings, hookType, command string) (string, bool) {
+	for _, entry := range template.Hooks[hookType] {
+		for _, hook := range entry.Hooks {
+			if filepath.Base(hook.Command) == command {
+				return entry.Matcher, true
+			}
+		}
+	}
+	return "", false
+}
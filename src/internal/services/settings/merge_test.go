@@ -580,3 +580,67 @@ func TestMergeSettings_ErrorCases(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeSettings_WidensTaskMatcher(t *testing.T) {
+	templateJSON := []byte(`{
+  "permissions": {"allow": [], "deny": [], "ask": []},
+  "hooks": {
+    "PreToolUse": [
+      {"hooks": [{"type": "command", "command": ".claude/hooks/pre-tool-use.sh"}]}
+    ]
+  }
+}`)
+
+	tests := []struct {
+		name     string
+		existing string
+		want     []HookEntry
+	}{
+		{
+			name:     "SoleHook",
+			existing: `{"hooks": {"PreToolUse": [{"matcher": "Task", "hooks": [{"type": "command", "command": "/p/.claude/hooks/pre-tool-use.sh"}]}]}}`,
+			want: []HookEntry{
+				{Hooks: []Hook{{Type: "command", Command: "/p/.claude/hooks/pre-tool-use.sh"}}},
+			},
+		},
+		{
+			name:     "SharedEntry",
+			existing: `{"hooks": {"PreToolUse": [{"matcher": "Task", "hooks": [{"type": "command", "command": "./mine.sh"}, {"type": "command", "command": "/p/.claude/hooks/pre-tool-use.sh"}]}]}}`,
+			want: []HookEntry{
+				{Matcher: "Task", Hooks: []Hook{{Type: "command", Command: "./mine.sh"}}},
+				{Hooks: []Hook{{Type: "command", Command: "/p/.claude/hooks/pre-tool-use.sh"}}},
+			},
+		},
+		{
+			name:     "UserMatcherKept",
+			existing: `{"hooks": {"PreToolUse": [{"matcher": "Bash", "hooks": [{"type": "command", "command": "/p/.claude/hooks/pre-tool-use.sh"}]}]}}`,
+			want: []HookEntry{
+				{Matcher: "Bash", Hooks: []Hook{{Type: "command", Command: "/p/.claude/hooks/pre-tool-use.sh"}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := MergeSettings(templateJSON, []byte(tt.existing))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// Merging again changes nothing
+			result, err = MergeSettings(templateJSON, result)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var resultSettings Settings
+			if err := json.Unmarshal(result, &resultSettings); err != nil {
+				t.Fatalf("failed to unmarshal result: %v", err)
+			}
+			got, _ := json.Marshal(resultSettings.Hooks["PreToolUse"])
+			want, _ := json.Marshal(tt.want)
+			if string(got) != string(want) {
+				t.Errorf("PreToolUse = %s, want %s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+// Package shellcmd reads Bash command lines the way the hooks need them: the
+// words each simple command passes, and the files a command line writes. It
+// lexes quotes, escapes, comments, redirections and here-documents and
+// follows cd, but does not run or expand anything; words whose value is only
+// known at run time are marked as such.
+package shellcmd
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Word is an argument or redirection target of a simple command
+type Word struct {
+	Text string
+
+	// Dir is the working directory of the command after the cd commands
+	// before it, or "" when a cd went somewhere the text does not show
+	Dir string
+
+	// Literal is false when the value is only known at run time
+	// (variables, command substitutions, globs, ~)
+	Literal bool
+}
+
+// Words returns the words of every simple command in a command line,
+// command names and redirection targets included. Here-document bodies and
+// comments are skipped, and cd is followed from cwd.
+func Words(command, cwd string) []Word {
+	var words []Word
+	dir := cwd
+	for _, cmd := range splitCommands(lex(command)) {
+		var args []token
+		for i, t := range cmd {
+			if t.kind != wordToken {
+				continue
+			}
+			words = append(words, Word{Text: t.text, Dir: dir, Literal: t.literal})
+			if i == 0 || cmd[i-1].kind == wordToken {
+				args = append(args, t)
+			}
+		}
+		args = stripPrefixes(args)
+		if len(args) > 0 && args[0].literal && args[0].text == "cd" {
+			dir = changeDir(dir, args[1:])
+		}
+	}
+	return words
+}
+
+// tokenKind classifies shell tokens
+type tokenKind int
+
+const (
+	wordToken tokenKind = iota
+	// separatorToken ends a simple command: ; && || | & ( ) and newlines
+	separatorToken
+	// outputToken is an output redirection: > >> >| &> &>> and 2> forms
+	outputToken
+	// inputToken is an input redirection or here-document: < << <<<
+	inputToken
+)
+
+// token is one lexed shell token
+type token struct {
+	kind tokenKind
+	text string
+
+	// literal is false when the word's value is only known at run time
+	// (variables, command substitutions, globs, ~)
+	literal bool
+}
+
+// lex splits a command into tokens, handling quotes, escapes, comments,
+// here-document bodies and the operators that matter for redirections
+func lex(command string) []token {
+	var tokens []token
+	var heredocs []string
+	runes := []rune(command)
+
+	var word strings.Builder
+	inWord, literal := false, true
+	flush := func() {
+		if inWord {
+			tokens = append(tokens, token{kind: wordToken, text: word.String(), literal: literal})
+		}
+		word.Reset()
+		inWord, literal = false, true
+	}
+	emit := func(kind tokenKind, text string) {
+		flush()
+		tokens = append(tokens, token{kind: kind, text: text})
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t':
+			flush()
+		case r == '\n':
+			emit(separatorToken, "\n")
+			// Here-document bodies are data, not commands
+			for _, delim := range heredocs {
+				for i+1 < len(runes) {
+					end := i + 1
+					for end < len(runes) && runes[end] != '\n' {
+						end++
+					}
+					line := strings.TrimLeft(string(runes[i+1:end]), "\t")
+					i = end
+					if line == delim {
+						break
+					}
+				}
+			}
+			heredocs = nil
+		case r == '#' && !inWord:
+			for i+1 < len(runes) && runes[i+1] != '\n' {
+				i++
+			}
+		case r == ';' || r == '(' || r == ')':
+			emit(separatorToken, string(r))
+		case r == '|':
+			if i+1 < len(runes) && (runes[i+1] == '|' || runes[i+1] == '&') {
+				i++
+			}
+			emit(separatorToken, "|")
+		case r == '&':
+			switch {
+			case i+1 < len(runes) && runes[i+1] == '>':
+				i++
+				if i+1 < len(runes) && runes[i+1] == '>' {
+					i++
+				}
+				emit(outputToken, "&>")
+			case i+1 < len(runes) && runes[i+1] == '&':
+				i++
+				emit(separatorToken, "&&")
+			default:
+				emit(separatorToken, "&")
+			}
+		case r == '>':
+			// A file descriptor number before > is not a word
+			if inWord && literal && isDigits(word.String()) {
+				word.Reset()
+				inWord = false
+			}
+			if i+1 < len(runes) && (runes[i+1] == '>' || runes[i+1] == '|') {
+				i++
+			}
+			if i+1 < len(runes) && runes[i+1] == '&' {
+				// >&2 duplicates a descriptor
+				flush()
+				i++
+				for i+1 < len(runes) && (isDigits(string(runes[i+1])) || runes[i+1] == '-') {
+					i++
+				}
+				continue
+			}
+			emit(outputToken, ">")
+		case r == '<':
+			count := 1
+			for i+1 < len(runes) && runes[i+1] == '<' {
+				count++
+				i++
+			}
+			emit(inputToken, strings.Repeat("<", count))
+			if count == 2 {
+				heredocs = append(heredocs, heredocDelimiter(runes, i+1))
+			}
+		case r == '\'':
+			inWord = true
+			for i+1 < len(runes) && runes[i+1] != '\'' {
+				i++
+				word.WriteRune(runes[i])
+			}
+			i++
+		case r == '"':
+			inWord = true
+			for i+1 < len(runes) && runes[i+1] != '"' {
+				i++
+				switch runes[i] {
+				case '\\':
+					if i+1 < len(runes) {
+						i++
+					}
+				case '$', '`':
+					literal = false
+				}
+				word.WriteRune(runes[i])
+			}
+			i++
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				if runes[i] != '\n' {
+					inWord = true
+					word.WriteRune(runes[i])
+				}
+			}
+		default:
+			if strings.ContainsRune("$`*?[", r) || (r == '~' && !inWord) {
+				literal = false
+			}
+			inWord = true
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// heredocDelimiter reads the delimiter word following << (or <<-) at start
+func heredocDelimiter(runes []rune, start int) string {
+	i := start
+	if i < len(runes) && runes[i] == '-' {
+		i++
+	}
+	for i < len(runes) && (runes[i] == ' ' || runes[i] == '\t') {
+		i++
+	}
+	var sb strings.Builder
+	for ; i < len(runes) && !strings.ContainsRune(" \t\n;|&<>()", runes[i]); i++ {
+		if runes[i] != '\'' && runes[i] != '"' && runes[i] != '\\' {
+			sb.WriteRune(runes[i])
+		}
+	}
+	return sb.String()
+}
+
+// splitCommands groups tokens into simple commands
+func splitCommands(tokens []token) [][]token {
+	var commands [][]token
+	var current []token
+	for _, t := range tokens {
+		if t.kind == separatorToken {
+			if len(current) > 0 {
+				commands = append(commands, current)
+			}
+			current = nil
+			continue
+		}
+		current = append(current, t)
+	}
+	if len(current) > 0 {
+		commands = append(commands, current)
+	}
+	return commands
+}
+
+// stripPrefixes drops variable assignments and command wrappers (sudo, env,
+// command, nohup, time) before the command name
+func stripPrefixes(words []token) []token {
+	for len(words) > 0 {
+		w := words[0].text
+		switch {
+		case isAssignment(w):
+		case w == "sudo" || w == "env" || w == "command" || w == "nohup" || w == "time":
+		default:
+			return words
+		}
+		words = words[1:]
+	}
+	return words
+}
+
+// isAssignment reports whether a word is a NAME=value assignment
+func isAssignment(word string) bool {
+	eq := strings.IndexByte(word, '=')
+	if eq <= 0 {
+		return false
+	}
+	for i, r := range word[:eq] {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// changeDir follows cd; an unknown directory is ""
+func changeDir(dir string, args []token) string {
+	if len(args) != 1 || !args[0].literal || args[0].text == "-" {
+		return ""
+	}
+	if filepath.IsAbs(args[0].text) {
+		return filepath.Clean(args[0].text)
+	}
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, args[0].text)
+}
+
+// isDigits reports whether s is a non-empty run of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
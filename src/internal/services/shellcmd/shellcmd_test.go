@@ -0,0 +1,58 @@
+package shellcmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrites(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    []string
+	}{
+		{"read only", "ls -la && cat go.mod | grep module", nil},
+		{"redirections", `echo hi > out.txt; date >> "logs/run.log" 2>&1; cmd 2>/dev/null >&2`, []string{"/repo/out.txt", "/repo/logs/run.log"}},
+		{"stderr redirection", "go test ./... 2> errors.txt", []string{"/repo/errors.txt"}},
+		{"rm and mkdir", "rm -rf build /tmp/x && mkdir -p -m 0755 dist", []string{"/repo/build", "/tmp/x", "/repo/dist"}},
+		{"touch and tee", "touch -d yesterday a.go; echo x | tee -a b.txt c.txt", []string{"/repo/a.go", "/repo/b.txt", "/repo/c.txt"}},
+		{"mv renames", "mv old.go new.go", []string{"/repo/old.go", "/repo/new.go"}},
+		{"mv into directory", "mv a.go b.go pkg/", []string{"/repo/a.go", "/repo/b.go", "/repo/pkg/a.go", "/repo/pkg/b.go"}},
+		{"cp destination only", "cp -r -S .orig src/a.go dst.go", []string{"/repo/dst.go"}},
+		{"sed in place", "sed -i 's/foo/bar/g' main.go util.go", []string{"/repo/main.go", "/repo/util.go"}},
+		{"sed with expression", "sed -i.bak -e 's/a/b/' -e 's/c/d/' main.go", []string{"/repo/main.go"}},
+		{"sed without -i", "sed -n '1,5p' main.go > head.txt", []string{"/repo/head.txt"}},
+		{"perl in place", "perl -pi -e 's/x/y/' lib.pl", []string{"/repo/lib.pl"}},
+		{"git subcommands", "git rm -q gone.go && git mv a.go b.go && git restore --source HEAD c.go && git commit -m msg", []string{"/repo/gone.go", "/repo/a.go", "/repo/b.go", "/repo/c.go"}},
+		{"wrappers and assignments", "FOO=1 sudo rm x.go", []string{"/repo/x.go"}},
+		{"cd is followed", "cd sub && touch f && cd /abs && rm g", []string{"/repo/sub/f", "/abs/g"}},
+		{"unknown cd", "cd $DIR && rm f", nil},
+		{"runtime values skipped", `rm "$FILE" *.tmp ~/x $(ls) && touch 'lit$eral'`, []string{"/repo/lit$eral"}},
+		{"heredoc body", "cat > notes.md <<'EOF'\nrm important.go\nEOF\ntouch done", []string{"/repo/notes.md", "/repo/done"}},
+		{"comments and operands", "rm -- -weird # rm commented.go", []string{"/repo/-weird"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Writes(tt.command, "/repo"))
+		})
+	}
+}
+
+func TestWords(t *testing.T) {
+	words := Words("cd sub && FOO=1 cat < in.txt > \"out file\" # cat gone\ncat <<EOF\nrm body\nEOF\ncd $DIR; ls ~/x", "/repo")
+	assert.Equal(t, []Word{
+		{Text: "cd", Dir: "/repo", Literal: true},
+		{Text: "sub", Dir: "/repo", Literal: true},
+		{Text: "FOO=1", Dir: "/repo/sub", Literal: true},
+		{Text: "cat", Dir: "/repo/sub", Literal: true},
+		{Text: "in.txt", Dir: "/repo/sub", Literal: true},
+		{Text: "out file", Dir: "/repo/sub", Literal: true},
+		{Text: "cat", Dir: "/repo/sub", Literal: true},
+		{Text: "EOF", Dir: "/repo/sub", Literal: true},
+		{Text: "cd", Dir: "/repo/sub", Literal: true},
+		{Text: "$DIR", Dir: "/repo/sub", Literal: false},
+		{Text: "ls", Dir: "", Literal: true},
+		{Text: "~/x", Dir: "", Literal: false},
+	}, words)
+}
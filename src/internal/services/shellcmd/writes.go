@@ -0,0 +1,241 @@
+package shellcmd
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// optionValues lists, per command, the short options that take a value
+var optionValues = map[string]string{
+	"cp":       "St",
+	"mv":       "St",
+	"ln":       "St",
+	"mkdir":    "m",
+	"touch":    "drt",
+	"truncate": "rs",
+	"sed":      "efl",
+	"perl":     "eEIlmM",
+	"git":      "s",
+}
+
+// Writes returns the absolute paths a command line writes, moves or
+// removes, as far as the command text shows it: output redirections, file
+// operands of rm, rmdir, unlink, shred, touch, mkdir, truncate and tee, the
+// sources and destination of mv, the destination of cp and ln, the files of
+// sed -i and perl -i, and git rm, git mv and git restore. cd is followed.
+// Values only known at run time are skipped, and a destination directory
+// is recorded as given unless the command names several sources or ends it
+// with a slash.
+func Writes(command, cwd string) []string {
+	var targets []string
+	seen := make(map[string]bool)
+	add := func(dir string, t token) {
+		if !t.literal || t.text == "" || t.text == "-" || strings.HasPrefix(t.text, "/dev/") {
+			return
+		}
+		path := t.text
+		if !filepath.IsAbs(path) {
+			if dir == "" {
+				return
+			}
+			path = filepath.Join(dir, path)
+		}
+		path = filepath.Clean(path)
+		if !seen[path] {
+			seen[path] = true
+			targets = append(targets, path)
+		}
+	}
+
+	dir := cwd
+	for _, cmd := range splitCommands(lex(command)) {
+		var words []token
+		for i := 0; i < len(cmd); i++ {
+			switch cmd[i].kind {
+			case outputToken:
+				if i+1 < len(cmd) && cmd[i+1].kind == wordToken {
+					add(dir, cmd[i+1])
+					i++
+				}
+			case inputToken:
+				if i+1 < len(cmd) && cmd[i+1].kind == wordToken {
+					i++
+				}
+			default:
+				words = append(words, cmd[i])
+			}
+		}
+
+		words = stripPrefixes(words)
+		if len(words) == 0 || !words[0].literal {
+			continue
+		}
+		name := filepath.Base(words[0].text)
+		args := words[1:]
+		if name == "git" {
+			name, args = gitCommand(args)
+		}
+
+		switch name {
+		case "cd":
+			dir = changeDir(dir, args)
+		case "rm", "rmdir", "unlink", "shred", "touch", "mkdir", "truncate", "tee", "git rm", "git restore":
+			for _, t := range operands(name, args) {
+				add(dir, t)
+			}
+		case "mv", "git mv":
+			files := operands(name, args)
+			if len(files) < 2 {
+				continue
+			}
+			for _, t := range files[:len(files)-1] {
+				add(dir, t)
+			}
+			for _, t := range destinations(files) {
+				add(dir, t)
+			}
+		case "cp", "ln":
+			files := operands(name, args)
+			if len(files) < 2 {
+				continue
+			}
+			for _, t := range destinations(files) {
+				add(dir, t)
+			}
+		case "sed", "perl":
+			if !inPlace(name, args) {
+				continue
+			}
+			files := operands(name, args)
+			if !hasScriptOption(name, args) && len(files) > 0 {
+				files = files[1:]
+			}
+			for _, t := range files {
+				add(dir, t)
+			}
+		}
+	}
+	return targets
+}
+
+// gitCommand names the git subcommands that change files ("git rm") and
+// returns their arguments
+func gitCommand(args []token) (string, []token) {
+	for i, t := range args {
+		if strings.HasPrefix(t.text, "-") {
+			continue
+		}
+		switch t.text {
+		case "rm", "mv", "restore":
+			return "git " + t.text, args[i+1:]
+		}
+		return "git", nil
+	}
+	return "git", nil
+}
+
+// operands returns a command's non-option arguments, skipping the values of
+// options that take one
+func operands(name string, args []token) []token {
+	var result []token
+	for i := 0; i < len(args); i++ {
+		text := args[i].text
+		switch {
+		case text == "--":
+			return append(result, args[i+1:]...)
+		case strings.HasPrefix(text, "--"):
+			if text == "--source" || text == "--expression" || text == "--file" {
+				i++
+			}
+		case strings.HasPrefix(text, "-") && text != "-":
+			if _, takesNext := shortOptions(name, text); takesNext {
+				i++
+			}
+		default:
+			result = append(result, args[i])
+		}
+	}
+	return result
+}
+
+// shortOptions returns the letters of an option cluster such as -pi or
+// -e, stopping at the first option that takes a value, and whether that
+// value is the next word. The in-place suffix of sed and perl -i is
+// attached (-i.bak), never the next word.
+func shortOptions(name, cluster string) (string, bool) {
+	values := optionValues[name]
+	if strings.HasPrefix(name, "git ") {
+		values = optionValues["git"]
+	}
+	letters := cluster[1:]
+	for j, r := range letters {
+		if (name == "sed" || name == "perl") && r == 'i' {
+			return letters[:j+1], false
+		}
+		if strings.ContainsRune(values, r) {
+			return letters[:j+1], j == len(letters)-1
+		}
+	}
+	return letters, false
+}
+
+// destinations returns where the sources of mv, cp or ln end up: the
+// destination itself, or its entries when it is a directory
+func destinations(files []token) []token {
+	dest := files[len(files)-1]
+	sources := files[:len(files)-1]
+	if len(sources) == 1 && !strings.HasSuffix(dest.text, "/") {
+		return []token{dest}
+	}
+	var result []token
+	for _, src := range sources {
+		if !src.literal {
+			continue
+		}
+		result = append(result, token{
+			kind:    wordToken,
+			text:    filepath.Join(dest.text, filepath.Base(src.text)),
+			literal: dest.literal,
+		})
+	}
+	return result
+}
+
+// inPlace reports whether sed or perl edits files in place (-i, -i.bak,
+// -pi, --in-place)
+func inPlace(name string, args []token) bool {
+	return hasOption(name, args, "i", "--in-place")
+}
+
+// hasScriptOption reports whether the script of sed or perl is given by an
+// option (-e, -f), so every operand is a file
+func hasScriptOption(name string, args []token) bool {
+	return hasOption(name, args, "ef", "--expression", "--file")
+}
+
+// hasOption reports whether one of the short option letters or long option
+// prefixes appears before the operands end
+func hasOption(name string, args []token, letters string, long ...string) bool {
+	for i := 0; i < len(args); i++ {
+		text := args[i].text
+		switch {
+		case text == "--":
+			return false
+		case strings.HasPrefix(text, "--"):
+			for _, l := range long {
+				if strings.HasPrefix(text, l) {
+					return true
+				}
+			}
+		case strings.HasPrefix(text, "-") && text != "-":
+			cluster, takesNext := shortOptions(name, text)
+			if strings.ContainsAny(cluster, letters) {
+				return true
+			}
+			if takesNext {
+				i++
+			}
+		}
+	}
+	return false
+}
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"claudex/internal/services/ignore"
+
 	"github.com/spf13/afero"
 )
 
@@ -14,43 +16,47 @@ import (
 // It returns a list of detected stack identifiers such as "typescript", "go", "python", "php".
 func Detect(fs afero.Fs, projectDir string) []string {
 	var stacks []string
+	m := ignore.New(fs, projectDir)
+	has := func(filename string) bool {
+		return findFile(m, projectDir, filename, 3)
+	}
 
 	// React Native detection (before TypeScript - RN projects also have package.json)
-	if FindFile(fs, projectDir, "app.json", 3) ||
-		FindFile(fs, projectDir, "react-native.config.js", 3) ||
-		FindFile(fs, projectDir, "metro.config.js", 3) {
+	if has("app.json") ||
+		has("react-native.config.js") ||
+		has("metro.config.js") {
 		stacks = append(stacks, "react-native")
 	}
 
 	// Flutter/Dart detection (after React Native, before TypeScript)
-	if FindFile(fs, projectDir, "pubspec.yaml", 3) {
+	if has("pubspec.yaml") {
 		stacks = append(stacks, "flutter")
 	}
 
 	// TypeScript detection
-	if FindFile(fs, projectDir, "tsconfig.json", 3) {
+	if has("tsconfig.json") {
 		stacks = append(stacks, "typescript")
-	} else if FindFile(fs, projectDir, "package.json", 3) {
+	} else if has("package.json") {
 		stacks = append(stacks, "typescript")
 	}
 
 	// Go detection
-	if FindFile(fs, projectDir, "go.mod", 3) {
+	if has("go.mod") {
 		stacks = append(stacks, "go")
 	}
 
 	// Python detection
-	if FindFile(fs, projectDir, "pyproject.toml", 3) ||
-		FindFile(fs, projectDir, "requirements.txt", 3) ||
-		FindFile(fs, projectDir, "setup.py", 3) ||
-		FindFile(fs, projectDir, "Pipfile", 3) {
+	if has("pyproject.toml") ||
+		has("requirements.txt") ||
+		has("setup.py") ||
+		has("Pipfile") {
 		stacks = append(stacks, "python")
 	}
 
 	// PHP detection
-	if FindFile(fs, projectDir, "composer.json", 3) ||
-		FindFile(fs, projectDir, "index.php", 3) ||
-		FindFile(fs, projectDir, "artisan", 3) {
+	if has("composer.json") ||
+		has("index.php") ||
+		has("artisan") {
 		stacks = append(stacks, "php")
 	}
 
@@ -58,26 +64,33 @@ func Detect(fs afero.Fs, projectDir string) []string {
 }
 
 // FindFile searches for a file in projectDir and subdirectories up to maxDepth.
-// It performs a breadth-first search, skipping hidden directories (those starting with '.').
+// It performs a depth-first search, skipping hidden directories (those starting
+// with '.') and paths ignored by .gitignore or .claudexignore.
 func FindFile(fs afero.Fs, dir string, filename string, maxDepth int) bool {
+	return findFile(ignore.New(fs, dir), dir, filename, maxDepth)
+}
+
+// findFile is FindFile with a shared ignore matcher
+func findFile(m *ignore.Matcher, dir string, filename string, maxDepth int) bool {
 	if maxDepth < 0 {
 		return false
 	}
 
 	// Check current directory
-	if FileExists(fs, filepath.Join(dir, filename)) {
-		return true
-	}
-
-	// Search subdirectories
-	entries, err := afero.ReadDir(fs, dir)
+	entries, err := m.ReadDir(dir)
 	if err != nil {
 		return false
 	}
+	for _, entry := range entries {
+		if !entry.IsDir() && entry.Name() == filename {
+			return true
+		}
+	}
 
+	// Search subdirectories
 	for _, entry := range entries {
 		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
-			if FindFile(fs, filepath.Join(dir, entry.Name()), filename, maxDepth-1) {
+			if findFile(m, filepath.Join(dir, entry.Name()), filename, maxDepth-1) {
 				return true
 			}
 		}
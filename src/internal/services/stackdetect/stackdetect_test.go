@@ -371,6 +371,52 @@ func Test_FindFile_SkipsHiddenDirectories(t *testing.T) {
 	}
 }
 
+func Test_FindFile_SkipsIgnoredPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		filename string
+		want     bool
+	}{
+		{
+			name:     "node_modules is ignored by default",
+			files:    map[string]string{"node_modules/lib/package.json": "{}"},
+			filename: "package.json",
+			want:     false,
+		},
+		{
+			name:     "Gitignored build output",
+			files:    map[string]string{".gitignore": "dist/\n", "dist/app.json": "{}"},
+			filename: "app.json",
+			want:     false,
+		},
+		{
+			name:     "Claudex ignore file",
+			files:    map[string]string{".claudexignore": "examples/\n", "examples/go.mod": "module x"},
+			filename: "go.mod",
+			want:     false,
+		},
+		{
+			name:     "Re-included by negation",
+			files:    map[string]string{".claudexignore": "!vendor/\n", "vendor/composer.json": "{}"},
+			filename: "composer.json",
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := testutil.NewTestHarness()
+			h.CreateDir("/project")
+			for path, content := range tt.files {
+				h.WriteFile("/project/"+path, content)
+			}
+
+			assert.Equal(t, tt.want, FindFile(h.FS, "/project", tt.filename, 3))
+		})
+	}
+}
+
 func Test_FileExists_WithAfero(t *testing.T) {
 	tests := []struct {
 		name       string
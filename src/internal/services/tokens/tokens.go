@@ -0,0 +1,11 @@
+// Package tokens estimates how many model tokens a text takes, for the
+// budgets claudex puts on prompt context
+package tokens
+
+// CharsPerToken is the rough ratio used to estimate token counts
+const CharsPerToken = 4
+
+// Estimate approximates the token count of s
+func Estimate(s string) int {
+	return (len(s) + CharsPerToken - 1) / CharsPerToken
+}
@@ -0,0 +1,182 @@
+// Package checkpolicy provides the usecase for checking policy rules offline
+// against a fixture of recorded tool calls (`claudex policy test <fixture>`).
+package checkpolicy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"claudex/internal/services/env"
+	"claudex/internal/services/policy"
+
+	"github.com/spf13/afero"
+)
+
+// Case is one fixture entry: a tool call and the decision it should get
+type Case struct {
+	Name        string                 `json:"name"`
+	ToolName    string                 `json:"tool_name"`
+	ToolInput   map[string]interface{} `json:"tool_input"`
+	AgentType   string                 `json:"agent_type"`
+	CWD         string                 `json:"cwd"`
+	SessionPath string                 `json:"session_path"`
+	Expect      policy.Decision        `json:"expect"`
+}
+
+// Outcome is the result of evaluating one case
+type Outcome struct {
+	Case   Case
+	Result policy.Result
+}
+
+// Passed reports whether the case got the expected decision
+func (o Outcome) Passed() bool {
+	return o.Result.Decision == o.Case.Expect
+}
+
+// UseCase checks policy files against fixtures
+type UseCase struct {
+	fs  afero.Fs
+	env env.Environment
+}
+
+// New creates a new CheckPolicy usecase
+func New(fs afero.Fs, environ env.Environment) *UseCase {
+	return &UseCase{fs: fs, env: environ}
+}
+
+// Execute loads the project and global policies, evaluates every case in the
+// fixture and prints one line per case. It fails when any case does not get
+// its expected decision.
+func (uc *UseCase) Execute(projectDir, fixturePath string) error {
+	outcomes, err := uc.Check(projectDir, fixturePath)
+	if err != nil {
+		return err
+	}
+	fmt.Print(Format(outcomes))
+
+	failed := 0
+	for _, o := range outcomes {
+		if !o.Passed() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d policy cases failed", failed, len(outcomes))
+	}
+	return nil
+}
+
+// Check evaluates the fixture without printing
+func (uc *UseCase) Check(projectDir, fixturePath string) ([]Outcome, error) {
+	p, err := policy.Load(uc.fs, projectDir, uc.env)
+	if err != nil {
+		return nil, err
+	}
+
+	if !filepath.IsAbs(fixturePath) {
+		fixturePath = filepath.Join(projectDir, fixturePath)
+	}
+	data, err := afero.ReadFile(uc.fs, fixturePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+	cases, err := ParseFixture(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fixturePath, err)
+	}
+
+	outcomes := make([]Outcome, 0, len(cases))
+	for _, c := range cases {
+		cwd := c.CWD
+		if cwd == "" {
+			cwd = projectDir
+		}
+		result := p.Evaluate(policy.Request{
+			Tool:        c.ToolName,
+			Input:       c.ToolInput,
+			Agent:       c.AgentType,
+			CWD:         cwd,
+			ProjectDir:  projectDir,
+			SessionPath: c.SessionPath,
+		})
+		outcomes = append(outcomes, Outcome{Case: c, Result: result})
+	}
+	return outcomes, nil
+}
+
+// ParseFixture reads cases from a JSON array or from JSON lines. Cases without
+// an expected decision expect allow.
+func ParseFixture(data []byte) ([]Case, error) {
+	var cases []Case
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &cases); err != nil {
+			return nil, fmt.Errorf("invalid fixture: %w", err)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+		scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+		line := 0
+		for scanner.Scan() {
+			line++
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" || strings.HasPrefix(text, "//") {
+				continue
+			}
+			var c Case
+			if err := json.Unmarshal([]byte(text), &c); err != nil {
+				return nil, fmt.Errorf("invalid fixture line %d: %w", line, err)
+			}
+			cases = append(cases, c)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range cases {
+		if cases[i].Expect == "" {
+			cases[i].Expect = policy.Allow
+		}
+		if cases[i].Name == "" {
+			cases[i].Name = fmt.Sprintf("case %d", i+1)
+		}
+		switch cases[i].Expect {
+		case policy.Allow, policy.Deny, policy.Ask:
+		default:
+			return nil, fmt.Errorf("%s: unknown expected decision %q", cases[i].Name, cases[i].Expect)
+		}
+	}
+	return cases, nil
+}
+
+// Format renders outcomes as PASS/FAIL lines with a summary
+func Format(outcomes []Outcome) string {
+	var b strings.Builder
+	passed := 0
+	for _, o := range outcomes {
+		status := "FAIL"
+		if o.Passed() {
+			status = "PASS"
+			passed++
+		}
+		fmt.Fprintf(&b, "%s  %s: %s", status, o.Case.Name, o.Result.Decision)
+		if !o.Passed() {
+			fmt.Fprintf(&b, " (expected %s)", o.Case.Expect)
+		}
+		if o.Result.Matched() {
+			fmt.Fprintf(&b, " by rule %q", o.Result.Rule)
+			if o.Result.Reason != "" {
+				fmt.Fprintf(&b, " - %s", o.Result.Reason)
+			}
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "\n%d passed, %d failed\n", passed, len(outcomes)-passed)
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package checkpolicy
+
+import (
+	"testing"
+
+	"claudex/internal/services/policy"
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+[[rule]]
+name = "ask-push"
+tools = ["Bash"]
+command = '^git push'
+decision = "ask"
+reason = "Pushing publishes commits"
+
+[[rule]]
+name = "no-env"
+tools = ["Write", "Edit"]
+paths = [".env"]
+decision = "deny"
+`
+
+func TestCheck_ArrayFixture(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/repo/.claudex/policy.toml", []byte(testPolicy), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/repo/fixture.json", []byte(`[
+  {"name": "push", "tool_name": "Bash", "tool_input": {"command": "git push"}, "expect": "ask"},
+  {"name": "env", "tool_name": "Write", "tool_input": {"file_path": "/repo/.env"}, "expect": "deny"},
+  {"name": "status", "tool_name": "Bash", "tool_input": {"command": "git status"}},
+  {"name": "wrong", "tool_name": "Edit", "tool_input": {"file_path": ".env"}, "expect": "allow"}
+]`), 0644))
+
+	uc := New(fs, testutil.NewMockEnv())
+	outcomes, err := uc.Check("/repo", "fixture.json")
+	require.NoError(t, err)
+	require.Len(t, outcomes, 4)
+
+	assert.True(t, outcomes[0].Passed())
+	assert.Equal(t, "ask-push", outcomes[0].Result.Rule)
+	assert.True(t, outcomes[1].Passed())
+	assert.True(t, outcomes[2].Passed())
+	assert.False(t, outcomes[3].Passed())
+	assert.Equal(t, policy.Deny, outcomes[3].Result.Decision)
+
+	out := Format(outcomes)
+	assert.Contains(t, out, `PASS  push: ask by rule "ask-push" - Pushing publishes commits`)
+	assert.Contains(t, out, `FAIL  wrong: deny (expected allow) by rule "no-env"`)
+	assert.Contains(t, out, "3 passed, 1 failed")
+
+	err = uc.Execute("/repo", "fixture.json")
+	assert.EqualError(t, err, "1 of 4 policy cases failed")
+}
+
+func TestParseFixture_JSONLines(t *testing.T) {
+	cases, err := ParseFixture([]byte(`// recorded calls
+{"tool_name": "Read", "tool_input": {"file_path": "a.go"}}
+
+{"name": "push", "tool_name": "Bash", "tool_input": {"command": "git push"}, "expect": "ask"}
+`))
+	require.NoError(t, err)
+	require.Len(t, cases, 2)
+	assert.Equal(t, "case 1", cases[0].Name)
+	assert.Equal(t, policy.Allow, cases[0].Expect)
+	assert.Equal(t, policy.Ask, cases[1].Expect)
+
+	_, err = ParseFixture([]byte(`[{"tool_name": "Bash", "expect": "maybe"}]`))
+	assert.ErrorContains(t, err, `unknown expected decision "maybe"`)
+}
@@ -11,43 +11,75 @@ import (
 	"path/filepath"
 	"strings"
 
+	"claudex/internal/doc/skeleton"
 	"claudex/internal/services/commander"
+	"claudex/internal/services/docfiles"
 	"claudex/internal/services/env"
+	"claudex/internal/services/ignore"
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/lock"
 
 	"github.com/spf13/afero"
 )
 
 // CreateIndexUseCase orchestrates the index.md generation workflow
 type CreateIndexUseCase struct {
-	fs  afero.Fs
-	cmd commander.Commander
-	env env.Environment
+	fs    afero.Fs
+	cmd   commander.Commander
+	env   env.Environment
+	names docfiles.Names
+
+	// executor runs the Claude jobs of ExecuteRecursive
+	executor jobs.Executor
+
+	// lockSvc guards ExecuteRecursive against concurrent doc updates
+	lockSvc lock.LockService
 }
 
-// New creates a new CreateIndexUseCase instance with the given dependencies
-func New(fs afero.Fs, cmd commander.Commander, env env.Environment) *CreateIndexUseCase {
+// New creates a new CreateIndexUseCase instance with the given dependencies.
+// names sets which files count as existing docs; new files use names.Primary().
+// lockOpts configures stale detection of the doc update lock.
+func New(fs afero.Fs, cmd commander.Commander, env env.Environment, names docfiles.Names, lockOpts lock.Options) *CreateIndexUseCase {
 	return &CreateIndexUseCase{
-		fs:  fs,
-		cmd: cmd,
-		env: env,
+		fs:       fs,
+		cmd:      cmd,
+		env:      env,
+		names:    docfiles.New(names),
+		executor: jobs.NewExecutor(),
+		lockSvc:  lock.NewWithOptions(fs, lockOpts),
 	}
 }
 
-// codeExtensions defines the file extensions that should be included in the scan
-var codeExtensions = []string{
+// CodeExtensions defines the file extensions that count as code (scan listings, docs lint coverage)
+var CodeExtensions = []string{
 	".go", ".ts", ".tsx", ".js", ".jsx", ".py", ".rs",
 	".java", ".kt", ".swift", ".c", ".cpp", ".h", ".rb", ".php",
 }
 
+// Options configures Execute
+type Options struct {
+	// Offline writes the static analysis skeleton as-is instead of asking
+	// Claude to turn it into prose
+	Offline bool
+
+	// Force lets Offline write the skeleton when the directory already has a
+	// doc file
+	Force bool
+}
+
 // Execute generates an index.md file for the specified directory path.
-// It validates the directory exists, scans for code files, finds nearby index.md
-// for style reference, builds a prompt, and invokes Claude to generate the content.
+// It validates the directory exists, builds a skeleton from static analysis,
+// finds nearby index.md for style reference, builds a prompt, and invokes
+// Claude to generate the content. With opts.Offline the skeleton is written
+// directly and Claude is not needed; a directory that already has a doc
+// file is refused unless opts.Force is set.
 //
 // Parameters:
 //   - dirPath: The directory path where index.md should be created
+//   - opts: Generation options
 //
 // Returns an error if the generation fails.
-func (uc *CreateIndexUseCase) Execute(dirPath string) error {
+func (uc *CreateIndexUseCase) Execute(dirPath string, opts Options) error {
 	// 1. Validate directory exists
 	absPath, err := filepath.Abs(dirPath)
 	if err != nil {
@@ -66,39 +98,80 @@ func (uc *CreateIndexUseCase) Execute(dirPath string) error {
 		return fmt.Errorf("path is a file, not a directory: %s", absPath)
 	}
 
-	// 2. Scan directory for code files
-	fileListing, err := uc.scanDirectory(absPath)
-	if err != nil {
-		return fmt.Errorf("failed to scan directory: %w", err)
+	outputPath := filepath.Join(absPath, uc.names.Primary())
+
+	// 2. Offline: write the skeleton without Claude
+	if opts.Offline {
+		if existing := uc.names.Find(uc.fs, absPath); existing != "" && !opts.Force {
+			return fmt.Errorf("%s already exists; use --force to write the skeleton anyway", existing)
+		}
+		if err := uc.writeSkeleton(absPath); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Created %s skeleton at: %s\n", uc.names.Primary(), outputPath)
+		return nil
 	}
 
-	// 3. Find nearby index.md for style reference
-	styleReference, err := uc.findStyleReference(absPath)
+	// 3. Build the prompt around the skeleton
+	prompt, err := uc.prompt(absPath)
 	if err != nil {
-		// Non-fatal - we can proceed without a style reference
-		styleReference = "(No nearby index.md found for style reference)"
+		return err
 	}
 
-	// 4. Build prompt
-	prompt := uc.buildPrompt(absPath, fileListing, styleReference)
-
-	// 5. Invoke Claude with haiku model - Claude will create the file directly
-	outputPath := filepath.Join(absPath, "index.md")
+	// 4. Invoke Claude with haiku model - Claude will create the file directly
 	if err := uc.invokeClaudeSync(prompt, outputPath); err != nil {
-		return fmt.Errorf("failed to generate index.md: %w", err)
+		return fmt.Errorf("failed to generate %s: %w", uc.names.Primary(), err)
 	}
 
-	// 6. Display success message
-	fmt.Printf("✓ Created index.md at: %s\n", outputPath)
+	// 5. Display success message
+	fmt.Printf("✓ Created %s at: %s\n", uc.names.Primary(), outputPath)
 
 	return nil
 }
 
-// scanDirectory scans the directory and returns a formatted listing of code files
+// prompt builds the Claude prompt for dirPath. The skeleton gives Claude the
+// structure and API to describe; when static analysis fails, the full file
+// listing is sent instead.
+func (uc *CreateIndexUseCase) prompt(dirPath string) (string, error) {
+	structure, err := skeleton.Generate(uc.fs, dirPath, uc.names)
+	if err != nil {
+		fileListing, err := uc.scanDirectory(dirPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to scan directory: %w", err)
+		}
+		structure = "FILES IN DIRECTORY:\n" + fileListing
+	} else {
+		structure = "SKELETON (generated from the source):\n" + structure
+	}
+
+	styleReference, err := uc.findStyleReference(dirPath)
+	if err != nil {
+		// Non-fatal - we can proceed without a style reference
+		styleReference = fmt.Sprintf("(No nearby %s found for style reference)", uc.names)
+	}
+
+	return uc.buildPrompt(dirPath, structure, styleReference), nil
+}
+
+// writeSkeleton writes the static analysis skeleton as dirPath's doc file
+func (uc *CreateIndexUseCase) writeSkeleton(dirPath string) error {
+	content, err := skeleton.Generate(uc.fs, dirPath, uc.names)
+	if err != nil {
+		return fmt.Errorf("failed to analyze directory: %w", err)
+	}
+	outputPath := filepath.Join(dirPath, uc.names.Primary())
+	if err := afero.WriteFile(uc.fs, outputPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	return nil
+}
+
+// scanDirectory scans the directory and returns a formatted listing of code
+// files, leaving out paths ignored by .gitignore or .claudexignore
 func (uc *CreateIndexUseCase) scanDirectory(dirPath string) (string, error) {
 	var files []string
 
-	err := afero.Walk(uc.fs, dirPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(uc.fs, dirPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -116,10 +189,9 @@ func (uc *CreateIndexUseCase) scanDirectory(dirPath string) (string, error) {
 
 		// Check if it's a directory
 		if info.IsDir() {
-			// Check if subdirectory has an index.md
-			indexPath := filepath.Join(path, "index.md")
-			if _, err := uc.fs.Stat(indexPath); err == nil {
-				files = append(files, fmt.Sprintf("%s/ (has index.md)", relPath))
+			// Check if subdirectory has a doc file
+			if indexPath := uc.names.Find(uc.fs, path); indexPath != "" {
+				files = append(files, fmt.Sprintf("%s/ (has %s)", relPath, filepath.Base(indexPath)))
 			} else {
 				files = append(files, relPath+"/")
 			}
@@ -128,15 +200,15 @@ func (uc *CreateIndexUseCase) scanDirectory(dirPath string) (string, error) {
 
 		// Check if it's a code file
 		ext := filepath.Ext(path)
-		for _, codeExt := range codeExtensions {
+		for _, codeExt := range CodeExtensions {
 			if ext == codeExt {
 				files = append(files, relPath)
 				break
 			}
 		}
 
-		// Also include existing index.md if present
-		if filepath.Base(path) == "index.md" {
+		// Also include existing doc files if present
+		if uc.names.Match(filepath.Base(path)) {
 			files = append(files, relPath)
 		}
 
@@ -154,15 +226,14 @@ func (uc *CreateIndexUseCase) scanDirectory(dirPath string) (string, error) {
 	return strings.Join(files, "\n"), nil
 }
 
-// findStyleReference looks for nearby index.md files in parent or sibling directories
+// findStyleReference looks for nearby doc files in parent or sibling directories
 func (uc *CreateIndexUseCase) findStyleReference(dirPath string) (string, error) {
 	// Try parent directory first
 	parentDir := filepath.Dir(dirPath)
-	parentIndex := filepath.Join(parentDir, "index.md")
-
-	content, err := afero.ReadFile(uc.fs, parentIndex)
-	if err == nil {
-		return string(content), nil
+	if parentIndex := uc.names.Find(uc.fs, parentDir); parentIndex != "" {
+		if content, err := afero.ReadFile(uc.fs, parentIndex); err == nil {
+			return string(content), nil
+		}
 	}
 
 	// Try sibling directories
@@ -176,9 +247,11 @@ func (uc *CreateIndexUseCase) findStyleReference(dirPath string) (string, error)
 			continue
 		}
 
-		siblingIndex := filepath.Join(parentDir, entry.Name(), "index.md")
-		content, err := afero.ReadFile(uc.fs, siblingIndex)
-		if err == nil {
+		siblingIndex := uc.names.Find(uc.fs, filepath.Join(parentDir, entry.Name()))
+		if siblingIndex == "" {
+			continue
+		}
+		if content, err := afero.ReadFile(uc.fs, siblingIndex); err == nil {
 			return string(content), nil
 		}
 	}
@@ -187,13 +260,13 @@ func (uc *CreateIndexUseCase) findStyleReference(dirPath string) (string, error)
 }
 
 // buildPrompt constructs the Claude prompt for index.md generation
-func (uc *CreateIndexUseCase) buildPrompt(dirPath, fileListing, styleReference string) string {
-	return fmt.Sprintf(`Create an index.md documentation file for the directory: %s
+func (uc *CreateIndexUseCase) buildPrompt(dirPath, structure, styleReference string) string {
+	name := uc.names.Primary()
+	return fmt.Sprintf(`Create a %s documentation file for the directory: %s
 
-FILES IN DIRECTORY:
 %s
 
-STYLE REFERENCE (from nearby index.md):
+STYLE REFERENCE (from a nearby documentation file):
 %s
 
 Requirements:
@@ -201,10 +274,11 @@ Requirements:
 - Include a title based on the package/directory name
 - Write a 1-2 sentence summary of the directory's purpose
 - List key files with brief descriptions (if relevant)
-- If subdirectories have index.md files, use markdown links: [subdir/](./subdir/index.md)
-- Match the style and tone of the reference index.md
+- If subdirectories have documentation files (%s), use markdown links to them, e.g. [subdir/](./subdir/%s)
+- If a skeleton is given, keep its section structure, replace every %s placeholder with prose, and trim API entries a reader would not need
+- Match the style and tone of the reference documentation file
 - Use the Write tool to create the file directly at the target path
-- Do NOT output the content to stdout - write it to the file`, dirPath, fileListing, styleReference)
+- Do NOT output the content to stdout - write it to the file`, name, dirPath, structure, styleReference, uc.names, name, skeleton.Placeholder)
 }
 
 // invokeClaudeSync invokes Claude synchronously to create the index.md file directly
@@ -215,7 +289,7 @@ func (uc *CreateIndexUseCase) invokeClaudeSync(prompt string, outputPath string)
 	}
 
 	// Add output path to prompt so Claude knows where to write
-	fullPrompt := fmt.Sprintf("%s\n\nWrite the index.md file to: %s", prompt, outputPath)
+	fullPrompt := fmt.Sprintf("%s\n\nWrite the %s file to: %s", prompt, uc.names.Primary(), outputPath)
 
 	// Create command with haiku model for cost efficiency
 	cmd := exec.Command("claude", "-p", fullPrompt, "--model", "haiku")
@@ -0,0 +1,35 @@
+package createindex
+
+import (
+	"testing"
+
+	"claudex/internal/services/docfiles"
+	"claudex/internal/services/lock"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecute_OfflineKeepsExistingDoc(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/repo/pkg/pkg.go", []byte("// Package pkg does things\npackage pkg\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/repo/pkg/README.md", []byte("# Hand-written\n"), 0644))
+	uc := New(fs, &mockCommander{}, mockEnv{}, docfiles.Names{"index.md", "README.md"}, lock.Options{})
+
+	err := uc.Execute("/repo/pkg", Options{Offline: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "/repo/pkg/README.md already exists")
+	content, err := afero.ReadFile(fs, "/repo/pkg/README.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# Hand-written\n", string(content))
+	exists, err := afero.Exists(fs, "/repo/pkg/index.md")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// --force writes the skeleton as the primary doc file anyway
+	require.NoError(t, uc.Execute("/repo/pkg", Options{Offline: true, Force: true}))
+	content, err = afero.ReadFile(fs, "/repo/pkg/index.md")
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "pkg")
+}
@@ -0,0 +1,509 @@
+package createindex
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"claudex/internal/doc/rangeupdater"
+	"claudex/internal/services/ignore"
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/paths"
+
+	"github.com/spf13/afero"
+)
+
+// StateFileName is the file in .claudex that records an unfinished recursive run
+const StateFileName = "create_index_state.json"
+
+// DefaultMinFiles is the number of code files a directory needs before it gets
+// its own doc file when RecursiveOptions.MinFiles is not set
+const DefaultMinFiles = 1
+
+// minChildDocs is how many documented subdirectories make a directory without
+// enough code files of its own worth an index that links them together
+const minChildDocs = 2
+
+// RecursiveOptions configures ExecuteRecursive
+type RecursiveOptions struct {
+	// MaxDepth limits how far below root directories are considered (0 means unlimited)
+	MaxDepth int
+
+	// MinFiles is the number of code files a directory must contain directly
+	// (<= 0 means DefaultMinFiles)
+	MinFiles int
+
+	// MaxJobs and JobTimeout configure the job supervisor (zero uses its defaults)
+	MaxJobs    int
+	JobTimeout time.Duration
+
+	// Resume continues the run recorded in the state file instead of planning again
+	Resume bool
+
+	// Restart discards the state file of an interrupted run and plans again
+	Restart bool
+
+	// DryRun prints the plan without invoking Claude
+	DryRun bool
+
+	// Offline writes static analysis skeletons instead of running Claude
+	Offline bool
+}
+
+// Plan lists the directories a recursive run creates doc files for
+type Plan struct {
+	// Dirs holds absolute directory paths, deepest first
+	Dirs []string
+
+	// Documented counts directories that already have a doc file
+	Documented int
+
+	// BelowThreshold counts directories skipped by MaxDepth or MinFiles
+	BelowThreshold int
+}
+
+// runState is persisted after each level so an interrupted run can resume
+type runState struct {
+	Root      string            `json:"root"`
+	Dirs      []string          `json:"dirs"`
+	Completed []string          `json:"completed,omitempty"`
+	Failed    map[string]string `json:"failed,omitempty"`
+	StartedAt time.Time         `json:"started_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// ExecuteRecursive creates doc files for every directory under root that lacks
+// one. Directories are processed one depth level at a time, deepest first, so
+// parents are generated after their children and can link to them; within a
+// level Claude runs with bounded parallelism (or, with opts.Offline, skeletons
+// are written without Claude). Progress is saved in
+// .claudex/create_index_state.json under projectDir until the run succeeds.
+// The doc update lock is held for the whole run, so hooks and "docs update"
+// do not edit the same files meanwhile.
+func (uc *CreateIndexUseCase) ExecuteRecursive(projectDir, root string, opts RecursiveOptions) error {
+	if !opts.Offline && uc.env.Get(rangeupdater.RecursionGuardEnv) == "1" {
+		return fmt.Errorf("recursion guard: already inside Claude hook invocation")
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path: %w", err)
+	}
+	if info, err := uc.fs.Stat(absRoot); err != nil || !info.IsDir() {
+		return fmt.Errorf("directory does not exist: %s", absRoot)
+	}
+
+	claudexDir := filepath.Join(projectDir, paths.ClaudexDir)
+	if err := uc.fs.MkdirAll(claudexDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", claudexDir, err)
+	}
+	l, err := uc.lockSvc.Acquire(filepath.Join(claudexDir, rangeupdater.LockFileName))
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("another docs update is running; try again when it finishes")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to acquire %s: %w", rangeupdater.LockFileName, err)
+	}
+	defer l.Release()
+
+	statePath := filepath.Join(claudexDir, StateFileName)
+	state, err := uc.prepareState(statePath, absRoot, opts)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return nil
+	}
+
+	start := time.Now()
+	supervisor := jobs.New(uc.fs, filepath.Join(claudexDir, rangeupdater.JobLogName), jobs.Options{
+		MaxConcurrent: opts.MaxJobs,
+		Timeout:       opts.JobTimeout,
+	}, uc.executor)
+
+	created := 0
+	for _, level := range groupByDepth(absRoot, uc.pending(state)) {
+		fmt.Printf("Creating %d %s file(s) at depth %d...\n", len(level), uc.names.Primary(), depth(absRoot, level[0]))
+
+		created += uc.runLevel(supervisor, level, state, opts.Offline)
+
+		if err := uc.saveState(statePath, state); err != nil {
+			return err
+		}
+	}
+
+	fmt.Print(formatSummary(state, created, time.Since(start)))
+
+	if len(state.Failed) > 0 {
+		return fmt.Errorf("%d director(ies) failed; run \"claudex docs create --recursive --resume\" to retry them", len(state.Failed))
+	}
+	if err := uc.fs.Remove(statePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", StateFileName, err)
+	}
+	return nil
+}
+
+// prepareState loads or plans the run. It returns nil when there is nothing
+// left to do (including dry runs, which print the plan instead).
+func (uc *CreateIndexUseCase) prepareState(statePath, absRoot string, opts RecursiveOptions) (*runState, error) {
+	previous, err := uc.loadState(statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Resume {
+		if previous == nil {
+			return nil, fmt.Errorf("no interrupted run to resume (%s not found)", StateFileName)
+		}
+		if previous.Root != absRoot {
+			return nil, fmt.Errorf("the interrupted run is for %s, not %s", previous.Root, absRoot)
+		}
+		if opts.DryRun {
+			fmt.Print(FormatPlan(Plan{Dirs: uc.pending(previous)}, absRoot, uc.names.Primary()))
+			return nil, nil
+		}
+		return previous, nil
+	}
+
+	if previous != nil && !opts.Restart && !opts.DryRun {
+		return nil, fmt.Errorf("an interrupted run for %s (started %s) exists; use --resume to continue it or --restart to plan again",
+			previous.Root, previous.StartedAt.Format(time.RFC3339))
+	}
+
+	plan, err := uc.Plan(absRoot, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun || len(plan.Dirs) == 0 {
+		fmt.Print(FormatPlan(plan, absRoot, uc.names.Primary()))
+		return nil, nil
+	}
+
+	now := time.Now()
+	state := &runState{
+		Root:      absRoot,
+		Dirs:      plan.Dirs,
+		Failed:    map[string]string{},
+		StartedAt: now,
+		UpdatedAt: now,
+	}
+	return state, uc.saveState(statePath, state)
+}
+
+// Plan finds the directories under root that need a doc file. Hidden
+// directories and paths ignored by .gitignore or .claudexignore are skipped.
+func (uc *CreateIndexUseCase) Plan(root string, opts RecursiveOptions) (Plan, error) {
+	minFiles := opts.MinFiles
+	if minFiles <= 0 {
+		minFiles = DefaultMinFiles
+	}
+
+	files, err := uc.listFiles(root)
+	if err != nil {
+		return Plan{}, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	// Count code files per directory and record every ancestor directory
+	codeFiles := map[string]int{}
+	dirs := map[string]bool{root: true}
+	for _, rel := range files {
+		dir := filepath.Join(root, filepath.Dir(rel))
+		if isCodeFile(rel) {
+			codeFiles[dir]++
+		}
+		for d := dir; !dirs[d]; d = filepath.Dir(d) {
+			dirs[d] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sorted = append(sorted, dir)
+	}
+	sortDeepestFirst(root, sorted)
+
+	// Walk deepest first so a parent knows which children will have docs
+	var plan Plan
+	hasDoc := map[string]bool{}
+	childDocs := map[string]int{}
+	for _, dir := range sorted {
+		switch {
+		case uc.names.Find(uc.fs, dir) != "":
+			plan.Documented++
+			hasDoc[dir] = true
+		case opts.MaxDepth > 0 && depth(root, dir) > opts.MaxDepth:
+			plan.BelowThreshold++
+		case codeFiles[dir] >= minFiles || childDocs[dir] >= minChildDocs:
+			plan.Dirs = append(plan.Dirs, dir)
+			hasDoc[dir] = true
+		default:
+			plan.BelowThreshold++
+		}
+		if hasDoc[dir] && dir != root {
+			childDocs[filepath.Dir(dir)]++
+		}
+	}
+
+	return plan, nil
+}
+
+// listFiles returns the files under root, relative to root, leaving out
+// hidden directories and paths ignored by .gitignore or .claudexignore
+func (uc *CreateIndexUseCase) listFiles(root string) ([]string, error) {
+	var files []string
+	err := ignore.Walk(uc.fs, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, rel)
+		return nil
+	})
+	return files, err
+}
+
+// indexJob builds the supervised Claude invocation that writes dir's doc file.
+// The prompt is built when the level starts, so it lists child docs created
+// by deeper levels.
+func (uc *CreateIndexUseCase) indexJob(dir string) (jobs.Job, error) {
+	prompt, err := uc.prompt(dir)
+	if err != nil {
+		return jobs.Job{}, err
+	}
+
+	outputPath := filepath.Join(dir, uc.names.Primary())
+	return jobs.Job{
+		Name:    outputPath,
+		Command: "claude",
+		Args:    []string{"-p", fmt.Sprintf("%s\n\nWrite the %s file to: %s", prompt, uc.names.Primary(), outputPath), "--model", "haiku"},
+		Env:     []string{rangeupdater.RecursionGuardEnv + "=1"},
+	}, nil
+}
+
+// runLevel creates the doc files of one depth level and records the outcome
+// in state. It returns how many were created.
+func (uc *CreateIndexUseCase) runLevel(supervisor *jobs.Supervisor, level []string, state *runState, offline bool) int {
+	if offline {
+		created := 0
+		for _, dir := range level {
+			if err := uc.writeSkeleton(dir); err != nil {
+				state.Failed[dir] = err.Error()
+				continue
+			}
+			delete(state.Failed, dir)
+			state.Completed = append(state.Completed, dir)
+			created++
+		}
+		return created
+	}
+
+	var batch []jobs.Job
+	var batchDirs []string
+	for _, dir := range level {
+		job, err := uc.indexJob(dir)
+		if err != nil {
+			state.Failed[dir] = err.Error()
+			continue
+		}
+		batch = append(batch, job)
+		batchDirs = append(batchDirs, dir)
+	}
+
+	created := 0
+	for i, record := range supervisor.Run(batch) {
+		dir := batchDirs[i]
+		switch {
+		case record.Status != jobs.StatusSucceeded:
+			state.Failed[dir] = failureReason(record)
+		case uc.names.Find(uc.fs, dir) == "":
+			state.Failed[dir] = "claude finished without writing " + uc.names.Primary()
+		default:
+			delete(state.Failed, dir)
+			state.Completed = append(state.Completed, dir)
+			created++
+		}
+	}
+	return created
+}
+
+// pending returns the planned directories that are neither completed nor
+// documented by now (e.g. by a job that finished before an interruption)
+func (uc *CreateIndexUseCase) pending(state *runState) []string {
+	completed := map[string]bool{}
+	for _, dir := range state.Completed {
+		completed[dir] = true
+	}
+
+	var dirs []string
+	for _, dir := range state.Dirs {
+		if completed[dir] {
+			continue
+		}
+		if uc.names.Find(uc.fs, dir) != "" {
+			state.Completed = append(state.Completed, dir)
+			delete(state.Failed, dir)
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// loadState reads the state file, returning nil when there is none
+func (uc *CreateIndexUseCase) loadState(statePath string) (*runState, error) {
+	data, err := afero.ReadFile(uc.fs, statePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", StateFileName, err)
+	}
+
+	var state runState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", StateFileName, err)
+	}
+	if state.Failed == nil {
+		state.Failed = map[string]string{}
+	}
+	return &state, nil
+}
+
+// saveState writes the state file
+func (uc *CreateIndexUseCase) saveState(statePath string, state *runState) error {
+	state.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := uc.fs.MkdirAll(filepath.Dir(statePath), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(statePath), err)
+	}
+	if err := afero.WriteFile(uc.fs, statePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", StateFileName, err)
+	}
+	return nil
+}
+
+// FormatPlan renders the directories a run would create doc files for
+func FormatPlan(plan Plan, root, docName string) string {
+	var b bytes.Buffer
+	if len(plan.Dirs) == 0 {
+		fmt.Fprintf(&b, "No directories need a %s\n", docName)
+	} else {
+		fmt.Fprintf(&b, "%d director(ies) need a %s:\n", len(plan.Dirs), docName)
+		for _, dir := range plan.Dirs {
+			fmt.Fprintf(&b, "  %s\n", relDir(root, dir))
+		}
+	}
+	if plan.Documented > 0 || plan.BelowThreshold > 0 {
+		fmt.Fprintf(&b, "Skipped: %d already documented, %d below depth or file thresholds\n", plan.Documented, plan.BelowThreshold)
+	}
+	return b.String()
+}
+
+// formatSummary renders the outcome of a recursive run
+func formatSummary(state *runState, created int, elapsed time.Duration) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "\nCreated %d, failed %d, %d of %d planned done (%s)\n",
+		created, len(state.Failed), len(state.Completed), len(state.Dirs), elapsed.Round(time.Second))
+
+	if len(state.Failed) > 0 {
+		failed := make([]string, 0, len(state.Failed))
+		for dir := range state.Failed {
+			failed = append(failed, dir)
+		}
+		sort.Strings(failed)
+
+		b.WriteString("Failed:\n")
+		for _, dir := range failed {
+			fmt.Fprintf(&b, "  %s: %s\n", relDir(state.Root, dir), state.Failed[dir])
+		}
+	}
+	return b.String()
+}
+
+// failureReason summarizes why a job did not succeed
+func failureReason(record jobs.Record) string {
+	switch {
+	case record.Status == jobs.StatusTimedOut:
+		return "timed out"
+	case record.Error != "":
+		return record.Error
+	default:
+		return fmt.Sprintf("claude exited with code %d", record.ExitCode)
+	}
+}
+
+// groupByDepth splits dirs into levels of equal depth, deepest level first
+func groupByDepth(root string, dirs []string) [][]string {
+	sorted := append([]string(nil), dirs...)
+	sortDeepestFirst(root, sorted)
+
+	var levels [][]string
+	for i, dir := range sorted {
+		if i == 0 || depth(root, dir) != depth(root, sorted[i-1]) {
+			levels = append(levels, nil)
+		}
+		levels[len(levels)-1] = append(levels[len(levels)-1], dir)
+	}
+	return levels
+}
+
+// sortDeepestFirst orders dirs by depth below root, deepest first, then by path
+func sortDeepestFirst(root string, dirs []string) {
+	sort.Slice(dirs, func(i, j int) bool {
+		di, dj := depth(root, dirs[i]), depth(root, dirs[j])
+		if di != dj {
+			return di > dj
+		}
+		return dirs[i] < dirs[j]
+	})
+}
+
+// depth returns how many levels dir is below root (root itself is 0)
+func depth(root, dir string) int {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// relDir renders dir relative to root for display
+func relDir(root, dir string) string {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return dir
+	}
+	if rel == "." {
+		return "./"
+	}
+	return rel + "/"
+}
+
+// isCodeFile reports whether path has one of CodeExtensions
+func isCodeFile(path string) bool {
+	ext := filepath.Ext(path)
+	for _, codeExt := range CodeExtensions {
+		if ext == codeExt {
+			return true
+		}
+	}
+	return false
+}
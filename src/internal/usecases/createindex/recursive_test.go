@@ -0,0 +1,224 @@
+package createindex
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"claudex/internal/doc/rangeupdater"
+	"claudex/internal/services/docfiles"
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/lock"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockCommander is a Commander that runs nothing
+type mockCommander struct{}
+
+func (m *mockCommander) Run(name string, args ...string) ([]byte, error) {
+	return nil, nil
+}
+
+func (m *mockCommander) Start(name string, stdin io.Reader, stdout, stderr io.Writer, args ...string) error {
+	return nil
+}
+
+type mockEnv map[string]string
+
+func (m mockEnv) Get(key string) string { return m[key] }
+func (m mockEnv) Set(key, value string) { m[key] = value }
+
+// fakeExecutor plays Claude: it writes the doc file named by the job, unless
+// the job's directory is listed in fail
+type fakeExecutor struct {
+	fs   afero.Fs
+	fail map[string]bool
+
+	mu   sync.Mutex
+	runs []string
+}
+
+func (f *fakeExecutor) Execute(ctx context.Context, job jobs.Job, started func(pid int)) jobs.Result {
+	started(1)
+	dir := filepath.Dir(job.Name)
+
+	f.mu.Lock()
+	f.runs = append(f.runs, dir)
+	f.mu.Unlock()
+
+	if f.fail[dir] {
+		return jobs.Result{ExitCode: 1, Err: errors.New("exit status 1")}
+	}
+	if err := afero.WriteFile(f.fs, job.Name, []byte("# "+filepath.Base(dir)+"\n"), 0644); err != nil {
+		return jobs.Result{ExitCode: 1, Err: err}
+	}
+	return jobs.Result{}
+}
+
+// newTestTree creates a project with a gitignored directory
+func newTestTree(t *testing.T) (afero.Fs, *mockCommander) {
+	t.Helper()
+	fs := afero.NewMemMapFs()
+	files := map[string]string{
+		"/repo/main.go":                "package main",
+		"/repo/pkg/x/one.go":           "package x",
+		"/repo/pkg/y/two.go":           "package y",
+		"/repo/pkg/z/three.go":         "package z",
+		"/repo/pkg/z/index.md":         "# z",
+		"/repo/docs/notes.txt":         "notes",
+		"/repo/generated/gen.go":       "package generated",
+		"/repo/node_modules/m/i.js":    "module.exports = {}",
+		"/repo/.claudex/config.toml":   "",
+		"/repo/.github/workflows/a.go": "package a",
+		"/repo/.gitignore":             "generated/\n",
+	}
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(fs, path, []byte(content), 0644))
+	}
+	return fs, &mockCommander{}
+}
+
+func relDirs(dirs []string) []string {
+	var rels []string
+	for _, dir := range dirs {
+		rels = append(rels, relDir("/repo", dir))
+	}
+	return rels
+}
+
+func TestPlan_RespectsGitignoreAndOrdersDeepestFirst(t *testing.T) {
+	fs, cmd := newTestTree(t)
+	uc := New(fs, cmd, mockEnv{}, docfiles.Default(), lock.Options{})
+
+	plan, err := uc.Plan("/repo", RecursiveOptions{})
+	require.NoError(t, err)
+
+	// pkg/ has no code of its own but links three documented children
+	assert.Equal(t, []string{"pkg/x/", "pkg/y/", "pkg/", "./"}, relDirs(plan.Dirs))
+	assert.Equal(t, 1, plan.Documented)
+	assert.Equal(t, 1, plan.BelowThreshold, "docs/ has no code files")
+}
+
+func TestPlan_RespectsClaudexIgnore(t *testing.T) {
+	fs, cmd := newTestTree(t)
+	require.NoError(t, afero.WriteFile(fs, "/repo/pkg/.claudexignore", []byte("y/\n"), 0644))
+	uc := New(fs, cmd, mockEnv{}, docfiles.Default(), lock.Options{})
+
+	plan, err := uc.Plan("/repo", RecursiveOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"pkg/x/", "pkg/", "./"}, relDirs(plan.Dirs))
+}
+
+func TestPlan_AppliesThresholds(t *testing.T) {
+	fs, cmd := newTestTree(t)
+	uc := New(fs, cmd, mockEnv{}, docfiles.Default(), lock.Options{})
+
+	plan, err := uc.Plan("/repo", RecursiveOptions{MaxDepth: 1})
+	require.NoError(t, err)
+	// pkg/x and pkg/y are too deep, so pkg/ links only one documented child
+	assert.Equal(t, []string{"./"}, relDirs(plan.Dirs))
+
+	plan, err = uc.Plan("/repo", RecursiveOptions{MinFiles: 2})
+	require.NoError(t, err)
+	assert.Empty(t, plan.Dirs)
+}
+
+func TestExecuteRecursive_CreatesBottomUp(t *testing.T) {
+	fs, cmd := newTestTree(t)
+	uc := New(fs, cmd, mockEnv{}, docfiles.Default(), lock.Options{})
+	executor := &fakeExecutor{fs: fs}
+	uc.executor = executor
+
+	require.NoError(t, uc.ExecuteRecursive("/repo", "/repo", RecursiveOptions{MaxJobs: 2, JobTimeout: time.Minute}))
+
+	require.Len(t, executor.runs, 4)
+	assert.ElementsMatch(t, []string{"pkg/x/", "pkg/y/"}, relDirs(executor.runs[:2]))
+	assert.Equal(t, []string{"pkg/", "./"}, relDirs(executor.runs[2:]))
+
+	for _, dir := range []string{"/repo", "/repo/pkg", "/repo/pkg/x", "/repo/pkg/y"} {
+		exists, _ := afero.Exists(fs, filepath.Join(dir, "index.md"))
+		assert.True(t, exists, dir)
+	}
+	exists, _ := afero.Exists(fs, "/repo/.claudex/"+StateFileName)
+	assert.False(t, exists, "state file should be removed after a successful run")
+}
+
+func TestExecuteRecursive_ResumesFailedDirectories(t *testing.T) {
+	fs, cmd := newTestTree(t)
+	uc := New(fs, cmd, mockEnv{}, docfiles.Default(), lock.Options{})
+	uc.executor = &fakeExecutor{fs: fs, fail: map[string]bool{"/repo/pkg/y": true}}
+
+	err := uc.ExecuteRecursive("/repo", "/repo", RecursiveOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--resume")
+
+	exists, _ := afero.Exists(fs, "/repo/.claudex/"+StateFileName)
+	require.True(t, exists, "state file should be kept after failures")
+
+	// A fresh run must not silently discard the interrupted one
+	err = uc.ExecuteRecursive("/repo", "/repo", RecursiveOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "interrupted run")
+
+	executor := &fakeExecutor{fs: fs}
+	uc.executor = executor
+	require.NoError(t, uc.ExecuteRecursive("/repo", "/repo", RecursiveOptions{Resume: true}))
+
+	assert.Equal(t, []string{"pkg/y/"}, relDirs(executor.runs))
+	exists, _ = afero.Exists(fs, "/repo/.claudex/"+StateFileName)
+	assert.False(t, exists)
+}
+
+func TestExecuteRecursive_RecursionGuard(t *testing.T) {
+	fs, cmd := newTestTree(t)
+	uc := New(fs, cmd, mockEnv{rangeupdater.RecursionGuardEnv: "1"}, docfiles.Default(), lock.Options{})
+
+	err := uc.ExecuteRecursive("/repo", "/repo", RecursiveOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "recursion guard")
+}
+
+func TestExecuteRecursive_RefusesWhileDocsUpdateRuns(t *testing.T) {
+	fs, cmd := newTestTree(t)
+	held, err := lock.New(fs).Acquire("/repo/.claudex/" + rangeupdater.LockFileName)
+	require.NoError(t, err)
+	uc := New(fs, cmd, mockEnv{}, docfiles.Default(), lock.Options{})
+	executor := &fakeExecutor{fs: fs}
+	uc.executor = executor
+
+	err = uc.ExecuteRecursive("/repo", "/repo", RecursiveOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "another docs update is running")
+	assert.Empty(t, executor.runs)
+
+	// Once the update finishes the run proceeds and releases the lock
+	require.NoError(t, held.Release())
+	require.NoError(t, uc.ExecuteRecursive("/repo", "/repo", RecursiveOptions{}))
+	assert.NotEmpty(t, executor.runs)
+	exists, _ := afero.Exists(fs, "/repo/.claudex/"+rangeupdater.LockFileName)
+	assert.False(t, exists)
+}
+
+func TestExecuteRecursive_OfflineWritesSkeletons(t *testing.T) {
+	fs, cmd := newTestTree(t)
+	// Offline generation needs neither Claude nor the recursion guard to be clear
+	uc := New(fs, cmd, mockEnv{rangeupdater.RecursionGuardEnv: "1"}, docfiles.Default(), lock.Options{})
+	executor := &fakeExecutor{fs: fs}
+	uc.executor = executor
+
+	require.NoError(t, uc.ExecuteRecursive("/repo", "/repo", RecursiveOptions{Offline: true}))
+	assert.Empty(t, executor.runs)
+
+	content, err := afero.ReadFile(fs, "/repo/pkg/index.md")
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "- [x/](./x/index.md)")
+	assert.Contains(t, string(content), "- [z/](./z/index.md)")
+}
@@ -0,0 +1,122 @@
+// Package docjobs provides the usecase for inspecting the supervised Claude
+// jobs that update index.md files (`claudex docs jobs`).
+package docjobs
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"claudex/internal/doc/rangeupdater"
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/paths"
+
+	"github.com/spf13/afero"
+)
+
+// UseCase lists recorded doc update jobs
+type UseCase struct {
+	fs afero.Fs
+}
+
+// New creates a new DocJobs usecase
+func New(fs afero.Fs) *UseCase {
+	return &UseCase{fs: fs}
+}
+
+// Execute prints running and failed jobs (all jobs when all is set)
+func (uc *UseCase) Execute(projectDir string, all bool) error {
+	logPath := filepath.Join(projectDir, paths.ClaudexDir, rangeupdater.JobLogName)
+	records, err := jobs.Load(uc.fs, logPath)
+	if err != nil {
+		return fmt.Errorf("failed to read job log: %w", err)
+	}
+
+	fmt.Print(Format(records, projectDir, time.Now(), all))
+	return nil
+}
+
+// Format renders records grouped by status, newest first. Succeeded jobs are
+// only included when all is set.
+func Format(records []jobs.Record, projectDir string, now time.Time, all bool) string {
+	groups := []struct {
+		title    string
+		statuses []jobs.Status
+	}{
+		{"Running", []jobs.Status{jobs.StatusRunning}},
+		{"Failed", []jobs.Status{jobs.StatusFailed, jobs.StatusTimedOut, jobs.StatusOrphaned}},
+	}
+	if all {
+		groups = append(groups, struct {
+			title    string
+			statuses []jobs.Status
+		}{"Succeeded", []jobs.Status{jobs.StatusSucceeded}})
+	}
+
+	var b strings.Builder
+	for _, g := range groups {
+		var lines []string
+		for i := len(records) - 1; i >= 0; i-- {
+			r := records[i]
+			if hasStatus(r.Status, g.statuses) {
+				lines = append(lines, formatRecord(r, projectDir, now))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "%s (%d):\n", g.title, len(lines))
+		for _, line := range lines {
+			b.WriteString(line)
+		}
+	}
+
+	if b.Len() == 0 {
+		if all {
+			return "No doc update jobs recorded\n"
+		}
+		return "No running or failed doc update jobs (use --all to include succeeded jobs)\n"
+	}
+	return b.String()
+}
+
+// formatRecord renders one job as an indented entry
+func formatRecord(r jobs.Record, projectDir string, now time.Time) string {
+	name := r.Name
+	if rel, err := filepath.Rel(projectDir, r.Name); err == nil && !strings.HasPrefix(rel, "..") {
+		name = rel
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "  %s  %s  %-8s  %s", r.ID, r.StartedAt.Format("2006-01-02 15:04:05"),
+		r.Duration(now).Round(time.Second), name)
+	if r.Status == jobs.StatusRunning && r.PID != 0 {
+		fmt.Fprintf(&b, "  (pid %d)", r.PID)
+	}
+	b.WriteString("\n")
+
+	if r.Error != "" {
+		fmt.Fprintf(&b, "      %s: %s\n", r.Status, r.Error)
+	}
+	if line := lastLine(r.Stderr); line != "" {
+		fmt.Fprintf(&b, "      stderr: %s\n", line)
+	}
+	return b.String()
+}
+
+// hasStatus reports whether status is one of statuses
+func hasStatus(status jobs.Status, statuses []jobs.Status) bool {
+	for _, s := range statuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// lastLine returns the last non-empty line of output
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
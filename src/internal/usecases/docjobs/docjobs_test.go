@@ -0,0 +1,50 @@
+package docjobs
+
+import (
+	"testing"
+	"time"
+
+	"claudex/internal/services/jobs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat_GroupsRunningAndFailed(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	records := []jobs.Record{
+		{ID: "1", Name: "/repo/a/index.md", Status: jobs.StatusSucceeded, StartedAt: now.Add(-time.Hour), FinishedAt: now.Add(-59 * time.Minute)},
+		{ID: "2", Name: "/repo/b/index.md", Status: jobs.StatusFailed, Error: "exit status 1", Stderr: "first\nrate limited\n", StartedAt: now.Add(-30 * time.Minute), FinishedAt: now.Add(-29 * time.Minute)},
+		{ID: "3", Name: "/repo/c/index.md", Status: jobs.StatusRunning, PID: 99, StartedAt: now.Add(-2 * time.Minute)},
+	}
+
+	out := Format(records, "/repo", now, false)
+
+	assert.Contains(t, out, "Running (1):")
+	assert.Contains(t, out, "c/index.md  (pid 99)")
+	assert.Contains(t, out, "Failed (1):")
+	assert.Contains(t, out, "failed: exit status 1")
+	assert.Contains(t, out, "stderr: rate limited")
+	assert.NotContains(t, out, "a/index.md")
+
+	all := Format(records, "/repo", now, true)
+	assert.Contains(t, all, "Succeeded (1):")
+	assert.Contains(t, all, "a/index.md")
+}
+
+func TestFormat_OrphanedJobsAreFailures(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	records := []jobs.Record{
+		{ID: "1", Name: "/repo/a/index.md", Status: jobs.StatusOrphaned, PID: 99, Error: "claudex process 42 exited before the job finished", StartedAt: now.Add(-time.Hour)},
+	}
+
+	out := Format(records, "/repo", now, false)
+
+	assert.NotContains(t, out, "Running")
+	assert.Contains(t, out, "Failed (1):")
+	assert.Contains(t, out, "orphaned: claudex process 42 exited before the job finished")
+}
+
+func TestFormat_NoJobs(t *testing.T) {
+	out := Format(nil, "/repo", time.Now(), false)
+	assert.Contains(t, out, "No running or failed doc update jobs")
+}
@@ -0,0 +1,450 @@
+// Package doclint provides the usecase for checking the documentation tree
+// claudex maintains (`claudex docs lint`): broken relative links, links to
+// missing subdirectory indexes, references to files that no longer exist,
+// code directories without a doc file, and doc files no parent links to.
+package doclint
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"claudex/internal/services/docfiles"
+	"claudex/internal/services/ignore"
+	"claudex/internal/usecases/createindex"
+
+	"github.com/spf13/afero"
+)
+
+// Check identifies the kind of issue found
+type Check string
+
+const (
+	// CheckBrokenLink is a relative link whose target does not exist
+	CheckBrokenLink Check = "broken-link"
+
+	// CheckMissingIndex is a link to a subdirectory doc file that does not exist
+	CheckMissingIndex Check = "missing-index"
+
+	// CheckMissingFile is a `file` reference to a file that no longer exists
+	CheckMissingFile Check = "missing-file"
+
+	// CheckUndocumentedDir is a directory with code files but no doc file
+	CheckUndocumentedDir Check = "undocumented-dir"
+
+	// CheckOrphan is a doc file not linked from the nearest parent doc file
+	CheckOrphan Check = "orphan"
+)
+
+// Severity ranks issues; errors fail the lint, warnings only with Strict
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// severities maps each check to its severity: wrong links are errors, gaps in
+// coverage are warnings
+var severities = map[Check]Severity{
+	CheckBrokenLink:      SeverityError,
+	CheckMissingIndex:    SeverityError,
+	CheckMissingFile:     SeverityError,
+	CheckUndocumentedDir: SeverityWarning,
+	CheckOrphan:          SeverityWarning,
+}
+
+// Issue is one lint finding
+type Issue struct {
+	Check    Check    `json:"check"`
+	Severity Severity `json:"severity"`
+
+	// Path is the doc file (or directory) relative to the lint root
+	Path string `json:"path"`
+
+	// Line is the 1-based line in Path, or 0 when not applicable
+	Line int `json:"line,omitempty"`
+
+	// Target is the link or reference that failed, if any
+	Target string `json:"target,omitempty"`
+
+	Message string `json:"message"`
+}
+
+// Report is the result of a lint run
+type Report struct {
+	Root     string  `json:"root"`
+	DocFiles int     `json:"doc_files"`
+	Issues   []Issue `json:"issues"`
+}
+
+// Count returns the number of issues with the given severity
+func (r Report) Count(severity Severity) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Options configures Execute
+type Options struct {
+	// JSON prints the report as JSON instead of text
+	JSON bool
+
+	// Strict fails on warnings as well as errors
+	Strict bool
+}
+
+var (
+	// inlineLink matches [text](target) and ![alt](target); the target may be
+	// followed by a quoted title
+	inlineLink = regexp.MustCompile(`!?\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+
+	// referenceLink matches a reference definition: [label]: target
+	referenceLink = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s*<?(\S+?)>?(?:\s|$)`)
+
+	// codeSpan matches inline code, used for file references like `app.go`
+	codeSpan = regexp.MustCompile("`([^`\\s]+)`")
+
+	// fileLike matches code span contents that look like a relative path
+	fileLike = regexp.MustCompile(`^[\w.-]+(?:/[\w.-]+)*$`)
+)
+
+// UseCase lints the documentation tree
+type UseCase struct {
+	fs    afero.Fs
+	names docfiles.Names
+}
+
+// New creates a new DocLint usecase
+func New(fs afero.Fs, names docfiles.Names) *UseCase {
+	return &UseCase{fs: fs, names: docfiles.New(names)}
+}
+
+// Execute lints the tree under root, prints the report to out and returns an
+// error when it contains errors (or warnings, with opts.Strict)
+func (uc *UseCase) Execute(root string, opts Options, out io.Writer) error {
+	report, err := uc.Lint(root)
+	if err != nil {
+		return err
+	}
+
+	if opts.JSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprint(out, Format(report))
+	}
+
+	errors, warnings := report.Count(SeverityError), report.Count(SeverityWarning)
+	if errors > 0 || (opts.Strict && warnings > 0) {
+		return fmt.Errorf("docs lint failed: %d error(s), %d warning(s)", errors, warnings)
+	}
+	return nil
+}
+
+// Lint checks the documentation tree under root
+func (uc *UseCase) Lint(root string) (Report, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to resolve path: %w", err)
+	}
+	if info, err := uc.fs.Stat(absRoot); err != nil || !info.IsDir() {
+		return Report{}, fmt.Errorf("not a directory: %s", absRoot)
+	}
+
+	docs, codeDirs, byName, err := uc.scan(absRoot)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to scan %s: %w", absRoot, err)
+	}
+
+	l := &linter{uc: uc, root: absRoot, byName: byName, links: make(map[string]map[string]bool)}
+	for _, doc := range docs {
+		if err := l.checkDoc(doc); err != nil {
+			return Report{}, err
+		}
+	}
+	for _, dir := range codeDirs {
+		l.checkCoverage(dir)
+	}
+	for _, doc := range docs {
+		l.checkOrphan(doc)
+	}
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		a, b := l.issues[i], l.issues[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+	return Report{Root: absRoot, DocFiles: len(docs), Issues: l.issues}, nil
+}
+
+// scan collects the doc files, the directories that directly contain code, and
+// the paths of all files by base name. Hidden directories and paths ignored by
+// .gitignore or .claudexignore are skipped.
+func (uc *UseCase) scan(root string) ([]string, []string, map[string][]string, error) {
+	var docs, codeDirs []string
+	seenCode := make(map[string]bool)
+	byName := make(map[string][]string)
+	err := ignore.Walk(uc.fs, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := info.Name()
+		if info.IsDir() {
+			if path != root && strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		byName[name] = append(byName[name], path)
+		if uc.names.Match(name) {
+			docs = append(docs, path)
+		}
+		dir := filepath.Dir(path)
+		if !seenCode[dir] && isCode(name) {
+			seenCode[dir] = true
+			codeDirs = append(codeDirs, dir)
+		}
+		return nil
+	})
+	return docs, codeDirs, byName, err
+}
+
+// isCode reports whether a file name has one of createindex.CodeExtensions
+func isCode(name string) bool {
+	ext := filepath.Ext(name)
+	for _, codeExt := range createindex.CodeExtensions {
+		if ext == codeExt {
+			return true
+		}
+	}
+	return false
+}
+
+// linter accumulates issues and the resolved links of every doc file
+type linter struct {
+	uc     *UseCase
+	root   string
+	byName map[string][]string
+	issues []Issue
+	// links maps a doc file to the absolute paths it links to
+	links map[string]map[string]bool
+}
+
+// checkDoc verifies the links and file references of one doc file
+func (l *linter) checkDoc(doc string) error {
+	file, err := l.uc.fs.Open(doc)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", doc, err)
+	}
+	defer file.Close()
+
+	dir := filepath.Dir(doc)
+	l.links[doc] = make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	inFence := false
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		var targets []string
+		for _, m := range inlineLink.FindAllStringSubmatch(line, -1) {
+			targets = append(targets, m[1])
+		}
+		if m := referenceLink.FindStringSubmatch(line); m != nil {
+			targets = append(targets, m[1])
+		}
+		for _, target := range targets {
+			l.checkLink(doc, dir, lineNo, target)
+		}
+
+		// Code spans are checked after removing links so `[x](y)` is not read twice
+		for _, m := range codeSpan.FindAllStringSubmatch(inlineLink.ReplaceAllString(line, ""), -1) {
+			l.checkReference(doc, dir, lineNo, m[1])
+		}
+	}
+	return scanner.Err()
+}
+
+// checkLink verifies a relative link target and records it for orphan detection
+func (l *linter) checkLink(doc, dir string, line int, target string) {
+	path, ok := localTarget(target)
+	if !ok {
+		return
+	}
+	abs := filepath.Join(dir, filepath.FromSlash(path))
+	if strings.HasPrefix(path, "/") {
+		abs = filepath.Join(l.root, filepath.FromSlash(path))
+	}
+	l.links[doc][abs] = true
+
+	if exists, _ := afero.Exists(l.uc.fs, abs); exists {
+		// A directory link counts as linking to its doc file
+		if info, err := l.uc.fs.Stat(abs); err == nil && info.IsDir() {
+			if index := l.uc.names.Find(l.uc.fs, abs); index != "" {
+				l.links[doc][index] = true
+			}
+		}
+		return
+	}
+
+	if l.uc.names.Match(filepath.Base(abs)) {
+		if isDir, _ := afero.DirExists(l.uc.fs, filepath.Dir(abs)); isDir {
+			l.add(CheckMissingIndex, doc, line, target, fmt.Sprintf("%s/ has no %s", l.rel(filepath.Dir(abs)), filepath.Base(abs)))
+			return
+		}
+	}
+	l.add(CheckBrokenLink, doc, line, target, fmt.Sprintf("link target %s does not exist", l.rel(abs)))
+}
+
+// checkReference verifies a `file` reference that looks like a path to a code file
+func (l *linter) checkReference(doc, dir string, line int, ref string) {
+	if !fileLike.MatchString(ref) || strings.HasPrefix(ref, ".") {
+		return
+	}
+	// Only code files are checked; other names are often runtime artifacts
+	if !isCode(ref) {
+		return
+	}
+
+	// References are usually relative to the doc's directory, sometimes to the root
+	for _, base := range []string{dir, l.root} {
+		if exists, _ := afero.Exists(l.uc.fs, filepath.Join(base, filepath.FromSlash(ref))); exists {
+			return
+		}
+	}
+
+	// A bare file name may describe a file in a subdirectory the doc also covers
+	if !strings.Contains(ref, "/") {
+		for _, path := range l.byName[ref] {
+			if strings.HasPrefix(path, dir+string(filepath.Separator)) {
+				return
+			}
+		}
+	}
+	l.add(CheckMissingFile, doc, line, ref, fmt.Sprintf("referenced file %s does not exist", ref))
+}
+
+// checkCoverage reports a code directory without a doc file
+func (l *linter) checkCoverage(dir string) {
+	if l.uc.names.Find(l.uc.fs, dir) != "" {
+		return
+	}
+	l.issues = append(l.issues, Issue{
+		Check:    CheckUndocumentedDir,
+		Severity: severities[CheckUndocumentedDir],
+		Path:     l.rel(dir) + "/",
+		Message:  fmt.Sprintf("directory has code files but no %s", l.uc.names.Primary()),
+	})
+}
+
+// checkOrphan reports a doc file that the nearest doc file above it does not
+// link to. Doc files with no doc file above them (within root) are entry points.
+func (l *linter) checkOrphan(doc string) {
+	dir := filepath.Dir(doc)
+	for dir != l.root {
+		dir = filepath.Dir(dir)
+		parent := l.uc.names.Find(l.uc.fs, dir)
+		if parent == "" {
+			if dir == l.root || !strings.HasPrefix(dir, l.root) {
+				return
+			}
+			continue
+		}
+		if l.links[parent][doc] || l.links[parent][filepath.Dir(doc)] {
+			return
+		}
+		l.issues = append(l.issues, Issue{
+			Check:    CheckOrphan,
+			Severity: severities[CheckOrphan],
+			Path:     l.rel(doc),
+			Message:  fmt.Sprintf("not linked from %s", l.rel(parent)),
+		})
+		return
+	}
+}
+
+// add records an issue found on a line of doc
+func (l *linter) add(check Check, doc string, line int, target, message string) {
+	l.issues = append(l.issues, Issue{
+		Check:    check,
+		Severity: severities[check],
+		Path:     l.rel(doc),
+		Line:     line,
+		Target:   target,
+		Message:  message,
+	})
+}
+
+// rel returns path relative to the lint root
+func (l *linter) rel(path string) string {
+	if rel, err := filepath.Rel(l.root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// localTarget strips fragments and queries from a link target and reports
+// whether it refers to a local file
+func localTarget(target string) (string, bool) {
+	if target == "" || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "//") {
+		return "", false
+	}
+	if u, err := url.Parse(target); err != nil || u.Scheme != "" {
+		return "", false
+	}
+	if i := strings.IndexAny(target, "#?"); i >= 0 {
+		target = target[:i]
+	}
+	if unescaped, err := url.PathUnescape(target); err == nil {
+		target = unescaped
+	}
+	return target, target != ""
+}
+
+// Format renders a report for terminals, grouped by file
+func Format(r Report) string {
+	var b strings.Builder
+	current := ""
+	for _, issue := range r.Issues {
+		if issue.Path != current {
+			current = issue.Path
+			fmt.Fprintf(&b, "%s\n", current)
+		}
+		location := ""
+		if issue.Line > 0 {
+			location = fmt.Sprintf("%d: ", issue.Line)
+		}
+		fmt.Fprintf(&b, "  %s%s [%s] %s\n", location, issue.Severity, issue.Check, issue.Message)
+	}
+
+	errors, warnings := r.Count(SeverityError), r.Count(SeverityWarning)
+	if len(r.Issues) > 0 {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "%d doc file(s) checked: %d error(s), %d warning(s)\n", r.DocFiles, errors, warnings)
+	return b.String()
+}
@@ -0,0 +1,107 @@
+package doclint
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"claudex/internal/services/docfiles"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTree builds a small project with one issue of each kind
+func newTree(t *testing.T) afero.Fs {
+	t.Helper()
+	fs := afero.NewMemMapFs()
+	files := map[string]string{
+		"/repo/index.md": "# Repo\n\n- [api/](./api/index.md)\n- [web/](web/index.md)\n- [guide](docs/guide.md#setup)\n- [site](https://example.com)\n\n```\n[ignored](nowhere.md)\n```\n",
+		"/repo/main.go":  "package main",
+		"/repo/api/index.md": "# API\n\n- `server.go` - HTTP server\n- `handlers.go` - removed long ago\n- `routes/routes.go` - routing\n" +
+			"- [anchor](#top)\n",
+		"/repo/api/server.go":        "package api",
+		"/repo/api/routes/routes.go": "package routes",
+		"/repo/web/app.ts":           "export {}",
+		"/repo/lib/index.md":         "# Lib\n",
+		"/repo/lib/lib.go":           "package lib",
+		"/repo/node_modules/x/a.js":  "",
+	}
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(fs, path, []byte(content), 0644))
+	}
+	return fs
+}
+
+func TestLint_ReportsEachCheck(t *testing.T) {
+	report, err := New(newTree(t), nil).Lint("/repo")
+	require.NoError(t, err)
+
+	type found struct {
+		check Check
+		path  string
+		line  int
+	}
+	var got []found
+	for _, issue := range report.Issues {
+		got = append(got, found{issue.Check, issue.Path, issue.Line})
+	}
+
+	assert.ElementsMatch(t, []found{
+		{CheckMissingFile, "api/index.md", 4},
+		{CheckUndocumentedDir, "api/routes/", 0},
+		{CheckBrokenLink, "index.md", 5},
+		{CheckMissingIndex, "index.md", 4},
+		{CheckOrphan, "lib/index.md", 0},
+		{CheckUndocumentedDir, "web/", 0},
+	}, got)
+	assert.Equal(t, 3, report.DocFiles)
+	assert.Equal(t, 3, report.Count(SeverityError))
+	assert.Equal(t, 3, report.Count(SeverityWarning))
+}
+
+func TestLint_ConfiguredNames(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/repo/README.md", []byte("# Repo\n\n- [pkg](pkg/)\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/repo/pkg/README.md", []byte("# Pkg\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/repo/pkg/pkg.go", []byte("package pkg"), 0644))
+
+	report, err := New(fs, docfiles.New([]string{"README.md"})).Lint("/repo")
+	require.NoError(t, err)
+
+	assert.Empty(t, report.Issues, "a directory link counts as linking its doc file")
+	assert.Equal(t, 2, report.DocFiles)
+}
+
+func TestExecute_JSONAndExitStatus(t *testing.T) {
+	uc := New(newTree(t), nil)
+
+	var out bytes.Buffer
+	err := uc.Execute("/repo", Options{JSON: true}, &out)
+	require.Error(t, err, "errors should fail the lint")
+
+	var report Report
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, "/repo", report.Root)
+	assert.Len(t, report.Issues, 6)
+
+	// Warnings alone only fail with Strict
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/repo/pkg/pkg.go", []byte("package pkg"), 0644))
+	out.Reset()
+	assert.NoError(t, New(fs, nil).Execute("/repo", Options{}, &out))
+	assert.Contains(t, out.String(), "0 doc file(s) checked: 0 error(s), 1 warning(s)")
+	assert.Error(t, New(fs, nil).Execute("/repo", Options{Strict: true}, &out))
+}
+
+func TestFormat_GroupsByFile(t *testing.T) {
+	out := Format(Report{DocFiles: 2, Issues: []Issue{
+		{Check: CheckBrokenLink, Severity: SeverityError, Path: "index.md", Line: 3, Message: "link target x.md does not exist"},
+		{Check: CheckOrphan, Severity: SeverityWarning, Path: "lib/index.md", Message: "not linked from index.md"},
+	}})
+
+	assert.Equal(t, "index.md\n  3: error [broken-link] link target x.md does not exist\n"+
+		"lib/index.md\n  warning [orphan] not linked from index.md\n"+
+		"\n2 doc file(s) checked: 1 error(s), 1 warning(s)\n", out)
+}
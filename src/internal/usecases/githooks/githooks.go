@@ -0,0 +1,160 @@
+// Package githooks provides the usecase behind `claudex hooks git
+// install|uninstall|status`: managing the claudex blocks in the repository's
+// git hooks, wherever git actually runs them from.
+package githooks
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"claudex/internal/services/commander"
+	"claudex/internal/services/hooksetup"
+
+	"github.com/spf13/afero"
+)
+
+// DefaultInstallHooks are installed when no hooks are named
+var DefaultInstallHooks = []string{hooksetup.PostCommit}
+
+// UseCase installs, removes and reports claudex git hooks
+type UseCase struct {
+	hookSvc    hooksetup.Service
+	projectDir string
+}
+
+// New creates a new GitHooks usecase
+func New(fs afero.Fs, projectDir string, cmdr commander.Commander) *UseCase {
+	return &UseCase{
+		hookSvc:    hooksetup.New(fs, projectDir, cmdr),
+		projectDir: projectDir,
+	}
+}
+
+// Install writes (or repairs) the claudex block of each named hook; without
+// names only the post-commit hook is installed
+func (uc *UseCase) Install(names []string) error {
+	names, err := uc.check(names, DefaultInstallHooks)
+	if err != nil {
+		return err
+	}
+
+	dir := uc.rel(uc.hookSvc.HooksDir())
+	for _, name := range names {
+		if err := uc.hookSvc.InstallHook(name); err != nil {
+			return fmt.Errorf("failed to install %s hook: %w", name, err)
+		}
+		fmt.Printf("✓ Installed %s hook in %s\n", name, dir)
+	}
+
+	switch uc.hookSvc.Manager() {
+	case hooksetup.ManagerHusky:
+		fmt.Printf("Hooks are managed by husky: commit the scripts in %s to share them\n", dir)
+	case hooksetup.ManagerLefthook:
+		fmt.Print(FormatLefthookHint(names))
+	}
+	return nil
+}
+
+// Uninstall removes the claudex block from each named hook, leaving the rest
+// of the hook untouched; without names every supported hook is cleaned
+func (uc *UseCase) Uninstall(names []string) error {
+	names, err := uc.check(names, hooksetup.HookNames)
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	for _, name := range names {
+		ok, err := uc.hookSvc.UninstallHook(name)
+		if err != nil {
+			return fmt.Errorf("failed to uninstall %s hook: %w", name, err)
+		}
+		if ok {
+			removed++
+			fmt.Printf("✓ Removed claudex from %s hook\n", name)
+		}
+	}
+	if removed == 0 {
+		fmt.Println("No claudex hooks installed")
+	}
+	return nil
+}
+
+// Status prints the hooks directory, the hook manager and the state of every
+// supported hook
+func (uc *UseCase) Status() error {
+	if !uc.hookSvc.IsGitRepo() {
+		return fmt.Errorf("%s is not a git repository", uc.projectDir)
+	}
+
+	var statuses []hooksetup.HookStatus
+	for _, name := range hooksetup.HookNames {
+		status := uc.hookSvc.HookStatus(name)
+		status.Path = uc.rel(status.Path)
+		statuses = append(statuses, status)
+	}
+	fmt.Print(FormatStatus(uc.rel(uc.hookSvc.HooksDir()), uc.hookSvc.Manager(), statuses))
+	return nil
+}
+
+// FormatStatus renders the hook states, one line per hook
+func FormatStatus(hooksDir, manager string, statuses []hooksetup.HookStatus) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hooks directory: %s\n", hooksDir)
+	if manager != "" {
+		fmt.Fprintf(&b, "Hook manager: %s\n", manager)
+	}
+	for _, s := range statuses {
+		state := "not installed"
+		switch {
+		case s.Installed && !s.Executable:
+			state = "installed, not executable (run install to repair)"
+		case s.Installed:
+			state = "installed"
+		case s.Exists:
+			state = "not installed (hook has other commands)"
+		}
+		fmt.Fprintf(&b, "  %-14s %s\n", s.Name, state)
+	}
+	return b.String()
+}
+
+// FormatLefthookHint explains that lefthook regenerates the hooks and shows
+// the equivalent lefthook.yml entries
+func FormatLefthookHint(names []string) string {
+	var b strings.Builder
+	b.WriteString("Hooks are managed by lefthook, and `lefthook install` overwrites them.\n")
+	b.WriteString("To keep claudex, add to lefthook.yml instead:\n")
+	// lefthook passes the git hook arguments as {1}, {2}... templates
+	args := strings.NewReplacer("$1", "{1}", "$2", "{2}", "$3", "{3}")
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %s:\n    commands:\n      claudex-docs:\n        run: %s\n", name, args.Replace(hooksetup.Command(name)))
+	}
+	return b.String()
+}
+
+// check validates hook names, falling back to defaults when none are given
+func (uc *UseCase) check(names, defaults []string) ([]string, error) {
+	if !uc.hookSvc.IsGitRepo() {
+		return nil, fmt.Errorf("%s is not a git repository", uc.projectDir)
+	}
+	if len(names) == 0 {
+		return defaults, nil
+	}
+	for _, name := range names {
+		if !hooksetup.IsSupported(name) {
+			return nil, fmt.Errorf("unsupported hook %q (supported: %s)", name, strings.Join(hooksetup.HookNames, ", "))
+		}
+	}
+	return names, nil
+}
+
+// rel shortens paths inside the project for display
+func (uc *UseCase) rel(path string) string {
+	rel, err := filepath.Rel(uc.projectDir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
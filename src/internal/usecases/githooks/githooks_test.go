@@ -0,0 +1,77 @@
+package githooks
+
+import (
+	"io"
+	"testing"
+
+	"claudex/internal/services/hooksetup"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hooksPathCommander answers `git rev-parse --git-path hooks`
+type hooksPathCommander struct {
+	hooksPath string
+}
+
+func (m *hooksPathCommander) Run(name string, args ...string) ([]byte, error) {
+	return []byte(m.hooksPath + "\n"), nil
+}
+
+func (m *hooksPathCommander) Start(name string, stdin io.Reader, stdout, stderr io.Writer, args ...string) error {
+	return nil
+}
+
+func newRepo(t *testing.T, hooksPath string) (afero.Fs, *UseCase) {
+	t.Helper()
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/repo/.git", 0755))
+	return fs, New(fs, "/repo", &hooksPathCommander{hooksPath: hooksPath})
+}
+
+func TestInstallAndUninstall(t *testing.T) {
+	fs, uc := newRepo(t, ".githooks")
+	require.NoError(t, afero.WriteFile(fs, "/repo/.githooks/pre-push", []byte("#!/bin/sh\nmake test\n"), 0755))
+
+	require.NoError(t, uc.Install([]string{hooksetup.PostCommit, hooksetup.PrePush}))
+	assert.True(t, uc.hookSvc.IsHookInstalled(hooksetup.PostCommit))
+	assert.True(t, uc.hookSvc.IsHookInstalled(hooksetup.PrePush))
+
+	require.NoError(t, uc.Uninstall(nil))
+	exists, _ := afero.Exists(fs, "/repo/.githooks/post-commit")
+	assert.False(t, exists, "hook created by claudex should be deleted")
+	data, err := afero.ReadFile(fs, "/repo/.githooks/pre-push")
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\nmake test\n", string(data))
+}
+
+func TestInstall_Validates(t *testing.T) {
+	_, uc := newRepo(t, "")
+	assert.Error(t, uc.Install([]string{"commit-msg"}))
+
+	notRepo := New(afero.NewMemMapFs(), "/elsewhere", &hooksPathCommander{})
+	assert.Error(t, notRepo.Install(nil))
+	assert.Error(t, notRepo.Status())
+}
+
+func TestFormatStatus(t *testing.T) {
+	out := FormatStatus(".husky", hooksetup.ManagerHusky, []hooksetup.HookStatus{
+		{Name: "post-commit", Exists: true, Executable: true, Installed: true},
+		{Name: "pre-commit", Exists: true, Executable: true},
+		{Name: "post-merge", Exists: true, Installed: true},
+		{Name: "pre-push"},
+	})
+
+	assert.Contains(t, out, "Hooks directory: .husky\nHook manager: husky\n")
+	assert.Contains(t, out, "  post-commit    installed\n")
+	assert.Contains(t, out, "  pre-commit     not installed (hook has other commands)\n")
+	assert.Contains(t, out, "  post-merge     installed, not executable")
+	assert.Contains(t, out, "  pre-push       not installed\n")
+}
+
+func TestFormatLefthookHint(t *testing.T) {
+	out := FormatLefthookHint([]string{hooksetup.PostCheckout})
+	assert.Contains(t, out, "  post-checkout:\n    commands:\n      claudex-docs:\n        run: [ \"{3}\" = \"1\" ] && claudex --update-docs &\n")
+}
@@ -0,0 +1,190 @@
+// Package locks provides the usecase for inspecting and clearing the lock
+// files claudex keeps under .claudex (`claudex locks list|clear`).
+package locks
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"claudex/internal/services/activity"
+	"claudex/internal/services/lock"
+	"claudex/internal/services/paths"
+
+	"github.com/spf13/afero"
+)
+
+// UseCase lists and clears lock files
+type UseCase struct {
+	fs      afero.Fs
+	lockSvc lock.LockService
+
+	// sessionLockSvc inspects session locks, which last as long as their
+	// Claude run and never expire by age (see services/activity)
+	sessionLockSvc lock.LockService
+}
+
+// New creates a new Locks usecase
+func New(fs afero.Fs, lockOpts lock.Options) *UseCase {
+	return &UseCase{
+		fs:             fs,
+		lockSvc:        lock.NewWithOptions(fs, lockOpts),
+		sessionLockSvc: lock.NewWithOptions(fs, lock.Options{TTL: -1, Flock: lockOpts.Flock}),
+	}
+}
+
+// service returns the lock service that judges the lock at path
+func (uc *UseCase) service(path string) lock.LockService {
+	if filepath.Base(path) == activity.LockFileName {
+		return uc.sessionLockSvc
+	}
+	return uc.lockSvc
+}
+
+// List prints every lock under the project's .claudex directory
+func (uc *UseCase) List(projectDir string) error {
+	infos, err := uc.inspectAll(projectDir)
+	if err != nil {
+		return err
+	}
+	fmt.Print(Format(infos, projectDir, time.Now()))
+	return nil
+}
+
+// Clear removes stale locks. With names, only those locks are considered
+// (paths relative to .claudex or the project); force also removes live locks.
+func (uc *UseCase) Clear(projectDir string, names []string, force bool) error {
+	var lockPaths []string
+	if len(names) == 0 {
+		infos, err := uc.inspectAll(projectDir)
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			lockPaths = append(lockPaths, info.Path)
+		}
+	} else {
+		for _, name := range names {
+			lockPaths = append(lockPaths, uc.resolve(projectDir, name))
+		}
+	}
+
+	var failed []string
+	cleared := 0
+	for _, path := range lockPaths {
+		rel := relPath(projectDir, path)
+		info, err := uc.service(path).Inspect(path)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", rel, err))
+			continue
+		}
+		if info == nil {
+			if len(names) > 0 {
+				failed = append(failed, fmt.Sprintf("%s: no such lock", rel))
+			}
+			continue
+		}
+		if !info.Stale && !force {
+			if len(names) > 0 {
+				failed = append(failed, fmt.Sprintf("%s: held by running process %d (use --force to remove it anyway)", rel, info.PID))
+			}
+			continue
+		}
+		if _, err := uc.service(path).Clear(path, force); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", rel, err))
+			continue
+		}
+		cleared++
+		fmt.Printf("Removed %s (%s)\n", rel, describe(*info))
+	}
+
+	if cleared == 0 && len(failed) == 0 {
+		fmt.Println("No stale locks")
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to clear locks:\n  %s", strings.Join(failed, "\n  "))
+	}
+	return nil
+}
+
+// inspectAll finds and inspects all *.lock files under .claudex, sorted by path
+func (uc *UseCase) inspectAll(projectDir string) ([]lock.Info, error) {
+	root := filepath.Join(projectDir, paths.ClaudexDir)
+	if exists, err := afero.DirExists(uc.fs, root); err != nil || !exists {
+		return nil, err
+	}
+
+	var infos []lock.Info
+	err := afero.Walk(uc.fs, root, func(path string, fi fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() || filepath.Ext(path) != ".lock" {
+			return nil
+		}
+		info, err := uc.service(path).Inspect(path)
+		if err != nil {
+			return fmt.Errorf("failed to inspect %s: %w", path, err)
+		}
+		if info != nil {
+			infos = append(infos, *info)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Path < infos[j].Path })
+	return infos, nil
+}
+
+// resolve maps a lock name to a path: absolute paths are kept, names are
+// tried relative to .claudex, then to the project directory
+func (uc *UseCase) resolve(projectDir, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	inClaudex := filepath.Join(projectDir, paths.ClaudexDir, name)
+	if exists, _ := afero.Exists(uc.fs, inClaudex); exists {
+		return inClaudex
+	}
+	return filepath.Join(projectDir, name)
+}
+
+// Format renders one line per lock with its holder, age and state
+func Format(infos []lock.Info, projectDir string, now time.Time) string {
+	if len(infos) == 0 {
+		return "No locks held\n"
+	}
+
+	var b strings.Builder
+	for _, info := range infos {
+		state := "live"
+		if info.Stale {
+			state = "stale: " + info.Reason
+		}
+		fmt.Fprintf(&b, "%s  %s  age %s  %s\n", relPath(projectDir, info.Path), describe(info),
+			now.Sub(info.CreatedAt).Round(time.Second), state)
+	}
+	return b.String()
+}
+
+// describe identifies the holder of a lock
+func describe(info lock.Info) string {
+	if info.Host == "" {
+		return fmt.Sprintf("pid %d", info.PID)
+	}
+	return fmt.Sprintf("pid %d@%s", info.PID, info.Host)
+}
+
+// relPath returns path relative to projectDir when it lies inside it
+func relPath(projectDir, path string) string {
+	if rel, err := filepath.Rel(projectDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
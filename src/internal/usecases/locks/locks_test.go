@@ -0,0 +1,76 @@
+package locks
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"claudex/internal/services/lock"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClear_RemovesOnlyStaleLocks(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	uc := New(fs, lock.Options{})
+
+	live, err := lock.New(fs).Acquire("/repo/.claudex/doc_update.lock")
+	require.NoError(t, err)
+	defer live.Release()
+	require.NoError(t, afero.WriteFile(fs, "/repo/.claudex/sessions/s1/other.lock", []byte("0\n"), 0644))
+
+	infos, err := uc.inspectAll("/repo")
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+
+	require.NoError(t, uc.Clear("/repo", nil, false))
+
+	exists, _ := afero.Exists(fs, "/repo/.claudex/doc_update.lock")
+	assert.True(t, exists, "live lock should be kept")
+	exists, _ = afero.Exists(fs, "/repo/.claudex/sessions/s1/other.lock")
+	assert.False(t, exists, "stale lock should be removed")
+
+	// Naming a live lock without force is an error
+	assert.Error(t, uc.Clear("/repo", []string{"doc_update.lock"}, false))
+	require.NoError(t, uc.Clear("/repo", []string{"doc_update.lock"}, true))
+	exists, _ = afero.Exists(fs, "/repo/.claudex/doc_update.lock")
+	assert.False(t, exists, "forced clear should remove the live lock")
+}
+
+func TestClear_KeepsLongRunningSessionLocks(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	uc := New(fs, lock.Options{})
+
+	// Both held by this live process for longer than the default TTL
+	old := fmt.Sprintf("%d\ncreated=%s\n", os.Getpid(), time.Now().Add(-3*time.Hour).Format(time.RFC3339Nano))
+	require.NoError(t, afero.WriteFile(fs, "/repo/.claudex/doc_update.lock", []byte(old), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/repo/.claudex/sessions/s1/session.lock", []byte(old), 0644))
+
+	infos, err := uc.inspectAll("/repo")
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+	assert.True(t, infos[0].Stale, "doc update lock expires by age")
+	assert.False(t, infos[1].Stale, "session lock lasts as long as its process")
+
+	require.NoError(t, uc.Clear("/repo", nil, false))
+	exists, _ := afero.Exists(fs, "/repo/.claudex/sessions/s1/session.lock")
+	assert.True(t, exists, "live session lock should be kept")
+	assert.Error(t, uc.Clear("/repo", []string{"sessions/s1/session.lock"}, false))
+}
+
+func TestFormat_ShowsStateAndReason(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	infos := []lock.Info{
+		{Path: "/repo/.claudex/doc_update.lock", PID: 42, Host: "devbox", CreatedAt: now.Add(-90 * time.Second)},
+		{Path: "/repo/.claudex/old.lock", PID: 7, CreatedAt: now.Add(-3 * time.Hour), Stale: true, Reason: "process 7 is not running"},
+	}
+
+	out := Format(infos, "/repo", now)
+
+	assert.Contains(t, out, ".claudex/doc_update.lock  pid 42@devbox  age 1m30s  live")
+	assert.Contains(t, out, ".claudex/old.lock  pid 7  age 3h0m0s  stale: process 7 is not running")
+	assert.Equal(t, "No locks held\n", Format(nil, "/repo", now))
+}
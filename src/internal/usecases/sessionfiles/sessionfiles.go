@@ -0,0 +1,179 @@
+// Package sessionfiles provides the usecase for reporting the files a
+// session changed (`claudex sessions files <name>`), from the change ledger
+// the PostToolUse hook keeps in the session folder.
+package sessionfiles
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"claudex/internal/services/ledger"
+	"claudex/internal/services/session"
+
+	"github.com/spf13/afero"
+)
+
+// State tells how a file compares with the session's last change to it
+type State string
+
+const (
+	// StateCurrent: the file still has the content the session left
+	StateCurrent State = "current"
+
+	// StateModified: the file changed after the session's last change
+	StateModified State = "modified since"
+
+	// StateDeleted: the session removed the file and it is still gone
+	StateDeleted State = "deleted"
+
+	// StateRemoved: the session wrote the file, which was removed later
+	StateRemoved State = "removed since"
+
+	// StateRecreated: the session removed the file, which exists again
+	StateRecreated State = "recreated since"
+
+	// StateDirectory: the change was to a directory, which has no hash
+	StateDirectory State = "directory"
+)
+
+// File is one changed file in the report
+type File struct {
+	Path    string    `json:"path"`
+	Changes int       `json:"changes"`
+	Tools   []string  `json:"tools"`
+	Agents  []string  `json:"agents"`
+	First   time.Time `json:"first"`
+	Last    time.Time `json:"last"`
+	Hash    string    `json:"hash,omitempty"`
+	State   State     `json:"state"`
+}
+
+// Report lists the files a session changed
+type Report struct {
+	Session string `json:"session"`
+	Files   []File `json:"files"`
+}
+
+// Changes counts the ledger entries behind the report
+func (r Report) Changes() int {
+	total := 0
+	for _, f := range r.Files {
+		total += f.Changes
+	}
+	return total
+}
+
+// Options controls the output
+type Options struct {
+	JSON bool
+}
+
+// UseCase reports the files changed by a session
+type UseCase struct {
+	fs afero.Fs
+}
+
+// New creates a new SessionFiles usecase
+func New(fs afero.Fs) *UseCase {
+	return &UseCase{fs: fs}
+}
+
+// Execute prints the report of the named session
+func (uc *UseCase) Execute(projectDir, name string, opts Options, out io.Writer) error {
+	report, err := uc.Report(projectDir, name)
+	if err != nil {
+		return err
+	}
+
+	if opts.JSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	fmt.Fprint(out, Format(report, projectDir))
+	return nil
+}
+
+// Report reads the named session's ledger and compares each file with its
+// current content
+func (uc *UseCase) Report(projectDir, name string) (Report, error) {
+	sessionPath, err := session.ResolveSessionFolder(uc.fs, projectDir, name)
+	if err != nil {
+		return Report{}, err
+	}
+	entries, err := ledger.Read(uc.fs, sessionPath)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to read %s: %w", ledger.Path(sessionPath), err)
+	}
+
+	report := Report{Session: filepath.Base(sessionPath), Files: []File{}}
+	for _, f := range ledger.Files(entries) {
+		report.Files = append(report.Files, File{
+			Path:    f.Path,
+			Changes: f.Changes,
+			Tools:   f.Tools,
+			Agents:  f.Agents,
+			First:   f.First,
+			Last:    f.Last,
+			Hash:    f.Hash,
+			State:   uc.state(f),
+		})
+	}
+	return report, nil
+}
+
+// state compares a file with the session's last change to it
+func (uc *UseCase) state(f ledger.File) State {
+	hash, exists := ledger.Hash(uc.fs, f.Path)
+	switch {
+	case f.Deleted && exists:
+		return StateRecreated
+	case f.Deleted:
+		return StateDeleted
+	case !exists:
+		return StateRemoved
+	case f.Hash == "" && hash == "":
+		return StateDirectory
+	case hash == f.Hash:
+		return StateCurrent
+	default:
+		return StateModified
+	}
+}
+
+// Format renders one line per file: path, change count, tools, agents, time
+// of the last change and state
+func Format(report Report, projectDir string) string {
+	if len(report.Files) == 0 {
+		return fmt.Sprintf("Session %s changed no files\n", report.Session)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Session %s: %s, %s\n\n", report.Session, plural(len(report.Files), "file"), plural(report.Changes(), "change"))
+	for _, f := range report.Files {
+		fmt.Fprintf(&b, "%s  %s  %s  by %s  last %s  %s\n",
+			relPath(projectDir, f.Path), plural(f.Changes, "change"), strings.Join(f.Tools, ","),
+			strings.Join(f.Agents, ","), f.Last.Local().Format("2006-01-02 15:04:05"), f.State)
+	}
+	return b.String()
+}
+
+// plural formats a count with its noun
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// relPath returns path relative to projectDir when it lies inside it
+func relPath(projectDir, path string) string {
+	if rel, err := filepath.Rel(projectDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
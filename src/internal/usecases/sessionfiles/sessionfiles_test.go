@@ -0,0 +1,106 @@
+package sessionfiles
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"claudex/internal/services/ledger"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sessionPath = "/repo/.claudex/sessions/fix-locks-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+
+// newSession records a session that wrote, edited and removed files, some of
+// which changed again afterwards
+func newSession(t *testing.T) afero.Fs {
+	t.Helper()
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
+	base := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+
+	write := func(path, content string) {
+		require.NoError(t, afero.WriteFile(fs, path, []byte(content), 0644))
+	}
+	record := func(minute int, call ledger.Call) {
+		call.CWD = "/repo"
+		call.Time = base.Add(time.Duration(minute) * time.Minute)
+		require.NoError(t, ledger.Append(fs, sessionPath, ledger.Entries(fs, call)))
+	}
+
+	write("/repo/lock.go", "v1")
+	record(0, ledger.Call{Tool: "Write", Input: map[string]interface{}{"file_path": "/repo/lock.go"}})
+	write("/repo/lock.go", "v2")
+	record(1, ledger.Call{Tool: "Edit", Input: map[string]interface{}{"file_path": "lock.go"}, AgentType: "Plan"})
+	write("/repo/notes.md", "draft")
+	record(2, ledger.Call{Tool: "Bash", Input: map[string]interface{}{"command": "echo draft > notes.md"}})
+	record(3, ledger.Call{Tool: "Bash", Input: map[string]interface{}{"command": "rm old.go"}})
+	write("/repo/gen.go", "generated")
+	record(4, ledger.Call{Tool: "Write", Input: map[string]interface{}{"file_path": "/repo/gen.go"}})
+
+	// After the session: notes.md edited by hand, gen.go removed
+	write("/repo/notes.md", "final")
+	require.NoError(t, fs.Remove("/repo/gen.go"))
+	return fs
+}
+
+func TestReport(t *testing.T) {
+	uc := New(newSession(t))
+
+	report, err := uc.Report("/repo", "fix-locks")
+	require.NoError(t, err)
+	assert.Equal(t, "fix-locks-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", report.Session)
+	assert.Equal(t, 5, report.Changes())
+
+	states := make(map[string]State)
+	for _, f := range report.Files {
+		states[f.Path] = f.State
+	}
+	assert.Equal(t, map[string]State{
+		"/repo/gen.go":   StateRemoved,
+		"/repo/lock.go":  StateCurrent,
+		"/repo/notes.md": StateModified,
+		"/repo/old.go":   StateDeleted,
+	}, states)
+
+	lock := report.Files[1]
+	assert.Equal(t, "/repo/lock.go", lock.Path)
+	assert.Equal(t, 2, lock.Changes)
+	assert.Equal(t, []string{"Write", "Edit"}, lock.Tools)
+	assert.Equal(t, []string{ledger.MainAgent, "Plan"}, lock.Agents)
+
+	_, err = uc.Report("/repo", "missing")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestExecute(t *testing.T) {
+	uc := New(newSession(t))
+
+	var out bytes.Buffer
+	require.NoError(t, uc.Execute("/repo", "fix-locks", Options{}, &out))
+	assert.Equal(t, "Session fix-locks-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee: 4 files, 5 changes\n\n"+
+		"gen.go  1 change  Write  by main  last 2026-10-18 12:04:00  removed since\n"+
+		"lock.go  2 changes  Write,Edit  by main,Plan  last 2026-10-18 12:01:00  current\n"+
+		"notes.md  1 change  Bash  by main  last 2026-10-18 12:02:00  modified since\n"+
+		"old.go  1 change  Bash  by main  last 2026-10-18 12:03:00  deleted\n", out.String())
+
+	out.Reset()
+	require.NoError(t, uc.Execute("/repo", "fix-locks", Options{JSON: true}, &out))
+	var report Report
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Len(t, report.Files, 4)
+	assert.Equal(t, StateDeleted, report.Files[3].State)
+}
+
+func TestExecute_NoChanges(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
+
+	var out bytes.Buffer
+	require.NoError(t, New(fs).Execute("/repo", "fix-locks", Options{}, &out))
+	assert.Equal(t, "Session fix-locks-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee changed no files\n", out.String())
+}
@@ -68,6 +68,10 @@ func Test_Execute_CreatesStructure(t *testing.T) {
 	testutil.AssertFileContains(t, h.FS, "/project/.claude/settings.local.json", "notification-hook.sh")
 	testutil.AssertFileContains(t, h.FS, "/project/.claude/settings.local.json", "session-end.sh")
 	testutil.AssertFileContains(t, h.FS, "/project/.claude/settings.local.json", "SessionEnd")
+	testutil.AssertFileContains(t, h.FS, "/project/.claude/settings.local.json", "session-start.sh")
+	testutil.AssertFileContains(t, h.FS, "/project/.claude/settings.local.json", "user-prompt-submit.sh")
+	testutil.AssertFileContains(t, h.FS, "/project/.claude/settings.local.json", "/stop.sh")
+	testutil.AssertFileContains(t, h.FS, "/project/.claude/settings.local.json", "pre-compact.sh")
 	testutil.AssertFileContains(t, h.FS, "/project/.claude/settings.local.json", "Notification")
 
 	// Verify - generated engineer profiles exist
@@ -61,6 +61,21 @@ func (uc *UseCase) Install() error {
 	return uc.hookSvc.Install()
 }
 
+// InstallPreCommit installs the pre-commit hook that updates docs for staged
+// changes and adds them to the commit being created. The post-commit hook is
+// installed too unless already present.
+func (uc *UseCase) InstallPreCommit() error {
+	if !uc.hookSvc.IsPreCommitInstalled() {
+		if err := uc.hookSvc.InstallPreCommit(); err != nil {
+			return err
+		}
+	}
+	if !uc.hookSvc.IsInstalled() {
+		return uc.hookSvc.Install()
+	}
+	return nil
+}
+
 // SaveDeclined saves the user's "never ask again" preference
 func (uc *UseCase) SaveDeclined() error {
 	prefs, _ := uc.prefSvc.Load() // Ignore error, start fresh if needed
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"claudex/internal/services/hooksetup"
 	"claudex/internal/services/preferences"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -12,14 +13,31 @@ import (
 
 // mockHookService is a mock implementation of hooksetup.Service
 type mockHookService struct {
-	isGitRepo  bool
-	isInstall  bool
-	installErr error
+	isGitRepo           bool
+	isInstall           bool
+	installErr          error
+	isPreCommit         bool
+	installCalled       bool
+	preCommitInstallErr error
+	preCommitCalled     bool
 }
 
-func (m *mockHookService) IsGitRepo() bool   { return m.isGitRepo }
-func (m *mockHookService) IsInstalled() bool { return m.isInstall }
-func (m *mockHookService) Install() error    { return m.installErr }
+func (m *mockHookService) IsGitRepo() bool            { return m.isGitRepo }
+func (m *mockHookService) IsInstalled() bool          { return m.isInstall }
+func (m *mockHookService) Install() error             { m.installCalled = true; return m.installErr }
+func (m *mockHookService) IsPreCommitInstalled() bool { return m.isPreCommit }
+func (m *mockHookService) InstallPreCommit() error {
+	m.preCommitCalled = true
+	return m.preCommitInstallErr
+}
+func (m *mockHookService) HooksDir() string                        { return "" }
+func (m *mockHookService) Manager() string                         { return "" }
+func (m *mockHookService) IsHookInstalled(name string) bool        { return false }
+func (m *mockHookService) InstallHook(name string) error           { return nil }
+func (m *mockHookService) UninstallHook(name string) (bool, error) { return false, nil }
+func (m *mockHookService) HookStatus(name string) hooksetup.HookStatus {
+	return hooksetup.HookStatus{Name: name}
+}
 
 // mockPrefService is a mock implementation of preferences.Service
 type mockPrefService struct {
@@ -126,6 +144,35 @@ func TestInstall_Error(t *testing.T) {
 	assert.Equal(t, expectedErr, err)
 }
 
+// TestInstallPreCommit_InstallsBothHooks verifies that the pre-commit mode
+// also installs the post-commit hook when it is missing
+func TestInstallPreCommit_InstallsBothHooks(t *testing.T) {
+	hookSvc := &mockHookService{}
+	uc := &UseCase{
+		hookSvc: hookSvc,
+		prefSvc: &mockPrefService{},
+	}
+
+	err := uc.InstallPreCommit()
+	require.NoError(t, err)
+	assert.True(t, hookSvc.preCommitCalled)
+	assert.True(t, hookSvc.installCalled)
+}
+
+// TestInstallPreCommit_SkipsInstalledHooks verifies hooks are not appended twice
+func TestInstallPreCommit_SkipsInstalledHooks(t *testing.T) {
+	hookSvc := &mockHookService{isPreCommit: true, isInstall: true}
+	uc := &UseCase{
+		hookSvc: hookSvc,
+		prefSvc: &mockPrefService{},
+	}
+
+	err := uc.InstallPreCommit()
+	require.NoError(t, err)
+	assert.False(t, hookSvc.preCommitCalled)
+	assert.False(t, hookSvc.installCalled)
+}
+
 // TestSaveDeclined_Success verifies that SaveDeclined persists the preference
 func TestSaveDeclined_Success(t *testing.T) {
 	prefSvc := &mockPrefService{}
@@ -9,11 +9,15 @@ import (
 	"os"
 	"path/filepath"
 
+	"claudex/internal/doc/docpatch"
 	"claudex/internal/doc/rangeupdater"
 	"claudex/internal/services/commander"
+	"claudex/internal/services/config"
+	"claudex/internal/services/docfiles"
 	"claudex/internal/services/doctracking"
 	"claudex/internal/services/env"
 	"claudex/internal/services/git"
+	"claudex/internal/services/jobs"
 	"claudex/internal/services/lock"
 	"claudex/internal/services/paths"
 
@@ -22,67 +26,233 @@ import (
 
 // UpdateDocsUseCase orchestrates the documentation update workflow
 type UpdateDocsUseCase struct {
-	fs  afero.Fs
-	cmd commander.Commander
-	env env.Environment
+	fs       afero.Fs
+	cmd      commander.Commander
+	env      env.Environment
+	docsCfg  config.Docs
+	lockOpts lock.Options
 }
 
 // New creates a new UpdateDocsUseCase instance with the given dependencies
-func New(fs afero.Fs, cmd commander.Commander, env env.Environment) *UpdateDocsUseCase {
+func New(fs afero.Fs, cmd commander.Commander, env env.Environment, docsCfg config.Docs, lockOpts lock.Options) *UpdateDocsUseCase {
 	return &UpdateDocsUseCase{
-		fs:  fs,
-		cmd: cmd,
-		env: env,
+		fs:       fs,
+		cmd:      cmd,
+		env:      env,
+		docsCfg:  docsCfg,
+		lockOpts: lockOpts,
 	}
 }
 
+// Mode selects which changes drive a documentation update
+type Mode string
+
+const (
+	// ModeCommits processes commits since the last update (post-commit hook)
+	ModeCommits Mode = "commits"
+
+	// ModeStaged processes files staged in the index
+	ModeStaged Mode = "staged"
+
+	// ModeWorktree processes uncommitted working tree changes, including untracked files
+	ModeWorktree Mode = "worktree"
+)
+
+// Options configures a documentation update run
+type Options struct {
+	// Mode selects the change source; empty means ModeCommits
+	Mode Mode
+
+	// AddDocs stages regenerated index.md files so they join the commit being
+	// created. Only valid with ModeStaged (pre-commit hook).
+	AddDocs bool
+
+	// DryRun previews the updates as a unified diff without changing any
+	// index.md file or tracking state
+	DryRun bool
+
+	// PatchFile, with DryRun, writes the diff to this file instead of stdout
+	PatchFile string
+}
+
 // Execute runs the documentation update workflow.
-// It computes changed files from git history, maps them to affected index.md
-// files, and invokes Claude to regenerate the documentation.
+// It computes changed files from git history (or from the index / working tree,
+// depending on opts.Mode), maps them to affected index.md files, and invokes
+// Claude to regenerate the documentation.
 //
 // Parameters:
 //   - projectDir: The project directory to update documentation for
+//   - opts: Change source and staging behavior
 //
 // Returns an error if the update fails.
-func (uc *UpdateDocsUseCase) Execute(projectDir string) error {
+func (uc *UpdateDocsUseCase) Execute(projectDir string, opts Options) error {
+	if opts.AddDocs && opts.Mode != ModeStaged {
+		return fmt.Errorf("--add-docs requires --staged")
+	}
+	if opts.AddDocs && opts.DryRun {
+		return fmt.Errorf("--add-docs cannot be combined with --dry-run")
+	}
+	if opts.PatchFile != "" && !opts.DryRun {
+		return fmt.Errorf("--patch requires --dry-run")
+	}
+
+	updater, err := uc.newUpdater(projectDir, func(config *rangeupdater.RangeUpdaterConfig) {
+		config.StageDocs = opts.AddDocs
+		config.DryRun = opts.DryRun
+	})
+	if err != nil {
+		return err
+	}
+
+	// Run update
+	var result *rangeupdater.UpdateResult
+	switch opts.Mode {
+	case ModeCommits, "":
+		result, err = updater.Run()
+	case ModeStaged:
+		result, err = updater.RunUncommitted(rangeupdater.ChangeSetStaged)
+	case ModeWorktree:
+		result, err = updater.RunUncommitted(rangeupdater.ChangeSetWorktree)
+	default:
+		return fmt.Errorf("unknown update mode: %s", opts.Mode)
+	}
+	if err != nil {
+		return fmt.Errorf("update failed: %w", err)
+	}
+
+	if opts.DryRun && result.Status == "success" {
+		return uc.outputPatch(projectDir, opts, result)
+	}
+
+	// Display result
+	displayResult(result)
+
+	return nil
+}
+
+// Apply applies a patch written by a dry run and commits the updated
+// index.md files. Only the files in the patch are committed.
+func (uc *UpdateDocsUseCase) Apply(projectDir, patchFile string) error {
+	updater, err := uc.newUpdater(projectDir, nil)
+	if err != nil {
+		return err
+	}
+
+	result, err := updater.ApplyPatch(patchFile)
+	if err != nil {
+		return fmt.Errorf("apply failed: %w", err)
+	}
+	if result.Status != "success" {
+		displayResult(result)
+		return nil
+	}
+
+	fmt.Printf("✓ Applied docs patch as %s\n", shortSHA(result.HeadSHA))
+	for _, file := range result.AffectedIndexes {
+		fmt.Printf("    - %s\n", file)
+	}
+	return nil
+}
+
+// newUpdater wires a range updater for projectDir; configure may adjust the
+// default configuration
+func (uc *UpdateDocsUseCase) newUpdater(projectDir string, configure func(*rangeupdater.RangeUpdaterConfig)) (*rangeupdater.RangeUpdater, error) {
+	jobTimeout, err := uc.docsCfg.JobTimeoutDuration()
+	if err != nil {
+		return nil, err
+	}
+
 	// Use .claudex directory for tracking state
 	sessionPath := filepath.Join(projectDir, paths.ClaudexDir)
 
 	// Create services
 	gitSvc := git.New(uc.cmd)
-	lockSvc := lock.New(uc.fs)
+	lockSvc := lock.NewWithOptions(uc.fs, uc.lockOpts)
 	trackingSvc := doctracking.New(uc.fs, sessionPath)
 
 	// Configure updater
 	config := rangeupdater.RangeUpdaterConfig{
-		SessionPath:   sessionPath,
-		DefaultBranch: "main",
-		SkipPatterns:  []string{"*.md", "docs/**"},
+		SessionPath:     sessionPath,
+		DefaultBranch:   uc.docsCfg.DefaultBranch,
+		DocFilenames:    docfiles.New(uc.docsCfg.Filenames),
+		SkipPatterns:    []string{"*.md", "docs/**"},
+		MaxJobs:         uc.docsCfg.MaxJobs,
+		JobTimeout:      jobTimeout,
+		DiffTokenBudget: uc.docsCfg.DiffTokenBudget,
+		AutoCommit:      uc.docsCfg.AutoCommit,
+		CommitMessage:   uc.docsCfg.CommitMessage,
+	}
+	if configure != nil {
+		configure(&config)
 	}
 
-	// Create updater
-	updater := rangeupdater.New(
+	return rangeupdater.New(
 		config,
 		gitSvc,
 		lockSvc,
 		trackingSvc,
-		uc.cmd,
+		jobs.NewExecutor(),
 		uc.fs,
 		uc.env,
-	)
+	), nil
+}
 
-	// Run update
-	result, err := updater.Run()
+// outputPatch renders the proposals of a dry run to stdout or opts.PatchFile
+func (uc *UpdateDocsUseCase) outputPatch(projectDir string, opts Options, result *rangeupdater.UpdateResult) error {
+	mode := opts.Mode
+	if mode == "" {
+		mode = ModeCommits
+	}
+	header := docpatch.Header{
+		Mode:   string(mode),
+		Branch: result.Branch,
+		Head:   result.HeadSHA,
+		Range:  result.ProcessedRange,
+	}
+
+	var changes []docpatch.FileChange
+	for _, p := range result.Proposals {
+		rel, err := filepath.Rel(projectDir, p.IndexPath)
+		if err != nil {
+			rel = p.IndexPath
+		}
+		changes = append(changes, docpatch.FileChange{
+			Path:     filepath.ToSlash(rel),
+			Original: p.Original,
+			Proposed: p.Proposed,
+		})
+	}
+
+	patch, err := docpatch.Render(header, changes)
 	if err != nil {
-		return fmt.Errorf("update failed: %w", err)
+		return err
+	}
+	if patch == "" {
+		fmt.Fprintf(os.Stderr, "○ No documentation changes proposed (%d index.md file(s) checked)\n", len(result.AffectedIndexes))
+		return nil
 	}
 
-	// Display result
-	displayResult(result)
+	if opts.PatchFile == "" {
+		fmt.Print(patch)
+		return nil
+	}
 
+	if err := afero.WriteFile(uc.fs, opts.PatchFile, []byte(patch), 0644); err != nil {
+		return fmt.Errorf("failed to write patch: %w", err)
+	}
+	fmt.Printf("✓ Wrote proposed changes for %d index.md file(s) to %s\n", len(changes), opts.PatchFile)
+	fmt.Printf("  Review it, then run: claudex docs update --apply %s\n", opts.PatchFile)
 	return nil
 }
 
+// shortSHA returns the first 7 characters of a SHA
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
 // displayResult prints the update result to stdout
 func displayResult(result *rangeupdater.UpdateResult) {
 	switch result.Status {
@@ -103,7 +273,12 @@ func displayResult(result *rangeupdater.UpdateResult) {
 				}
 				fmt.Printf("    - %s\n", rel)
 			}
+			if result.DocsCommit != "" {
+				fmt.Printf("  Committed as %s\n", shortSHA(result.DocsCommit))
+			}
 		}
+		displayFallback(result)
+		displayFailed(result)
 
 	case "skipped":
 		fmt.Printf("○ Documentation update skipped\n")
@@ -113,6 +288,7 @@ func displayResult(result *rangeupdater.UpdateResult) {
 		if result.ProcessedRange != "" {
 			fmt.Printf("  Range: %s\n", result.ProcessedRange)
 		}
+		displayFallback(result)
 
 	case "locked":
 		fmt.Printf("⊙ Documentation update already in progress\n")
@@ -129,3 +305,21 @@ func displayResult(result *rangeupdater.UpdateResult) {
 		os.Exit(1)
 	}
 }
+
+// displayFailed prints the indexes whose Claude job failed, if any
+func displayFailed(result *rangeupdater.UpdateResult) {
+	if len(result.FailedIndexes) == 0 {
+		return
+	}
+	fmt.Printf("  %d update(s) failed (see 'claudex docs jobs'):\n", len(result.FailedIndexes))
+	for _, idx := range result.FailedIndexes {
+		fmt.Printf("    - %s\n", idx)
+	}
+}
+
+// displayFallback prints which merge-base fallback was used, if any
+func displayFallback(result *rangeupdater.UpdateResult) {
+	if result.Fallback != nil {
+		fmt.Printf("  Base commit unreachable, used %s\n", result.Fallback)
+	}
+}
@@ -0,0 +1,407 @@
+// Package why provides the usecase for finding the sessions that changed a
+// file (`claudex why <path>[:line]`): it searches the change ledgers of all
+// sessions and, for each change, points at the session overview and the
+// transcript moment, with the assistant message that led to it.
+package why
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"claudex/internal/doc"
+	"claudex/internal/services/env"
+	"claudex/internal/services/ledger"
+	"claudex/internal/services/paths"
+	"claudex/internal/services/session"
+
+	"github.com/spf13/afero"
+)
+
+// overviewFile is the session overview maintained by the doc updater
+const overviewFile = "session-overview.md"
+
+// maxMessage bounds the message shown per moment in text output, in bytes
+const maxMessage = 600
+
+// minLineText is the shortest line worth matching against changes; shorter
+// lines ("}", "") appear in nearly every edit
+const minLineText = 4
+
+// Moment is one change to the file and the reasoning behind it
+type Moment struct {
+	Session  string    `json:"session"`
+	Overview string    `json:"overview,omitempty"`
+	Time     time.Time `json:"time"`
+	Tool     string    `json:"tool"`
+	Agent    string    `json:"agent"`
+	Command  string    `json:"command,omitempty"`
+
+	// Transcript and TranscriptLine locate the tool call, or the message
+	// when the call itself was not found
+	Transcript     string `json:"transcript,omitempty"`
+	TranscriptLine int    `json:"transcript_line,omitempty"`
+
+	// Message is the last assistant message before the tool call
+	Message string `json:"message,omitempty"`
+
+	// WroteLine reports whether the change's new content holds the line
+	WroteLine bool `json:"wrote_line,omitempty"`
+}
+
+// Report lists the moments that changed a file, newest first
+type Report struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line,omitempty"`
+	LineText string `json:"line_text,omitempty"`
+
+	// LineMatched is true when Moments holds only the changes that wrote the
+	// line; otherwise it holds every change to the file
+	LineMatched bool     `json:"line_matched"`
+	Moments     []Moment `json:"moments"`
+}
+
+// Options controls the output
+type Options struct {
+	JSON bool
+}
+
+// UseCase finds the sessions and transcript moments behind a file
+type UseCase struct {
+	fs  afero.Fs
+	env env.Environment
+
+	// transcripts caches parsed assistant messages per transcript
+	transcripts map[string][]doc.TranscriptEntry
+}
+
+// New creates a new Why usecase
+func New(fs afero.Fs, environ env.Environment) *UseCase {
+	return &UseCase{fs: fs, env: environ, transcripts: make(map[string][]doc.TranscriptEntry)}
+}
+
+// ParseTarget splits "path[:line]"; line is 0 when absent
+func ParseTarget(target string) (string, int) {
+	i := strings.LastIndexByte(target, ':')
+	if i <= 0 {
+		return target, 0
+	}
+	line, err := strconv.Atoi(target[i+1:])
+	if err != nil || line <= 0 {
+		return target, 0
+	}
+	return target[:i], line
+}
+
+// Execute prints the moments behind target, a path relative to projectDir
+// or absolute, optionally followed by :line
+func (uc *UseCase) Execute(projectDir, target string, opts Options, out io.Writer) error {
+	path, line := ParseTarget(target)
+	report, err := uc.Find(projectDir, path, line)
+	if err != nil {
+		return err
+	}
+
+	if opts.JSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	fmt.Fprint(out, Format(report, projectDir))
+	return nil
+}
+
+// Find searches every session's ledger for changes to path. With a line,
+// only the changes whose new content holds that line are kept, unless none
+// does.
+func (uc *UseCase) Find(projectDir, path string, line int) (Report, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectDir, path)
+	}
+	report := Report{Path: filepath.Clean(path), Line: line, Moments: []Moment{}}
+
+	if line > 0 {
+		text, err := uc.lineText(report.Path, line)
+		if err != nil {
+			return Report{}, err
+		}
+		report.LineText = text
+	}
+
+	sessionsDir := filepath.Join(projectDir, paths.SessionsDir)
+	dirs, err := afero.ReadDir(uc.fs, sessionsDir)
+	if err != nil {
+		return report, nil
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		sessionPath := filepath.Join(sessionsDir, dir.Name())
+		entries, err := ledger.Read(uc.fs, sessionPath)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.Path == report.Path {
+				report.Moments = append(report.Moments, uc.moment(sessionPath, e, report.LineText))
+			}
+		}
+	}
+
+	if line > 0 && attributable(report.LineText) {
+		var wrote []Moment
+		for _, m := range report.Moments {
+			if m.WroteLine {
+				wrote = append(wrote, m)
+			}
+		}
+		if len(wrote) > 0 {
+			report.Moments = wrote
+			report.LineMatched = true
+		}
+	}
+
+	sort.SliceStable(report.Moments, func(i, j int) bool {
+		return report.Moments[i].Time.After(report.Moments[j].Time)
+	})
+	return report, nil
+}
+
+// attributable reports whether a line is distinctive enough to match
+// against the content of changes
+func attributable(line string) bool {
+	return len(strings.TrimSpace(line)) >= minLineText
+}
+
+// lineText returns the 1-indexed line of a file
+func (uc *UseCase) lineText(path string, line int) (string, error) {
+	data, err := afero.ReadFile(uc.fs, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if line > len(lines) {
+		return "", fmt.Errorf("%s has %d lines", path, len(lines))
+	}
+	return lines[line-1], nil
+}
+
+// moment locates a ledger entry's tool call and the message before it
+func (uc *UseCase) moment(sessionPath string, e ledger.Entry, lineText string) Moment {
+	m := Moment{
+		Session: filepath.Base(sessionPath),
+		Time:    e.Time,
+		Tool:    e.Tool,
+		Agent:   e.Agent(),
+		Command: e.Command,
+	}
+	if exists, _ := afero.Exists(uc.fs, filepath.Join(sessionPath, overviewFile)); exists {
+		m.Overview = filepath.Join(sessionPath, overviewFile)
+	}
+
+	transcript, use := uc.findToolUse(sessionPath, e)
+	if transcript == "" {
+		return m
+	}
+	m.Transcript = transcript
+
+	var input map[string]interface{}
+	if use != nil {
+		m.TranscriptLine = use.Line
+		input = use.Input
+	}
+	if message := uc.message(transcript, use, e.Time); message != nil {
+		m.Message = strings.Join(message.Content, "\n\n")
+		if use == nil {
+			m.TranscriptLine = message.Line
+		}
+	}
+	trimmed := strings.TrimSpace(lineText)
+	m.WroteLine = trimmed != "" && strings.Contains(newContent(input), trimmed)
+	return m
+}
+
+// findToolUse returns the transcript holding the entry's tool call, and the
+// call when it is found. A subagent's calls are looked up in its own
+// transcript first; entries recorded without a transcript fall back to the
+// Claude session ID in the session folder name.
+func (uc *UseCase) findToolUse(sessionPath string, e ledger.Entry) (string, *doc.ToolUse) {
+	transcript := e.Transcript
+	if transcript == "" {
+		transcript = uc.claudeTranscript(sessionPath)
+	}
+	if transcript == "" {
+		return "", nil
+	}
+
+	var candidates []string
+	if e.AgentID != "" {
+		base := "agent-" + e.AgentID + ".jsonl"
+		candidates = append(candidates,
+			filepath.Join(strings.TrimSuffix(transcript, ".jsonl"), "subagents", base),
+			filepath.Join(filepath.Dir(transcript), base))
+	}
+	candidates = append(candidates, transcript)
+
+	for _, candidate := range candidates {
+		use, err := doc.FindToolUse(uc.fs, candidate, e.ToolUseID)
+		if err == nil && use != nil {
+			return candidate, use
+		}
+	}
+	if exists, _ := afero.Exists(uc.fs, transcript); !exists {
+		return "", nil
+	}
+	return transcript, nil
+}
+
+// claudeTranscript finds the Claude Code transcript of a session folder
+// named after its Claude session ID, under the Claude config directory
+func (uc *UseCase) claudeTranscript(sessionPath string) string {
+	id := session.ExtractClaudeSessionID(filepath.Base(sessionPath))
+	if id == "" {
+		return ""
+	}
+	configDir := uc.env.Get("CLAUDE_CONFIG_DIR")
+	if configDir == "" {
+		home := uc.env.Get("HOME")
+		if home == "" {
+			return ""
+		}
+		configDir = filepath.Join(home, ".claude")
+	}
+	matches, err := afero.Glob(uc.fs, filepath.Join(configDir, "projects", "*", id+".jsonl"))
+	if err != nil || len(matches) == 0 {
+		return ""
+	}
+	return matches[0]
+}
+
+// message returns the last assistant message before the tool call, or
+// before the change time when the call was not found
+func (uc *UseCase) message(transcript string, use *doc.ToolUse, at time.Time) *doc.TranscriptEntry {
+	entries, ok := uc.transcripts[transcript]
+	if !ok {
+		entries, _, _ = doc.ParseTranscript(uc.fs, transcript, 1)
+		uc.transcripts[transcript] = entries
+	}
+
+	var last *doc.TranscriptEntry
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Type != "assistant_message" {
+			continue
+		}
+		if use != nil {
+			if entry.Line > use.Line {
+				break
+			}
+		} else if ts, err := time.Parse(time.RFC3339, entry.Timestamp); err != nil || ts.After(at) {
+			continue
+		}
+		last = entry
+	}
+	return last
+}
+
+// newContent returns the text a file tool call wrote: Write content, Edit
+// and MultiEdit replacement strings, the new notebook cell source, or the
+// Bash command
+func newContent(input map[string]interface{}) string {
+	var parts []string
+	for _, key := range []string{"content", "new_string", "new_source", "command"} {
+		if s, ok := input[key].(string); ok {
+			parts = append(parts, s)
+		}
+	}
+	if edits, ok := input["edits"].([]interface{}); ok {
+		for _, edit := range edits {
+			if fields, ok := edit.(map[string]interface{}); ok {
+				if s, ok := fields["new_string"].(string); ok {
+					parts = append(parts, s)
+				}
+			}
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Format renders the moments, newest first, each with its session overview,
+// transcript location and the message that led to it
+func Format(report Report, projectDir string) string {
+	var b strings.Builder
+	target := relPath(projectDir, report.Path)
+	if report.Line > 0 {
+		fmt.Fprintf(&b, "%s:%d: %s\n", target, report.Line, strings.TrimSpace(report.LineText))
+	}
+	if len(report.Moments) == 0 {
+		fmt.Fprintf(&b, "No recorded session changed %s\n", target)
+		return b.String()
+	}
+	switch {
+	case report.Line > 0 && !attributable(report.LineText):
+		fmt.Fprintf(&b, "Line %d is too short to attribute; showing every change to %s\n", report.Line, target)
+	case report.Line > 0 && !report.LineMatched:
+		fmt.Fprintf(&b, "No recorded change wrote line %d; showing every change to %s\n", report.Line, target)
+	}
+
+	for _, m := range report.Moments {
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "%s  %s  %s by %s\n", m.Time.Local().Format("2006-01-02 15:04:05"), m.Session, m.Tool, m.Agent)
+		if m.Command != "" {
+			fmt.Fprintf(&b, "  command:    %s\n", firstLine(m.Command))
+		}
+		if m.Overview != "" {
+			fmt.Fprintf(&b, "  overview:   %s\n", relPath(projectDir, m.Overview))
+		}
+		switch {
+		case m.Transcript != "" && m.TranscriptLine > 0:
+			fmt.Fprintf(&b, "  transcript: %s:%d\n", m.Transcript, m.TranscriptLine)
+		case m.Transcript != "":
+			fmt.Fprintf(&b, "  transcript: %s\n", m.Transcript)
+		default:
+			b.WriteString("  transcript: not found\n")
+		}
+		if m.Message != "" {
+			for _, line := range strings.Split(truncate(m.Message), "\n") {
+				fmt.Fprintf(&b, "  > %s\n", line)
+			}
+		}
+	}
+	return b.String()
+}
+
+// truncate bounds a message for text output
+func truncate(message string) string {
+	message = strings.TrimSpace(message)
+	if len(message) <= maxMessage {
+		return message
+	}
+	cut := strings.LastIndexAny(message[:maxMessage], " \n")
+	if cut <= 0 {
+		cut = maxMessage
+	}
+	return strings.TrimRight(message[:cut], " \n") + " ..."
+}
+
+// firstLine returns the first line of a command, marking the rest as cut
+func firstLine(command string) string {
+	if i := strings.IndexByte(command, '\n'); i >= 0 {
+		return command[:i] + " ..."
+	}
+	return command
+}
+
+// relPath returns path relative to projectDir when it lies inside it
+func relPath(projectDir, path string) string {
+	if rel, err := filepath.Rel(projectDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,189 @@
+package why
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"claudex/internal/services/ledger"
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	lockSession = "/repo/.claudex/sessions/fix-locks-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+	docsSession = "/repo/.claudex/sessions/docs-pass"
+	transcript  = "/home/u/.claude/projects/-repo/aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee.jsonl"
+)
+
+var base = time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+
+// newProject sets up two sessions that changed lock.go: fix-locks through
+// the main agent and a Plan subagent (with transcripts), docs-pass with a
+// Bash command and no transcript
+func newProject(t *testing.T) *testutil.TestHarness {
+	t.Helper()
+	h := testutil.NewTestHarness()
+	h.Env.Set("HOME", "/home/u")
+	h.WriteFile("/repo/lock.go", "package lock\n\nfunc alive(pid int) bool {\n\treturn signal(pid) == nil\n}\n}\n")
+	h.WriteFile(lockSession+"/session-overview.md", "# Fix locks\n")
+	h.CreateDir(docsSession)
+
+	h.WriteFile(transcript, `{"type":"user","timestamp":"2026-10-18T10:00:00Z","message":{"content":[{"type":"text","text":"Fix stale locks"}]}}
+{"type":"assistant","timestamp":"2026-10-18T10:00:10Z","message":{"content":[{"type":"text","text":"Stale locks survive crashes.\nI'll check PID liveness with signal 0."}]}}
+{"type":"assistant","timestamp":"2026-10-18T10:00:20Z","message":{"content":[{"type":"tool_use","id":"toolu_main","name":"Write","input":{"file_path":"/repo/lock.go","content":"func alive(pid int) bool {\n\treturn signal(pid) == nil\n}"}}]}}
+{"type":"assistant","timestamp":"2026-10-18T10:00:30Z","message":{"content":[{"type":"text","text":"Now delegating the docs."}]}}
+`)
+	h.WriteFile("/home/u/.claude/projects/-repo/aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee/subagents/agent-a1.jsonl",
+		`{"type":"assistant","timestamp":"2026-10-18T10:05:00Z","message":{"content":[{"type":"text","text":"Adding the package clause."}]}}
+{"type":"assistant","timestamp":"2026-10-18T10:05:10Z","message":{"content":[{"type":"tool_use","id":"toolu_sub","name":"Edit","input":{"file_path":"/repo/lock.go","old_string":"","new_string":"package lock"}}]}}
+`)
+
+	record := func(sessionPath string, e ledger.Entry) {
+		e.Path = "/repo/lock.go"
+		require.NoError(t, ledger.Append(h.FS, sessionPath, []ledger.Entry{e}))
+	}
+	record(lockSession, ledger.Entry{Time: base, Tool: "Write", ToolUseID: "toolu_main", Transcript: transcript})
+	record(lockSession, ledger.Entry{Time: base.Add(5 * time.Minute), Tool: "Edit", ToolUseID: "toolu_sub",
+		AgentID: "a1", AgentType: "Plan", Transcript: transcript})
+	record(docsSession, ledger.Entry{Time: base.Add(10 * time.Minute), Tool: "Bash", Command: "gofmt -w lock.go > lock.go"})
+	require.NoError(t, ledger.Append(h.FS, docsSession, []ledger.Entry{{Time: base, Tool: "Write", Path: "/repo/other.go"}}))
+	return h
+}
+
+func TestParseTarget(t *testing.T) {
+	path, line := ParseTarget("internal/lock.go:42")
+	assert.Equal(t, "internal/lock.go", path)
+	assert.Equal(t, 42, line)
+
+	path, line = ParseTarget("/repo/lock.go")
+	assert.Equal(t, "/repo/lock.go", path)
+	assert.Zero(t, line)
+
+	path, line = ParseTarget("c:weird:name")
+	assert.Equal(t, "c:weird:name", path)
+	assert.Zero(t, line)
+}
+
+func TestFind_AllChanges(t *testing.T) {
+	h := newProject(t)
+	uc := New(h.FS, h.Env)
+
+	report, err := uc.Find("/repo", "lock.go", 0)
+	require.NoError(t, err)
+	require.Len(t, report.Moments, 3)
+
+	// Newest first
+	docs, sub, main := report.Moments[0], report.Moments[1], report.Moments[2]
+	assert.Equal(t, "docs-pass", docs.Session)
+	assert.Empty(t, docs.Overview)
+	assert.Empty(t, docs.Transcript, "no transcript and no Claude session ID")
+	assert.Equal(t, "gofmt -w lock.go > lock.go", docs.Command)
+
+	assert.Equal(t, "Plan", sub.Agent)
+	assert.Equal(t, "/home/u/.claude/projects/-repo/aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee/subagents/agent-a1.jsonl", sub.Transcript)
+	assert.Equal(t, 2, sub.TranscriptLine)
+	assert.Equal(t, "Adding the package clause.", sub.Message)
+
+	assert.Equal(t, ledger.MainAgent, main.Agent)
+	assert.Equal(t, lockSession+"/session-overview.md", main.Overview)
+	assert.Equal(t, transcript, main.Transcript)
+	assert.Equal(t, 3, main.TranscriptLine)
+	assert.Equal(t, "Stale locks survive crashes.\nI'll check PID liveness with signal 0.", main.Message)
+
+	// Files no session changed
+	report, err = uc.Find("/repo", "/repo/untouched.go", 0)
+	require.NoError(t, err)
+	assert.Empty(t, report.Moments)
+}
+
+func TestFind_Line(t *testing.T) {
+	h := newProject(t)
+	uc := New(h.FS, h.Env)
+
+	report, err := uc.Find("/repo", "lock.go", 4)
+	require.NoError(t, err)
+	assert.True(t, report.LineMatched)
+	require.Len(t, report.Moments, 1)
+	assert.Equal(t, "Write", report.Moments[0].Tool)
+	assert.True(t, report.Moments[0].WroteLine)
+
+	report, err = uc.Find("/repo", "lock.go", 1)
+	require.NoError(t, err)
+	require.Len(t, report.Moments, 1)
+	assert.Equal(t, "Plan", report.Moments[0].Agent)
+
+	// A line no change wrote, or too short to tell, lists every change
+	report, err = uc.Find("/repo", "lock.go", 6)
+	require.NoError(t, err)
+	assert.False(t, report.LineMatched)
+	assert.Len(t, report.Moments, 3)
+
+	_, err = uc.Find("/repo", "lock.go", 99)
+	assert.ErrorContains(t, err, "has 6 lines")
+}
+
+func TestFind_TranscriptFromClaudeSessionID(t *testing.T) {
+	h := newProject(t)
+	// An entry recorded without its transcript, and no tool use ID: the
+	// message is found by time
+	require.NoError(t, afero.WriteFile(h.FS, lockSession+"/"+ledger.FileName, nil, 0644))
+	require.NoError(t, ledger.Append(h.FS, lockSession, []ledger.Entry{
+		{Time: time.Date(2026, 10, 18, 10, 0, 25, 0, time.UTC), Tool: "Write", Path: "/repo/lock.go"},
+	}))
+
+	report, err := New(h.FS, h.Env).Find("/repo", "lock.go", 0)
+	require.NoError(t, err)
+	require.Len(t, report.Moments, 2)
+	m := report.Moments[0]
+	if m.Session == "docs-pass" {
+		m = report.Moments[1]
+	}
+	assert.Equal(t, transcript, m.Transcript)
+	assert.Equal(t, 2, m.TranscriptLine)
+	assert.Contains(t, m.Message, "PID liveness")
+}
+
+func TestExecute(t *testing.T) {
+	h := newProject(t)
+	uc := New(h.FS, h.Env)
+
+	var out bytes.Buffer
+	require.NoError(t, uc.Execute("/repo", "lock.go:1", Options{}, &out))
+	assert.Equal(t, "lock.go:1: package lock\n\n"+
+		"2026-10-18 12:05:00  fix-locks-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee  Edit by Plan\n"+
+		"  overview:   .claudex/sessions/fix-locks-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee/session-overview.md\n"+
+		"  transcript: /home/u/.claude/projects/-repo/aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee/subagents/agent-a1.jsonl:2\n"+
+		"  > Adding the package clause.\n", out.String())
+
+	out.Reset()
+	require.NoError(t, uc.Execute("/repo", "lock.go:6", Options{}, &out))
+	assert.Contains(t, out.String(), "Line 6 is too short to attribute; showing every change to lock.go\n")
+	assert.Contains(t, out.String(), "  command:    gofmt -w lock.go > lock.go\n  transcript: not found\n")
+
+	out.Reset()
+	require.NoError(t, uc.Execute("/repo", "other.go", Options{JSON: true}, &out))
+	var report Report
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	require.Len(t, report.Moments, 1)
+	assert.Equal(t, "docs-pass", report.Moments[0].Session)
+
+	out.Reset()
+	require.NoError(t, uc.Execute("/repo", "new.go", Options{}, &out))
+	assert.Equal(t, "No recorded session changed new.go\n", out.String())
+}
+
+func TestTruncate(t *testing.T) {
+	long := ""
+	for len(long) < 2*maxMessage {
+		long += "word "
+	}
+	cut := truncate(long)
+	assert.LessOrEqual(t, len(cut), maxMessage+4)
+	assert.Equal(t, " ...", cut[len(cut)-4:])
+	assert.Equal(t, "short", truncate("  short\n"))
+}